@@ -0,0 +1,151 @@
+package barcode
+
+import (
+	"image"
+
+	"github.com/boombuler/barcode"
+)
+
+// autoHeightMaxMM is the tallest label AutoHeight produces. Inputs are
+// validated and laid out at this height before their own height is known.
+const autoHeightMaxMM = 1000.0
+
+// autoHeightMinBarMM is the shortest bar height AutoHeight gives linear
+// symbols, a quarter inch as recommended for Code128
+const autoHeightMinBarMM = 6.35
+
+// eanHeightPercent is the bar height of EAN-13 symbols on AutoHeight labels
+// relative to their width, the proportions of the nominal 22.85 by 31.35mm
+// symbol
+const eanHeightPercent = 73
+
+// applyAutoHeight sets the height of an AutoHeight label to fit its barcode
+// and text stack within the margins, and asks for the label length in ZPL so
+// printers loaded with continuous media feed exactly that much. The barcode
+// is sized from the label width alone.
+func applyAutoHeight(input BarcodeInput) (BarcodeInput, error) {
+	if !input.AutoHeight {
+		return input, nil
+	}
+	if err := validateAutoHeight(input); err != nil {
+		return BarcodeInput{}, err
+	}
+
+	sized := input
+	sized.Height = autoHeightMaxMM
+	if err := validateInput(sized); err != nil {
+		return BarcodeInput{}, err
+	}
+	bc, err := encodeBarcode(sized)
+	if err != nil {
+		return BarcodeInput{}, err
+	}
+	height, err := autoLabelHeight(sized, bc)
+	if err != nil {
+		return BarcodeInput{}, err
+	}
+	input.Height = float64(height) * 25.4 / float64(input.Dpi)
+	input.ZPL.SetLabelLength = true
+	return input, nil
+}
+
+// autoLabelHeight returns the label height in pixels at the printer DPI that
+// holds the barcode and the text around it within the margins. The barcode
+// and its text are drawn on a label tall enough for any text stack, and the
+// height is taken from the ink above and below the barcode, since glyphs
+// reach beyond the rows they are stacked in. The barcode is centered
+// together with its rows as layoutLabel places it.
+func autoLabelHeight(input BarcodeInput, bc barcode.Barcode) (int, error) {
+	layout, err := layoutLabel(input, bc, input.Dpi)
+	if err != nil {
+		return 0, err
+	}
+	barcodeHeight := layout.barcode.Bounds().Dy()
+
+	// Absolute lines keep their place whatever the height
+	var textLines []TextLine
+	for _, textLine := range input.TextLines {
+		if textLine.Position != TextPositionAbsolute {
+			textLines = append(textLines, textLine)
+		}
+	}
+	input.TextLines = textLines
+	stacked := labelTextLines(input)
+	stackHeight := 0
+	for _, row := range layoutTextRows(stacked, textLineHeight(input.Dpi, labelFontScale(input))) {
+		stackHeight += int(row.height)
+	}
+
+	margin := labelMargin(input)
+	trial := input
+	trial.Height = float64(barcodeHeight+stackHeight*3+margin*2) * 25.4 / float64(input.Dpi)
+	img, barcodeRect, err := renderLabel(trial, bc, input.Dpi)
+	if err != nil {
+		return 0, err
+	}
+	if err := renderTextLines(img, trial, barcodeRect, input.Dpi); err != nil {
+		return 0, err
+	}
+	top, bottom := inkRowSpan(img)
+	above, below := max(0, barcodeRect.Min.Y-top), max(0, bottom-barcodeRect.Max.Y)
+
+	shift := calculateTextBlockShift(stacked, input.Dpi, labelFontScale(input))
+	offset := max(margin+above-shift, margin+below+shift)
+	return barcodeHeight + offset*2, nil
+}
+
+// inkRowSpan returns the first row of img with a pixel that is not white and
+// the row after the last
+func inkRowSpan(img *image.RGBA) (int, int) {
+	bounds := img.Bounds()
+	top, bottom := bounds.Max.Y, bounds.Min.Y
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if c := img.RGBAAt(x, y); c.R != 255 || c.G != 255 || c.B != 255 {
+				top, bottom = min(top, y), y+1
+				break
+			}
+		}
+	}
+	return top, bottom
+}
+
+// autoHeightBarcodeSize returns the barcode size for an AutoHeight label,
+// which depends on the label width only. EAN-13 symbols keep their nominal
+// proportions and other linear symbols are 15% of their width tall, at
+// least autoHeightMinBarMM; IMb and Swiss QR keep their fixed
+// sizes, QR codes span the width, and stacked symbols and images take the
+// height they need at the full width.
+func autoHeightBarcodeSize(input BarcodeInput, labelWidth int) image.Point {
+	margin := labelMargin(input)
+	width := labelWidth - margin*2
+	tallest := mmToPixels(autoHeightMaxMM, input.Dpi)
+	switch input.BarcodeType {
+	case BarcodeTypeISBN, BarcodeTypeISSN:
+		return image.Pt(width, width*eanHeightPercent/100)
+	case BarcodeTypeCode128, BarcodeTypeGS1128, BarcodeTypeITF,
+		BarcodeTypeTelepen, BarcodeTypeTelepenNumeric, BarcodeTypeDataBarOmni, BarcodeTypeDataBarExpanded:
+		return image.Pt(width, max(width*15/100, mmToPixels(autoHeightMinBarMM, input.Dpi)))
+	case BarcodeTypeIMb:
+		return calculateIMbSize(input.Dpi, labelWidth, tallest, margin)
+	case BarcodeTypeDataBarExpandedStacked, BarcodeTypeImage:
+		return image.Pt(width, tallest)
+	case BarcodeTypeSwissQR:
+		size := min(mmToPixels(swissQRSizeMM, input.Dpi), width)
+		return image.Pt(size, size)
+	default:
+		return image.Pt(width, width)
+	}
+}
+
+// validateAutoHeight ensures the barcode of an AutoHeight label is laid out
+// across the label, where its height follows from the width
+func validateAutoHeight(input BarcodeInput) error {
+	if input.BarcodeRotation%180 != 0 {
+		return errorf(ErrInvalidInput, "invalid auto height: a barcode rotated %d degrees runs along the label height. Set Height instead", input.BarcodeRotation)
+	}
+	if input.BarcodePlacement.isSet() {
+		return errorf(ErrInvalidInput, "invalid auto height: BarcodePlacement fixes the barcode position. Set Height instead")
+	}
+	return nil
+}
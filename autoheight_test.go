@@ -0,0 +1,86 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateBarcode_AutoHeight verifies the label is as tall as the
+// barcode and its text within the margins, and reports its height
+func TestGenerateBarcode_AutoHeight(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "RCPT-000123",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       72,
+		Height:      200,
+		Dpi:         203,
+		AutoHeight:  true,
+		TextLines: []TextLine{
+			{Text: "THANK YOU", Position: TextPositionAbove, Size: TextSizeLarge},
+			{Text: "RCPT-000123", Position: TextPositionBelow, Size: TextSizeSmall},
+			{Text: "2026-10-15 14:02", Position: TextPositionBelow, Size: TextSizeSmall},
+		},
+		OutputFormats: []OutputFormat{OutputFormatZPL},
+	}
+	img, layout, err := GenerateBarcodeImage(input)
+	require.NoError(t, err)
+	assert.Equal(t, mmToPixels(72, 203), img.Bounds().Dx())
+	assert.Less(t, img.Bounds().Dy(), mmToPixels(40, 203), "the label fits its content, whatever Height says")
+	assert.Equal(t, max(img.Bounds().Dx()-labelMarginPixels*2, 0)*15/100, layout.BarcodeRect.Dy())
+
+	top, bottom := inkRowSpan(img.(*image.RGBA))
+	assert.GreaterOrEqual(t, top, labelMarginPixels)
+	assert.LessOrEqual(t, bottom, img.Bounds().Dy()-labelMarginPixels)
+	assert.True(t, top == labelMarginPixels || bottom == img.Bounds().Dy()-labelMarginPixels, "no media is wasted")
+
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Equal(t, img.Bounds().Dy(), mmToPixels(output.HeightMM, 203))
+	assert.Contains(t, output.ZPL, fmt.Sprintf("^LL%d", img.Bounds().Dy()))
+}
+
+// TestGenerateBarcode_AutoHeightQR verifies QR codes span the label width
+// and the label grows to hold them
+func TestGenerateBarcode_AutoHeightQR(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "https://example.com/r/123",
+		BarcodeType: BarcodeTypeQR,
+		Width:       50,
+		Dpi:         203,
+		AutoHeight:  true,
+		TextLines:   []TextLine{{Text: "Scan for your receipt", Position: TextPositionBelow, Size: TextSizeSmall}},
+	}
+	img, layout, err := GenerateBarcodeImage(input)
+	require.NoError(t, err)
+	assert.Greater(t, img.Bounds().Dy(), img.Bounds().Dx())
+	assert.Equal(t, layout.BarcodeRect.Dx(), layout.BarcodeRect.Dy())
+	assert.LessOrEqual(t, layout.BarcodeRect.Dx(), img.Bounds().Dx()-labelMarginPixels*2)
+}
+
+// TestValidateAutoHeight verifies barcodes whose length runs along the label
+// height, or that are placed, are rejected
+func TestValidateAutoHeight(t *testing.T) {
+	assert.NoError(t, validateAutoHeight(BarcodeInput{BarcodeRotation: 180}))
+	assert.ErrorContains(t, validateAutoHeight(BarcodeInput{BarcodeRotation: 90}), "runs along the label height")
+	assert.ErrorContains(t, validateAutoHeight(BarcodeInput{BarcodePlacement: Placement{WidthMM: 10, HeightMM: 10}}), "BarcodePlacement fixes the barcode position")
+}
+
+// TestGenerateBarcode_AutoHeightEAN verifies EAN-13 symbols keep room for
+// their human-readable digits
+func TestGenerateBarcode_AutoHeightEAN(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:   "978-0-306-40615-7",
+		BarcodeType:   BarcodeTypeISBN,
+		Width:         40,
+		Dpi:           300,
+		AutoHeight:    true,
+		HumanReadable: true,
+	}
+	img, layout, err := GenerateBarcodeImage(input)
+	require.NoError(t, err)
+	assert.Equal(t, img.Bounds().Dy()-labelMarginPixels*2, layout.BarcodeRect.Dy())
+}
@@ -1,9 +1,12 @@
 /*
 Package barcode provides barcode and label generation for warehouse operations.
 
-Supports Code128 and QR code formats with dual output:
-  - PNG images (base64-encoded) for web display
-  - ZPL (Zebra Programming Language) for thermal printer output
+Supports Code128, GS1-128, ISBN/ISSN (EAN-13), Interleaved 2 of 5, Telepen, QR, Swiss QR-bill, USPS Intelligent Mail (IMb) and GS1 DataBar formats with selectable output formats:
+  - PNG images (base64-encoded) for web display (default)
+  - ZPL (Zebra Programming Language) for thermal printer output (default)
+  - Optional PDF sized to the label for office printers
+  - Optional printer languages: EPL2 for older Zebra printers, TSPL for TSC printers, SBPL for SATO printers,
+    ESC/POS for receipt printers, DPL for Datamax-O'Neil printers and CPCL for Zebra mobile printers
 
 Key features:
   - DPI-aware scaling for standard thermal printers (203, 300, 600 DPI)
@@ -13,31 +16,114 @@ Key features:
 package barcode
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
 	"fmt"
 	"image"
 
 	"github.com/boombuler/barcode"
-	"github.com/boombuler/barcode/code128"
-	"github.com/boombuler/barcode/qr"
+	"github.com/golang/freetype/truetype"
 )
 
 // Standard DPI values supported by most thermal printers
 var standardDPIValues = []int{203, 300, 600}
 
+// Range of DPI values accepted with AllowNonStandardDPI, covering printers
+// such as 152 DPI mobile units, SATO 305 DPI and Intermec 406 DPI
+const (
+	minNonStandardDPI = 100
+	maxNonStandardDPI = 1200
+)
+
 // BarcodeType represents supported barcode formats
 type BarcodeType string
 
 const (
 	BarcodeTypeCode128 BarcodeType = "CODE128"
 	BarcodeTypeQR      BarcodeType = "QR"
+	BarcodeTypeSwissQR BarcodeType = "SWISS_QR"
+	BarcodeTypeIMb     BarcodeType = "IMB"
+	BarcodeTypeITF     BarcodeType = "ITF"
+	BarcodeTypeGS1128  BarcodeType = "GS1_128"
+	BarcodeTypeISBN    BarcodeType = "ISBN"
+	BarcodeTypeISSN    BarcodeType = "ISSN"
+
+	BarcodeTypeTelepen        BarcodeType = "TELEPEN"
+	BarcodeTypeTelepenNumeric BarcodeType = "TELEPEN_NUMERIC"
+
+	BarcodeTypeDataBarOmni            BarcodeType = "DATABAR_OMNI"
+	BarcodeTypeDataBarExpanded        BarcodeType = "DATABAR_EXPANDED"
+	BarcodeTypeDataBarExpandedStacked BarcodeType = "DATABAR_EXPANDED_STACKED"
+
+	BarcodeTypeImage BarcodeType = "IMAGE" // Pre-rendered symbol supplied in BarcodeImage
+)
+
+// Barcode types accepted by GenerateBarcode
+var supportedBarcodeTypes = []BarcodeType{
+	BarcodeTypeCode128,
+	BarcodeTypeQR,
+	BarcodeTypeSwissQR,
+	BarcodeTypeIMb,
+	BarcodeTypeITF,
+	BarcodeTypeGS1128,
+	BarcodeTypeISBN,
+	BarcodeTypeISSN,
+	BarcodeTypeTelepen,
+	BarcodeTypeTelepenNumeric,
+	BarcodeTypeDataBarOmni,
+	BarcodeTypeDataBarExpanded,
+	BarcodeTypeDataBarExpandedStacked,
+	BarcodeTypeImage,
+}
+
+// OutputFormat selects a representation of the label in BarcodeOutput
+type OutputFormat string
+
+const (
+	OutputFormatPNG    OutputFormat = "PNG"    // Base64-encoded PNG for web display, at PreviewDpi when set
+	OutputFormatZPL    OutputFormat = "ZPL"    // Zebra printers
+	OutputFormatPDF    OutputFormat = "PDF"    // Base64-encoded PDF sized to the label, for office printers
+	OutputFormatTIFF   OutputFormat = "TIFF"   // Base64-encoded TIFF with resolution tags, for print bureaus
+	OutputFormatBMP    OutputFormat = "BMP"    // Base64-encoded 1-bit monochrome BMP, for legacy Windows print pipelines
+	OutputFormatEPS    OutputFormat = "EPS"    // Vector barcode symbol for packaging artwork
+	OutputFormatEPL    OutputFormat = "EPL"    // Older Zebra printers that do not speak ZPL
+	OutputFormatTSPL   OutputFormat = "TSPL"   // TSC printers
+	OutputFormatSBPL   OutputFormat = "SBPL"   // SATO printers
+	OutputFormatESCPOS OutputFormat = "ESCPOS" // Receipt printers
+	OutputFormatDPL    OutputFormat = "DPL"    // Datamax-O'Neil printers
+	OutputFormatCPCL   OutputFormat = "CPCL"   // Zebra mobile printers
 )
 
+// supportedOutputFormats lists every format that can be requested
+var supportedOutputFormats = []OutputFormat{
+	OutputFormatPNG,
+	OutputFormatZPL,
+	OutputFormatPDF,
+	OutputFormatTIFF,
+	OutputFormatBMP,
+	OutputFormatEPS,
+	OutputFormatEPL,
+	OutputFormatTSPL,
+	OutputFormatSBPL,
+	OutputFormatESCPOS,
+	OutputFormatDPL,
+	OutputFormatCPCL,
+}
+
+// defaultOutputFormats are produced when OutputFormats is empty
+var defaultOutputFormats = []OutputFormat{OutputFormatPNG, OutputFormatZPL}
+
 // TextPosition defines where text appears relative to the barcode
 type TextPosition string
 
 const (
 	TextPositionAbove TextPosition = "ABOVE"
 	TextPositionBelow TextPosition = "BELOW"
+	TextPositionLeft  TextPosition = "LEFT"  // In a column beside the barcode, e.g. on narrow wristbands
+	TextPositionRight TextPosition = "RIGHT" // In a column beside the barcode
+
+	TextPositionAbsolute TextPosition = "ABSOLUTE" // At the line's XMM and YMM, outside the automatic layout
 )
 
 // TextSize defines predefined text sizes
@@ -51,71 +137,323 @@ const (
 
 // TextLine represents a line of text to render on the label
 type TextLine struct {
-	Text     string
-	Position TextPosition
-	Size     TextSize
+	Text       string
+	Position   TextPosition
+	Size       TextSize
+	FitGroup   string        // Optional: lines sharing a group are shrunk together by the same factor
+	Scale      float64       // Optional: font size multiplier, e.g. 0.75 for secondary lines (defaults to 1)
+	Alignment  TextAlignment // Optional: LEFT or RIGHT within the label margins (defaults to CENTER)
+	XOffsetMM  float64       // Optional: move the line right, or left when negative, in millimeters
+	SameRow    bool          // Optional: print on the row of the previous line at the same position, e.g. a right-aligned quantity beside a left-aligned SKU
+	FontName   string        // Optional: name of a font added with RegisterFont (defaults to Go Regular)
+	FontSizePt float64       // Optional: exact font size in points, used instead of Size and FontScaling
+	Overflow   TextOverflow  // Optional: SHRINK, TRUNCATE, ELLIPSIS or ERROR when the line is too wide (defaults to SHRINK)
+	Rotation   int           // Optional: turn a LEFT or RIGHT line 90 or 270 degrees clockwise to run along the label edge
+	XMM        float64       // For ABSOLUTE lines: left edge in millimeters, or the centre or right edge when Alignment is CENTER or RIGHT
+	YMM        float64       // For ABSOLUTE lines: top of the line in millimeters
+
+	font *truetype.Font // The font a Generator holds under FontName
 }
 
 // BarcodeInput contains all parameters needed to generate a barcode label
 type BarcodeInput struct {
-	BarcodeData string      // The data to encode in the barcode
-	BarcodeType BarcodeType // Type of barcode (CODE128 or QR)
-	Width       float64     // Label width in millimeters
-	Height      float64     // Label height in millimeters
-	Dpi         int         // Printer DPI (203, 300, or 600)
-	TextLines   []TextLine  // Optional text lines to render
+	BarcodeData           string            // The data to encode in the barcode
+	BarcodeDataBytes      []byte            // Optional raw binary data for QR codes, used instead of BarcodeData
+	BarcodeType           BarcodeType       // Type of barcode (see supportedBarcodeTypes)
+	BarcodeImage          []byte            // PNG of a pre-rendered symbol for the IMAGE type
+	Width                 float64           // Label width in millimeters
+	Height                float64           // Label height in millimeters
+	LabelPreset           LabelPreset       // Optional stock size such as 4X6, used instead of Width and Height
+	AutoHeight            bool              // Optional: compute Height from the barcode and its text, e.g. for continuous receipt stock
+	Units                 Unit              // Optional unit of the label size, margins and positions: MM (default), INCH or DOTS at Dpi
+	Dpi                   int               // Printer DPI (203, 300, or 600)
+	AllowNonStandardDPI   bool              // Optional: accept any Dpi from 100 to 1200, e.g. 305 for SATO or 406 for Intermec printers
+	PreviewDpi            int               // Optional DPI for the PNG image (defaults to Dpi)
+	Monochrome            bool              // Optional: encode the PNG and TIFF as 1-bit black and white images
+	ForegroundColor       string            // Optional hex color such as "#1A4D8F" for the PNG barcode and text (defaults to black)
+	BackgroundColor       string            // Optional hex color for the PNG label background (defaults to white)
+	TransparentBackground bool              // Optional: make the PNG label background transparent for web overlays
+	HumanReadable         bool              // Optional: print the encoded data below linear symbols, between the guard bars of EAN-13
+	TextLines             []TextLine        // Optional text lines to render
+	TextBlocks            []TextBlock       // Optional bilingual text blocks, rendered after TextLines
+	Paragraphs            []TextParagraph   // Optional long text wrapped across lines, rendered after TextBlocks
+	Barcodes              []LabelBarcode    // Optional additional barcodes at fixed positions, e.g. a QR code beside a Code128
+	Graphics              []LabelGraphic    // Optional static artwork such as logos, drawn over the barcode and text
+	Shapes                []LabelShape      // Optional lines, boxes, circles and diamonds, drawn below the graphics
+	ReverseRegions        []Placement       // Optional areas printed white on black, e.g. a hazard band across the top
+	BarcodePlacement      Placement         // Optional: scale the barcode into this area instead of centering it with its text
+	XDimensionMils        float64           // Optional: module width in mils, e.g. 10, instead of scaling the barcode to fill its space
+	StrictScannability    bool              // Optional: reject labels with scannability warnings instead of returning them in Warnings
+	BarWidthReduction     BarWidthReduction // Optional: narrow the bars of the barcode to offset printhead bleed
+	BilevelText           bool              // Optional: render text without anti-aliasing, every pixel black or white
+	FontScaling           FontScaling       // Optional: how text grows with the label width
+	DataBar               DataBarOptions    // Optional settings for GS1 DataBar types
+	Code128               Code128Options    // Optional settings for Code128
+	QR                    QROptions         // Optional settings for QR codes
+	ITF                   ITFOptions        // Optional settings for Interleaved 2 of 5
+	ISBN                  ISBNOptions       // Optional settings for ISBN and ISSN types
+	Margins               Margins           // Optional margin and quiet zone settings
+	Mirror                bool              // Optional: flip the layout for reverse-side applicators (barcodes stay unmirrored)
+	BarcodeRotation       int               // Optional: rotate only the barcode clockwise by 0, 90, 180 or 270 degrees
+	Rotation              int               // Optional: rotate the whole label clockwise by 0, 90, 180 or 270 degrees, e.g. for media loaded sideways
+	AutoRotate            bool              // Optional: lay the label out turned 90 degrees when it does not fit upright
+	UpsideDown            bool              // Optional: the printer is mounted inverted, so printer output is turned 180 degrees
+	OutputFormats         []OutputFormat    // Optional formats to produce (defaults to PNG and ZPL)
+	ZPL                   ZPLOptions        // Optional settings for ZPL output
+	CPCL                  CPCLOptions       // Optional settings for CPCL output
+	RFID                  RFIDOptions       // Optional RFID tag encoding, in ZPL output only
 }
 
-// BarcodeOutput contains the generated barcode in multiple formats
+// BarcodeOutput contains the generated barcode in the requested formats.
+// Formats that were not requested are left empty.
 type BarcodeOutput struct {
-	ImageBase64 string // Base64-encoded PNG image
-	ZPL         string // ZPL (Zebra Programming Language) commands
+	ImageBase64 string      // Base64-encoded PNG image
+	ZPL         string      // ZPL (Zebra Programming Language) commands
+	PDFBase64   string      // Base64-encoded single-page PDF sized to the label
+	TIFFBase64  string      // Base64-encoded TIFF at the printer DPI, bilevel Group 4 when Monochrome is set
+	BMPBase64   string      // Base64-encoded 1-bit monochrome BMP at the printer DPI
+	EPS         string      // Encapsulated PostScript of the barcode symbol with vector bars
+	EPL         string      // EPL2 commands
+	TSPL        string      // TSPL commands
+	SBPL        string      // SBPL commands
+	ESCPOS      string      // ESC/POS commands
+	DPL         string      // DPL commands
+	CPCL        string      // CPCL commands
+	Warnings    []string    // Scannability problems of a label generated anyway, e.g. modules narrower than 2 dots
+	HeightMM    float64     // Height of the label in millimeters, as computed for AutoHeight labels
+	Landscape   bool        // AutoRotate turned the layout 90 degrees to fit the label
+	Layout      LabelLayout // Where the barcode and text lines landed on the label at the printer DPI
+}
+
+// ImageDataURL returns the PNG image as a data URL ready for an HTML img
+// src or CSS, or an empty string when PNG output was not requested
+func (o *BarcodeOutput) ImageDataURL() string {
+	if o.ImageBase64 == "" {
+		return ""
+	}
+	return "data:image/png;base64," + o.ImageBase64
 }
 
 // GenerateBarcode creates a barcode label with optional text lines.
-// It returns both a PNG image (as base64) and ZPL commands for thermal printers.
+// It returns the formats listed in OutputFormats, by default a PNG image (as
+// base64) and ZPL commands for thermal printers. When PreviewDpi is set, the
+// PNG is rendered at that DPI with the same physical layout as the ZPL,
+// which is always rendered at the printer DPI.
 //
 // The function coordinates the barcode generation pipeline:
 //  1. Validates input parameters
 //  2. Encodes the barcode data
 //  3. Calculates appropriate barcode dimensions
 //  4. Renders barcode and text onto a label image
-//  5. Exports to PNG and ZPL formats
+//  5. Exports to the requested output formats
+//
+// Panics raised by the underlying encoding and rendering libraries are
+// recovered and returned as errors.
 func GenerateBarcode(input BarcodeInput) (*BarcodeOutput, error) {
+	return generateBarcode(context.Background(), input, &Generator{})
+}
+
+// GenerateBarcodeCtx creates a barcode label like GenerateBarcode, but stops
+// between pipeline stages once ctx is cancelled or its deadline passes, e.g.
+// when the HTTP request asking for the label goes away. The error then wraps
+// ctx.Err().
+func GenerateBarcodeCtx(ctx context.Context, input BarcodeInput) (*BarcodeOutput, error) {
+	return generateBarcode(ctx, input, &Generator{})
+}
+
+// generateBarcode runs the pipeline with the generator's settings, applying
+// its post-processors to each rendered image before it is encoded
+func generateBarcode(ctx context.Context, input BarcodeInput, g *Generator) (output *BarcodeOutput, err error) {
+	defer recoverToError(&err)
+
+	input = g.applyDefaults(input)
+	if input, err = applyUnits(input); err != nil {
+		return nil, err
+	}
+	if input, err = applyLabelPreset(input); err != nil {
+		return nil, err
+	}
+	output, err = generateLabel(ctx, input, g)
+	if err != nil && input.AutoRotate && ctx.Err() == nil {
+		if landscape, landscapeErr := generateLabel(ctx, landscapeInput(input), g); landscapeErr == nil {
+			landscape.Landscape, landscape.Layout.Landscape = true, true
+			return landscape, nil
+		}
+	}
+	return output, err
+}
+
+// generateLabel generates the label in the orientation the input describes
+func generateLabel(ctx context.Context, input BarcodeInput, g *Generator) (output *BarcodeOutput, err error) {
+	if input, err = applyAutoHeight(input); err != nil {
+		return nil, err
+	}
 	if err := validateInput(input); err != nil {
 		return nil, err
 	}
+	input = g.Features.apply(input)
 
 	bc, err := encodeBarcode(input)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
 
-	labelImg, barcodeRect, err := renderLabel(input, bc)
+	labelImg, barcodeRects, err := composeLabelImage(input, bc, input.Dpi)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+	warnings, err := checkScannability(input, bc, labelImg, barcodeRects[0])
+	if err != nil {
+		return nil, err
+	}
+	layout := describeLabelLayout(input, bc, labelImg, barcodeRects[0], input.Dpi)
+	labelImg = applyPostProcessors(orientLabelImage(labelImg, input, barcodeRects), g.PostProcessors, input.Dpi)
+
+	formats := requestedOutputFormats(input)
+	previewImg := labelImg
+	if formats[OutputFormatPNG] && input.PreviewDpi != 0 && input.PreviewDpi != input.Dpi {
+		previewImg, err = renderLabelImage(input, bc, input.PreviewDpi)
+		if err != nil {
+			return nil, err
+		}
+		previewImg = applyPostProcessors(previewImg, g.PostProcessors, input.PreviewDpi)
+	}
+	previewImg = colorizeLabel(previewImg, input, g)
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
 
-	if err := renderTextLines(labelImg, input, barcodeRect); err != nil {
+	output, err = generateOutputFormats(input, formats, previewImg, labelImg)
+	if err != nil {
 		return nil, err
 	}
+	output.Warnings = warnings
+	output.HeightMM = input.Height
+	output.Layout = layout
+	if formats[OutputFormatEPS] {
+		if output.EPS, err = generateEPS(input, bc); err != nil {
+			return nil, err
+		}
+	}
 
-	return generateOutputFormats(labelImg)
+	// Native commands would lose mirroring, rotation, QR logos and post-processing, so those labels are sent as graphics
+	native := !input.Mirror && input.Rotation == 0 && !hasQRLogo(input) && len(g.PostProcessors) == 0
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := generatePrinterLanguages(output, input, formats, bc, labelImg, native); err != nil {
+		return nil, err
+	}
+	return output, nil
 }
 
-// validateInput checks that all input parameters are valid
-func validateInput(input BarcodeInput) error {
-	if err := validateDPI(input.Dpi); err != nil {
-		return err
+// LabelLayout describes where the content of a label image landed, once the
+// label is mirrored and rotated. GenerateBarcodeImage returns it for the
+// image, and BarcodeOutput for the print image at the printer DPI.
+type LabelLayout struct {
+	Dpi         int              // Resolution of the image: PreviewDpi when set, otherwise Dpi
+	Width       int              // Width of the image in pixels
+	Height      int              // Height of the image in pixels
+	BarcodeRect image.Rectangle  // Position of the barcode in the image, in pixels
+	ModuleWidth int              // Width of a barcode module in pixels, printer dots at the printer DPI
+	TextLines   []TextLineLayout // Text lines in the order they were drawn
+	Landscape   bool             // AutoRotate turned the layout 90 degrees to fit the label
+}
+
+// GenerateBarcodeImage renders the label like GenerateBarcode but returns the
+// composed image instead of encoding it, for callers that composite the
+// label into a larger image such as a packing slip. The image is rendered at
+// PreviewDpi when set in the PNG colors, and OutputFormats is ignored.
+func GenerateBarcodeImage(input BarcodeInput) (image.Image, LabelLayout, error) {
+	return generateBarcodeImage(context.Background(), input, &Generator{})
+}
+
+// GenerateBarcodeImageCtx renders the label like GenerateBarcodeImage, but
+// stops between pipeline stages once ctx is done
+func GenerateBarcodeImageCtx(ctx context.Context, input BarcodeInput) (image.Image, LabelLayout, error) {
+	return generateBarcodeImage(ctx, input, &Generator{})
+}
+
+// generateBarcodeImage renders the label, applies the generator's
+// post-processors and colors it
+func generateBarcodeImage(ctx context.Context, input BarcodeInput, g *Generator) (img image.Image, layout LabelLayout, err error) {
+	defer recoverToError(&err)
+
+	input = g.applyDefaults(input)
+	if input, err = applyUnits(input); err != nil {
+		return nil, LabelLayout{}, err
 	}
+	if input, err = applyLabelPreset(input); err != nil {
+		return nil, LabelLayout{}, err
+	}
+	img, layout, err = renderBarcodeImage(ctx, input, g)
+	if err != nil && input.AutoRotate && ctx.Err() == nil {
+		if landscapeImg, landscapeLayout, landscapeErr := renderBarcodeImage(ctx, landscapeInput(input), g); landscapeErr == nil {
+			landscapeLayout.Landscape = true
+			return landscapeImg, landscapeLayout, nil
+		}
+	}
+	return img, layout, err
+}
 
-	if err := validateBarcodeType(input.BarcodeType); err != nil {
-		return err
+// renderBarcodeImage renders the label image in the orientation the input
+// describes
+func renderBarcodeImage(ctx context.Context, input BarcodeInput, g *Generator) (img image.Image, layout LabelLayout, err error) {
+	if input, err = applyAutoHeight(input); err != nil {
+		return nil, LabelLayout{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return nil, LabelLayout{}, err
+	}
+	input = g.Features.apply(input)
+	bc, err := encodeBarcode(input)
+	if err != nil {
+		return nil, LabelLayout{}, err
+	}
+	if err := checkContext(ctx); err != nil {
+		return nil, LabelLayout{}, err
 	}
 
+	dpi := input.Dpi
+	if input.PreviewDpi != 0 {
+		dpi = input.PreviewDpi
+	}
+	labelImg, barcodeRects, err := composeLabelImage(input, bc, dpi)
+	if err != nil {
+		return nil, LabelLayout{}, err
+	}
+	if err := checkContext(ctx); err != nil {
+		return nil, LabelLayout{}, err
+	}
+	layout = describeLabelLayout(input, bc, labelImg, barcodeRects[0], dpi)
+	labelImg = applyPostProcessors(orientLabelImage(labelImg, input, barcodeRects), g.PostProcessors, dpi)
+	return colorizeLabel(labelImg, input, g), layout, nil
+}
+
+// checkContext returns an error wrapping ctx.Err() once the context is
+// cancelled or its deadline has passed, so the pipeline stops before its
+// next stage
+func checkContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("label generation stopped: %w", err)
+	}
 	return nil
 }
 
+// recoverToError converts a panic into an error so one bad request cannot
+// crash the calling service. It must be deferred directly.
+func recoverToError(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("barcode generation failed unexpectedly: %v", r)
+	}
+}
+
 // validateDPI ensures the DPI is a supported thermal printer value
 func validateDPI(dpi int) error {
 	for _, validDpi := range standardDPIValues {
@@ -123,89 +461,456 @@ func validateDPI(dpi int) error {
 			return nil
 		}
 	}
-	return fmt.Errorf("invalid dpi value: %d. Supported dpi values are: %v", dpi, standardDPIValues)
+	return errorf(ErrInvalidDPI, "invalid dpi value: %d. Supported dpi values are: %v", dpi, standardDPIValues)
+}
+
+// validateNonStandardDPI ensures the DPI is in the range accepted for
+// printers with other resolutions
+func validateNonStandardDPI(dpi int) error {
+	if dpi < minNonStandardDPI || dpi > maxNonStandardDPI {
+		return errorf(ErrInvalidDPI, "invalid dpi value: %d. Non-standard dpi values must be between %d and %d", dpi, minNonStandardDPI, maxNonStandardDPI)
+	}
+	return nil
+}
+
+// validatePreviewDPI ensures the optional preview DPI is positive.
+// Previews target screens, so any resolution is accepted.
+func validatePreviewDPI(dpi int) error {
+	if dpi < 0 {
+		return errorf(ErrInvalidDPI, "invalid preview dpi value: %d. Preview dpi must be positive", dpi)
+	}
+	return nil
+}
+
+// validateDimensions ensures the label is positive and, at the printer DPI,
+// wider and taller than its margins so there is room for the barcode.
+func validateDimensions(width, height float64, dpi, margin int) error {
+	if err := validateLabelWidth(width, dpi, margin); err != nil {
+		return err
+	}
+	return validateLabelHeight(height, dpi, margin)
+}
+
+// validateLabelWidth ensures the label is positive and wider than its
+// margins at the printer DPI
+func validateLabelWidth(width float64, dpi, margin int) error {
+	if !(width > 0) {
+		return errorf(ErrInvalidDimensions, "invalid label width: %.1fmm. Width must be positive", width)
+	}
+	if mmToPixels(width, dpi) <= margin*2 {
+		return errorf(ErrInvalidDimensions, "invalid label width: %.1fmm. Width must be at least %.1fmm at %d dpi to fit the label margins", width, minLabelSizeMM(dpi, margin), dpi)
+	}
+	return nil
+}
+
+// validateLabelHeight ensures the label is positive and taller than its
+// margins at the printer DPI
+func validateLabelHeight(height float64, dpi, margin int) error {
+	if !(height > 0) {
+		return errorf(ErrInvalidDimensions, "invalid label height: %.1fmm. Height must be positive", height)
+	}
+	if mmToPixels(height, dpi) <= margin*2 {
+		return errorf(ErrInvalidDimensions, "invalid label height: %.1fmm. Height must be at least %.1fmm at %d dpi to fit the label margins", height, minLabelSizeMM(dpi, margin), dpi)
+	}
+	return nil
+}
+
+// minLabelSizeMM returns the smallest label side that leaves a pixel between
+// the margins
+func minLabelSizeMM(dpi, margin int) float64 {
+	return float64(margin*2+1) * 25.4 / float64(dpi)
 }
 
 // validateBarcodeType ensures the barcode type is supported
 func validateBarcodeType(barcodeType BarcodeType) error {
-	switch barcodeType {
-	case BarcodeTypeCode128, BarcodeTypeQR:
+	for _, supported := range supportedBarcodeTypes {
+		if barcodeType == supported {
+			return nil
+		}
+	}
+	return errorf(ErrUnsupportedType, "invalid barcode type: %s. Supported types: %v", barcodeType, supportedBarcodeTypes)
+}
+
+// validateOutputFormats ensures every requested output format is supported
+func validateOutputFormats(formats []OutputFormat) error {
+	for _, format := range formats {
+		if !isSupportedOutputFormat(format) {
+			return errorf(ErrInvalidOutputFormat, "invalid output format: %s. Supported formats: %v", format, supportedOutputFormats)
+		}
+	}
+	return nil
+}
+
+func isSupportedOutputFormat(format OutputFormat) bool {
+	for _, supported := range supportedOutputFormats {
+		if format == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// requestedOutputFormats returns the set of formats to produce
+func requestedOutputFormats(input BarcodeInput) map[OutputFormat]bool {
+	formats := input.OutputFormats
+	if len(formats) == 0 {
+		formats = defaultOutputFormats
+	}
+
+	requested := make(map[OutputFormat]bool, len(formats))
+	for _, format := range formats {
+		requested[format] = true
+	}
+	return requested
+}
+
+// validateBarcodeRotation ensures the barcode is turned by a multiple of 90 degrees
+func validateBarcodeRotation(rotation int) error {
+	switch rotation {
+	case 0, 90, 180, 270:
 		return nil
 	default:
-		return fmt.Errorf("invalid barcode type: %s. Supported types: CODE128, QR", barcodeType)
+		return errorf(ErrInvalidInput, "invalid barcode rotation: %d. Supported rotations are 0, 90, 180 and 270 degrees", rotation)
+	}
+}
+
+// validateLabelRotation ensures the label is turned by a multiple of 90 degrees
+func validateLabelRotation(rotation int) error {
+	switch rotation {
+	case 0, 90, 180, 270:
+		return nil
+	default:
+		return errorf(ErrInvalidInput, "invalid label rotation: %d. Supported rotations are 0, 90, 180 and 270 degrees", rotation)
+	}
+}
+
+// labelSizeMM returns the width and height of the label as printed, which
+// are swapped when it is turned by a quarter turn
+func labelSizeMM(input BarcodeInput) (float64, float64) {
+	if input.Rotation%180 != 0 {
+		return input.Height, input.Width
+	}
+	return input.Width, input.Height
+}
+
+// landscapeInput returns the input laid out across the label's height and
+// turned a further 90 degrees clockwise, so it prints on the same label
+// turned on its side. Positions are not moved, so placed content rarely fits
+// in landscape.
+func landscapeInput(input BarcodeInput) BarcodeInput {
+	input.Width, input.Height = input.Height, input.Width
+	input.Rotation = (input.Rotation + 90) % 360
+	return input
+}
+
+// validateBarcodeData applies symbology-specific checks to the barcode data
+func validateBarcodeData(input BarcodeInput) error {
+	if len(input.BarcodeDataBytes) > 0 {
+		return validateBarcodeDataBytes(input)
+	}
+	if input.BarcodeType == BarcodeTypeImage || len(input.BarcodeImage) > 0 {
+		return validateBarcodeImage(input)
+	}
+
+	data := input.BarcodeData
+	switch input.BarcodeType {
+	case BarcodeTypeCode128:
+		return validateCode128Options(data, input.Code128)
+	case BarcodeTypeQR:
+		return validateQRData(data, input.QR)
+	case BarcodeTypeIMb:
+		return validateIMbData(data)
+	case BarcodeTypeITF:
+		_, err := itfContent(data, input.ITF)
+		return err
+	case BarcodeTypeGS1128:
+		return validateGS1128Data(data)
+	case BarcodeTypeSwissQR:
+		return validateSwissQRPayload(data)
+	case BarcodeTypeISBN:
+		if _, err := normalizeISBN(data); err != nil {
+			return err
+		}
+		return validateEANAddOn(input.ISBN.AddOn)
+	case BarcodeTypeISSN:
+		if _, err := normalizeISSN(data); err != nil {
+			return err
+		}
+		return validateEANAddOn(input.ISBN.AddOn)
+	case BarcodeTypeTelepen:
+		return validateTelepenData(data)
+	case BarcodeTypeTelepenNumeric:
+		return validateTelepenNumericData(data)
+	case BarcodeTypeDataBarOmni:
+		_, err := normalizeDataBarGTIN(data)
+		return err
+	case BarcodeTypeDataBarExpanded, BarcodeTypeDataBarExpandedStacked:
+		return validateDataBarExpandedData(data)
+	default:
+		return nil
 	}
 }
 
-// encodeBarcode creates the actual barcode from the input data
+// encodeBarcode creates the actual barcode from the input data. Failures are
+// returned as an *EncodingError.
 func encodeBarcode(input BarcodeInput) (barcode.Barcode, error) {
+	bc, err := encodeSymbol(input)
+	if err != nil {
+		return nil, &EncodingError{Type: input.BarcodeType, Data: input.BarcodeData, Err: err}
+	}
+	return bc, nil
+}
+
+// encodeSymbol encodes the data with the encoder of the input's barcode type
+func encodeSymbol(input BarcodeInput) (barcode.Barcode, error) {
 	switch input.BarcodeType {
 	case BarcodeTypeCode128:
-		return encodeCode128(input.BarcodeData)
+		return encodeCode128(input.BarcodeData, input.Code128)
 	case BarcodeTypeQR:
-		return encodeQRCode(input.BarcodeData)
+		if len(input.BarcodeDataBytes) > 0 {
+			return encodeQRBytes(input.BarcodeDataBytes, qrLogoOptions(input))
+		}
+		return encodeQRCode(input.BarcodeData, qrLogoOptions(input))
+	case BarcodeTypeSwissQR:
+		return encodeSwissQR(input.BarcodeData)
+	case BarcodeTypeIMb:
+		return encodeIMb(input.BarcodeData)
+	case BarcodeTypeITF:
+		return encodeITF(input.BarcodeData, input.ITF)
+	case BarcodeTypeGS1128:
+		return encodeGS1128(input.BarcodeData)
+	case BarcodeTypeISBN:
+		return encodeISBN(input.BarcodeData, input.ISBN)
+	case BarcodeTypeISSN:
+		return encodeISSN(input.BarcodeData, input.ISBN)
+	case BarcodeTypeTelepen:
+		return encodeTelepen(input.BarcodeData)
+	case BarcodeTypeTelepenNumeric:
+		return encodeTelepenNumeric(input.BarcodeData)
+	case BarcodeTypeDataBarOmni:
+		return encodeDataBarOmni(input.BarcodeData)
+	case BarcodeTypeDataBarExpanded:
+		return encodeDataBarExpanded(input.BarcodeData)
+	case BarcodeTypeDataBarExpandedStacked:
+		return encodeDataBarExpandedStacked(input.BarcodeData, input.DataBar)
+	case BarcodeTypeImage:
+		return encodeImportedBarcode(input.BarcodeImage, input.BarcodeData)
 	default:
 		// This should never happen due to validation, but included for safety
-		return nil, fmt.Errorf("unsupported barcode type: %s", input.BarcodeType)
+		return nil, errorf(ErrUnsupportedType, "unsupported barcode type: %s", input.BarcodeType)
 	}
 }
 
-// encodeCode128 creates a Code128 barcode
-func encodeCode128(data string) (barcode.Barcode, error) {
-	bc, err := code128.Encode(data)
+// renderLabelImage renders the barcode and text lines onto a label at the given DPI.
+// Layout is always calculated at the printer DPI and scaled, so labels rendered
+// at other resolutions keep the same physical geometry.
+func renderLabelImage(input BarcodeInput, bc barcode.Barcode, dpi int) (*image.RGBA, error) {
+	labelImg, barcodeRects, err := composeLabelImage(input, bc, dpi)
 	if err != nil {
-		return nil, fmt.Errorf("failed to encode Code128 barcode: %w", err)
+		return nil, err
 	}
-	return bc, nil
+	return orientLabelImage(labelImg, input, barcodeRects), nil
 }
 
-// encodeQRCode creates a QR code
-func encodeQRCode(data string) (barcode.Barcode, error) {
-	bc, err := qr.Encode(data, qr.M, qr.Auto)
+// composeLabelImage draws everything on the label before it is mirrored and
+// rotated, and returns where the main barcode and then each additional
+// barcode was drawn
+func composeLabelImage(input BarcodeInput, bc barcode.Barcode, dpi int) (*image.RGBA, []image.Rectangle, error) {
+	labelImg, barcodeRect, err := renderLabel(input, bc, dpi)
 	if err != nil {
-		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+		return nil, nil, err
 	}
-	return bc, nil
+
+	if err := renderTextLines(labelImg, input, barcodeRect, dpi); err != nil {
+		return nil, nil, err
+	}
+
+	barcodeRects, err := drawLabelBarcodes(labelImg, input.Barcodes, dpi)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	drawLabelShapes(labelImg, input.Shapes, dpi)
+
+	if err := drawLabelGraphics(labelImg, input.Graphics, dpi); err != nil {
+		return nil, nil, err
+	}
+
+	reverseLabelRegions(labelImg, input.ReverseRegions, dpi)
+
+	return labelImg, append([]image.Rectangle{barcodeRect}, barcodeRects...), nil
 }
 
-// renderLabel creates the label image and places the barcode on it
-func renderLabel(input BarcodeInput, bc barcode.Barcode) (*image.RGBA, image.Rectangle, error) {
-	labelWidth := mmToPixels(input.Width, input.Dpi)
-	labelHeight := mmToPixels(input.Height, input.Dpi)
+// orientLabelImage mirrors and rotates a composed label as requested,
+// keeping the barcodes unmirrored
+func orientLabelImage(labelImg *image.RGBA, input BarcodeInput, barcodeRects []image.Rectangle) *image.RGBA {
+	if input.Mirror {
+		labelImg = mirrorLabel(labelImg, barcodeRects...)
+	}
+	return rotateLabel(labelImg, input.Rotation)
+}
 
-	barcodeSize := calculateBarcodeSize(input, labelWidth, labelHeight)
-	scaledBc, err := scaleBarcodeToFit(bc, barcodeSize)
+// renderLabel creates the label image and places the barcode on it
+func renderLabel(input BarcodeInput, bc barcode.Barcode, dpi int) (*image.RGBA, image.Rectangle, error) {
+	layout, err := layoutLabel(input, bc, dpi)
 	if err != nil {
 		return nil, image.Rectangle{}, err
 	}
 
-	img := createBlankLabel(labelWidth, labelHeight)
-	barcodeRect := centerBarcodeOnLabel(img, scaledBc)
+	img := createBlankLabel(layout.width, layout.height)
+	drawBarcodeOnLabel(img, layout.barcode, layout.barcodeRect)
+	if hasQRLogo(input) {
+		if err := drawQRLogo(img, input.QR, layout.barcodeRect); err != nil {
+			return nil, image.Rectangle{}, err
+		}
+	}
 
-	drawBarcodeOnLabel(img, scaledBc, barcodeRect)
+	return img, layout.barcodeRect, nil
+}
 
-	return img, barcodeRect, nil
+// labelLayout is the placement of the scaled barcode on a label at one DPI
+type labelLayout struct {
+	width, height int
+	barcode       barcode.Barcode // Scaled to the size of barcodeRect
+	barcodeRect   image.Rectangle
 }
 
-// renderTextLines adds all text lines to the label image
-func renderTextLines(img *image.RGBA, input BarcodeInput, barcodeRect image.Rectangle) error {
-	for _, textLine := range input.TextLines {
-		textY := calculateTextYPosition(barcodeRect, textLine.Position)
-		addTextLine(img, textLine.Text, img.Bounds().Dx()/2, textY, textLine.Size, input.Dpi, textLine.Position)
+// layoutLabel scales the barcode and centers it together with its text,
+// between any side columns, or in its BarcodePlacement when one is set
+func layoutLabel(input BarcodeInput, bc barcode.Barcode, dpi int) (labelLayout, error) {
+	layoutWidth := mmToPixels(input.Width, input.Dpi)
+	layoutHeight := mmToPixels(input.Height, input.Dpi)
+	left, right := sideColumnWidths(input)
+	left, right = sideColumnSpace(left, labelMargin(input)), sideColumnSpace(right, labelMargin(input))
+
+	available := calculateRotatedBarcodeSize(input, layoutWidth-left-right, layoutHeight)
+	if input.BarcodePlacement.isSet() {
+		available = placedBarcodeSize(input)
 	}
-	return nil
+	barcodeSize, err := fitXDimension(input, bc, available)
+	if err != nil {
+		return labelLayout{}, err
+	}
+	barcodeSize = scaleSizeToDPI(barcodeSize, input.Dpi, dpi)
+	labelWidth, labelHeight := mmToPixels(input.Width, dpi), mmToPixels(input.Height, dpi)
+	if err := validateRenderSize(labelWidth, labelHeight, barcodeSize); err != nil {
+		return labelLayout{}, err
+	}
+
+	var scaledBc barcode.Barcode
+	reduction := input.BarWidthReduction.pixels(input.Dpi, dpi)
+	if isHumanReadableEAN(input) {
+		scaledBc, err = scaleEANWithHumanReadable(bc, barcodeSize, dpi, reduction)
+	} else {
+		scaledBc, err = scaleBarcodeToFit(bc, barcodeSize)
+		scaledBc = reduceBarWidths(scaledBc, reduction)
+	}
+	if err != nil {
+		return labelLayout{}, err
+	}
+	if ean, ok := scaledBc.(*humanReadableEAN); ok && input.BilevelText {
+		thresholdLabel(ean.img)
+	}
+	scaledBc = rotateBarcode(scaledBc, input.BarcodeRotation)
+
+	if input.BarcodePlacement.isSet() {
+		area := input.BarcodePlacement.rect(dpi)
+		barcodeRect := centerBarcodeOnLabel(image.Rect(0, 0, area.Dx(), area.Dy()), scaledBc).Add(area.Min)
+		return labelLayout{width: labelWidth, height: labelHeight, barcode: scaledBc, barcodeRect: barcodeRect}, nil
+	}
+
+	barcodeRect := centerBarcodeOnLabel(image.Rect(0, 0, labelWidth, labelHeight), scaledBc)
+	barcodeRect = barcodeRect.Add(image.Pt((left-right)*dpi/input.Dpi/2, calculateTextBlockShift(labelTextLines(input), dpi, labelFontScale(input))))
+
+	return labelLayout{width: labelWidth, height: labelHeight, barcode: scaledBc, barcodeRect: barcodeRect}, nil
 }
 
-// generateOutputFormats converts the label image to PNG and ZPL formats
-func generateOutputFormats(img *image.RGBA) (*BarcodeOutput, error) {
-	base64Image, err := imageToBase64(img)
+// renderTextLines adds all text lines to the label image.
+// Lines sharing a position are stacked in order, top to bottom, except lines
+// on the same row, and aligned across the label or in their side column.
+// Lines in a fit group are drawn at their group's shared scale; all other
+// lines are sized independently. Rotated lines are drawn as a turned image.
+func renderTextLines(img *image.RGBA, input BarcodeInput, barcodeRect image.Rectangle, dpi int) error {
+	fontScale := labelFontScale(input)
+	area := labelTextArea(input, img)
+	textLines, err := applyTextOverflow(labelTextLines(input), area, dpi, fontScale)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert image to base64: %w", err)
+		return err
+	}
+	groupScales := calculateFitGroupScales(textLines, area, float64(dpi), fontScale)
+
+	offsets := calculateTextLineOffsets(textLines, dpi, fontScale)
+
+	// Bilevel text is drawn on a transparent layer whose coverage is then
+	// thresholded onto the label
+	target := img
+	if input.BilevelText {
+		target = image.NewRGBA(img.Bounds())
+	}
+
+	for i, textLine := range textLines {
+		textY := textLineBaseY(textLine, barcodeRect, offsets[i], dpi)
+		anchor := textLineAnchor(textLine, area, dpi)
+		if isRotatedText(textLine) {
+			fontSize := fittedFontSize(textLine, area, groupScales, dpi, fontScale)
+			drawRotatedText(target, textLine, rotatedTextRect(textLine, anchor, textY, fontSize, dpi, fontScale), fontSize, dpi)
+			continue
+		}
+		if scale, ok := groupScales[textLine.FitGroup]; ok {
+			addScaledTextLine(target, textLine, anchor, textY, float64(dpi), fontScale, scale)
+			continue
+		}
+		addTextLine(target, textLine, anchor, textY, float64(dpi), area.maxLength(textLine), fontScale)
+	}
+
+	if input.BilevelText {
+		drawBilevelText(img, target)
+	}
+	return nil
+}
+
+// generateOutputFormats converts the preview image to PNG and the print image
+// to PDF, TIFF and BMP, as requested
+func generateOutputFormats(input BarcodeInput, formats map[OutputFormat]bool, previewImg, printImg *image.RGBA) (*BarcodeOutput, error) {
+	output := &BarcodeOutput{}
+	previewDpi := input.Dpi
+	if input.PreviewDpi != 0 {
+		previewDpi = input.PreviewDpi
 	}
 
-	zplCode := imageToZPL(img)
+	var err error
+	if formats[OutputFormatPNG] {
+		if output.ImageBase64, err = imageFormatBase64(input, OutputFormatPNG, previewImg, previewDpi); err != nil {
+			return nil, err
+		}
+	}
+	if formats[OutputFormatPDF] {
+		if output.PDFBase64, err = imageFormatBase64(input, OutputFormatPDF, printImg, input.Dpi); err != nil {
+			return nil, err
+		}
+	}
+	if formats[OutputFormatTIFF] {
+		if output.TIFFBase64, err = imageFormatBase64(input, OutputFormatTIFF, printImg, input.Dpi); err != nil {
+			return nil, err
+		}
+	}
+	if formats[OutputFormatBMP] {
+		if output.BMPBase64, err = imageFormatBase64(input, OutputFormatBMP, printImg, input.Dpi); err != nil {
+			return nil, err
+		}
+	}
+	return output, nil
+}
 
-	return &BarcodeOutput{
-		ImageBase64: base64Image,
-		ZPL:         zplCode,
-	}, nil
+// imageFormatBase64 encodes a label image as a PNG, PDF, TIFF or BMP in base64
+func imageFormatBase64(input BarcodeInput, format OutputFormat, img *image.RGBA, dpi int) (string, error) {
+	var buf bytes.Buffer
+	encoder := base64.NewEncoder(base64.StdEncoding, &buf)
+	if err := writeImageFormat(encoder, input, format, img, dpi); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
 }
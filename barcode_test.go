@@ -1,8 +1,17 @@
 package barcode
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
 	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -27,6 +36,42 @@ func TestValidateDPI_InvalidValue(t *testing.T) {
 	assert.Contains(t, err.Error(), "invalid dpi value")
 }
 
+// TestValidateNonStandardDPI ensures resolutions such as 305 and 406 pass
+// with AllowNonStandardDPI while values outside the range are rejected
+func TestValidateNonStandardDPI(t *testing.T) {
+	for _, dpi := range []int{152, 203, 305, 406, 1200} {
+		assert.NoError(t, validateNonStandardDPI(dpi), "DPI %d should be valid", dpi)
+	}
+	for _, dpi := range []int{0, -203, 99, 2400} {
+		assert.ErrorContains(t, validateNonStandardDPI(dpi), "Non-standard dpi values must be between 100 and 1200")
+	}
+}
+
+// TestGenerateBarcode_NonStandardDPI ensures labels render at a non-standard
+// DPI only when AllowNonStandardDPI is set
+func TestGenerateBarcode_NonStandardDPI(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:   "LOC-A1-B2-C3",
+		BarcodeType:   BarcodeTypeCode128,
+		Width:         50,
+		Height:        25,
+		Dpi:           406,
+		TextLines:     []TextLine{{Text: "AISLE 1", Position: TextPositionBelow, Size: TextSizeMedium}},
+		OutputFormats: []OutputFormat{OutputFormatZPL},
+	}
+	_, err := GenerateBarcode(input)
+	assert.ErrorContains(t, err, "invalid dpi value: 406")
+
+	input.AllowNonStandardDPI = true
+	img, _, err := GenerateBarcodeImage(input)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, mmToPixels(50, 406), mmToPixels(25, 406)), img.Bounds())
+
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Contains(t, output.ZPL, "^GFA,")
+}
+
 // TestValidateBarcodeType_Valid ensures supported types pass validation
 func TestValidateBarcodeType_Valid(t *testing.T) {
 	validTypes := []BarcodeType{BarcodeTypeCode128, BarcodeTypeQR}
@@ -116,6 +161,39 @@ func TestGenerateBarcode_InvalidInput(t *testing.T) {
 			},
 			expectedErr: "invalid dpi value",
 		},
+		{
+			name: "Zero Width",
+			input: BarcodeInput{
+				BarcodeData: "test",
+				BarcodeType: BarcodeTypeCode128,
+				Width:       0,
+				Height:      30.0,
+				Dpi:         203,
+			},
+			expectedErr: "Width must be positive",
+		},
+		{
+			name: "Negative Height",
+			input: BarcodeInput{
+				BarcodeData: "test",
+				BarcodeType: BarcodeTypeCode128,
+				Width:       50.0,
+				Height:      -10.0,
+				Dpi:         203,
+			},
+			expectedErr: "Height must be positive",
+		},
+		{
+			name: "Width Smaller Than Margins",
+			input: BarcodeInput{
+				BarcodeData: "test",
+				BarcodeType: BarcodeTypeCode128,
+				Width:       2.0,
+				Height:      30.0,
+				Dpi:         203,
+			},
+			expectedErr: "to fit the label margins",
+		},
 		{
 			name: "Invalid Barcode Type",
 			input: BarcodeInput{
@@ -227,6 +305,71 @@ func TestCalculateBarcodeSize_QR(t *testing.T) {
 	assert.Greater(t, size.X, 0, "Size should be positive")
 }
 
+// TestCalculateBarcodeSize_Margins verifies the margin setting replaces the
+// default margin and negative margins are rejected
+func TestCalculateBarcodeSize_Margins(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       100.0,
+		Height:      60.0,
+		Dpi:         600,
+		Margins:     Margins{MM: 3},
+	}
+
+	labelWidth := mmToPixels(input.Width, input.Dpi)
+	size := calculateBarcodeSize(input, labelWidth, mmToPixels(input.Height, input.Dpi))
+	assert.Equal(t, labelWidth-mmToPixels(3, input.Dpi)*2, size.X)
+
+	input.Margins.MM = -1
+	_, err := GenerateBarcode(input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid label margin")
+}
+
+// TestFitQuietZone verifies symbols are sized in whole modules with their
+// quiet zone clear, and other types or unset options keep the offered size
+func TestFitQuietZone(t *testing.T) {
+	input := BarcodeInput{BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeCode128, Margins: Margins{QuietZone: true}}
+	bc, err := encodeBarcode(input)
+	require.NoError(t, err)
+	modules := bc.Bounds().Dx()
+
+	size := fitQuietZone(input, bc, image.Pt((modules+20)*3+2, 100))
+	assert.Equal(t, image.Pt(modules*3, 100), size)
+
+	input.Margins.QuietZone = false
+	assert.Equal(t, image.Pt(500, 100), fitQuietZone(input, bc, image.Pt(500, 100)))
+
+	qrInput := BarcodeInput{BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeQR, Margins: Margins{QuietZone: true}}
+	qrCode, err := encodeBarcode(qrInput)
+	require.NoError(t, err)
+	qrModules := qrCode.Bounds().Dx()
+	assert.Equal(t, image.Pt(qrModules*4, qrModules*4), fitQuietZone(qrInput, qrCode, image.Pt((qrModules+8)*4, (qrModules+8)*4)))
+}
+
+// TestGenerateBarcode_QuietZone verifies the rendered Code128 keeps ten
+// modules clear on each side at 600 DPI
+func TestGenerateBarcode_QuietZone(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1-B2-C3",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50.0,
+		Height:      25.0,
+		Dpi:         600,
+		Margins:     Margins{MM: 1, QuietZone: true},
+	}
+	bc, err := encodeBarcode(input)
+	require.NoError(t, err)
+
+	img, layout, err := GenerateBarcodeImage(input)
+	require.NoError(t, err)
+	moduleWidth := layout.BarcodeRect.Dx() / bc.Bounds().Dx()
+	require.Equal(t, bc.Bounds().Dx()*moduleWidth, layout.BarcodeRect.Dx(), "The symbol should be whole modules")
+	assert.GreaterOrEqual(t, layout.BarcodeRect.Min.X, moduleWidth*10)
+	assert.GreaterOrEqual(t, img.Bounds().Max.X-layout.BarcodeRect.Max.X, moduleWidth*10)
+}
+
 // TestGetFontSize verifies font sizing and scaling
 func TestGetFontSize(t *testing.T) {
 	tests := []struct {
@@ -242,9 +385,632 @@ func TestGetFontSize(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			fontSize, height := getFontSize(tt.size, tt.dpi, 200)
+			fontSize, height := getFontSize(tt.size, tt.dpi, 1.1)
 			assert.Greater(t, fontSize, 0.0, "Font size should be positive")
 			assert.Greater(t, height, 0.0, "Font height should be positive")
 		})
 	}
 }
+
+// TestGenerateBarcode_OutputFormats verifies only the requested formats are produced
+func TestGenerateBarcode_OutputFormats(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1-B2-C3",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50.0,
+		Height:      30.0,
+		Dpi:         203,
+	}
+
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.NotEmpty(t, output.ImageBase64, "PNG should be produced by default")
+	assert.NotEmpty(t, output.ZPL, "ZPL should be produced by default")
+
+	input.OutputFormats = []OutputFormat{OutputFormatPNG}
+	input.PreviewDpi = 300
+	output, err = GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.NotEmpty(t, output.ImageBase64)
+	assert.Empty(t, output.ZPL, "ZPL should be skipped when not requested")
+
+	input.OutputFormats = []OutputFormat{OutputFormatZPL, OutputFormatEPL}
+	output, err = GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Empty(t, output.ImageBase64, "PNG should be skipped when not requested")
+	assert.NotEmpty(t, output.ZPL)
+	assert.NotEmpty(t, output.EPL)
+
+	input.OutputFormats = []OutputFormat{"SVG"}
+	_, err = GenerateBarcode(input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid output format: SVG")
+}
+
+// TestFontScaling verifies text scales with the physical label width, not its pixel width
+func TestFontScaling(t *testing.T) {
+	for _, dpi := range []int{203, 300, 600} {
+		input := BarcodeInput{Width: 50.0, Dpi: dpi}
+		assert.InDelta(t, 1.2, labelFontScale(input), 0.0001, "50mm labels should scale the same at %d DPI", dpi)
+	}
+
+	assert.Equal(t, 1.1, labelFontScale(BarcodeInput{Width: 30.0}), "Narrow labels should use the minimum scale")
+	assert.Equal(t, 2.0, labelFontScale(BarcodeInput{Width: 200.0}), "Wide labels should use the maximum scale")
+	assert.Equal(t, 1.0, labelFontScale(BarcodeInput{Width: 100.0, FontScaling: FontScaling{Min: 1, Max: 1}}), "Min and Max of 1 should turn scaling off")
+	assert.InDelta(t, 1.5, labelFontScale(BarcodeInput{Width: 100.0, FontScaling: FontScaling{BaselineMM: 50, PerMM: 0.01}}), 0.0001)
+
+	_, err := GenerateBarcode(BarcodeInput{
+		BarcodeData: "LOC-A1",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50.0,
+		Height:      30.0,
+		Dpi:         203,
+		FontScaling: FontScaling{Min: 1.5, Max: 1.2},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid font scaling")
+}
+
+// TestGetTextLineFontSize_Exact verifies an exact point size ignores the
+// text size and the label's font scale, and sets the ZPL font height
+func TestGetTextLineFontSize_Exact(t *testing.T) {
+	line := TextLine{Text: "SKU 10442", Position: TextPositionBelow, FontSizePt: 9}
+	for _, fontScale := range []float64{1.1, 2.0} {
+		fontSize, height := getTextLineFontSize(line, 203, fontScale)
+		assert.Equal(t, 9.0, fontSize)
+		assert.Equal(t, calculateFontHeight(fontRef{}, 9, 203), height)
+	}
+
+	for _, width := range []float64{50.0, 150.0} {
+		output, err := GenerateBarcode(BarcodeInput{
+			BarcodeData: "10442",
+			BarcodeType: BarcodeTypeCode128,
+			Width:       width,
+			Height:      30.0,
+			Dpi:         203,
+			TextLines:   []TextLine{line},
+			ZPL:         ZPLOptions{NativeCommands: true},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, output.ZPL, "^A0N,25,25^FDSKU 10442^FS", "9pt at 203 DPI is 25 dots on a %gmm label", width)
+	}
+}
+
+// TestGenerateBarcode_PreviewDPI verifies the PNG is rendered at the preview DPI
+// while the ZPL keeps the printer geometry
+func TestGenerateBarcode_PreviewDPI(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1-B2-C3",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50.0,
+		Height:      30.0,
+		Dpi:         203,
+		TextLines: []TextLine{
+			{
+				Text:     "LOC-A1-B2-C3",
+				Position: TextPositionBelow,
+				Size:     TextSizeMedium,
+			},
+		},
+	}
+
+	printOnly, err := GenerateBarcode(input)
+	require.NoError(t, err)
+
+	input.PreviewDpi = 300
+	withPreview, err := GenerateBarcode(input)
+	require.NoError(t, err)
+
+	assert.Equal(t, printOnly.ZPL, withPreview.ZPL, "ZPL should not change when a preview DPI is set")
+
+	raw, err := base64.StdEncoding.DecodeString(withPreview.ImageBase64)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(raw))
+	require.NoError(t, err)
+
+	assert.Equal(t, mmToPixels(input.Width, 300), img.Bounds().Dx(), "PNG width should match the preview DPI")
+	assert.Equal(t, mmToPixels(input.Height, 300), img.Bounds().Dy(), "PNG height should match the preview DPI")
+}
+
+// TestGenerateBarcode_PNGResolution verifies the PNG records its DPI so it opens at the label size
+func TestGenerateBarcode_PNGResolution(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1-B2-C3",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50.0,
+		Height:      30.0,
+		Dpi:         203,
+	}
+
+	for _, previewDpi := range []int{0, 300} {
+		input.PreviewDpi = previewDpi
+		output, err := GenerateBarcode(input)
+		require.NoError(t, err)
+
+		raw, err := base64.StdEncoding.DecodeString(output.ImageBase64)
+		require.NoError(t, err)
+		img, err := png.Decode(bytes.NewReader(raw))
+		require.NoError(t, err, "pHYs chunk should keep the PNG valid")
+
+		dpi := input.Dpi
+		if previewDpi != 0 {
+			dpi = previewDpi
+		}
+		require.Equal(t, "pHYs", string(raw[pngIHDREnd+4:pngIHDREnd+8]))
+		pixelsPerMeter := binary.BigEndian.Uint32(raw[pngIHDREnd+8:])
+		assert.Equal(t, pixelsPerMeter, binary.BigEndian.Uint32(raw[pngIHDREnd+12:]))
+		assert.Equal(t, byte(1), raw[pngIHDREnd+16], "unit should be the meter")
+
+		widthMM := float64(img.Bounds().Dx()) / float64(pixelsPerMeter) * 1000
+		assert.InDelta(t, input.Width, widthMM, 25.4/float64(dpi), "PNG should open at the label width")
+	}
+}
+
+// TestGenerateBarcode_Monochrome verifies the PNG is a 1-bit image that is much smaller than the RGBA one
+func TestGenerateBarcode_Monochrome(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1-B2-C3",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50.0,
+		Height:      30.0,
+		Dpi:         203,
+		TextLines:   []TextLine{{Text: "LOC-A1-B2-C3", Position: TextPositionBelow, Size: TextSizeMedium}},
+	}
+	rgba, err := GenerateBarcode(input)
+	require.NoError(t, err)
+
+	input.Monochrome = true
+	mono, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Equal(t, rgba.ZPL, mono.ZPL, "ZPL should not change for a monochrome PNG")
+
+	raw, err := base64.StdEncoding.DecodeString(mono.ImageBase64)
+	require.NoError(t, err)
+	assert.Equal(t, byte(1), raw[24], "IHDR bit depth should be 1")
+	assert.Less(t, len(mono.ImageBase64)*4, len(rgba.ImageBase64))
+
+	img, err := png.Decode(bytes.NewReader(raw))
+	require.NoError(t, err)
+	paletted, ok := img.(*image.Paletted)
+	require.True(t, ok)
+	assert.Len(t, paletted.Palette, 2)
+	assert.Equal(t, mmToPixels(input.Width, input.Dpi), img.Bounds().Dx())
+}
+
+// TestGenerateBarcodeImage verifies the image matches the PNG output and the layout locates the barcode
+func TestGenerateBarcodeImage(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1-B2-C3",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50.0,
+		Height:      30.0,
+		Dpi:         203,
+		PreviewDpi:  300,
+		TextLines:   []TextLine{{Text: "LOC-A1-B2-C3", Position: TextPositionBelow, Size: TextSizeMedium}},
+	}
+
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	raw, err := base64.StdEncoding.DecodeString(output.ImageBase64)
+	require.NoError(t, err)
+	expected, err := png.Decode(bytes.NewReader(raw))
+	require.NoError(t, err)
+
+	img, layout, err := GenerateBarcodeImage(input)
+	require.NoError(t, err)
+	assert.Equal(t, 300, layout.Dpi)
+	require.Equal(t, expected.Bounds(), img.Bounds())
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		for x := 0; x < img.Bounds().Dx(); x++ {
+			require.Equal(t, color.RGBAModel.Convert(expected.At(x, y)), color.RGBAModel.Convert(img.At(x, y)), "pixel %d,%d", x, y)
+		}
+	}
+
+	// The bars on the middle row of the barcode all lie within its rectangle
+	rect := layout.BarcodeRect
+	require.True(t, rect.In(img.Bounds()))
+	bars := 0
+	for x := 0; x < img.Bounds().Dx(); x++ {
+		if color.GrayModel.Convert(img.At(x, (rect.Min.Y+rect.Max.Y)/2)).(color.Gray).Y < 128 {
+			assert.True(t, x >= rect.Min.X && x < rect.Max.X, "bar at x=%d outside %v", x, rect)
+			bars++
+		}
+	}
+	assert.Positive(t, bars)
+
+	input.Mirror = true
+	_, mirroredLayout, err := GenerateBarcodeImage(input)
+	require.NoError(t, err)
+	assert.Equal(t, mirrorRect(img.Bounds(), rect), mirroredLayout.BarcodeRect)
+
+	_, _, err = GenerateBarcodeImage(BarcodeInput{BarcodeType: BarcodeTypeCode128, Width: 50, Height: 30, Dpi: 150})
+	assert.Error(t, err)
+}
+
+// TestBarcodeOutput_ImageDataURL verifies the data URL prefix and that it is empty without a PNG
+func TestBarcodeOutput_ImageDataURL(t *testing.T) {
+	input := BarcodeInput{BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeCode128, Width: 50.0, Height: 25.0, Dpi: 203}
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Equal(t, "data:image/png;base64,"+output.ImageBase64, output.ImageDataURL())
+
+	input.OutputFormats = []OutputFormat{OutputFormatZPL}
+	output, err = GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Empty(t, output.ImageDataURL())
+}
+
+// TestValidatePreviewDPI ensures negative preview DPI values are rejected
+func TestValidatePreviewDPI(t *testing.T) {
+	assert.NoError(t, validatePreviewDPI(0), "Zero preview DPI means use the printer DPI")
+	assert.NoError(t, validatePreviewDPI(144), "Screen DPI values should be accepted")
+
+	err := validatePreviewDPI(-1)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid preview dpi value")
+}
+
+// TestCalculateFitGroupScales verifies grouped lines share the smallest scale
+func TestCalculateFitGroupScales(t *testing.T) {
+	area := textArea{width: mmToPixels(50.0, 300), margin: labelMarginPixels}
+	textLines := []TextLine{
+		{Text: "Jane Doe", Size: TextSizeLarge, FitGroup: "address"},
+		{Text: "1234 Very Long Industrial Parkway Extension, Building 7", Size: TextSizeMedium, FitGroup: "address"},
+		{Text: "Short", Size: TextSizeMedium},
+	}
+
+	scales := calculateFitGroupScales(textLines, area, 300, 1.25)
+
+	require.Contains(t, scales, "address")
+	assert.NotContains(t, scales, "", "Ungrouped lines should not get a scale")
+	assert.Less(t, scales["address"], 1.0, "Long line should force the group to shrink")
+
+	longFontSize, _ := getFontSize(TextSizeMedium, 300, 1.25)
+	expected := fitFontSize(fontRef{}, textLines[1].Text, longFontSize, 300, area.maxWidth(TextPositionBelow)) / longFontSize
+	assert.InDelta(t, expected, scales["address"], 0.0001, "Group should use the longest line's scale")
+}
+
+// TestGenerateBarcode_FitGroup verifies labels with fit groups render
+func TestGenerateBarcode_FitGroup(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "SHIP-0001",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50.0,
+		Height:      30.0,
+		Dpi:         203,
+		TextLines: []TextLine{
+			{Text: "ACME Distribution Center North", Position: TextPositionAbove, Size: TextSizeLarge, FitGroup: "address"},
+			{Text: "Dock 4", Position: TextPositionBelow, Size: TextSizeSmall, FitGroup: "address"},
+		},
+	}
+
+	output, err := GenerateBarcode(input)
+
+	require.NoError(t, err, "Should successfully generate barcode with a fit group")
+	assert.NotEmpty(t, output.ImageBase64, "Image should not be empty")
+}
+
+// TestCalculateTextLineOffsets verifies lines sharing a position are stacked
+func TestCalculateTextLineOffsets(t *testing.T) {
+	lines := []TextLine{
+		{Text: "Top", Position: TextPositionAbove, Size: TextSizeMedium},
+		{Text: "Nearest above", Position: TextPositionAbove, Size: TextSizeMedium},
+		{Text: "Nearest below", Position: TextPositionBelow, Size: TextSizeMedium},
+		{Text: "Bottom", Position: TextPositionBelow, Size: TextSizeMedium},
+	}
+
+	offsets := calculateTextLineOffsets(lines, 203, 1.4)
+	_, height := getFontSize(TextSizeMedium, 203, 1.4)
+
+	assert.Equal(t, []int{-int(height), 0, 0, int(height)}, offsets)
+}
+
+// TestRecoverToError verifies panics are converted into errors
+func TestRecoverToError(t *testing.T) {
+	run := func() (err error) {
+		defer recoverToError(&err)
+		panic("nil dereference")
+	}
+
+	err := run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nil dereference")
+}
+
+// TestGenerateBarcode_LabelTooSmall verifies labels with no room left for the
+// barcode fail before rendering
+func TestGenerateBarcode_LabelTooSmall(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1",
+		BarcodeType: BarcodeTypeQR,
+		Width:       20.0,
+		Height:      8.0,
+		Dpi:         203,
+		TextLines: []TextLine{
+			{Text: "A", Position: TextPositionAbove, Size: TextSizeLarge},
+			{Text: "B", Position: TextPositionBelow, Size: TextSizeLarge},
+		},
+	}
+
+	_, err := GenerateBarcode(input)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "label too small")
+}
+
+// TestWarm verifies the label font is parsed once and shared
+func TestWarm(t *testing.T) {
+	require.NoError(t, Warm())
+
+	first, err := labelFont()
+	require.NoError(t, err)
+	second, err := labelFont()
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+}
+
+// TestMeasureText verifies text is measured at its rendered size and shrunk to fit
+func TestMeasureText(t *testing.T) {
+	fits, err := MeasureText("LOC-A1", TextSizeMedium, 203, 500)
+	require.NoError(t, err)
+	assert.False(t, fits.Reduced, "Short text should keep its font size")
+	assert.LessOrEqual(t, fits.Width, 500)
+	assert.Greater(t, fits.Height, 0)
+
+	long, err := MeasureText("Warehouse A - Aisle 14 - Rack 3 - Shelf 2 - Bin 7", TextSizeLarge, 203, 200)
+	require.NoError(t, err)
+	assert.True(t, long.Reduced, "Long text should be shrunk")
+	assert.LessOrEqual(t, long.Width, 200)
+
+	_, err = MeasureText("x", TextSizeSmall, 203, 0)
+	assert.Error(t, err)
+}
+
+// TestMirrorLabel verifies the layout is flipped while the barcode keeps its orientation
+func TestMirrorLabel(t *testing.T) {
+	label := createBlankLabel(10, 4)
+	label.Set(0, 0, color.Black) // Text pixel in the top-left corner
+
+	// Barcode occupying columns 1-3 with a bar only in its first column
+	barcodeRect := image.Rect(1, 2, 4, 4)
+	label.Set(1, 2, color.Black)
+	label.Set(1, 3, color.Black)
+
+	mirrored := mirrorLabel(label, barcodeRect)
+
+	assert.Equal(t, color.RGBA{A: 255}, mirrored.RGBAAt(9, 0), "Text should move to the right edge")
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, mirrored.RGBAAt(0, 0))
+
+	// The barcode moves to columns 6-8 with its bar still in its first column
+	assert.Equal(t, color.RGBA{A: 255}, mirrored.RGBAAt(6, 2))
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, mirrored.RGBAAt(8, 2))
+}
+
+// TestGenerateBarcode_Mirror verifies mirrored labels differ from the normal layout
+func TestGenerateBarcode_Mirror(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50.0,
+		Height:      25.0,
+		Dpi:         203,
+		TextLines: []TextLine{
+			{Text: "LOC-A1", Position: TextPositionBelow, Size: TextSizeMedium},
+		},
+	}
+
+	normal, err := GenerateBarcode(input)
+	require.NoError(t, err)
+
+	input.Mirror = true
+	mirrored, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.NotEqual(t, normal.ZPL, mirrored.ZPL)
+}
+
+// TestRotateLabel180 verifies the top-left pixel moves to the bottom-right
+func TestRotateLabel180(t *testing.T) {
+	label := createBlankLabel(10, 4)
+	label.Set(0, 0, color.Black)
+
+	rotated := rotateLabel180(label)
+	assert.Equal(t, color.RGBA{A: 255}, rotated.RGBAAt(9, 3))
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, rotated.RGBAAt(0, 0))
+}
+
+// TestGenerateBarcode_UpsideDown verifies ZPL is inverted with ^POI while
+// other printer languages send the turned print image and the PNG stays upright
+func TestGenerateBarcode_UpsideDown(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:   "LOC-A1",
+		BarcodeType:   BarcodeTypeCode128,
+		Width:         50.0,
+		Height:        25.0,
+		Dpi:           203,
+		OutputFormats: []OutputFormat{OutputFormatPNG, OutputFormatZPL, OutputFormatTSPL},
+		ZPL:           ZPLOptions{NativeCommands: true},
+	}
+	normal, err := GenerateBarcode(input)
+	require.NoError(t, err)
+
+	input.UpsideDown = true
+	inverted, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Equal(t, normal.ImageBase64, inverted.ImageBase64)
+	assert.Equal(t, strings.Replace(normal.ZPL, "^XA\n", "^XA\n^POI\n", 1), inverted.ZPL)
+	assert.Contains(t, normal.TSPL, "BARCODE ")
+	assert.Contains(t, inverted.TSPL, "BITMAP 0,0,50,199,0,")
+}
+
+// TestRotateBarcode verifies each quarter turn moves the corner module clockwise
+func TestRotateBarcode(t *testing.T) {
+	bc := &importedBarcode{modules: []bool{true, false, false, false, false, false}, width: 3, height: 2}
+
+	tests := []struct {
+		degrees int
+		bounds  image.Rectangle
+		corner  image.Point
+	}{
+		{0, image.Rect(0, 0, 3, 2), image.Pt(0, 0)},
+		{90, image.Rect(0, 0, 2, 3), image.Pt(1, 0)},
+		{180, image.Rect(0, 0, 3, 2), image.Pt(2, 1)},
+		{270, image.Rect(0, 0, 2, 3), image.Pt(0, 2)},
+	}
+
+	for _, tt := range tests {
+		rotated := rotateBarcode(bc, tt.degrees)
+		assert.Equal(t, tt.bounds, rotated.Bounds(), "%d degrees", tt.degrees)
+		assert.Equal(t, color.Black, rotated.At(tt.corner.X, tt.corner.Y), "%d degrees", tt.degrees)
+	}
+}
+
+// TestGenerateBarcode_BarcodeRotation verifies a quarter-turned Code128 runs
+// along the height of a narrow tag and invalid rotations are rejected
+func TestGenerateBarcode_BarcodeRotation(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:     "ASSET-000123",
+		BarcodeType:     BarcodeTypeCode128,
+		Width:           20,
+		Height:          60,
+		Dpi:             203,
+		BarcodeRotation: 90,
+		TextLines:       []TextLine{{Text: "A-123", Position: TextPositionBelow, Size: TextSizeSmall}},
+	}
+
+	bc, err := encodeBarcode(input)
+	require.NoError(t, err)
+	layout, err := layoutLabel(input, bc, input.Dpi)
+	require.NoError(t, err)
+	assert.Greater(t, layout.barcodeRect.Dy(), layout.barcodeRect.Dx())
+	assert.LessOrEqual(t, layout.barcodeRect.Dx(), layout.width)
+
+	_, err = GenerateBarcode(input)
+	require.NoError(t, err)
+
+	input.BarcodeRotation = 45
+	_, err = GenerateBarcode(input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid barcode rotation")
+}
+
+// TestRotateLabel verifies each quarter turn moves the top-left pixel
+// clockwise and rotateRect follows it
+func TestRotateLabel(t *testing.T) {
+	label := createBlankLabel(10, 4)
+	label.Set(0, 0, color.Black)
+	pixel := image.Rect(0, 0, 1, 1)
+
+	tests := []struct {
+		degrees int
+		bounds  image.Rectangle
+		corner  image.Point
+	}{
+		{0, image.Rect(0, 0, 10, 4), image.Pt(0, 0)},
+		{90, image.Rect(0, 0, 4, 10), image.Pt(3, 0)},
+		{180, image.Rect(0, 0, 10, 4), image.Pt(9, 3)},
+		{270, image.Rect(0, 0, 4, 10), image.Pt(0, 9)},
+	}
+
+	for _, tt := range tests {
+		rotated := rotateLabel(label, tt.degrees)
+		assert.Equal(t, tt.bounds, rotated.Bounds(), "%d degrees", tt.degrees)
+		assert.Equal(t, color.RGBA{A: 255}, rotated.RGBAAt(tt.corner.X, tt.corner.Y), "%d degrees", tt.degrees)
+		assert.Equal(t, image.Rectangle{Min: tt.corner, Max: tt.corner.Add(image.Pt(1, 1))},
+			rotateRect(label.Bounds().Size(), pixel, tt.degrees), "%d degrees", tt.degrees)
+	}
+}
+
+// TestGenerateBarcode_Rotation verifies a quarter-turned label swaps its
+// dimensions in the PNG and printer outputs, and invalid rotations are rejected
+func TestGenerateBarcode_Rotation(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:   "LOC-A1",
+		BarcodeType:   BarcodeTypeCode128,
+		Width:         50.0,
+		Height:        25.0,
+		Dpi:           203,
+		Rotation:      90,
+		OutputFormats: []OutputFormat{OutputFormatPNG, OutputFormatZPL, OutputFormatTSPL},
+		ZPL:           ZPLOptions{NativeCommands: true, SetPrintWidth: true, SetLabelLength: true},
+	}
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+
+	raw, err := base64.StdEncoding.DecodeString(output.ImageBase64)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 199, 399), img.Bounds())
+	assert.Contains(t, output.ZPL, "^PW199\n")
+	assert.Contains(t, output.ZPL, "^LL399\n")
+	assert.NotContains(t, output.ZPL, "^BC")
+	assert.Contains(t, output.TSPL, "SIZE 25 mm,50 mm")
+
+	labelImg, layout, err := GenerateBarcodeImage(input)
+	require.NoError(t, err)
+	assert.True(t, layout.BarcodeRect.In(labelImg.Bounds()))
+	assert.Greater(t, layout.BarcodeRect.Dy(), layout.BarcodeRect.Dx())
+
+	input.Rotation = 45
+	_, err = GenerateBarcode(input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid label rotation")
+}
+
+// TestGenerateBarcode_AutoRotate ensures a barcode too long for the label
+// upright is laid out in landscape on the same label, and labels that fit
+// upright are left upright
+func TestGenerateBarcode_AutoRotate(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:   "LOC-A1-B2-C3-D4-E5",
+		BarcodeType:   BarcodeTypeCode128,
+		Width:         20,
+		Height:        60,
+		Dpi:           203,
+		TextLines:     []TextLine{{Text: "LOC-A1-B2-C3-D4-E5", Position: TextPositionBelow, Size: TextSizeSmall}},
+		OutputFormats: []OutputFormat{OutputFormatZPL},
+	}
+	_, err := GenerateBarcode(input)
+	require.Error(t, err)
+
+	input.AutoRotate = true
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.True(t, output.Landscape)
+	assert.Contains(t, output.ZPL, fmt.Sprintf("^GFA,%d,", (mmToPixels(20, 203)+7)/8*mmToPixels(60, 203)))
+
+	img, layout, err := GenerateBarcodeImage(input)
+	require.NoError(t, err)
+	assert.True(t, layout.Landscape)
+	assert.Equal(t, image.Rect(0, 0, mmToPixels(20, 203), mmToPixels(60, 203)), img.Bounds())
+	assert.Greater(t, layout.BarcodeRect.Dy(), layout.BarcodeRect.Dx(), "the bars run across the label")
+
+	input.Width, input.Height = 60, 20
+	output, err = GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.False(t, output.Landscape)
+}
+
+// TestGenerateBarcodeCtx verifies labels are generated with a live context
+// and not rendered once it is cancelled or past its deadline
+func TestGenerateBarcodeCtx(t *testing.T) {
+	input := BarcodeInput{BarcodeData: "LOC-A1-B2-C3", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 600}
+	expected, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	output, err := GenerateBarcodeCtx(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, expected, output)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = GenerateBarcodeCtx(ctx, input)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, _, err = GenerateBarcodeImageCtx(ctx, input)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	ctx, cancel = context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	input.AutoRotate = true
+	_, err = GenerateBarcodeCtx(ctx, input)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,101 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/boombuler/barcode"
+)
+
+// BarWidthReduction narrows every bar of the barcode to offset printhead
+// bleed, which makes bars print wider than they are drawn. Set one of Dots
+// or MM.
+type BarWidthReduction struct {
+	Dots int     // Reduction in printer dots at Dpi
+	MM   float64 // Reduction in millimeters, converted to dots at each DPI
+}
+
+// pixels returns the reduction in pixels when rendering at dpi for a label
+// printed at printerDpi
+func (r BarWidthReduction) pixels(printerDpi, dpi int) int {
+	if r.MM != 0 {
+		return int(math.Round(r.MM * float64(dpi) / 25.4))
+	}
+	return int(math.Round(float64(r.Dots*dpi) / float64(printerDpi)))
+}
+
+// reducedBarcode is a scaled barcode with its bars narrowed. Printer
+// languages cannot narrow the bars of native barcode commands, so it is sent
+// as a graphic.
+type reducedBarcode struct {
+	barcode.Barcode
+	img *image.Gray
+}
+
+func (bc *reducedBarcode) ColorModel() color.Model { return color.GrayModel }
+func (bc *reducedBarcode) Bounds() image.Rectangle { return bc.img.Bounds() }
+func (bc *reducedBarcode) At(x, y int) color.Color { return bc.img.At(x, y) }
+
+// reduceBarWidths narrows each dark run of a scaled barcode by the reduction
+// in pixels, half from each edge, along its rows and, for 2D symbols, its
+// columns too. Every run keeps at least one pixel.
+func reduceBarWidths(bc barcode.Barcode, reduction int) barcode.Barcode {
+	if reduction <= 0 {
+		return bc
+	}
+
+	bounds := bc.Bounds()
+	original := image.NewGray(bounds)
+	draw.Draw(original, bounds, bc, bounds.Min, draw.Src)
+	reduced := image.NewGray(bounds)
+	copy(reduced.Pix, original.Pix)
+
+	dark := func(x, y int) bool { return original.GrayAt(x, y).Y < 128 }
+	lighten := func(x, y int) { reduced.SetGray(x, y, color.Gray{Y: 255}) }
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		trimDarkRuns(bounds.Min.X, bounds.Max.X, reduction, func(x int) bool { return dark(x, y) }, func(x int) { lighten(x, y) })
+	}
+	if bc.Metadata().Dimensions == 2 {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			trimDarkRuns(bounds.Min.Y, bounds.Max.Y, reduction, func(y int) bool { return dark(x, y) }, func(y int) { lighten(x, y) })
+		}
+	}
+	return &reducedBarcode{Barcode: bc, img: reduced}
+}
+
+// trimDarkRuns lightens the outer pixels of each dark run between start and
+// end, reduction pixels in total with the extra one at the trailing edge
+func trimDarkRuns(start, end, reduction int, dark func(int) bool, lighten func(int)) {
+	for i := start; i < end; {
+		if !dark(i) {
+			i++
+			continue
+		}
+		runStart := i
+		for i < end && dark(i) {
+			i++
+		}
+		trim := min(reduction, i-runStart-1)
+		for j := 0; j < trim/2; j++ {
+			lighten(runStart + j)
+		}
+		for j := 0; j < trim-trim/2; j++ {
+			lighten(i - 1 - j)
+		}
+	}
+}
+
+// validateBarWidthReduction ensures the reduction is not negative and is
+// given in one unit
+func validateBarWidthReduction(reduction BarWidthReduction) error {
+	if reduction.Dots < 0 || reduction.MM < 0 {
+		return fmt.Errorf("invalid bar width reduction: %d dots, %gmm. Reduction must not be negative", reduction.Dots, reduction.MM)
+	}
+	if reduction.Dots != 0 && reduction.MM != 0 {
+		return fmt.Errorf("invalid bar width reduction: %d dots, %gmm. Set either Dots or MM", reduction.Dots, reduction.MM)
+	}
+	return nil
+}
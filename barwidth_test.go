@@ -0,0 +1,103 @@
+package barcode
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// darkRuns returns the lengths of the dark runs along row y of img
+func darkRuns(img image.Image, y int) []int {
+	var runs []int
+	run := 0
+	for x := img.Bounds().Min.X; x <= img.Bounds().Max.X; x++ {
+		if x < img.Bounds().Max.X && color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y < 128 {
+			run++
+			continue
+		}
+		if run > 0 {
+			runs = append(runs, run)
+		}
+		run = 0
+	}
+	return runs
+}
+
+// TestBarWidthReductionPixels verifies dots scale with the rendering DPI and
+// millimeters are converted at it
+func TestBarWidthReductionPixels(t *testing.T) {
+	assert.Equal(t, 1, BarWidthReduction{Dots: 1}.pixels(203, 203))
+	assert.Equal(t, 3, BarWidthReduction{Dots: 1}.pixels(203, 600))
+	assert.Equal(t, 1, BarWidthReduction{MM: 0.125}.pixels(203, 203))
+	assert.Equal(t, 3, BarWidthReduction{MM: 0.125}.pixels(203, 600))
+	assert.Equal(t, 0, BarWidthReduction{}.pixels(203, 203))
+}
+
+// TestReduceBarWidths verifies every bar loses the reduction while keeping at
+// least one pixel, and spaces grow to keep the module pitch
+func TestReduceBarWidths(t *testing.T) {
+	input := BarcodeInput{BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeCode128}
+	bc, err := encodeBarcode(input)
+	require.NoError(t, err)
+	scaled, err := scaleBarcodeToFit(bc, image.Pt(bc.Bounds().Dx()*3, 20))
+	require.NoError(t, err)
+
+	assert.Same(t, scaled, reduceBarWidths(scaled, 0))
+
+	before, after := darkRuns(scaled, 10), darkRuns(reduceBarWidths(scaled, 1), 10)
+	require.Len(t, after, len(before))
+	for i := range before {
+		assert.Equal(t, before[i]-1, after[i])
+	}
+
+	thinnest := darkRuns(reduceBarWidths(scaled, 5), 10)
+	for _, run := range thinnest {
+		assert.GreaterOrEqual(t, run, 1)
+	}
+}
+
+// TestGenerateBarcode_BarWidthReduction verifies reduced bars are drawn in the
+// raster and sent as a graphic instead of a native barcode command
+func TestGenerateBarcode_BarWidthReduction(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:    "LOC-A1-B2-C3",
+		BarcodeType:    BarcodeTypeCode128,
+		Width:          80,
+		Height:         25,
+		Dpi:            203,
+		XDimensionMils: 15,
+		TextLines:      []TextLine{{Text: "LOC-A1-B2-C3", Position: TextPositionBelow, Size: TextSizeSmall}},
+		OutputFormats:  []OutputFormat{OutputFormatZPL, OutputFormatTSPL},
+		ZPL:            ZPLOptions{NativeCommands: true},
+	}
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Contains(t, output.ZPL, "^BCN,")
+	assert.Contains(t, output.TSPL, "BARCODE ")
+
+	input.BarWidthReduction = BarWidthReduction{Dots: 1}
+	img, layout, err := GenerateBarcodeImage(input)
+	require.NoError(t, err)
+	row := layout.BarcodeRect.Min.Y + layout.BarcodeRect.Dy()/2
+	for _, run := range darkRuns(img, row) {
+		assert.Contains(t, []int{2, 5, 8, 11}, run, "bars of 1-4 modules at 3 dots, less 1 dot")
+	}
+
+	output, err = GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.NotContains(t, output.ZPL, "^BCN,")
+	assert.Contains(t, output.ZPL, "^GFA,")
+	assert.Contains(t, output.ZPL, "^FDLOC-A1-B2-C3^FS")
+	assert.NotContains(t, output.TSPL, "BARCODE ")
+}
+
+// TestValidateBarWidthReduction verifies negative and doubly specified
+// reductions are rejected
+func TestValidateBarWidthReduction(t *testing.T) {
+	assert.NoError(t, validateBarWidthReduction(BarWidthReduction{MM: 0.1}))
+	assert.ErrorContains(t, validateBarWidthReduction(BarWidthReduction{Dots: -1}), "must not be negative")
+	assert.ErrorContains(t, validateBarWidthReduction(BarWidthReduction{Dots: 1, MM: 0.1}), "Set either Dots or MM")
+}
@@ -0,0 +1,103 @@
+package barcode
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// BatchOptions configures GenerateBatch
+type BatchOptions struct {
+	Workers  int                                                         // Optional: concurrent generators (defaults to GOMAXPROCS)
+	Generate func(context.Context, BarcodeInput) (*BarcodeOutput, error) // Optional: e.g. a Generator's GenerateCtx method (defaults to GenerateBarcodeCtx)
+}
+
+// BatchError reports the labels of a batch that failed, by their index in
+// the batch
+type BatchError struct {
+	Total  int           // Number of labels in the batch
+	Errors map[int]error // Failure of each label that failed
+}
+
+// Error names the number of failures and the first of them
+func (e *BatchError) Error() string {
+	first := e.Indexes()[0]
+	return fmt.Sprintf("batch failed: %d of %d labels failed. Label %d: %v", len(e.Errors), e.Total, first, e.Errors[first])
+}
+
+// Indexes returns the indexes of the labels that failed in ascending order
+func (e *BatchError) Indexes() []int {
+	indexes := make([]int, 0, len(e.Errors))
+	for index := range e.Errors {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// Unwrap returns the failures in label order, for errors.Is and errors.As
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, index := range e.Indexes() {
+		errs = append(errs, e.Errors[index])
+	}
+	return errs
+}
+
+// GenerateBatch generates labels across a bounded pool of workers. A label
+// that fails does not stop the others: outputs are returned in input order,
+// nil for failed labels, together with a *BatchError listing each failure by
+// index. The error is nil when every label was generated.
+func GenerateBatch(inputs []BarcodeInput, options BatchOptions) ([]*BarcodeOutput, error) {
+	return GenerateBatchCtx(context.Background(), inputs, options)
+}
+
+// GenerateBatchCtx generates labels like GenerateBatch, but once ctx is done
+// the labels being generated stop and the rest are not started. They fail
+// with an error wrapping ctx.Err().
+func GenerateBatchCtx(ctx context.Context, inputs []BarcodeInput, options BatchOptions) ([]*BarcodeOutput, error) {
+	generate := options.Generate
+	if generate == nil {
+		generate = GenerateBarcodeCtx
+	}
+	workers := options.Workers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(inputs))
+
+	outputs := make([]*BarcodeOutput, len(inputs))
+	errs := make([]error, len(inputs))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if errs[i] = checkContext(ctx); errs[i] == nil {
+					outputs[i], errs[i] = generate(ctx, inputs[i])
+				}
+			}
+		}()
+	}
+	for i := range inputs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	failures := map[int]error{}
+	for i, err := range errs {
+		if err != nil {
+			outputs[i] = nil
+			failures[i] = err
+		}
+	}
+	if len(failures) > 0 {
+		return outputs, &BatchError{Total: len(inputs), Errors: failures}
+	}
+	return outputs, nil
+}
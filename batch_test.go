@@ -0,0 +1,101 @@
+package barcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateBatch verifies outputs come back in input order and bad labels
+// are reported by index without failing the rest
+func TestGenerateBatch(t *testing.T) {
+	var inputs []BarcodeInput
+	for i := 0; i < 12; i++ {
+		inputs = append(inputs, BarcodeInput{BarcodeData: fmt.Sprintf("LOC-%02d", i), BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203, OutputFormats: []OutputFormat{OutputFormatZPL}})
+	}
+	inputs[3].Dpi = 150
+	inputs[7].BarcodeType = "PDF417"
+
+	outputs, err := GenerateBatch(inputs, BatchOptions{Workers: 4})
+	require.Len(t, outputs, len(inputs))
+	var batchErr *BatchError
+	require.True(t, errors.As(err, &batchErr))
+	assert.Equal(t, []int{3, 7}, batchErr.Indexes())
+	assert.ErrorContains(t, batchErr.Errors[3], "invalid dpi value: 150")
+	assert.ErrorContains(t, batchErr.Errors[7], "invalid barcode type: PDF417")
+	assert.EqualError(t, err, "batch failed: 2 of 12 labels failed. Label 3: "+batchErr.Errors[3].Error())
+
+	for i, output := range outputs {
+		if i == 3 || i == 7 {
+			assert.Nil(t, output)
+			continue
+		}
+		expected, err := GenerateBarcode(inputs[i])
+		require.NoError(t, err)
+		assert.Equal(t, expected, output, "label %d", i)
+	}
+
+	outputs, err = GenerateBatch(append(inputs[:3:3], inputs[4:7]...), BatchOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, outputs, 6)
+}
+
+// TestGenerateBatch_Workers verifies no more than the requested number of
+// labels are generated at once
+func TestGenerateBatch_Workers(t *testing.T) {
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	generate := func(context.Context, BarcodeInput) (*BarcodeOutput, error) {
+		n := running.Add(1)
+		for {
+			current := peak.Load()
+			if n <= current || peak.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+		return &BarcodeOutput{}, nil
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := GenerateBatch(make([]BarcodeInput, 10), BatchOptions{Workers: 3, Generate: generate})
+		done <- err
+	}()
+	for deadline := time.Now().Add(time.Second); running.Load() < 3 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < 10; i++ {
+		release <- struct{}{}
+	}
+	require.NoError(t, <-done)
+	assert.Equal(t, int32(3), peak.Load())
+}
+
+// TestGenerateBatchCtx verifies labels not yet started when the context is
+// cancelled fail with the context error
+func TestGenerateBatchCtx(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	generate := func(ctx context.Context, input BarcodeInput) (*BarcodeOutput, error) {
+		if input.BarcodeData == "LOC-02" {
+			cancel()
+		}
+		return &BarcodeOutput{}, nil
+	}
+	inputs := []BarcodeInput{{BarcodeData: "LOC-00"}, {BarcodeData: "LOC-01"}, {BarcodeData: "LOC-02"}, {BarcodeData: "LOC-03"}, {BarcodeData: "LOC-04"}}
+
+	outputs, err := GenerateBatchCtx(ctx, inputs, BatchOptions{Workers: 1, Generate: generate})
+	var batchErr *BatchError
+	require.True(t, errors.As(err, &batchErr))
+	assert.Equal(t, []int{3, 4}, batchErr.Indexes())
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotNil(t, outputs[2])
+	assert.Nil(t, outputs[3])
+}
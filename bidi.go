@@ -0,0 +1,294 @@
+package barcode
+
+import (
+	"slices"
+	"unicode"
+)
+
+// Bidirectional character classes used to order right-to-left text
+const (
+	bidiNeutral = iota
+	bidiLeft    // Strong left-to-right, e.g. Latin letters
+	bidiRight   // Strong right-to-left: Hebrew and Arabic letters
+	bidiNumber  // Digits, which read left to right inside right-to-left text
+)
+
+// bidiMirrors maps paired punctuation to its mirror image, drawn in
+// right-to-left runs so brackets still open towards their contents
+var bidiMirrors = map[rune]rune{
+	'(': ')', ')': '(', '[': ']', ']': '[', '{': '}', '}': '{', '<': '>', '>': '<', '«': '»', '»': '«',
+}
+
+// arabicJoining is how an Arabic letter connects to its neighbours: dual
+// joining letters connect on both sides, right joining letters only to the
+// letter before them, and non-joining letters to neither
+type arabicJoining int
+
+const (
+	arabicNonJoining arabicJoining = iota
+	arabicRightJoining
+	arabicDualJoining
+)
+
+// arabicLetter is an Arabic letter's joining type and its isolated form in
+// the Arabic Presentation Forms-B block. The final, initial and medial forms
+// follow it in that order, as far as the joining type has them.
+type arabicLetter struct {
+	isolated rune
+	joining  arabicJoining
+}
+
+// arabicLetters lists the letters of the basic Arabic alphabet
+var arabicLetters = map[rune]arabicLetter{
+	0x0621: {0xFE80, arabicNonJoining},   // Hamza
+	0x0622: {0xFE81, arabicRightJoining}, // Alef with madda above
+	0x0623: {0xFE83, arabicRightJoining}, // Alef with hamza above
+	0x0624: {0xFE85, arabicRightJoining}, // Waw with hamza above
+	0x0625: {0xFE87, arabicRightJoining}, // Alef with hamza below
+	0x0626: {0xFE89, arabicDualJoining},  // Yeh with hamza above
+	0x0627: {0xFE8D, arabicRightJoining}, // Alef
+	0x0628: {0xFE8F, arabicDualJoining},  // Beh
+	0x0629: {0xFE93, arabicRightJoining}, // Teh marbuta
+	0x062A: {0xFE95, arabicDualJoining},  // Teh
+	0x062B: {0xFE99, arabicDualJoining},  // Theh
+	0x062C: {0xFE9D, arabicDualJoining},  // Jeem
+	0x062D: {0xFEA1, arabicDualJoining},  // Hah
+	0x062E: {0xFEA5, arabicDualJoining},  // Khah
+	0x062F: {0xFEA9, arabicRightJoining}, // Dal
+	0x0630: {0xFEAB, arabicRightJoining}, // Thal
+	0x0631: {0xFEAD, arabicRightJoining}, // Reh
+	0x0632: {0xFEAF, arabicRightJoining}, // Zain
+	0x0633: {0xFEB1, arabicDualJoining},  // Seen
+	0x0634: {0xFEB5, arabicDualJoining},  // Sheen
+	0x0635: {0xFEB9, arabicDualJoining},  // Sad
+	0x0636: {0xFEBD, arabicDualJoining},  // Dad
+	0x0637: {0xFEC1, arabicDualJoining},  // Tah
+	0x0638: {0xFEC5, arabicDualJoining},  // Zah
+	0x0639: {0xFEC9, arabicDualJoining},  // Ain
+	0x063A: {0xFECD, arabicDualJoining},  // Ghain
+	0x0641: {0xFED1, arabicDualJoining},  // Feh
+	0x0642: {0xFED5, arabicDualJoining},  // Qaf
+	0x0643: {0xFED9, arabicDualJoining},  // Kaf
+	0x0644: {0xFEDD, arabicDualJoining},  // Lam
+	0x0645: {0xFEE1, arabicDualJoining},  // Meem
+	0x0646: {0xFEE5, arabicDualJoining},  // Noon
+	0x0647: {0xFEE9, arabicDualJoining},  // Heh
+	0x0648: {0xFEED, arabicRightJoining}, // Waw
+	0x0649: {0xFEEF, arabicRightJoining}, // Alef maksura
+	0x064A: {0xFEF1, arabicDualJoining},  // Yeh
+}
+
+// arabicLamAlef maps the alef that follows a lam to the isolated form of
+// their mandatory ligature; the final form follows it
+var arabicLamAlef = map[rune]rune{
+	0x0622: 0xFEF5,
+	0x0623: 0xFEF7,
+	0x0625: 0xFEF9,
+	0x0627: 0xFEFB,
+}
+
+const (
+	arabicLam     = 0x0644
+	arabicTatweel = 0x0640 // Joins on both sides without a form of its own
+)
+
+// hasRightToLeft reports whether text contains Hebrew or Arabic letters
+func hasRightToLeft(text string) bool {
+	for _, r := range text {
+		if bidiClass(r) == bidiRight {
+			return true
+		}
+	}
+	return false
+}
+
+// visualText returns text in the order it is drawn from left to right:
+// Arabic letters are replaced by their joined presentation forms, and
+// right-to-left runs are reversed following a simplified Unicode
+// bidirectional algorithm, with numbers and embedded left-to-right words
+// kept in reading order. Text without Hebrew or Arabic is returned as is.
+func visualText(text string) string {
+	if !hasRightToLeft(text) {
+		return text
+	}
+	return string(reorderBidi(shapeArabic([]rune(text))))
+}
+
+// bidiClass returns the bidirectional class of a character
+func bidiClass(r rune) int {
+	switch {
+	case unicode.In(r, unicode.Hebrew, unicode.Arabic):
+		if unicode.IsDigit(r) {
+			return bidiNumber
+		}
+		if unicode.IsLetter(r) || unicode.Is(unicode.Mn, r) {
+			return bidiRight
+		}
+		return bidiNeutral
+	case unicode.IsDigit(r):
+		return bidiNumber
+	case unicode.IsLetter(r):
+		return bidiLeft
+	default:
+		return bidiNeutral
+	}
+}
+
+// reorderBidi reorders a line from logical to visual order. The paragraph
+// direction is that of its first strong character. Each character gets an
+// embedding level: even for left-to-right, odd for right-to-left, with
+// numbers and left-to-right text inside a right-to-left paragraph raised to
+// 2. Neutrals between characters of the same direction take it, and others
+// the paragraph direction. Runs at each level and above are then reversed
+// from the highest level down, and brackets in right-to-left runs mirrored.
+func reorderBidi(text []rune) []rune {
+	classes := make([]int, len(text))
+	for i, r := range text {
+		classes[i] = bidiClass(r)
+	}
+	base, baseDirection := 0, bidiLeft
+	for _, class := range classes {
+		if class == bidiLeft || class == bidiRight {
+			if class == bidiRight {
+				base, baseDirection = 1, bidiRight
+			}
+			break
+		}
+	}
+
+	// Numbers read as right-to-left when resolving neutrals unless they
+	// follow left-to-right text in a left-to-right paragraph
+	direction := make([]int, len(text))
+	strong := baseDirection
+	for i, class := range classes {
+		switch class {
+		case bidiLeft, bidiRight:
+			strong = class
+			direction[i] = class
+		case bidiNumber:
+			direction[i] = bidiRight
+			if base == 0 && strong == bidiLeft {
+				direction[i] = bidiLeft
+			}
+		}
+	}
+	for i := 0; i < len(text); {
+		if direction[i] != bidiNeutral {
+			i++
+			continue
+		}
+		end := i
+		for end < len(text) && direction[end] == bidiNeutral {
+			end++
+		}
+		before, after := baseDirection, baseDirection
+		if i > 0 {
+			before = direction[i-1]
+		}
+		if end < len(text) {
+			after = direction[end]
+		}
+		resolved := baseDirection
+		if before == after {
+			resolved = before
+		}
+		for ; i < end; i++ {
+			direction[i] = resolved
+		}
+	}
+
+	levels := make([]int, len(text))
+	maxLevel := 0
+	for i := range text {
+		switch {
+		case classes[i] == bidiNumber && (base == 1 || direction[i] == bidiRight):
+			levels[i] = 2
+		case direction[i] == bidiRight:
+			levels[i] = 1
+		default:
+			levels[i] = 2 * base // Left-to-right: 0, or 2 inside right-to-left
+		}
+		maxLevel = max(maxLevel, levels[i])
+	}
+
+	visual := append([]rune(nil), text...)
+	for i, r := range visual {
+		if mirror, ok := bidiMirrors[r]; ok && levels[i]%2 == 1 {
+			visual[i] = mirror
+		}
+	}
+	for level := maxLevel; level >= 1; level-- {
+		for i := 0; i < len(visual); {
+			if levels[i] < level {
+				i++
+				continue
+			}
+			end := i
+			for end < len(visual) && levels[end] >= level {
+				end++
+			}
+			slices.Reverse(visual[i:end])
+			slices.Reverse(levels[i:end])
+			i = end
+		}
+	}
+	return visual
+}
+
+// shapeArabic replaces Arabic letters with the presentation form for how
+// they join their neighbours, and lam followed by alef with their ligature.
+// Harakat and other marks are skipped when finding neighbours.
+func shapeArabic(text []rune) []rune {
+	joinsNext := func(i int) bool {
+		letter, ok := arabicLetters[text[i]]
+		return text[i] == arabicTatweel || (ok && letter.joining == arabicDualJoining)
+	}
+	joinsPrevious := func(i int) bool {
+		letter, ok := arabicLetters[text[i]]
+		return text[i] == arabicTatweel || (ok && letter.joining != arabicNonJoining)
+	}
+	neighbour := func(i, step int) int {
+		for i += step; i >= 0 && i < len(text); i += step {
+			if !unicode.Is(unicode.Mn, text[i]) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	shaped := make([]rune, 0, len(text))
+	for i := 0; i < len(text); i++ {
+		letter, ok := arabicLetters[text[i]]
+		if !ok {
+			shaped = append(shaped, text[i])
+			continue
+		}
+		previous, next := neighbour(i, -1), neighbour(i, 1)
+		joinedBefore := previous >= 0 && joinsNext(previous)
+
+		if text[i] == arabicLam && next == i+1 {
+			if ligature, ok := arabicLamAlef[text[next]]; ok {
+				if joinedBefore {
+					ligature++ // Final form
+				}
+				shaped = append(shaped, ligature)
+				i = next
+				continue
+			}
+		}
+
+		joinedAfter := letter.joining == arabicDualJoining && next >= 0 && joinsPrevious(next)
+		form := letter.isolated
+		switch {
+		case letter.joining == arabicNonJoining:
+		case joinedBefore && joinedAfter:
+			form += 3 // Medial
+		case joinedAfter:
+			form += 2 // Initial
+		case joinedBefore:
+			form++ // Final
+		}
+		shaped = append(shaped, form)
+	}
+	return shaped
+}
@@ -0,0 +1,61 @@
+package barcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVisualText verifies right-to-left runs are reversed with numbers and
+// left-to-right words kept in reading order
+func TestVisualText(t *testing.T) {
+	tests := []struct {
+		name, logical, visual string
+	}{
+		{"Latin", "SKU 10442", "SKU 10442"},
+		{"Hebrew", "שלום", "םולש"},
+		{"Hebrew with number", "מחיר 50", "50 ריחמ"},
+		{"Hebrew with Latin", "שלום abc", "abc םולש"},
+		{"Latin with Hebrew", "Price מחיר 50", "Price 50 ריחמ"},
+		{"Mirrored brackets", "(שלום)", "(םולש)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.visual, visualText(tt.logical))
+		})
+	}
+}
+
+// TestShapeArabic verifies letters take their joined forms and lam-alef
+// becomes a ligature
+func TestShapeArabic(t *testing.T) {
+	// Beh, Beh, Beh: initial, medial, final
+	assert.Equal(t, []rune{0xFE91, 0xFE92, 0xFE90}, shapeArabic([]rune{0x0628, 0x0628, 0x0628}))
+	// Dal does not join the next letter, so the beh after it is isolated
+	assert.Equal(t, []rune{0xFE91, 0xFEAA, 0xFE8F}, shapeArabic([]rune{0x0628, 0x062F, 0x0628}))
+	// Beh, lam, alef: the ligature joins the beh before it
+	assert.Equal(t, []rune{0xFE91, 0xFEFC}, shapeArabic([]rune{0x0628, 0x0644, 0x0627}))
+	assert.Equal(t, []rune{0xFEFB}, shapeArabic([]rune{0x0644, 0x0627}))
+}
+
+// TestGenerateBarcode_RightToLeft verifies right-to-left lines are sent to
+// ZPL as graphics and rejected in stored formats
+func TestGenerateBarcode_RightToLeft(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "10442",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50.0,
+		Height:      25.0,
+		Dpi:         203,
+		TextLines:   []TextLine{{Text: "שלום", Position: TextPositionBelow, Size: TextSizeMedium}},
+		ZPL:         ZPLOptions{NativeCommands: true, Font: "E:NOTOSANS.TTF"},
+	}
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.NotContains(t, output.ZPL, "שלום")
+	assert.Contains(t, output.ZPL, "^GF")
+
+	_, err = GenerateZPLStoredFormat(input, "E:LABEL.ZPL")
+	assert.ErrorContains(t, err, "Right-to-left")
+}
@@ -0,0 +1,40 @@
+package barcode
+
+import (
+	"image"
+	"image/color"
+)
+
+// bilevelCoverage is the glyph coverage, as alpha, from which a pixel of
+// bilevel text is black. Glyphs are not hinted, so text keeps the widths it
+// was measured and laid out with.
+const bilevelCoverage = 128
+
+// drawBilevelText draws the text rendered on a transparent layer onto the
+// label, with pixels that the glyphs cover at least half black and the rest
+// left as they are
+func drawBilevelText(label, layer *image.RGBA) {
+	bounds := layer.Bounds().Intersect(label.Bounds())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if layer.RGBAAt(x, y).A >= bilevelCoverage {
+				label.SetRGBA(x, y, color.RGBA{A: 255})
+			}
+		}
+	}
+}
+
+// thresholdLabel turns an opaque image black and white in place, for text
+// drawn together with bars such as EAN-13 human-readable digits
+func thresholdLabel(img *image.RGBA) {
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			if color.GrayModel.Convert(img.RGBAAt(x, y)).(color.Gray).Y < bilevelCoverage {
+				c = color.RGBA{A: 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
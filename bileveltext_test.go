@@ -0,0 +1,76 @@
+package barcode
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countGreyPixels returns the number of pixels of img that are neither black
+// nor white
+func countGreyPixels(img image.Image) int {
+	grey := 0
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			if level := (r + g + b) / 3 >> 8; level != 0 && level != 255 {
+				grey++
+			}
+		}
+	}
+	return grey
+}
+
+// TestGenerateBarcode_BilevelText verifies text, including rotated lines, is
+// anti-aliased by default and black and white with BilevelText
+func TestGenerateBarcode_BilevelText(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1-B2-C3",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       80,
+		Height:      40,
+		Dpi:         203,
+		TextLines: []TextLine{
+			{Text: "LOC-A1-B2-C3", Position: TextPositionBelow, Size: TextSizeSmall},
+			{Text: "AISLE 4", Position: TextPositionAbove, Size: TextSizeLarge},
+			{Text: "FRONT", Position: TextPositionLeft, Size: TextSizeSmall, Rotation: 90},
+		},
+	}
+	img, _, err := GenerateBarcodeImage(input)
+	require.NoError(t, err)
+	assert.Greater(t, countGreyPixels(img), 0)
+
+	input.BilevelText = true
+	bilevel, _, err := GenerateBarcodeImage(input)
+	require.NoError(t, err)
+	assert.Zero(t, countGreyPixels(bilevel))
+
+	input.OutputFormats = []OutputFormat{OutputFormatZPL}
+	input.ZPL = ZPLOptions{RasterMode: ZPLRasterBilevel}
+	_, err = GenerateBarcode(input)
+	assert.NoError(t, err, "bilevel text passes the BILEVEL raster check")
+}
+
+// TestGenerateBarcode_BilevelTextEAN verifies the human-readable digits of
+// ISBN EAN-13 symbols are black and white with BilevelText
+func TestGenerateBarcode_BilevelTextEAN(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:   "978-0-306-40615-7",
+		BarcodeType:   BarcodeTypeISBN,
+		Width:         50,
+		Height:        30,
+		Dpi:           203,
+		HumanReadable: true,
+	}
+	img, _, err := GenerateBarcodeImage(input)
+	require.NoError(t, err)
+	assert.Greater(t, countGreyPixels(img), 0)
+
+	input.BilevelText = true
+	bilevel, _, err := GenerateBarcodeImage(input)
+	require.NoError(t, err)
+	assert.Zero(t, countGreyPixels(bilevel))
+}
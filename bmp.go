@@ -0,0 +1,55 @@
+package barcode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"math"
+)
+
+// BMP header sizes
+const (
+	bmpFileHeaderSize = 14
+	bmpInfoHeaderSize = 40 // BITMAPINFOHEADER
+	bmpPaletteSize    = 2 * 4
+)
+
+// imageToBMP encodes an image as a 1-bit monochrome BMP, as legacy Windows
+// print pipelines expect. Pixels are thresholded like the printer language
+// graphics, and the header records the DPI as pixels per meter.
+func imageToBMP(img *image.RGBA, dpi int) []byte {
+	mono := toMonochrome(img)
+	bounds := mono.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	rowBytes := (width + 31) / 32 * 4 // Rows are padded to 4 bytes
+	dataOffset := bmpFileHeaderSize + bmpInfoHeaderSize + bmpPaletteSize
+	pixelsPerMeter := int32(math.Round(float64(dpi) / 0.0254))
+
+	var bmp bytes.Buffer
+	bmp.WriteString("BM")
+	binary.Write(&bmp, binary.LittleEndian, struct {
+		FileSize, Reserved, DataOffset uint32
+	}{uint32(dataOffset + rowBytes*height), 0, uint32(dataOffset)})
+	binary.Write(&bmp, binary.LittleEndian, struct {
+		Size                             uint32
+		Width, Height                    int32
+		Planes, BitCount                 uint16
+		Compression, ImageSize           uint32
+		XPixelsPerMeter, YPixelsPerMeter int32
+		ColorsUsed, ColorsImportant      uint32
+	}{bmpInfoHeaderSize, int32(width), int32(height), 1, 1, 0, uint32(rowBytes * height), pixelsPerMeter, pixelsPerMeter, 2, 2})
+	bmp.Write([]byte{0, 0, 0, 0, 0xFF, 0xFF, 0xFF, 0}) // Black and white, as blue, green, red and reserved
+
+	// Rows are stored bottom-up, with set bits for white
+	row := make([]byte, rowBytes)
+	for y := bounds.Max.Y - 1; y >= bounds.Min.Y; y-- {
+		clear(row)
+		for x := 0; x < width; x++ {
+			if mono.ColorIndexAt(bounds.Min.X+x, y) == 1 {
+				row[x/8] |= 0x80 >> (x % 8)
+			}
+		}
+		bmp.Write(row)
+	}
+	return bmp.Bytes()
+}
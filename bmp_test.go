@@ -0,0 +1,55 @@
+package barcode
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestImageToBMP verifies the headers, palette and bottom-up 1-bit rows
+func TestImageToBMP(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 33, 2))
+	for x := 0; x < 33; x++ {
+		img.Set(x, 0, color.White)
+		img.Set(x, 1, color.White)
+	}
+	img.Set(0, 0, color.Black)
+	img.Set(32, 1, color.Black)
+
+	bmp := imageToBMP(img, 203)
+	require.Len(t, bmp, 14+40+8+2*8)
+	assert.Equal(t, "BM", string(bmp[:2]))
+	assert.Equal(t, uint32(len(bmp)), binary.LittleEndian.Uint32(bmp[2:]))
+	assert.Equal(t, uint32(62), binary.LittleEndian.Uint32(bmp[10:]), "pixel data offset")
+	assert.Equal(t, uint32(33), binary.LittleEndian.Uint32(bmp[18:]))
+	assert.Equal(t, uint32(2), binary.LittleEndian.Uint32(bmp[22:]))
+	assert.Equal(t, uint16(1), binary.LittleEndian.Uint16(bmp[28:]), "bits per pixel")
+	assert.Equal(t, uint32(7992), binary.LittleEndian.Uint32(bmp[38:]), "203 DPI in pixels per meter")
+	assert.Equal(t, []byte{0, 0, 0, 0, 0xFF, 0xFF, 0xFF, 0}, bmp[54:62])
+
+	// The bottom row comes first, with its last pixel black
+	assert.Equal(t, []byte{0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0, 0, 0}, bmp[62:70])
+	assert.Equal(t, []byte{0x7F, 0xFF, 0xFF, 0xFF, 0x80, 0, 0, 0}, bmp[70:78])
+}
+
+// TestGenerateBarcode_BMP verifies the BMP is only produced on request, at the printer DPI
+func TestGenerateBarcode_BMP(t *testing.T) {
+	input := BarcodeInput{BarcodeData: "LOC-A1-B2-C3", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 300}
+
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Empty(t, output.BMPBase64)
+
+	input.OutputFormats = []OutputFormat{OutputFormatBMP}
+	output, err = GenerateBarcode(input)
+	require.NoError(t, err)
+	bmp, err := base64.StdEncoding.DecodeString(output.BMPBase64)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(mmToPixels(input.Width, input.Dpi)), binary.LittleEndian.Uint32(bmp[18:]))
+	assert.Equal(t, uint32(mmToPixels(input.Height, input.Dpi)), binary.LittleEndian.Uint32(bmp[22:]))
+}
@@ -0,0 +1,183 @@
+package barcode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// cacheKeyVersion is mixed into cache keys so artifacts rendered by an older
+// version of the module are not served after rendering changes.
+const cacheKeyVersion = "1"
+
+// cacheFileExt is the extension of artifact files written by DiskCache
+const cacheFileExt = ".json"
+
+// ArtifactCache stores generated label artifacts by input hash.
+// Implementations may be local (DiskCache) or backed by a remote store.
+type ArtifactCache interface {
+	Get(key string) (*BarcodeOutput, bool)
+	Set(key string, output *BarcodeOutput) error
+}
+
+// GenerateBarcodeWithCache returns the cached artifacts for the input when
+// present, and otherwise generates them with GenerateBarcode and stores them.
+// Cache write failures are ignored so a broken cache never fails a label.
+func GenerateBarcodeWithCache(input BarcodeInput, cache ArtifactCache) (*BarcodeOutput, error) {
+	key, err := CacheKey(input)
+	if err != nil {
+		return nil, err
+	}
+	if output, ok := cache.Get(key); ok {
+		return output, nil
+	}
+
+	output, err := GenerateBarcode(input)
+	if err != nil {
+		return nil, err
+	}
+	_ = cache.Set(key, output)
+	return output, nil
+}
+
+// CacheKey returns the hex SHA-256 of the input, identifying its artifacts
+func CacheKey(input BarcodeInput) (string, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash barcode input: %w", err)
+	}
+	sum := sha256.Sum256(append([]byte(cacheKeyVersion+":"), data...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// DiskCache is an ArtifactCache storing one file per artifact in a directory.
+// Entries older than TTL are treated as missing, and the oldest entries are
+// evicted once there are more than MaxEntries.
+type DiskCache struct {
+	Dir        string        // Directory holding the artifact files
+	TTL        time.Duration // Optional maximum entry age (0 keeps entries until evicted)
+	MaxEntries int           // Optional maximum number of entries (0 is unlimited)
+}
+
+// NewDiskCache creates the cache directory if needed and returns the cache
+func NewDiskCache(dir string, ttl time.Duration, maxEntries int) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &DiskCache{Dir: dir, TTL: ttl, MaxEntries: maxEntries}, nil
+}
+
+// Get returns the artifacts for key unless they are missing, expired or unreadable
+func (c *DiskCache) Get(key string) (*BarcodeOutput, bool) {
+	path, err := c.path(key)
+	if err != nil {
+		return nil, false
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	if c.expired(info) {
+		os.Remove(path)
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var output BarcodeOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, false
+	}
+	return &output, true
+}
+
+// Set writes the artifacts for key, then evicts expired and excess entries
+func (c *DiskCache) Set(key string, output *BarcodeOutput) error {
+	path, err := c.path(key)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(output)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	// Write to a temporary file and rename so readers never see partial entries
+	tmp, err := os.CreateTemp(c.Dir, "tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	return c.evict()
+}
+
+// evict removes expired entries and, above MaxEntries, the least recently written
+func (c *DiskCache) evict() error {
+	dirEntries, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	var entries []os.FileInfo
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), cacheFileExt) {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		if c.expired(info) {
+			os.Remove(filepath.Join(c.Dir, info.Name()))
+			continue
+		}
+		entries = append(entries, info)
+	}
+
+	if c.MaxEntries <= 0 || len(entries) <= c.MaxEntries {
+		return nil
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ModTime().Before(entries[j].ModTime())
+	})
+	for _, info := range entries[:len(entries)-c.MaxEntries] {
+		os.Remove(filepath.Join(c.Dir, info.Name()))
+	}
+	return nil
+}
+
+func (c *DiskCache) expired(info os.FileInfo) bool {
+	return c.TTL > 0 && time.Since(info.ModTime()) > c.TTL
+}
+
+// path maps a key to its file, rejecting keys that are not plain hex hashes
+func (c *DiskCache) path(key string) (string, error) {
+	if _, err := hex.DecodeString(key); err != nil || key == "" {
+		return "", errors.New("invalid cache key: must be a hex hash")
+	}
+	return filepath.Join(c.Dir, key+cacheFileExt), nil
+}
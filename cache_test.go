@@ -0,0 +1,95 @@
+package barcode
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCacheKey verifies keys are stable and differ between inputs
+func TestCacheKey(t *testing.T) {
+	input := BarcodeInput{BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203}
+	a, err := CacheKey(input)
+	require.NoError(t, err)
+	b, _ := CacheKey(input)
+	input.BarcodeData = "LOC-A2"
+	c, _ := CacheKey(input)
+
+	assert.Len(t, a, 64)
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+}
+
+// TestGenerateBarcodeWithCache verifies artifacts are stored and served from disk
+func TestGenerateBarcodeWithCache(t *testing.T) {
+	cache, err := NewDiskCache(t.TempDir(), time.Hour, 0)
+	require.NoError(t, err)
+
+	input := BarcodeInput{BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203}
+	first, err := GenerateBarcodeWithCache(input, cache)
+	require.NoError(t, err)
+
+	key, _ := CacheKey(input)
+	cached, ok := cache.Get(key)
+	require.True(t, ok, "Output should be cached")
+	assert.Equal(t, first, cached)
+
+	second, err := GenerateBarcodeWithCache(input, cache)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+}
+
+// TestDiskCache_TTL verifies expired entries are treated as missing and removed
+func TestDiskCache_TTL(t *testing.T) {
+	cache, err := NewDiskCache(t.TempDir(), time.Minute, 0)
+	require.NoError(t, err)
+
+	key, _ := CacheKey(BarcodeInput{BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203})
+	require.NoError(t, cache.Set(key, &BarcodeOutput{ZPL: "^XA^XZ"}))
+
+	path := filepath.Join(cache.Dir, key+cacheFileExt)
+	old := time.Now().Add(-2 * time.Minute)
+	require.NoError(t, os.Chtimes(path, old, old))
+
+	_, ok := cache.Get(key)
+	assert.False(t, ok, "Expired entry should be a miss")
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "Expired entry should be removed")
+}
+
+// TestDiskCache_MaxEntries verifies the oldest entries are evicted first
+func TestDiskCache_MaxEntries(t *testing.T) {
+	cache, err := NewDiskCache(t.TempDir(), 0, 2)
+	require.NoError(t, err)
+
+	var keys []string
+	for i, data := range []string{"LOC-A1", "LOC-A2", "LOC-A3"} {
+		key, _ := CacheKey(BarcodeInput{BarcodeData: data, BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203})
+		keys = append(keys, key)
+		require.NoError(t, cache.Set(key, &BarcodeOutput{ZPL: data}))
+
+		// Space out write times so eviction order is deterministic
+		written := time.Now().Add(time.Duration(i-3) * time.Minute)
+		require.NoError(t, os.Chtimes(filepath.Join(cache.Dir, key+cacheFileExt), written, written))
+	}
+	require.NoError(t, cache.evict())
+
+	_, ok := cache.Get(keys[0])
+	assert.False(t, ok, "Oldest entry should be evicted")
+	_, ok = cache.Get(keys[2])
+	assert.True(t, ok, "Newest entry should be kept")
+}
+
+// TestDiskCache_InvalidKey ensures keys cannot escape the cache directory
+func TestDiskCache_InvalidKey(t *testing.T) {
+	cache, err := NewDiskCache(t.TempDir(), 0, 0)
+	require.NoError(t, err)
+
+	assert.Error(t, cache.Set("../outside", &BarcodeOutput{}))
+	_, ok := cache.Get("../outside")
+	assert.False(t, ok)
+}
@@ -0,0 +1,155 @@
+package barcode
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/boombuler/barcode"
+)
+
+// capacitySamples is the character MaxDataLength measures data in for each
+// type it supports: a letter for types that encode text, since digit runs
+// pack tighter, and a byte-mode letter for QR codes.
+var capacitySamples = map[BarcodeType]string{
+	BarcodeTypeCode128:        "A",
+	BarcodeTypeQR:             "a",
+	BarcodeTypeITF:            "0",
+	BarcodeTypeTelepen:        "A",
+	BarcodeTypeTelepenNumeric: "0",
+}
+
+// MinLabelSize returns the smallest label that holds the data as a barcode
+// of the type that passes the scannability checks at the DPI, laid out with
+// the default margins, Margins.QuietZone and no text: the narrowest width
+// that gives every module minScannableModuleDots and leaves the quiet zone
+// clear, and the height AutoHeight gives a label of that width. QR codes are
+// laid out square, so their labels are. Labels too small for IMb and Swiss QR
+// codes would shrink them, so their size holds them at full size.
+func MinLabelSize(barcodeType BarcodeType, data string, dpi int) (LabelSize, error) {
+	input := capacityInput(barcodeType, dpi)
+	input.BarcodeData = data
+	if err := validateInput(input); err != nil {
+		return LabelSize{}, err
+	}
+	bc, err := encodeBarcode(input)
+	if err != nil {
+		return LabelSize{}, err
+	}
+
+	margin := labelMargin(input)
+	var width int
+	switch barcodeType {
+	case BarcodeTypeIMb:
+		width = mmToPixels(imbLengthMM, dpi) + margin*2
+	case BarcodeTypeSwissQR:
+		width = mmToPixels(swissQRSizeMM, dpi) + margin*2
+	default:
+		width = (bc.Bounds().Dx() + quietZoneModules[barcodeType]*2) * minScannableModuleDots
+	}
+	for widest := mmToPixels(autoHeightMaxMM, dpi); width <= widest; width++ {
+		input.Width = float64(width) * 25.4 / float64(dpi)
+		if sized, ok := scannableLabel(input, bc); ok {
+			return LabelSize{Width: sized.Width, Height: sized.Height, MarginMM: float64(margin) * 25.4 / float64(dpi)}, nil
+		}
+	}
+	return LabelSize{}, errorf(ErrLabelTooSmall, "no scannable label size for %s data %q at %d dpi up to %gmm wide", barcodeType, data, dpi, autoHeightMaxMM)
+}
+
+// MaxDataLength returns the most characters a barcode of the type holds
+// across a label of the width at the DPI, laid out as MinLabelSize lays it
+// out, while passing the scannability checks. Lengths are counted in letters
+// for Code128, Telepen and byte-mode QR codes at the default error
+// correction, and in digits for ITF and Telepen numeric; Code128 and QR
+// codes hold more digits than letters. Fixed-length types are not supported.
+// Zero means no data fits.
+func MaxDataLength(barcodeType BarcodeType, widthMM float64, dpi int) (int, error) {
+	sample, ok := capacitySamples[barcodeType]
+	if !ok {
+		types := make([]string, 0, len(capacitySamples))
+		for supported := range capacitySamples {
+			types = append(types, string(supported))
+		}
+		sort.Strings(types)
+		return 0, errorf(ErrUnsupportedType, "invalid barcode type for data length: %s. Supported types: %v", barcodeType, types)
+	}
+	input := capacityInput(barcodeType, dpi)
+	input.Width = widthMM
+	input.BarcodeData = sample
+	if err := validateInput(input); err != nil {
+		return 0, err
+	}
+
+	if barcodeType == BarcodeTypeQR {
+		return qrMaxDataLength(input, sample), nil
+	}
+
+	// Longer data never takes fewer modules, so the longest that fits is found
+	// by bisection. Every character takes at least one module.
+	fits := func(length int) bool {
+		input.BarcodeData = strings.Repeat(sample, length)
+		bc, err := encodeBarcode(input)
+		if err != nil {
+			return false
+		}
+		_, ok := scannableLabel(input, bc)
+		return ok
+	}
+	longest := mmToPixels(widthMM, dpi) / minScannableModuleDots
+	return sort.Search(longest, func(length int) bool { return !fits(length + 1) }), nil
+}
+
+// qrMaxDataLength returns the most sample characters held by the largest QR
+// version that passes the scannability checks on the input's label. Versions
+// are searched rather than lengths, since the version alone sets the size.
+func qrMaxDataLength(input BarcodeInput, sample string) int {
+	level := int(qrErrorCorrectionLevels[input.QR.ErrorCorrection])
+	versions := sort.Search(qrMaxVersion, func(i int) bool {
+		bc, err := encodeQRVersion(sample, level, QRModeByte, i+1)
+		if err != nil {
+			return true
+		}
+		_, ok := scannableLabel(input, bc)
+		return !ok
+	})
+	if versions == 0 {
+		return 0
+	}
+	return qrVersionMaxLength(QRModeByte, level, versions)
+}
+
+// capacityInput returns the input MinLabelSize and MaxDataLength lay out:
+// the type at the DPI with the default margins, the quiet zone and no text
+func capacityInput(barcodeType BarcodeType, dpi int) BarcodeInput {
+	return BarcodeInput{
+		BarcodeType:         barcodeType,
+		Width:               autoHeightMaxMM,
+		Height:              autoHeightMaxMM,
+		Dpi:                 dpi,
+		AllowNonStandardDPI: true,
+		Margins:             Margins{QuietZone: true},
+		ITF:                 ITFOptions{PadOddLength: true},
+	}
+}
+
+// scannableLabel sizes the label of an input to its barcode, as AutoHeight
+// does or square for QR codes, and reports whether the barcode passes the
+// scannability checks on it. AutoHeight leaves room for the quiet zone of
+// linear symbols only.
+func scannableLabel(input BarcodeInput, bc barcode.Barcode) (BarcodeInput, bool) {
+	sized := input
+	if input.BarcodeType == BarcodeTypeQR {
+		sized.Height = input.Width
+	} else {
+		input.AutoHeight = true
+		var err error
+		if sized, err = applyAutoHeight(input); err != nil {
+			return input, false
+		}
+	}
+	labelImg, barcodeRects, err := composeLabelImage(sized, bc, sized.Dpi)
+	if err != nil {
+		return input, false
+	}
+	warnings, err := checkScannability(sized, bc, labelImg, barcodeRects[0])
+	return sized, err == nil && len(warnings) == 0
+}
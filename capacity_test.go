@@ -0,0 +1,128 @@
+package barcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMinLabelSize verifies labels of the minimum size scan and narrower ones
+// do not
+func TestMinLabelSize(t *testing.T) {
+	for _, test := range []struct {
+		barcodeType BarcodeType
+		data        string
+	}{
+		{BarcodeTypeCode128, "LOC-A1-B2-C3"},
+		{BarcodeTypeQR, "https://example.com/item/12345"},
+		{BarcodeTypeITF, "12345678"},
+		{BarcodeTypeISBN, "978-0-306-40615-7"},
+	} {
+		for _, dpi := range []int{203, 600} {
+			size, err := MinLabelSize(test.barcodeType, test.data, dpi)
+			require.NoError(t, err, test.barcodeType)
+			assert.InDelta(t, float64(labelMarginPixels)*25.4/float64(dpi), size.MarginMM, 1e-9)
+
+			// Laid out as MinLabelSize lays it out, and rejected if it may not scan
+			input := BarcodeInput{
+				BarcodeData:        test.data,
+				BarcodeType:        test.barcodeType,
+				Width:              size.Width,
+				Height:             size.Height,
+				Dpi:                dpi,
+				Margins:            Margins{QuietZone: true},
+				StrictScannability: true,
+			}
+			_, err = GenerateBarcode(input)
+			assert.NoError(t, err, "%s at %d dpi", test.barcodeType, dpi)
+
+			input.Width -= 25.4 / float64(dpi)
+			_, err = GenerateBarcode(input)
+			assert.ErrorIs(t, err, ErrNotScannable, "%s at %d dpi", test.barcodeType, dpi)
+		}
+	}
+}
+
+// TestMinLabelSize_Shapes verifies QR labels are square, linear labels are
+// sized by AutoHeight and IMb labels hold the symbol at full size
+func TestMinLabelSize_Shapes(t *testing.T) {
+	size, err := MinLabelSize(BarcodeTypeQR, "LOC-A1", 203)
+	require.NoError(t, err)
+	assert.Equal(t, size.Width, size.Height)
+
+	size, err = MinLabelSize(BarcodeTypeCode128, "LOC-A1", 203)
+	require.NoError(t, err)
+	output, err := GenerateBarcode(BarcodeInput{BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeCode128, Width: size.Width, Dpi: 203, AutoHeight: true, Margins: Margins{QuietZone: true}})
+	require.NoError(t, err)
+	assert.Equal(t, output.HeightMM, size.Height)
+
+	size, err = MinLabelSize(BarcodeTypeIMb, "0123456709498765432101234567891", 203)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, size.Width, imbLengthMM)
+}
+
+// TestMinLabelSize_Invalid verifies data and DPI problems are reported as
+// they are by generation
+func TestMinLabelSize_Invalid(t *testing.T) {
+	_, err := MinLabelSize(BarcodeTypeISBN, "978-0-306-40615-8", 203)
+	assert.ErrorIs(t, err, ErrInvalidData)
+
+	_, err = MinLabelSize(BarcodeTypeQR, strings.Repeat("a", 3000), 203)
+	assert.ErrorIs(t, err, ErrInvalidData)
+
+	_, err = MinLabelSize(BarcodeTypeCode128, "LOC-A1", 10)
+	assert.ErrorIs(t, err, ErrInvalidDPI)
+
+	_, err = MinLabelSize("PDF417", "LOC-A1", 203)
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+// TestMaxDataLength verifies the longest data fits within the width and one
+// more character does not
+func TestMaxDataLength(t *testing.T) {
+	for _, test := range []struct {
+		barcodeType BarcodeType
+		sample      string
+		widthMM     float64
+	}{
+		{BarcodeTypeCode128, "A", 50},
+		{BarcodeTypeCode128, "A", 100},
+		{BarcodeTypeQR, "a", 25},
+		{BarcodeTypeITF, "0", 50},
+		{BarcodeTypeTelepen, "A", 100},
+		{BarcodeTypeTelepenNumeric, "0", 50},
+	} {
+		length, err := MaxDataLength(test.barcodeType, test.widthMM, 203)
+		require.NoError(t, err, test.barcodeType)
+		require.Positive(t, length, test.barcodeType)
+
+		size, err := MinLabelSize(test.barcodeType, strings.Repeat(test.sample, length), 203)
+		require.NoError(t, err, test.barcodeType)
+		assert.LessOrEqual(t, size.Width, test.widthMM, test.barcodeType)
+
+		size, err = MinLabelSize(test.barcodeType, strings.Repeat(test.sample, length+1), 203)
+		if err == nil {
+			assert.Greater(t, size.Width, test.widthMM, test.barcodeType)
+		}
+	}
+}
+
+// TestMaxDataLength_Limits verifies narrow labels hold nothing, wide QR
+// labels hold the largest version, and fixed-length types are rejected
+func TestMaxDataLength_Limits(t *testing.T) {
+	length, err := MaxDataLength(BarcodeTypeCode128, 5, 203)
+	require.NoError(t, err)
+	assert.Zero(t, length)
+
+	length, err = MaxDataLength(BarcodeTypeQR, 100, 203)
+	require.NoError(t, err)
+	assert.Equal(t, qrMaxLength(QRModeByte, int(qrErrorCorrectionLevels[""])), length)
+
+	_, err = MaxDataLength(BarcodeTypeISBN, 50, 203)
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+
+	_, err = MaxDataLength(BarcodeTypeCode128, 0, 203)
+	assert.ErrorIs(t, err, ErrInvalidDimensions)
+}
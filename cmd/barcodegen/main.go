@@ -0,0 +1,231 @@
+// Command barcodegen generates labels and sends them to network printers so
+// labels can be tested end-to-end without extra tooling.
+//
+// Usage:
+//
+//	barcodegen print [--printer host[:port]] (--zpl file | --data text [--preset 4X6] [flags])
+//	barcodegen discover [--timeout 3s]
+//	barcodegen soak [--duration 24h] [--interval 1m] [--workers 4] [--data text [flags]]
+//
+// When --printer is omitted, print discovers printers over mDNS and uses the
+// only one found. soak generates labels until interrupted, reporting memory
+// and goroutine counts to catch slow growth over multi-day runs.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	barcode "github.com/mattador/barcode-generator"
+)
+
+const defaultDiscoveryTimeout = 3 * time.Second
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "print":
+		err = runPrint(os.Args[2:])
+	case "discover":
+		err = runDiscover(os.Args[2:])
+	case "soak":
+		err = runSoak(os.Args[2:])
+	case "-h", "--help", "help":
+		usage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "barcodegen:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage:")
+	fmt.Fprintln(os.Stderr, "  barcodegen print [--printer host[:port]] (--zpl file | --data text [--preset 4X6] [flags])")
+	fmt.Fprintln(os.Stderr, "  barcodegen discover [--timeout 3s]")
+	fmt.Fprintln(os.Stderr, "  barcodegen soak [--duration 24h] [--interval 1m] [--workers 4] [--data text [flags]]")
+}
+
+// runPrint sends a ZPL file, or a label generated from flags, to a printer
+func runPrint(args []string) error {
+	fs := flag.NewFlagSet("print", flag.ExitOnError)
+	printer := fs.String("printer", "", "printer address, host or host:port (discovered when omitted)")
+	zplFile := fs.String("zpl", "", "ZPL file to send, or - for stdin")
+	data := fs.String("data", "", "barcode data to generate a label from")
+	barcodeType := fs.String("type", string(barcode.BarcodeTypeCode128), "barcode type")
+	width := fs.Float64("width", 50, "label width in millimeters")
+	height := fs.Float64("height", 25, "label height in millimeters")
+	preset := fs.String("preset", "", "label stock preset such as 4X6, used instead of --width and --height")
+	dpi := fs.Int("dpi", 203, "printer dpi")
+	text := fs.String("text", "", "optional text line below the barcode")
+	copies := fs.Int("copies", 1, "number of labels to print")
+	darkness := fs.Int("darkness", 0, "darkness change relative to the printer setting (-30 to 30)")
+	timeout := fs.Duration("timeout", defaultDiscoveryTimeout, "printer discovery timeout")
+	fs.Parse(args)
+	if *preset != "" {
+		*width, *height = 0, 0
+	}
+
+	zpl, err := printZPL(*zplFile, barcode.BarcodeInput{
+		BarcodeData: *data,
+		BarcodeType: barcode.BarcodeType(*barcodeType),
+		Width:       *width,
+		Height:      *height,
+		LabelPreset: barcode.LabelPreset(*preset),
+		Dpi:         *dpi,
+		ZPL:         barcode.ZPLOptions{Quantity: *copies, Darkness: *darkness},
+	}, *text)
+	if err != nil {
+		return err
+	}
+	if err := barcode.ValidateZPL(zpl); err != nil {
+		return err
+	}
+
+	address := *printer
+	if address == "" {
+		address, err = discoverSinglePrinter(*timeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := barcode.SendZPL(address, zpl); err != nil {
+		return err
+	}
+	fmt.Printf("sent %d bytes to %s\n", len(zpl), address)
+	return nil
+}
+
+// printZPL reads the ZPL file when given, and otherwise generates the label
+func printZPL(zplFile string, input barcode.BarcodeInput, text string) (string, error) {
+	switch {
+	case zplFile == "-":
+		zpl, err := io.ReadAll(os.Stdin)
+		return string(zpl), err
+	case zplFile != "":
+		zpl, err := os.ReadFile(zplFile)
+		return string(zpl), err
+	case input.BarcodeData == "":
+		return "", errors.New("either --zpl or --data is required")
+	}
+
+	if text != "" {
+		input.TextLines = []barcode.TextLine{{Text: text, Position: barcode.TextPositionBelow, Size: barcode.TextSizeMedium}}
+	}
+	input.OutputFormats = []barcode.OutputFormat{barcode.OutputFormatZPL}
+	output, err := barcode.GenerateBarcode(input)
+	if err != nil {
+		return "", err
+	}
+	for _, warning := range output.Warnings {
+		fmt.Fprintln(os.Stderr, "barcodegen: warning:", warning)
+	}
+	return output.ZPL, nil
+}
+
+// discoverSinglePrinter returns the address of the only printer on the network
+func discoverSinglePrinter(timeout time.Duration) (string, error) {
+	printers, err := barcode.DiscoverPrinters(timeout)
+	if err != nil {
+		return "", err
+	}
+	switch len(printers) {
+	case 0:
+		return "", errors.New("no printers found, pass --printer")
+	case 1:
+		return printers[0].Address, nil
+	default:
+		listPrinters(printers)
+		return "", fmt.Errorf("found %d printers, pass --printer", len(printers))
+	}
+}
+
+// runDiscover lists printers found over mDNS
+func runDiscover(args []string) error {
+	fs := flag.NewFlagSet("discover", flag.ExitOnError)
+	timeout := fs.Duration("timeout", defaultDiscoveryTimeout, "how long to wait for responses")
+	fs.Parse(args)
+
+	printers, err := barcode.DiscoverPrinters(*timeout)
+	if err != nil {
+		return err
+	}
+	if len(printers) == 0 {
+		fmt.Println("no printers found")
+		return nil
+	}
+	listPrinters(printers)
+	return nil
+}
+
+func listPrinters(printers []barcode.Printer) {
+	for _, printer := range printers {
+		fmt.Printf("%-21s  %s\n", printer.Address, printer.Name)
+	}
+}
+
+// runSoak generates labels continuously and prints a report at each interval
+func runSoak(args []string) error {
+	fs := flag.NewFlagSet("soak", flag.ExitOnError)
+	duration := fs.Duration("duration", 0, "how long to run (until interrupted when 0)")
+	interval := fs.Duration("interval", time.Minute, "time between reports")
+	workers := fs.Int("workers", 1, "concurrent generators")
+	data := fs.String("data", "SOAK-0000001", "barcode data")
+	barcodeType := fs.String("type", string(barcode.BarcodeTypeCode128), "barcode type")
+	width := fs.Float64("width", 50, "label width in millimeters")
+	height := fs.Float64("height", 25, "label height in millimeters")
+	dpi := fs.Int("dpi", 203, "printer dpi")
+	text := fs.String("text", "", "optional text line below the barcode")
+	fs.Parse(args)
+
+	input := barcode.BarcodeInput{
+		BarcodeData: *data,
+		BarcodeType: barcode.BarcodeType(*barcodeType),
+		Width:       *width,
+		Height:      *height,
+		Dpi:         *dpi,
+	}
+	if *text != "" {
+		input.TextLines = []barcode.TextLine{{Text: *text, Position: barcode.TextPositionBelow, Size: barcode.TextSizeMedium}}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Printf("%-10s  %10s  %8s  %10s  %10s  %10s  %10s\n", "elapsed", "labels", "errors", "heap", "objects", "rss", "goroutines")
+	stats, err := barcode.RunSoak(ctx, barcode.SoakOptions{
+		Inputs:         []barcode.BarcodeInput{input},
+		Workers:        *workers,
+		Duration:       *duration,
+		ReportInterval: *interval,
+		Report: func(s barcode.SoakStats) {
+			fmt.Printf("%-10s  %10d  %8d  %9.1fM  %10d  %9.1fM  %10d\n", s.Elapsed.Round(time.Second), s.Labels, s.Errors,
+				float64(s.HeapAlloc)/(1<<20), s.HeapObjects, float64(s.RSS)/(1<<20), s.Goroutines)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if stats.LastError != nil {
+		return fmt.Errorf("%d labels failed, last error: %w", stats.Errors, stats.LastError)
+	}
+	return nil
+}
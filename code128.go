@@ -0,0 +1,184 @@
+package barcode
+
+import (
+	"fmt"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/utils"
+)
+
+// Code128CodeSet selects the Code 128 character set used for the whole symbol
+type Code128CodeSet string
+
+const (
+	Code128CodeSetAuto Code128CodeSet = ""  // Let the encoder switch sets to minimise width
+	Code128CodeSetA    Code128CodeSet = "A" // Uppercase, digits, punctuation and control characters
+	Code128CodeSetB    Code128CodeSet = "B" // Printable ASCII including lowercase
+	Code128CodeSetC    Code128CodeSet = "C" // Digit pairs
+)
+
+// Function characters that may be embedded in Code128 data, e.g.
+// string(Code128FNC1) + "0109501101530003" for a GS1 element string.
+// FNC2-FNC4 are not available in code set C.
+const (
+	Code128FNC1 = code128.FNC1
+	Code128FNC2 = code128.FNC2
+	Code128FNC3 = code128.FNC3
+	Code128FNC4 = code128.FNC4
+)
+
+// Code128Options configures Code128 barcodes
+type Code128Options struct {
+	CodeSet Code128CodeSet // Optional: force a single code set for a deterministic symbol width
+}
+
+// Code 128 symbol values
+const (
+	code128StartA = 103
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// code128Patterns holds the alternating bar and space widths of each symbol value
+var code128Patterns = [107]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+// validateCode128Options ensures the code set is known and, when one is
+// forced, that every character of the data exists in it
+func validateCode128Options(data string, options Code128Options) error {
+	switch options.CodeSet {
+	case Code128CodeSetAuto:
+		return nil
+	case Code128CodeSetA, Code128CodeSetB, Code128CodeSetC:
+		_, err := code128Values(data, options.CodeSet)
+		return err
+	default:
+		return fmt.Errorf("invalid Code128 code set: %q. Supported code sets are A, B and C", options.CodeSet)
+	}
+}
+
+// encodeCode128 creates a Code128 barcode, in a single code set when one is forced
+func encodeCode128(data string, options Code128Options) (barcode.Barcode, error) {
+	if options.CodeSet == Code128CodeSetAuto {
+		bc, err := code128.Encode(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode Code128 barcode: %w", err)
+		}
+		return bc, nil
+	}
+
+	if err := validateCode128Options(data, options); err != nil {
+		return nil, fmt.Errorf("failed to encode Code128 barcode: %w", err)
+	}
+	values, _ := code128Values(data, options.CodeSet)
+
+	// The check value weights the start symbol by 1 and each following value by its position
+	check := values[0]
+	for i, value := range values[1:] {
+		check += (i + 1) * value
+	}
+	values = append(values, check%103, code128Stop)
+
+	bits := new(utils.BitList)
+	for _, value := range values {
+		for i, width := range code128Patterns[value] {
+			for j := 0; j < int(width-'0'); j++ {
+				bits.AddBit(i%2 == 0)
+			}
+		}
+	}
+	return utils.New1DCode(barcode.TypeCode128, data, bits), nil
+}
+
+// code128Values converts the data to symbol values in a single code set,
+// starting with the code set's start symbol
+func code128Values(data string, codeSet Code128CodeSet) ([]int, error) {
+	runes := []rune(data)
+	if len(runes) == 0 {
+		return nil, fmt.Errorf("invalid Code128 data: data must not be empty")
+	}
+
+	switch codeSet {
+	case Code128CodeSetC:
+		values := []int{code128StartC}
+		for i := 0; i < len(runes); i++ {
+			if runes[i] == Code128FNC1 {
+				values = append(values, 102)
+				continue
+			}
+			if i+1 >= len(runes) || !isDigitRune(runes[i]) || !isDigitRune(runes[i+1]) {
+				return nil, fmt.Errorf("invalid Code128 data for code set C: %q. Only digit pairs and FNC1 are allowed", data)
+			}
+			values = append(values, int(runes[i]-'0')*10+int(runes[i+1]-'0'))
+			i++
+		}
+		return values, nil
+	case Code128CodeSetA:
+		values := []int{code128StartA}
+		for _, r := range runes {
+			switch {
+			case r >= 32 && r <= 95:
+				values = append(values, int(r-32))
+			case r >= 0 && r < 32:
+				values = append(values, int(r+64))
+			case r == Code128FNC4:
+				values = append(values, 101)
+			default:
+				value, ok := code128FunctionValue(r)
+				if !ok {
+					return nil, fmt.Errorf("invalid Code128 data for code set A: %q. Lowercase and non-ASCII characters are not allowed", data)
+				}
+				values = append(values, value)
+			}
+		}
+		return values, nil
+	default:
+		values := []int{code128StartB}
+		for _, r := range runes {
+			switch {
+			case r >= 32 && r <= 127:
+				values = append(values, int(r-32))
+			case r == Code128FNC4:
+				values = append(values, 100)
+			default:
+				value, ok := code128FunctionValue(r)
+				if !ok {
+					return nil, fmt.Errorf("invalid Code128 data for code set B: %q. Control and non-ASCII characters are not allowed", data)
+				}
+				values = append(values, value)
+			}
+		}
+		return values, nil
+	}
+}
+
+// code128FunctionValue returns the value of FNC1-FNC3, which are shared by code sets A and B
+func code128FunctionValue(r rune) (int, bool) {
+	switch r {
+	case Code128FNC1:
+		return 102, true
+	case Code128FNC2:
+		return 97, true
+	case Code128FNC3:
+		return 96, true
+	default:
+		return 0, false
+	}
+}
+
+func isDigitRune(r rune) bool {
+	return r >= '0' && r <= '9'
+}
@@ -0,0 +1,95 @@
+package barcode
+
+import (
+	"testing"
+
+	"github.com/boombuler/barcode/code128"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEncodeCode128_MatchesAuto verifies forced code sets produce the same
+// symbol as the automatic encoder when it would choose the same set
+func TestEncodeCode128_MatchesAuto(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		codeSet Code128CodeSet
+	}{
+		{"SetA", "\x01LOC\x02", Code128CodeSetA},
+		{"SetB", "loc-a1", Code128CodeSetB},
+		{"SetC", "123456", Code128CodeSetC},
+		{"SetCWithFNC1", string(Code128FNC1) + "0109501101530003", Code128CodeSetC},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auto, err := code128.Encode(tt.data)
+			require.NoError(t, err)
+			forced, err := encodeCode128(tt.data, Code128Options{CodeSet: tt.codeSet})
+			require.NoError(t, err)
+
+			require.Equal(t, auto.Bounds().Dx(), forced.Bounds().Dx())
+			for x := 0; x < auto.Bounds().Dx(); x++ {
+				assert.Equal(t, auto.At(x, 0), forced.At(x, 0), "Module %d should match", x)
+			}
+		})
+	}
+}
+
+// TestEncodeCode128_FixedWidth verifies a forced set gives a width that depends only on length
+func TestEncodeCode128_FixedWidth(t *testing.T) {
+	// Auto would switch to set C for the digits, producing a narrower symbol
+	digits, err := encodeCode128("LOC1234", Code128Options{CodeSet: Code128CodeSetB})
+	require.NoError(t, err)
+	letters, err := encodeCode128("LOCABCD", Code128Options{CodeSet: Code128CodeSetB})
+	require.NoError(t, err)
+
+	// Start, 7 characters and check are 11 modules each, the stop is 13
+	assert.Equal(t, 9*11+13, digits.Bounds().Dx())
+	assert.Equal(t, digits.Bounds().Dx(), letters.Bounds().Dx())
+}
+
+// TestValidateCode128Options ensures data outside the forced set is rejected
+func TestValidateCode128Options(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		codeSet     Code128CodeSet
+		expectedErr string
+	}{
+		{"UnknownSet", "ABC", "D", "invalid Code128 code set"},
+		{"LowercaseInA", "abc", Code128CodeSetA, "code set A"},
+		{"ControlInB", "A\x01", Code128CodeSetB, "code set B"},
+		{"OddDigitsInC", "12345", Code128CodeSetC, "code set C"},
+		{"FNC4InC", "12" + string(Code128FNC4), Code128CodeSetC, "code set C"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCode128Options(tt.data, Code128Options{CodeSet: tt.codeSet})
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+// TestGenerateBarcode_Code128CodeSet verifies forced sets are validated before rendering
+func TestGenerateBarcode_Code128CodeSet(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "00123456",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50.0,
+		Height:      25.0,
+		Dpi:         203,
+		Code128:     Code128Options{CodeSet: Code128CodeSetC},
+	}
+
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.NotEmpty(t, output.ZPL)
+
+	input.BarcodeData = "0012345"
+	_, err = GenerateBarcode(input)
+	assert.Error(t, err)
+}
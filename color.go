@@ -0,0 +1,93 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// ColorToHex formats a color as the "#RRGGBB" string accepted by
+// ForegroundColor and BackgroundColor, ignoring its alpha
+func ColorToHex(c color.Color) string {
+	rgba := color.RGBAModel.Convert(c).(color.RGBA)
+	return fmt.Sprintf("#%02X%02X%02X", rgba.R, rgba.G, rgba.B)
+}
+
+// parseHexColor parses a "#RRGGBB" or "#RGB" color; the leading # is optional
+func parseHexColor(hex string) (color.RGBA, error) {
+	digits := strings.TrimPrefix(hex, "#")
+	if len(digits) == 3 {
+		digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2]})
+	}
+	value, err := strconv.ParseUint(digits, 16, 32)
+	if len(digits) != 6 || err != nil {
+		return color.RGBA{}, fmt.Errorf("not a hex color")
+	}
+	return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 255}, nil
+}
+
+// validateLabelColor ensures an optional color is a hex color
+func validateLabelColor(name, value string) error {
+	if value == "" {
+		return nil
+	}
+	if _, err := parseHexColor(value); err != nil {
+		return fmt.Errorf("invalid %s color: %q. Must be a hex color such as #1A4D8F", name, value)
+	}
+	return nil
+}
+
+// labelColors returns the requested foreground and background colors, and
+// false when the label stays black on white: no colors were requested, the
+// image is monochrome, or color output is disabled for the deployment
+func labelColors(input BarcodeInput, g *Generator) (color.RGBA, color.RGBA, bool) {
+	foreground, background := color.RGBA{A: 255}, color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	if input.ForegroundColor == "" && input.BackgroundColor == "" || input.Monochrome || g.Features.DisableColorOutput {
+		return foreground, background, false
+	}
+	if input.ForegroundColor != "" {
+		foreground, _ = parseHexColor(input.ForegroundColor)
+	}
+	if input.BackgroundColor != "" {
+		background, _ = parseHexColor(input.BackgroundColor)
+	}
+	return foreground, background, true
+}
+
+// colorizeLabel maps a black on white label to the requested colors. Gray
+// pixels, such as anti-aliased text edges, are blended between the two, so
+// the label is recolored without rendering it again. With a transparent
+// background, gray pixels instead become partly transparent foreground. The
+// label is returned unchanged when it stays black on white.
+func colorizeLabel(label *image.RGBA, input BarcodeInput, g *Generator) *image.RGBA {
+	foreground, background, colored := labelColors(input, g)
+	transparent := input.TransparentBackground && !input.Monochrome
+	if !colored && !transparent {
+		return label
+	}
+
+	bounds := label.Bounds()
+	result := image.NewRGBA(bounds)
+	blend := func(from, to uint8, y uint32) uint8 {
+		return uint8((uint32(from)*(255-y) + uint32(to)*y + 127) / 255)
+	}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			gray := uint32(color.GrayModel.Convert(label.RGBAAt(x, y)).(color.Gray).Y)
+			if transparent {
+				c := color.NRGBA{R: foreground.R, G: foreground.G, B: foreground.B, A: uint8(255 - gray)}
+				result.Set(x, y, c)
+				continue
+			}
+			result.SetRGBA(x, y, color.RGBA{
+				R: blend(foreground.R, background.R, gray),
+				G: blend(foreground.G, background.G, gray),
+				B: blend(foreground.B, background.B, gray),
+				A: 255,
+			})
+		}
+	}
+	return result
+}
@@ -0,0 +1,127 @@
+package barcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseHexColor verifies long and short hex colors parse and other strings are rejected
+func TestParseHexColor(t *testing.T) {
+	c, err := parseHexColor("#1A4D8F")
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{R: 0x1A, G: 0x4D, B: 0x8F, A: 255}, c)
+
+	c, err = parseHexColor("f80")
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{R: 0xFF, G: 0x88, A: 255}, c)
+
+	for _, hex := range []string{"", "#12345", "#GGGGGG", "#+12345", "red"} {
+		_, err := parseHexColor(hex)
+		assert.Error(t, err, hex)
+	}
+
+	assert.Equal(t, "#1A4D8F", ColorToHex(color.RGBA{R: 0x1A, G: 0x4D, B: 0x8F, A: 255}))
+}
+
+// TestColorizeLabel verifies black, white and gray pixels map onto the colors
+func TestColorizeLabel(t *testing.T) {
+	label := createBlankLabel(3, 1)
+	label.SetRGBA(0, 0, color.RGBA{A: 255})
+	label.SetRGBA(1, 0, color.RGBA{R: 128, G: 128, B: 128, A: 255})
+	input := BarcodeInput{ForegroundColor: "#FF0000", BackgroundColor: "#0000FF"}
+
+	colored := colorizeLabel(label, input, &Generator{})
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, colored.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{R: 127, B: 128, A: 255}, colored.RGBAAt(1, 0))
+	assert.Equal(t, color.RGBA{B: 255, A: 255}, colored.RGBAAt(2, 0))
+	assert.Equal(t, color.RGBA{A: 255}, label.RGBAAt(0, 0), "The original label should be unchanged")
+
+	assert.Same(t, label, colorizeLabel(label, BarcodeInput{}, &Generator{}))
+	assert.Same(t, label, colorizeLabel(label, input, &Generator{Features: Features{DisableColorOutput: true}}))
+}
+
+// TestGenerateBarcode_Colors verifies the PNG uses the requested colors while
+// printer output stays black on white, and invalid colors are rejected
+func TestGenerateBarcode_Colors(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:   "LOC-A1",
+		BarcodeType:   BarcodeTypeCode128,
+		Width:         50.0,
+		Height:        25.0,
+		Dpi:           203,
+		OutputFormats: []OutputFormat{OutputFormatPNG, OutputFormatZPL},
+	}
+	plain, err := GenerateBarcode(input)
+	require.NoError(t, err)
+
+	input.ForegroundColor, input.BackgroundColor = "#1A4D8F", "#FFF8E0"
+	colored, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Equal(t, plain.ZPL, colored.ZPL)
+
+	raw, err := base64.StdEncoding.DecodeString(colored.ImageBase64)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{R: 0xFF, G: 0xF8, B: 0xE0, A: 255}, color.RGBAModel.Convert(img.At(0, 0)))
+
+	labelImg, layout, err := GenerateBarcodeImage(input)
+	require.NoError(t, err)
+	y := layout.BarcodeRect.Min.Y + layout.BarcodeRect.Dy()/2
+	found := false
+	for x := layout.BarcodeRect.Min.X; x < layout.BarcodeRect.Max.X; x++ {
+		found = found || labelImg.At(x, y) == color.Color(color.RGBA{R: 0x1A, G: 0x4D, B: 0x8F, A: 255})
+	}
+	assert.True(t, found, "Bars should use the foreground color")
+
+	disabled, err := (&Generator{Features: Features{DisableColorOutput: true}}).Generate(input)
+	require.NoError(t, err)
+	assert.Equal(t, plain.ImageBase64, disabled.ImageBase64)
+
+	input.BackgroundColor = "beige"
+	_, err = GenerateBarcode(input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid background color")
+}
+
+// TestGenerateBarcode_TransparentBackground verifies the PNG background is
+// transparent with opaque bars, while ZPL stays flattened to white
+func TestGenerateBarcode_TransparentBackground(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:   "LOC-A1",
+		BarcodeType:   BarcodeTypeCode128,
+		Width:         50.0,
+		Height:        25.0,
+		Dpi:           203,
+		OutputFormats: []OutputFormat{OutputFormatPNG, OutputFormatZPL, OutputFormatPDF},
+	}
+	plain, err := GenerateBarcode(input)
+	require.NoError(t, err)
+
+	input.TransparentBackground = true
+	transparent, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Equal(t, plain.ZPL, transparent.ZPL)
+	assert.Equal(t, plain.PDFBase64, transparent.PDFBase64)
+
+	raw, err := base64.StdEncoding.DecodeString(transparent.ImageBase64)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{}, color.NRGBAModel.Convert(img.At(0, 0)))
+
+	labelImg, layout, err := GenerateBarcodeImage(input)
+	require.NoError(t, err)
+	y := layout.BarcodeRect.Min.Y + layout.BarcodeRect.Dy()/2
+	found := false
+	for x := layout.BarcodeRect.Min.X; x < layout.BarcodeRect.Max.X; x++ {
+		found = found || labelImg.At(x, y) == color.Color(color.RGBA{A: 255})
+	}
+	assert.True(t, found, "Bars should stay opaque black")
+}
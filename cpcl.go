@@ -0,0 +1,177 @@
+package barcode
+
+import (
+	"encoding/hex"
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/boombuler/barcode"
+)
+
+// CPCL limits
+const (
+	cpclMaxMultiplier  = 16 // Largest SETMAG magnification in both directions
+	cpclMaxQRCellWidth = 32 // Largest QR unit width in dots
+)
+
+// cpclFonts lists the resident fonts used for text with their cells in dots
+var cpclFonts = []residentFont{{12, 24}, {23, 47}}
+
+// cpclFontNumbers selects each font in cpclFonts as font number and size
+var cpclFontNumbers = []string{"7 0", "4 0"}
+
+// CPCLOptions configures CPCL output
+type CPCLOptions struct {
+	// Optional: the media is continuous, e.g. receipt paper. The printer then
+	// feeds exactly the label height instead of searching for a gap or black
+	// mark, which on continuous media would feed up to its maximum length.
+	ContinuousMedia bool
+}
+
+// generateCPCL converts the label to CPCL for Zebra mobile printers such as
+// the ZQ520. The label height in the session header is the label length.
+// Text, QR codes and the linear barcodes CPCL supports are sent as native
+// commands laid out like the print image. Other symbols, and text no resident
+// font fits, are sent as EG graphics cropped from the print image. When
+// native is false, the whole print image is sent as one graphic.
+func generateCPCL(input BarcodeInput, bc barcode.Barcode, printImg *image.RGBA, native bool) (string, error) {
+	var cpcl strings.Builder
+	bounds := printImg.Bounds()
+	fmt.Fprintf(&cpcl, "! 0 %d %d %d 1\r\nPAGE-WIDTH %d\r\n", cpclResolution(input.Dpi), cpclResolution(input.Dpi), bounds.Dy(), bounds.Dx())
+
+	if !native {
+		writeCPCLGraphic(&cpcl, printImg, bounds)
+		writeCPCLEnd(&cpcl, input.CPCL)
+		return cpcl.String(), nil
+	}
+
+	layout, err := layoutLabel(input, bc, input.Dpi)
+	if err != nil {
+		return "", err
+	}
+	if command, ok := cpclBarcodeCommand(input, bc, layout); ok {
+		cpcl.WriteString(command)
+	} else {
+		writeCPCLGraphic(&cpcl, printImg, layout.barcodeRect)
+	}
+
+	writeCPCLTextLines(&cpcl, input, printImg, layout)
+	writeCPCLEnd(&cpcl, input.CPCL)
+	return cpcl.String(), nil
+}
+
+// cpclResolution returns the resolution CPCL expects in the session header,
+// which is 200 for 203 DPI printers
+func cpclResolution(dpi int) int {
+	if dpi == 203 {
+		return 200
+	}
+	return dpi
+}
+
+// cpclBarcodeCommand returns the native BARCODE command for barcodes CPCL can
+// print identically: unrotated Code128 in the automatic code set without
+// function characters, ITF, ISBN or ISSN without an add-on, and QR codes the
+// printer encodes at the same version
+func cpclBarcodeCommand(input BarcodeInput, bc barcode.Barcode, layout labelLayout) (string, bool) {
+	if input.BarcodeRotation != 0 {
+		return "", false
+	}
+	origin, moduleWidth, ok := nativeModuleLayout(bc, layout)
+	if !ok {
+		return "", false
+	}
+
+	// The ratio selects the wide to narrow bar ratio: 1 is 2:1 and 3 is 3:1
+	var barcodeType, data string
+	ratio := 1
+	switch input.BarcodeType {
+	case BarcodeTypeCode128:
+		if input.Code128.CodeSet != Code128CodeSetAuto || !isPrintableASCII(input.BarcodeData) {
+			return "", false
+		}
+		barcodeType = "128"
+		data = input.BarcodeData
+	case BarcodeTypeITF:
+		barcodeType = "I2OF5"
+		data = bc.Content()
+		ratio = 3
+	case BarcodeTypeISBN, BarcodeTypeISSN:
+		if input.ISBN.AddOn != "" {
+			return "", false
+		}
+		barcodeType = "EAN13"
+		data = bc.Content()[:12] // The printer adds the check digit
+	case BarcodeTypeQR:
+		return cpclQRCommand(input, origin, moduleWidth)
+	default:
+		return "", false
+	}
+
+	return fmt.Sprintf("BARCODE %s %d %d %d %d %d %s\r\n", barcodeType, moduleWidth, ratio,
+		layout.barcodeRect.Dy(), origin.X, origin.Y, data), true
+}
+
+// cpclQRCommand returns the model 2 QR command for QR codes in the automatic
+// mode and version, which the printer picks the same way as the encoder
+func cpclQRCommand(input BarcodeInput, origin image.Point, cellWidth int) (string, bool) {
+	if input.BarcodeDataBytes != nil || input.QR.Mode != QRModeAuto || input.QR.MinVersion > 1 ||
+		cellWidth > cpclMaxQRCellWidth || !isPrintableASCII(input.BarcodeData) {
+		return "", false
+	}
+
+	level := input.QR.ErrorCorrection
+	if level == "" {
+		level = QRErrorCorrectionM
+	}
+	return fmt.Sprintf("BARCODE QR %d %d M 2 U %d\r\n%sA,%s\r\nENDQR\r\n", origin.X, origin.Y, cellWidth, level, input.BarcodeData), true
+}
+
+// writeCPCLTextLines writes each text line as a native TEXT command in the
+// largest resident font that fits its rendered size, falling back to a
+// graphic of the line when none fits
+func writeCPCLTextLines(cpcl *strings.Builder, input BarcodeInput, printImg *image.RGBA, layout labelLayout) {
+	magnified := false
+	for _, line := range layoutNativeTextLines(input, printImg, layout) {
+		font, multiplier, ok := selectResidentFont(cpclFonts, cpclMaxMultiplier, line, input.Dpi)
+		if !ok {
+			writeCPCLGraphic(cpcl, printImg, textBand(line, input.Dpi, layout.width))
+			continue
+		}
+
+		pos := residentTextPosition(line, cpclFonts[font], multiplier)
+		fmt.Fprintf(cpcl, "SETMAG %d %d\r\nTEXT %s %d %d %s\r\n", multiplier, multiplier, cpclFontNumbers[font], pos.X, pos.Y, line.Text)
+		magnified = true
+	}
+
+	// Magnification persists across labels until reset
+	if magnified {
+		cpcl.WriteString("SETMAG 0 0\r\n")
+	}
+}
+
+// writeCPCLGraphic writes the area of img as an EG command with hex data
+func writeCPCLGraphic(cpcl *strings.Builder, img *image.RGBA, area image.Rectangle) {
+	area = area.Intersect(img.Bounds())
+	if area.Empty() {
+		return
+	}
+
+	rowBytes, data := packMonochrome(img, area)
+	for i := range data {
+		data[i] = ^data[i] // EG prints set bits
+	}
+	fmt.Fprintf(cpcl, "EG %d %d %d %d %s\r\n", rowBytes, area.Dy(), area.Min.X, area.Min.Y, strings.ToUpper(hex.EncodeToString(data)))
+}
+
+// writeCPCLEnd prints the label. On gap or black mark media FORM then feeds
+// to the top of the next label, while on continuous media JOURNAL turns off
+// the media search so only the label length is fed.
+func writeCPCLEnd(cpcl *strings.Builder, options CPCLOptions) {
+	if options.ContinuousMedia {
+		cpcl.WriteString("JOURNAL\r\nPRINT\r\n")
+		return
+	}
+	cpcl.WriteString("FORM\r\nPRINT\r\n")
+}
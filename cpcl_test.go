@@ -0,0 +1,87 @@
+package barcode
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateBarcode_CPCL verifies supported barcodes and text use native commands
+func TestGenerateBarcode_CPCL(t *testing.T) {
+	output, err := GenerateBarcode(BarcodeInput{
+		BarcodeData:   "LOC-A1-B2-C3",
+		BarcodeType:   BarcodeTypeCode128,
+		Width:         50,
+		Height:        25,
+		Dpi:           203,
+		OutputFormats: []OutputFormat{OutputFormatCPCL},
+		TextLines:     []TextLine{{Text: "LOC-A1-B2-C3", Position: TextPositionBelow, Size: TextSizeMedium}},
+	})
+	require.NoError(t, err)
+
+	lines := strings.Split(output.CPCL, "\r\n")
+	require.Len(t, lines, 9)
+	assert.Equal(t, []string{"! 0 200 200 199 1", "PAGE-WIDTH 399"}, lines[:2])
+	assert.Regexp(t, `^BARCODE 128 2 1 \d+ \d+ \d+ LOC-A1-B2-C3$`, lines[2])
+	assert.Regexp(t, `^SETMAG \d+ \d+$`, lines[3])
+	assert.Regexp(t, `^TEXT [47] 0 \d+ \d+ LOC-A1-B2-C3$`, lines[4])
+	assert.Equal(t, []string{"SETMAG 0 0", "FORM", "PRINT", ""}, lines[5:])
+}
+
+// TestGenerateBarcode_CPCLContinuousMedia verifies continuous media feeds only the label length
+func TestGenerateBarcode_CPCLContinuousMedia(t *testing.T) {
+	output, err := GenerateBarcode(BarcodeInput{
+		BarcodeData:   "LOC-A1-B2-C3",
+		BarcodeType:   BarcodeTypeCode128,
+		Width:         50,
+		Height:        40,
+		Dpi:           203,
+		OutputFormats: []OutputFormat{OutputFormatCPCL},
+		CPCL:          CPCLOptions{ContinuousMedia: true},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(output.CPCL, "! 0 200 200 319 1\r\n"))
+	assert.True(t, strings.HasSuffix(output.CPCL, "\r\nJOURNAL\r\nPRINT\r\n"))
+	assert.NotContains(t, output.CPCL, "FORM")
+}
+
+// TestGenerateBarcode_CPCLBarcodes verifies the barcode selection for each native type
+func TestGenerateBarcode_CPCLBarcodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    BarcodeInput
+		expected string
+	}{
+		{"ITF", BarcodeInput{BarcodeData: "12345678", BarcodeType: BarcodeTypeITF}, "BARCODE I2OF5 "},
+		{"ISBN", BarcodeInput{BarcodeData: "978-0-306-40615-7", BarcodeType: BarcodeTypeISBN}, " 978030640615\r\n"},
+		{"QR", BarcodeInput{BarcodeData: "https://example.com", BarcodeType: BarcodeTypeQR}, " M 2 U "},
+		{"QRBytes", BarcodeInput{BarcodeDataBytes: []byte{0x01, 0x02}, BarcodeType: BarcodeTypeQR}, "EG "},
+		{"Code128SetC", BarcodeInput{BarcodeData: "123456", BarcodeType: BarcodeTypeCode128, Code128: Code128Options{CodeSet: Code128CodeSetC}}, "EG "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input.Width, tt.input.Height, tt.input.Dpi = 50, 30, 203
+			tt.input.OutputFormats = []OutputFormat{OutputFormatCPCL}
+			output, err := GenerateBarcode(tt.input)
+			require.NoError(t, err)
+			assert.Contains(t, output.CPCL, "PAGE-WIDTH 399\r\n")
+			assert.Contains(t, output.CPCL[strings.Index(output.CPCL, "PAGE-WIDTH"):], tt.expected)
+		})
+	}
+}
+
+// TestWriteCPCLGraphic verifies dark pixels are set bits and rows are padded white
+func TestWriteCPCLGraphic(t *testing.T) {
+	img := createBlankLabel(10, 2)
+	img.Set(0, 0, color.Black)
+	img.Set(9, 1, color.Black)
+
+	var cpcl strings.Builder
+	writeCPCLGraphic(&cpcl, img, image.Rect(0, 0, 20, 2))
+	assert.Equal(t, "EG 2 2 0 0 80000040\r\n", cpcl.String())
+}
@@ -0,0 +1,849 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/utils"
+)
+
+// GS1 DataBar (ISO/IEC 24724) constants
+const (
+	dataBarOmniCodeKind            = "GS1 DataBar Omnidirectional"
+	dataBarExpandedCodeKind        = "GS1 DataBar Expanded"
+	dataBarExpandedStackedCodeKind = "GS1 DataBar Expanded Stacked"
+
+	dataBarExpandedRowHeight = 34 // Minimum row height in modules
+
+	dataBarMinDataChars      = 3  // Smallest Expanded symbol has 4 symbol characters
+	dataBarMaxDataChars      = 21 // Largest Expanded symbol has 22 symbol characters
+	dataBarDefaultSegments   = 4  // Default Expanded Stacked symbol characters per row
+	dataBarMaxSegmentsPerRow = 22
+)
+
+// DataBarOptions configures GS1 DataBar symbols
+type DataBarOptions struct {
+	SegmentsPerRow int // Expanded Stacked: symbol characters per row, even 2-22 (default 4)
+}
+
+// Omnidirectional character groups (ISO/IEC 24724 Tables 2 and 3).
+// Groups 0-4 are outside characters, 5-8 inside characters.
+var (
+	dataBarOmniGroupSum    = [9]int{0, 161, 961, 2015, 2715, 0, 336, 1036, 1516}
+	dataBarOmniGroupTotal  = [9]int{1, 10, 34, 70, 126, 4, 20, 48, 81}
+	dataBarOmniModulesOdd  = [9]int{12, 10, 8, 6, 4, 5, 7, 9, 11}
+	dataBarOmniModulesEven = [9]int{4, 6, 8, 10, 12, 10, 8, 6, 4}
+	dataBarOmniWidestOdd   = [9]int{8, 6, 4, 3, 1, 2, 4, 6, 8}
+	dataBarOmniWidestEven  = [9]int{1, 3, 5, 6, 8, 7, 5, 3, 1}
+)
+
+// Omnidirectional finder patterns, indexed by finder value
+var dataBarOmniFinders = [9][5]int{
+	{3, 8, 2, 1, 1}, {3, 5, 5, 1, 1}, {3, 3, 7, 1, 1},
+	{3, 1, 9, 1, 1}, {2, 7, 4, 1, 1}, {2, 5, 6, 1, 1},
+	{2, 3, 8, 1, 1}, {1, 5, 7, 1, 1}, {1, 3, 9, 1, 1},
+}
+
+// Expanded character groups (ISO/IEC 24724 Table 11)
+var (
+	dataBarExpGroupSum    = [5]int{0, 348, 1388, 2948, 3988}
+	dataBarExpEvenTotal   = [5]int{4, 20, 52, 104, 204}
+	dataBarExpModulesOdd  = [5]int{12, 10, 8, 6, 4}
+	dataBarExpModulesEven = [5]int{5, 7, 9, 11, 13}
+	dataBarExpWidestOdd   = [5]int{7, 5, 4, 3, 1}
+	dataBarExpWidestEven  = [5]int{2, 4, 5, 6, 8}
+)
+
+// Expanded finder patterns A-F in their left-to-right ("1") form
+var dataBarExpFinders = [6][5]int{
+	{1, 8, 4, 1, 1}, {3, 6, 4, 1, 1}, {3, 4, 6, 1, 1},
+	{3, 2, 8, 1, 1}, {2, 6, 5, 1, 1}, {2, 2, 9, 1, 1},
+}
+
+// Expanded finder sequences by number of finder patterns (ISO/IEC 24724 Table 12).
+// Values are finder letters, A=0 to F=5; finders at odd positions are reversed.
+var dataBarExpFinderSequences = [10][]int{
+	{0, 0},
+	{0, 1, 1},
+	{0, 2, 1, 3},
+	{0, 4, 1, 3, 2},
+	{0, 4, 1, 3, 3, 5},
+	{0, 4, 1, 3, 4, 5, 5},
+	{0, 0, 1, 1, 2, 2, 3, 3},
+	{0, 0, 1, 1, 2, 2, 3, 4, 4},
+	{0, 0, 1, 1, 2, 2, 3, 4, 5, 5},
+	{0, 0, 1, 1, 2, 3, 3, 4, 4, 5, 5},
+}
+
+// gs1FNC1 marks an FNC1 separator in general-purpose data
+const gs1FNC1 = -1
+
+// General-purpose compaction modes
+const (
+	gpModeNumeric = iota
+	gpModeAlphanumeric
+	gpModeISO646
+)
+
+// ISO/IEC 646 punctuation values in general-purpose compaction
+var gpISO646Punctuation = map[rune]int{
+	'!': 232, '"': 233, '%': 234, '&': 235, '\'': 236, '(': 237, ')': 238,
+	'*': 239, '+': 240, ',': 241, '-': 242, '.': 243, '/': 244, ':': 245,
+	';': 246, '<': 247, '=': 248, '>': 249, '?': 250, '_': 251, ' ': 252,
+}
+
+// Alphanumeric punctuation values in general-purpose compaction
+var gpAlphanumericPunctuation = map[rune]int{
+	'*': 58, ',': 59, '-': 60, '.': 61, '/': 62,
+}
+
+// multiRowBarcode is a barcode made of stacked rows of modules, such as
+// GS1 DataBar Expanded Stacked. Rows one module high are separators and keep
+// a height of one module when scaled; data rows share the remaining height.
+type multiRowBarcode struct {
+	content     string
+	kind        string
+	rows        [][]bool
+	rowHeights  []int // Modules when unscaled, pixels when scaled
+	moduleWidth int
+	offsetX     int
+	rect        image.Rectangle
+}
+
+func (bc *multiRowBarcode) Content() string { return bc.content }
+
+func (bc *multiRowBarcode) Metadata() barcode.Metadata {
+	return barcode.Metadata{CodeKind: bc.kind, Dimensions: 2}
+}
+
+func (bc *multiRowBarcode) ColorModel() color.Model { return color.Gray16Model }
+
+func (bc *multiRowBarcode) Bounds() image.Rectangle { return bc.rect }
+
+func (bc *multiRowBarcode) At(x, y int) color.Color {
+	if x < bc.offsetX {
+		return color.White
+	}
+	module := (x - bc.offsetX) / bc.moduleWidth
+
+	for i, height := range bc.rowHeights {
+		if y < height {
+			if module < len(bc.rows[i]) && bc.rows[i][module] {
+				return color.Black
+			}
+			return color.White
+		}
+		y -= height
+	}
+	return color.White
+}
+
+// scale returns the barcode rendered with the largest module width that fits
+// the given width. Data rows are shortened when the height is constrained.
+func (bc *multiRowBarcode) scale(width, height int) (barcode.Barcode, error) {
+	columns := len(bc.rows[0])
+	moduleWidth := width / columns
+	if moduleWidth == 0 {
+		return nil, fmt.Errorf("can not scale barcode to an image smaller than %dx%d", columns, len(bc.rows))
+	}
+
+	separators, dataRows, maxDataHeight := 0, 0, 0
+	for _, rowHeight := range bc.rowHeights {
+		if rowHeight == 1 {
+			separators++
+			continue
+		}
+		dataRows++
+		if rowHeight > maxDataHeight {
+			maxDataHeight = rowHeight
+		}
+	}
+
+	dataRowHeight := maxDataHeight * moduleWidth
+	if dataRows > 0 {
+		available := (height - separators*moduleWidth) / dataRows
+		if available < dataRowHeight {
+			dataRowHeight = available
+		}
+	}
+	if dataRowHeight < moduleWidth {
+		return nil, fmt.Errorf("can not scale barcode to an image smaller than %dx%d", columns, len(bc.rows))
+	}
+
+	rowHeights := make([]int, len(bc.rowHeights))
+	totalHeight := 0
+	for i, rowHeight := range bc.rowHeights {
+		rowHeights[i] = dataRowHeight
+		if rowHeight == 1 {
+			rowHeights[i] = moduleWidth
+		}
+		totalHeight += rowHeights[i]
+	}
+
+	return &multiRowBarcode{
+		content:     bc.content,
+		kind:        bc.kind,
+		rows:        bc.rows,
+		rowHeights:  rowHeights,
+		moduleWidth: moduleWidth,
+		offsetX:     (width - moduleWidth*columns) / 2,
+		rect:        image.Rect(0, 0, width, totalHeight),
+	}, nil
+}
+
+// validateDataBarOptions ensures the Expanded Stacked row width is usable
+func validateDataBarOptions(options DataBarOptions) error {
+	segments := options.SegmentsPerRow
+	if segments == 0 {
+		return nil
+	}
+	if segments < 2 || segments > dataBarMaxSegmentsPerRow || segments%2 != 0 {
+		return fmt.Errorf("invalid DataBar segments per row: %d. Must be an even number from 2 to %d", segments, dataBarMaxSegmentsPerRow)
+	}
+	return nil
+}
+
+// normalizeDataBarGTIN accepts a 13 or 14 digit GTIN, optionally prefixed
+// with (01), and returns the 14-digit GTIN including its check digit.
+func normalizeDataBarGTIN(data string) (string, error) {
+	gtin := strings.TrimPrefix(data, "(01)")
+	if len(gtin) == 13 && isNumeric(gtin) {
+		gtin += string(gs1CheckDigit(gtin))
+	}
+	if err := validateGTIN(gtin); err != nil {
+		return "", err
+	}
+	return gtin, nil
+}
+
+// validateDataBarExpandedData ensures the data is a GS1 element string that
+// fits in a DataBar Expanded symbol.
+func validateDataBarExpandedData(data string) error {
+	_, err := dataBarExpandedBinary(data, 0)
+	return err
+}
+
+// encodeDataBarOmni creates a GS1 DataBar Omnidirectional barcode from a GTIN.
+func encodeDataBarOmni(data string) (barcode.Barcode, error) {
+	gtin, err := normalizeDataBarGTIN(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode DataBar barcode: %w", err)
+	}
+
+	// The check digit is implied; the remaining 13 digits split into four characters
+	value, _ := strconv.ParseInt(gtin[:13], 10, 64)
+	left, right := int(value/4537077), int(value%4537077)
+	characters := [4]int{left / 1597, left % 1597, right / 1597, right % 1597}
+
+	var widths [4][8]int
+	for i, character := range characters {
+		widths[i] = dataBarOmniCharWidths(character, i%2 == 1)
+	}
+
+	// Checksum weights are successive powers of 3 modulo 79
+	checksum, weight := 0, 1
+	for _, charWidths := range widths {
+		for _, w := range charWidths {
+			checksum += w * weight
+			weight = weight * 3 % 79
+		}
+	}
+	checksum %= 79
+	if checksum >= 8 {
+		checksum++
+	}
+	if checksum >= 72 {
+		checksum++
+	}
+	leftFinder, rightFinder := dataBarOmniFinders[checksum/9], dataBarOmniFinders[checksum%9]
+
+	// Left guard, char 1, left finder, char 2 (reversed), char 4, right finder (reversed), char 3 (reversed), right guard
+	elements := []int{1, 1}
+	elements = append(elements, widths[0][:]...)
+	elements = append(elements, leftFinder[:]...)
+	elements = append(elements, reversedWidths(widths[1][:])...)
+	elements = append(elements, widths[3][:]...)
+	elements = append(elements, reversedWidths(rightFinder[:])...)
+	elements = append(elements, reversedWidths(widths[2][:])...)
+	elements = append(elements, 1, 1)
+
+	return utils.New1DCode(dataBarOmniCodeKind, "(01)"+gtin, elementsToBitList(elements)), nil
+}
+
+// encodeDataBarExpanded creates a single-row GS1 DataBar Expanded barcode
+// from a bracketed GS1 element string.
+func encodeDataBarExpanded(data string) (barcode.Barcode, error) {
+	elements, err := dataBarExpandedElements(data, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode DataBar Expanded barcode: %w", err)
+	}
+	return utils.New1DCode(dataBarExpandedCodeKind, data, elementsToBitList(elements)), nil
+}
+
+// encodeDataBarExpandedStacked creates a GS1 DataBar Expanded Stacked barcode,
+// splitting the symbol into rows of SegmentsPerRow symbol characters.
+func encodeDataBarExpandedStacked(data string, options DataBarOptions) (barcode.Barcode, error) {
+	segments := options.SegmentsPerRow
+	if segments == 0 {
+		segments = dataBarDefaultSegments
+	}
+
+	elements, err := dataBarExpandedElements(data, segments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode DataBar Expanded Stacked barcode: %w", err)
+	}
+
+	rows, rowHeights := stackDataBarExpanded(elements, segments/2)
+	return &multiRowBarcode{
+		content:     data,
+		kind:        dataBarExpandedStackedCodeKind,
+		rows:        rows,
+		rowHeights:  rowHeights,
+		moduleWidth: 1,
+		rect:        image.Rect(0, 0, len(rows[0]), sumInts(rowHeights)),
+	}, nil
+}
+
+// dataBarExpandedElements encodes the data and returns the element widths of
+// the complete single-row symbol, starting with a space.
+func dataBarExpandedElements(data string, segmentsPerRow int) ([]int, error) {
+	binary, err := dataBarExpandedBinary(data, segmentsPerRow)
+	if err != nil {
+		return nil, err
+	}
+
+	dataChars := len(binary) / 12
+	symbolChars := dataChars + 1
+	finders := (symbolChars + 1) / 2
+	sequence := dataBarExpFinderSequences[finders-2]
+
+	// Weight rows follow each character's position relative to its finder:
+	// left and right of finder value f use rows 2f-1 and 2f (check character excluded)
+	charWidths := make([][8]int, dataChars)
+	checksum := 0
+	for i := range charWidths {
+		value, _ := strconv.ParseInt(binary[i*12:(i+1)*12], 2, 32)
+		charWidths[i] = dataBarExpandedCharWidths(int(value))
+
+		position := i + 1
+		finderIndex := position / 2
+		finderValue := 2*sequence[finderIndex] + 1 + finderIndex%2
+		weightRow := 2*(finderValue-1) + position%2 - 1
+
+		for j, w := range charWidths[i] {
+			checksum += w * dataBarExpandedWeight(weightRow*8+j)
+		}
+	}
+	checkWidths := dataBarExpandedCharWidths(211*(symbolChars-4) + checksum%211)
+
+	// Each finder has the character to its left read forwards and the one to its right reversed
+	elements := []int{1, 1}
+	for f := 0; f < finders; f++ {
+		if f == 0 {
+			elements = append(elements, checkWidths[:]...)
+		} else {
+			elements = append(elements, charWidths[2*f-1][:]...)
+		}
+
+		finder := dataBarExpFinders[sequence[f]][:]
+		if f%2 == 1 {
+			finder = reversedWidths(finder)
+		}
+		elements = append(elements, finder...)
+
+		if 2*f < dataChars {
+			elements = append(elements, reversedWidths(charWidths[2*f][:])...)
+		}
+	}
+	elements = append(elements, 1, 1)
+
+	return elements, nil
+}
+
+// dataBarExpandedBinary builds the padded binary data string for a DataBar
+// Expanded symbol. segmentsPerRow is non-zero for stacked symbols, whose last
+// row must not hold a single symbol character.
+func dataBarExpandedBinary(data string, segmentsPerRow int) (string, error) {
+	elements, err := parseGS1ElementString(data)
+	if err != nil {
+		return "", err
+	}
+
+	var bits strings.Builder
+	bits.WriteByte('0') // Linkage flag: no 2D composite component
+
+	// Encodation method 1 compresses a leading GTIN, method 2 is general purpose only
+	general := elements
+	if elements[0].AI == "01" {
+		gtin := elements[0].Value
+		if err := validateGTIN(gtin); err != nil {
+			return "", err
+		}
+		bits.WriteString("1XX")
+		writeBits(&bits, int(gtin[0]-'0'), 4)
+		for i := 1; i < 13; i += 3 {
+			group, _ := strconv.Atoi(gtin[i : i+3])
+			writeBits(&bits, group, 10)
+		}
+		general = elements[1:]
+	} else {
+		bits.WriteString("00XX")
+	}
+
+	mode, err := writeGeneralPurpose(&bits, gs1GeneralPurposeChars(general))
+	if err != nil {
+		return "", err
+	}
+
+	dataChars := (bits.Len() + 11) / 12
+	if dataChars < dataBarMinDataChars {
+		dataChars = dataBarMinDataChars
+	}
+	if segmentsPerRow > 0 && (dataChars+1)%segmentsPerRow == 1 {
+		dataChars++
+	}
+	if dataChars > dataBarMaxDataChars {
+		return "", fmt.Errorf("invalid DataBar Expanded data: %w", &DataTooLongError{Length: dataChars + 1, Max: dataBarMaxDataChars + 1, Unit: "symbol characters"})
+	}
+
+	// Padding starts with a latch out of numeric mode, then repeats the ISO/IEC 646 latch
+	padding := ""
+	if mode == gpModeNumeric {
+		padding = "0000"
+	}
+	padding += strings.Repeat("00100", dataChars*12/5+1)
+	binary := bits.String() + padding[:dataChars*12-bits.Len()]
+
+	// Variable length field: odd symbol character count, more than 14 symbol characters
+	symbolChars := dataChars + 1
+	variableLength := []byte{'0', '0'}
+	if symbolChars%2 == 1 {
+		variableLength[0] = '1'
+	}
+	if symbolChars > 14 {
+		variableLength[1] = '1'
+	}
+	return strings.Replace(binary, "XX", string(variableLength), 1), nil
+}
+
+// gs1GeneralPurposeChars flattens elements into AI and value characters with
+// FNC1 separators after variable-length fields.
+func gs1GeneralPurposeChars(elements []gs1Element) []rune {
+	var chars []rune
+	for i, element := range elements {
+		chars = append(chars, []rune(element.AI+element.Value)...)
+		if i < len(elements)-1 && gs1NeedsSeparator(element) {
+			chars = append(chars, gs1FNC1)
+		}
+	}
+	return chars
+}
+
+// writeGeneralPurpose encodes characters with general-purpose compaction,
+// switching between numeric, alphanumeric and ISO/IEC 646 modes as needed.
+// It returns the mode in effect at the end of the data.
+func writeGeneralPurpose(bits *strings.Builder, chars []rune) (int, error) {
+	mode := gpModeNumeric
+	for i := 0; i < len(chars); {
+		c := chars[i]
+		switch mode {
+		case gpModeNumeric:
+			if i+1 < len(chars) && isNumericPair(c, chars[i+1]) {
+				writeBits(bits, 11*numericValue(c)+numericValue(chars[i+1])+8, 7)
+				i += 2
+				continue
+			}
+			bits.WriteString("0000")
+			mode = gpModeAlphanumeric
+			if _, ok := alphanumericValue(c); !ok && !isGPDigitOrFNC1(c) {
+				bits.WriteString("00100")
+				mode = gpModeISO646
+			}
+
+		case gpModeAlphanumeric, gpModeISO646:
+			if c == gs1FNC1 {
+				// FNC1 implies a return to numeric mode
+				bits.WriteString("01111")
+				mode = gpModeNumeric
+				i++
+				continue
+			}
+			if digitRunLength(chars, i) >= 4 {
+				bits.WriteString("000")
+				mode = gpModeNumeric
+				continue
+			}
+			if c >= '0' && c <= '9' {
+				writeBits(bits, int(c-'0')+5, 5)
+				i++
+				continue
+			}
+
+			value, alphanumeric := alphanumericValue(c)
+			if mode == gpModeAlphanumeric {
+				if alphanumeric {
+					writeBits(bits, value, 6)
+					i++
+					continue
+				}
+				bits.WriteString("00100")
+				mode = gpModeISO646
+				continue
+			}
+
+			if alphanumeric && alphanumericRunLength(chars, i) >= 4 {
+				bits.WriteString("00100")
+				mode = gpModeAlphanumeric
+				continue
+			}
+			switch {
+			case c >= 'A' && c <= 'Z':
+				writeBits(bits, int(c-'A')+64, 7)
+			case c >= 'a' && c <= 'z':
+				writeBits(bits, int(c-'a')+90, 7)
+			default:
+				punctuation, ok := gpISO646Punctuation[c]
+				if !ok {
+					return 0, fmt.Errorf("invalid character %q. GS1 data supports digits, letters and GS1 punctuation only", c)
+				}
+				writeBits(bits, punctuation, 8)
+			}
+			i++
+		}
+	}
+	return mode, nil
+}
+
+// isNumericPair reports whether two characters can share a numeric-mode value.
+// Either may be FNC1, but not both.
+func isNumericPair(a, b rune) bool {
+	return isGPDigitOrFNC1(a) && isGPDigitOrFNC1(b) && !(a == gs1FNC1 && b == gs1FNC1)
+}
+
+func isGPDigitOrFNC1(c rune) bool {
+	return c == gs1FNC1 || (c >= '0' && c <= '9')
+}
+
+// numericValue returns the numeric-mode value of a digit, with FNC1 as 10
+func numericValue(c rune) int {
+	if c == gs1FNC1 {
+		return 10
+	}
+	return int(c - '0')
+}
+
+// alphanumericValue returns the 6-bit alphanumeric-mode value of an uppercase
+// letter or punctuation character.
+func alphanumericValue(c rune) (int, bool) {
+	if c >= 'A' && c <= 'Z' {
+		return int(c-'A') + 32, true
+	}
+	value, ok := gpAlphanumericPunctuation[c]
+	return value, ok
+}
+
+// digitRunLength counts consecutive digits from position i
+func digitRunLength(chars []rune, i int) int {
+	n := 0
+	for ; i+n < len(chars) && chars[i+n] >= '0' && chars[i+n] <= '9'; n++ {
+	}
+	return n
+}
+
+// alphanumericRunLength counts consecutive characters from position i that
+// alphanumeric mode can encode.
+func alphanumericRunLength(chars []rune, i int) int {
+	n := 0
+	for ; i+n < len(chars); n++ {
+		c := chars[i+n]
+		if _, ok := alphanumericValue(c); !ok && (c < '0' || c > '9') {
+			break
+		}
+	}
+	return n
+}
+
+// dataBarOmniCharWidths returns the 8 element widths of an Omnidirectional
+// data character, interleaved odd/even. Inside characters use groups 5-8.
+func dataBarOmniCharWidths(value int, inside bool) [8]int {
+	first, last := 0, 4
+	if inside {
+		first, last = 5, 8
+	}
+	group := first
+	for group < last && value >= dataBarOmniGroupSum[group+1] {
+		group++
+	}
+
+	value -= dataBarOmniGroupSum[group]
+	total := dataBarOmniGroupTotal[group]
+
+	// Outside characters group by odd elements, inside characters by even elements
+	var odd, even []int
+	if inside {
+		odd = rssWidths(value%total, dataBarOmniModulesOdd[group], dataBarOmniWidestOdd[group], true)
+		even = rssWidths(value/total, dataBarOmniModulesEven[group], dataBarOmniWidestEven[group], false)
+	} else {
+		odd = rssWidths(value/total, dataBarOmniModulesOdd[group], dataBarOmniWidestOdd[group], false)
+		even = rssWidths(value%total, dataBarOmniModulesEven[group], dataBarOmniWidestEven[group], true)
+	}
+	return interleaveWidths(odd, even)
+}
+
+// dataBarExpandedCharWidths returns the 8 element widths of a 12-bit
+// Expanded symbol character, interleaved odd/even.
+func dataBarExpandedCharWidths(value int) [8]int {
+	group := 0
+	for group < 4 && value >= dataBarExpGroupSum[group+1] {
+		group++
+	}
+
+	value -= dataBarExpGroupSum[group]
+	total := dataBarExpEvenTotal[group]
+	odd := rssWidths(value/total, dataBarExpModulesOdd[group], dataBarExpWidestOdd[group], true)
+	even := rssWidths(value%total, dataBarExpModulesEven[group], dataBarExpWidestEven[group], false)
+	return interleaveWidths(odd, even)
+}
+
+// dataBarExpandedWeight returns checksum weight n, the nth power of 3 modulo 211
+func dataBarExpandedWeight(n int) int {
+	weight := 1
+	for i := 0; i < n; i++ {
+		weight = weight * 3 % 211
+	}
+	return weight
+}
+
+// rssWidths returns the widths of 4 elements spanning n modules for the given
+// value, no element wider than maxWidth (ISO/IEC 24724 Annex B). With
+// requireNarrow, combinations without a single-module element are skipped.
+func rssWidths(value, n, maxWidth int, requireNarrow bool) []int {
+	const elements = 4
+	widths := make([]int, elements)
+	narrowMask := 0
+
+	for bar := 0; bar < elements-1; bar++ {
+		var subVal int
+		elmWidth := 1
+		narrowMask |= 1 << uint(bar)
+		for {
+			// All combinations with this element at elmWidth
+			subVal = combinations(n-elmWidth-1, elements-bar-2)
+
+			// Less those with no single-module element
+			if requireNarrow && narrowMask == 0 && n-elmWidth-(elements-bar-1) >= elements-bar-1 {
+				subVal -= combinations(n-elmWidth-(elements-bar), elements-bar-2)
+			}
+
+			// Less those with an element wider than maxWidth
+			if elements-bar-1 > 1 {
+				lessVal := 0
+				for widest := n - elmWidth - (elements - bar - 2); widest > maxWidth; widest-- {
+					lessVal += combinations(n-elmWidth-widest-1, elements-bar-3)
+				}
+				subVal -= lessVal * (elements - 1 - bar)
+			} else if n-elmWidth > maxWidth {
+				subVal--
+			}
+
+			value -= subVal
+			if value < 0 {
+				break
+			}
+			elmWidth++
+			narrowMask &^= 1 << uint(bar)
+		}
+		value += subVal
+		n -= elmWidth
+		widths[bar] = elmWidth
+	}
+	widths[elements-1] = n
+	return widths
+}
+
+// combinations returns n choose r
+func combinations(n, r int) int {
+	if r < 0 || r > n {
+		return 0
+	}
+	if r > n-r {
+		r = n - r
+	}
+	result := 1
+	for i := 1; i <= r; i++ {
+		result = result * (n - r + i) / i
+	}
+	return result
+}
+
+// stackDataBarExpanded splits a single-row Expanded symbol into rows of
+// blocksPerRow finder blocks, with separator rows between them. Returns the
+// module rows and their heights in modules.
+func stackDataBarExpanded(elements []int, blocksPerRow int) ([][]bool, []int) {
+	// A block is a finder with the characters either side: 8 + 5 + 8 elements
+	const blockElements = 21
+	data := elements[2 : len(elements)-2]
+	blocks := (len(data) + blockElements - 1) / blockElements
+	stackRows := (blocks + blocksPerRow - 1) / blocksPerRow
+
+	var rows [][]bool
+	var rowHeights []int
+	var previous []bool
+	var previousFinders []int
+	var previousSpecial int
+	block := 0
+
+	for row := 1; row <= stackRows; row++ {
+		inRow := blocksPerRow
+		if remaining := blocks - block; remaining < inRow {
+			inRow = remaining
+		}
+		partialLastRow := row == stackRows && blocks != row*blocksPerRow
+
+		// Rows alternate direction so finders keep their parity, except where
+		// an odd number of blocks per row or a short last row keeps them aligned
+		leftToRight := blocksPerRow%2 == 1 || row%2 == 1 ||
+			(partialLastRow && (row*blocksPerRow-blocks)%2 == 1)
+
+		// Slots are padded to whole blocks; zero widths leave the color unchanged
+		slots := make([]int, 2+inRow*blockElements+2)
+		slots[0], slots[1] = 1, 1
+		for reader := 0; reader < inRow; reader++ {
+			source := block + reader
+			if !leftToRight {
+				source = block + inRow - reader - 1
+			}
+			for j := 0; j < blockElements; j++ {
+				index := source*blockElements + j
+				if index >= len(data) {
+					break
+				}
+				if leftToRight {
+					slots[2+reader*blockElements+j] = data[index]
+				} else {
+					slots[2+reader*blockElements+blockElements-1-j] = data[index]
+				}
+			}
+		}
+		slots[len(slots)-2], slots[len(slots)-1] = 1, 1
+		block += inRow
+
+		dark := row%2 == 0
+		special := 0
+		if partialLastRow && row%2 == 0 && blocksPerRow%2 == 0 {
+			// Bottom row needs an extra module to keep the guard light-first
+			slots[0] = 2
+			dark = false
+			special = 1
+		}
+
+		// Finders always occupy slots 8-12 of each block; record where their modules start
+		var modules []bool
+		var finderStarts []int
+		for i, width := range slots {
+			if i >= 2 && (i-2)%blockElements == 8 && i < len(slots)-2 {
+				finderStarts = append(finderStarts, len(modules))
+			}
+			for k := 0; k < width; k++ {
+				modules = append(modules, dark)
+			}
+			dark = !dark
+		}
+
+		if row > 1 {
+			rows = append(rows, dataBarSeparator(previous, previousFinders, previousSpecial))
+			middle := make([]bool, len(previous))
+			for j := 5; j < len(middle)-4; j += 2 {
+				middle[j] = true
+			}
+			rows = append(rows, middle)
+			rows = append(rows, dataBarSeparator(modules, finderStarts, special))
+			rowHeights = append(rowHeights, 1, 1, 1)
+		}
+		rows = append(rows, modules)
+		rowHeights = append(rowHeights, dataBarExpandedRowHeight)
+		previous, previousFinders, previousSpecial = modules, finderStarts, special
+	}
+
+	// Pad shorter rows so the matrix is rectangular
+	width := len(rows[0])
+	for i, row := range rows {
+		if len(row) < width {
+			rows[i] = append(row, make([]bool, width-len(row))...)
+		}
+	}
+	return rows, rowHeights
+}
+
+// dataBarSeparator builds the separator adjacent to a stacked row: the
+// complement of the row, light for four modules at each end, with alternating
+// modules where the finder patterns are light.
+func dataBarSeparator(row []bool, finderStarts []int, special int) []bool {
+	separator := make([]bool, len(row))
+	for j := 4 + special; j < len(row)-4; j++ {
+		separator[j] = !row[j]
+	}
+
+	for _, start := range finderStarts {
+		for j := start + 1; j < start+14 && j < len(row)-4; j++ {
+			if row[j] {
+				separator[j] = false
+			} else {
+				separator[j] = !separator[j-1]
+			}
+		}
+	}
+	return separator
+}
+
+// elementsToBitList converts element widths, starting with a space, to modules
+func elementsToBitList(elements []int) *utils.BitList {
+	bits := utils.NewBitList(sumInts(elements))
+	module, dark := 0, false
+	for _, width := range elements {
+		for i := 0; i < width; i++ {
+			bits.SetBit(module, dark)
+			module++
+		}
+		dark = !dark
+	}
+	return bits
+}
+
+// interleaveWidths merges odd and even element widths into character order
+func interleaveWidths(odd, even []int) [8]int {
+	var widths [8]int
+	for i := 0; i < 4; i++ {
+		widths[2*i] = odd[i]
+		widths[2*i+1] = even[i]
+	}
+	return widths
+}
+
+// reversedWidths returns a reversed copy of the element widths
+func reversedWidths(widths []int) []int {
+	reversed := make([]int, len(widths))
+	for i, w := range widths {
+		reversed[len(widths)-1-i] = w
+	}
+	return reversed
+}
+
+// writeBits appends value as a fixed-width binary string
+func writeBits(bits *strings.Builder, value, count int) {
+	for i := count - 1; i >= 0; i-- {
+		bits.WriteByte(byte('0' + value>>uint(i)&1))
+	}
+}
+
+func sumInts(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
@@ -0,0 +1,163 @@
+package barcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRSSWidths_Groups verifies every value in each Omnidirectional group maps
+// to a distinct, valid set of widths. Group totals count the even elements of
+// outside characters and the odd elements of inside characters.
+func TestRSSWidths_Groups(t *testing.T) {
+	for group := range dataBarOmniGroupTotal {
+		modules, widest := dataBarOmniModulesEven[group], dataBarOmniWidestEven[group]
+		if group >= 5 {
+			modules, widest = dataBarOmniModulesOdd[group], dataBarOmniWidestOdd[group]
+		}
+
+		seen := make(map[[4]int]bool)
+		for value := 0; value < dataBarOmniGroupTotal[group]; value++ {
+			widths := rssWidths(value, modules, widest, true)
+			require.Len(t, widths, 4)
+			assert.Equal(t, modules, sumInts(widths))
+
+			var key [4]int
+			copy(key[:], widths)
+			assert.False(t, seen[key], "group %d value %d repeats widths %v", group, value, widths)
+			seen[key] = true
+
+			narrow := false
+			for _, w := range widths {
+				assert.LessOrEqual(t, w, widest)
+				assert.GreaterOrEqual(t, w, 1)
+				narrow = narrow || w == 1
+			}
+			assert.True(t, narrow, "group %d value %d has no narrow element", group, value)
+		}
+	}
+}
+
+// TestEncodeDataBarOmni verifies symbol width and check digit handling
+func TestEncodeDataBarOmni(t *testing.T) {
+	bc, err := encodeDataBarOmni("0950110153001")
+	require.NoError(t, err)
+	assert.Equal(t, 96, bc.Bounds().Dx(), "Omnidirectional symbols are 96 modules wide")
+	assert.Equal(t, "(01)09501101530010", bc.Content(), "Check digit should be appended")
+
+	_, err = encodeDataBarOmni("(01)09501101530010")
+	assert.NoError(t, err)
+
+	_, err = encodeDataBarOmni("09501101530011")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid GTIN check digit")
+}
+
+// TestDataBarExpandedBinary verifies the header and padding of a GTIN-only symbol
+func TestDataBarExpandedBinary(t *testing.T) {
+	binary, err := dataBarExpandedBinary("(01)09501101530010", 0)
+	require.NoError(t, err)
+
+	// Method 1 header, 4-bit indicator and four 10-bit groups fill 48 bits: 4 data characters
+	assert.Len(t, binary, 48)
+	assert.Equal(t, "0110", binary[:4], "Linkage flag, method 1 and variable length bits")
+	assert.Equal(t, "0000", binary[4:8], "Indicator digit 0")
+}
+
+// TestDataBarExpandedElements verifies characters and finders have their fixed widths
+func TestDataBarExpandedElements(t *testing.T) {
+	elements, err := dataBarExpandedElements("(01)09501101530010(3103)000123(10)ABC123", 0)
+	require.NoError(t, err)
+
+	data := elements[2 : len(elements)-2]
+	for block := 0; block*21 < len(data); block++ {
+		start := block * 21
+		assert.Equal(t, 17, sumInts(data[start:start+8]), "Left character of block %d", block)
+		assert.Equal(t, 15, sumInts(data[start+8:start+13]), "Finder of block %d", block)
+		if start+21 <= len(data) {
+			assert.Equal(t, 17, sumInts(data[start+13:start+21]), "Right character of block %d", block)
+		}
+	}
+}
+
+// TestEncodeDataBarExpandedStacked verifies rows are stacked with separators
+func TestEncodeDataBarExpandedStacked(t *testing.T) {
+	bc, err := encodeDataBarExpandedStacked("(01)09501101530010(3103)000123(10)ABC123", DataBarOptions{SegmentsPerRow: 4})
+	require.NoError(t, err)
+
+	stacked := bc.(*multiRowBarcode)
+	assert.Greater(t, len(stacked.rowHeights), 1, "Data should span several rows")
+	for _, row := range stacked.rows {
+		assert.Len(t, row, len(stacked.rows[0]), "Rows should have equal width")
+	}
+
+	scaled, err := scaleBarcodeToFit(bc, scaleSizeToDPI(bc.Bounds().Size(), 1, 3))
+	require.NoError(t, err)
+	assert.Equal(t, bc.Bounds().Dx()*3, scaled.Bounds().Dx())
+}
+
+// TestValidateDataBarOptions ensures segments per row must be even and in range
+func TestValidateDataBarOptions(t *testing.T) {
+	assert.NoError(t, validateDataBarOptions(DataBarOptions{}))
+	assert.NoError(t, validateDataBarOptions(DataBarOptions{SegmentsPerRow: 6}))
+	assert.Error(t, validateDataBarOptions(DataBarOptions{SegmentsPerRow: 3}))
+	assert.Error(t, validateDataBarOptions(DataBarOptions{SegmentsPerRow: 24}))
+}
+
+// TestParseGS1ElementString_Invalid ensures malformed element strings are rejected
+func TestParseGS1ElementString_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		expectedErr string
+	}{
+		{"NoBracket", "0109501101530010", "must start with a bracketed AI"},
+		{"Unterminated", "(01", "Unterminated AI bracket"},
+		{"BadAI", "(1A)123", "AIs must be 2-4 digits"},
+		{"EmptyValue", "(10)(21)5", "has no value"},
+		{"WrongLength", "(01)123", "requires 14 data characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseGS1ElementString(tt.data)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+// TestGenerateBarcode_DataBar_Success verifies each DataBar type renders on a label
+func TestGenerateBarcode_DataBar_Success(t *testing.T) {
+	tests := []struct {
+		name        string
+		barcodeType BarcodeType
+		data        string
+	}{
+		{"Omnidirectional", BarcodeTypeDataBarOmni, "09501101530010"},
+		{"Expanded", BarcodeTypeDataBarExpanded, "(01)09501101530010(3103)000123"},
+		{"ExpandedStacked", BarcodeTypeDataBarExpandedStacked, "(01)09501101530010(3103)000123(10)ABC123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := BarcodeInput{
+				BarcodeData: tt.data,
+				BarcodeType: tt.barcodeType,
+				Width:       100.0,
+				Height:      50.0,
+				Dpi:         203,
+				TextLines: []TextLine{
+					{Text: tt.data, Position: TextPositionBelow, Size: TextSizeSmall},
+				},
+			}
+
+			output, err := GenerateBarcode(input)
+
+			require.NoError(t, err)
+			assert.NotEmpty(t, output.ImageBase64)
+			assert.Contains(t, output.ZPL, "^XA")
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package barcode
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// GS1 Digital Link constants
+const (
+	gs1DigitalLinkDomain    = "https://id.gs1.org" // GS1 global resolver
+	gs1DigitalLinkLabelMM   = 30.0
+	gs1DigitalLinkMaxLength = 20 // Maximum length of AI 10 and AI 21 values
+)
+
+// gs1CharacterSet82 is GS1 AI encodable character set 82, allowed in batch
+// and serial numbers
+const gs1CharacterSet82 = "!\"%&'()*+,-./0123456789:;<=>?ABCDEFGHIJKLMNOPQRSTUVWXYZ_abcdefghijklmnopqrstuvwxyz"
+
+// GS1DigitalLink holds the structured fields of a GS1 Digital Link URI
+type GS1DigitalLink struct {
+	Domain     string     // Optional resolver URL, e.g. https://id.example.com (defaults to https://id.gs1.org)
+	GTIN       string     // GTIN-8, 12, 13 or 14 (AI 01)
+	BatchLot   string     // Optional batch or lot number (AI 10)
+	Serial     string     // Optional serial number (AI 21)
+	ExpiryDate string     // Optional expiry date as YYMMDD (AI 17)
+	BestBefore string     // Optional best before date as YYMMDD (AI 15)
+	QR         QROptions  // Optional QR settings
+	Width      float64    // Label width in millimeters (defaults to 30)
+	Height     float64    // Label height in millimeters (defaults to 30)
+	Dpi        int        // Printer DPI (203, 300, or 600)
+	PreviewDpi int        // Optional DPI for the PNG image (defaults to Dpi)
+	TextLines  []TextLine // Optional text lines to render
+}
+
+// GenerateGS1DigitalLink builds the Digital Link URI and renders it as a QR code
+func GenerateGS1DigitalLink(link GS1DigitalLink) (*BarcodeOutput, error) {
+	uri, err := BuildGS1DigitalLinkURI(link)
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := link.Width, link.Height
+	if width == 0 && height == 0 {
+		width, height = gs1DigitalLinkLabelMM, gs1DigitalLinkLabelMM
+	}
+
+	return GenerateBarcode(BarcodeInput{
+		BarcodeData: uri,
+		BarcodeType: BarcodeTypeQR,
+		Width:       width,
+		Height:      height,
+		Dpi:         link.Dpi,
+		PreviewDpi:  link.PreviewDpi,
+		TextLines:   link.TextLines,
+		QR:          link.QR,
+	})
+}
+
+// BuildGS1DigitalLinkURI validates the fields and returns the URI, with the
+// GTIN and its key qualifiers in the path and dates in the query, e.g.
+// https://id.gs1.org/01/09506000134352/10/ABC123/21/12345?17=261231
+func BuildGS1DigitalLinkURI(link GS1DigitalLink) (string, error) {
+	domain, err := normalizeDigitalLinkDomain(link.Domain)
+	if err != nil {
+		return "", err
+	}
+
+	gtin, err := normalizeDigitalLinkGTIN(link.GTIN)
+	if err != nil {
+		return "", err
+	}
+	if err := validateGS1CharacterSet82("batch/lot", link.BatchLot); err != nil {
+		return "", err
+	}
+	if err := validateGS1CharacterSet82("serial", link.Serial); err != nil {
+		return "", err
+	}
+	if err := validateGS1Date("expiry", link.ExpiryDate); err != nil {
+		return "", err
+	}
+	if err := validateGS1Date("best before", link.BestBefore); err != nil {
+		return "", err
+	}
+
+	var uri strings.Builder
+	uri.WriteString(domain + "/01/" + gtin)
+	if link.BatchLot != "" {
+		uri.WriteString("/10/" + escapeDigitalLinkValue(link.BatchLot))
+	}
+	if link.Serial != "" {
+		uri.WriteString("/21/" + escapeDigitalLinkValue(link.Serial))
+	}
+
+	var query []string
+	if link.ExpiryDate != "" {
+		query = append(query, "17="+link.ExpiryDate)
+	}
+	if link.BestBefore != "" {
+		query = append(query, "15="+link.BestBefore)
+	}
+	if len(query) > 0 {
+		uri.WriteString("?" + strings.Join(query, "&"))
+	}
+	return uri.String(), nil
+}
+
+// normalizeDigitalLinkDomain validates the resolver URL and removes any trailing slash
+func normalizeDigitalLinkDomain(domain string) (string, error) {
+	if domain == "" {
+		return gs1DigitalLinkDomain, nil
+	}
+
+	u, err := url.Parse(domain)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("invalid Digital Link domain: %q. Domain must be an http(s) URL without query or fragment", domain)
+	}
+	return strings.TrimRight(domain, "/"), nil
+}
+
+// normalizeDigitalLinkGTIN pads a GTIN-8, 12 or 13 to the 14 digits used in
+// Digital Link URIs and validates its check digit
+func normalizeDigitalLinkGTIN(gtin string) (string, error) {
+	switch len(gtin) {
+	case 8, 12, 13, 14:
+		gtin = strings.Repeat("0", 14-len(gtin)) + gtin
+	default:
+		return "", fmt.Errorf("invalid GTIN: %q. GTIN must be 8, 12, 13 or 14 digits", gtin)
+	}
+	if err := validateGTIN(gtin); err != nil {
+		return "", err
+	}
+	return gtin, nil
+}
+
+// validateGS1CharacterSet82 ensures an optional batch or serial value is up
+// to 20 characters from GS1 character set 82
+func validateGS1CharacterSet82(field, value string) error {
+	if len(value) > gs1DigitalLinkMaxLength {
+		return fmt.Errorf("invalid %s: %q. Maximum length is %d characters", field, value, gs1DigitalLinkMaxLength)
+	}
+	for _, r := range value {
+		if !strings.ContainsRune(gs1CharacterSet82, r) {
+			return fmt.Errorf("invalid %s: %q. Character %q is not in the GS1 character set", field, value, r)
+		}
+	}
+	return nil
+}
+
+// validateGS1Date ensures an optional date is YYMMDD. A day of 00 means the
+// last day of the month.
+func validateGS1Date(field, date string) error {
+	if date == "" {
+		return nil
+	}
+	if len(date) != 6 || !isNumeric(date) {
+		return fmt.Errorf("invalid %s date: %q. Date must be YYMMDD", field, date)
+	}
+	month := int(date[2]-'0')*10 + int(date[3]-'0')
+	day := int(date[4]-'0')*10 + int(date[5]-'0')
+	if month < 1 || month > 12 || day > 31 {
+		return fmt.Errorf("invalid %s date: %q. Month or day is out of range", field, date)
+	}
+	return nil
+}
+
+// escapeDigitalLinkValue percent-encodes every character other than the URI
+// unreserved characters, as the Digital Link standard requires
+func escapeDigitalLinkValue(value string) string {
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~' {
+			sb.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&sb, "%%%02X", c)
+	}
+	return sb.String()
+}
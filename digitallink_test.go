@@ -0,0 +1,84 @@
+package barcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuildGS1DigitalLinkURI verifies path order, query attributes and escaping
+func TestBuildGS1DigitalLinkURI(t *testing.T) {
+	tests := []struct {
+		name     string
+		link     GS1DigitalLink
+		expected string
+	}{
+		{
+			"GTINOnly",
+			GS1DigitalLink{GTIN: "09506000134352"},
+			"https://id.gs1.org/01/09506000134352",
+		},
+		{
+			"GTIN13Padded",
+			GS1DigitalLink{GTIN: "9506000134352", Domain: "https://id.example.com/"},
+			"https://id.example.com/01/09506000134352",
+		},
+		{
+			"LotSerialAndDates",
+			GS1DigitalLink{GTIN: "09506000134352", BatchLot: "ABC123", Serial: "12345", ExpiryDate: "261231", BestBefore: "261130", Domain: "https://id.example.com"},
+			"https://id.example.com/01/09506000134352/10/ABC123/21/12345?17=261231&15=261130",
+		},
+		{
+			"EscapedSerial",
+			GS1DigitalLink{GTIN: "09506000134352", Serial: "A/B%1"},
+			"https://id.gs1.org/01/09506000134352/21/A%2FB%251",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uri, err := BuildGS1DigitalLinkURI(tt.link)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, uri)
+		})
+	}
+}
+
+// TestBuildGS1DigitalLinkURI_Invalid ensures AI values are validated
+func TestBuildGS1DigitalLinkURI_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		link        GS1DigitalLink
+		expectedErr string
+	}{
+		{"BadGTINLength", GS1DigitalLink{GTIN: "12345"}, "must be 8, 12, 13 or 14 digits"},
+		{"BadGTINCheck", GS1DigitalLink{GTIN: "09506000134353"}, "invalid GTIN check digit"},
+		{"LongLot", GS1DigitalLink{GTIN: "09506000134352", BatchLot: "ABCDEFGHIJKLMNOPQRSTU"}, "Maximum length is 20"},
+		{"BadSerialCharacter", GS1DigitalLink{GTIN: "09506000134352", Serial: "AB#1"}, "not in the GS1 character set"},
+		{"BadExpiry", GS1DigitalLink{GTIN: "09506000134352", ExpiryDate: "261331"}, "out of range"},
+		{"BadDomain", GS1DigitalLink{GTIN: "09506000134352", Domain: "id.example.com"}, "invalid Digital Link domain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BuildGS1DigitalLinkURI(tt.link)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+// TestGenerateGS1DigitalLink_Success verifies the URI renders as a QR code
+func TestGenerateGS1DigitalLink_Success(t *testing.T) {
+	output, err := GenerateGS1DigitalLink(GS1DigitalLink{
+		GTIN:     "09506000134352",
+		BatchLot: "ABC123",
+		Dpi:      300,
+		QR:       QROptions{ErrorCorrection: QRErrorCorrectionQ},
+	})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, output.ImageBase64)
+	assert.Contains(t, output.ZPL, "^XA")
+}
@@ -1,6 +1,7 @@
 package barcode
 
 import (
+	"fmt"
 	"image"
 	"math"
 
@@ -8,32 +9,159 @@ import (
 )
 
 // Constants for label layout
-const labelMarginPixels = 10
+const labelMarginPixels = 10 // Default margin on each side, in printer dots
+
+// Margins sets the clear space around the label contents
+type Margins struct {
+	MM        float64 // Optional margin on each side of the label in millimeters (defaults to 10 printer dots)
+	QuietZone bool    // Optional: size the symbol in whole modules that leave its minimum quiet zone clear
+}
+
+// quietZoneModules is the minimum clear space on each side of a symbol in
+// modules, from the symbology specifications. Other types have no quiet zone
+// requirement or, like IMb and Swiss QR, are printed at a fixed size.
+var quietZoneModules = map[BarcodeType]int{
+	BarcodeTypeCode128:        10,
+	BarcodeTypeGS1128:         10,
+	BarcodeTypeITF:            10,
+	BarcodeTypeISBN:           11,
+	BarcodeTypeISSN:           11,
+	BarcodeTypeTelepen:        10,
+	BarcodeTypeTelepenNumeric: 10,
+	BarcodeTypeQR:             4,
+}
+
+// labelMargin returns the margin on each side of the label in dots at the
+// printer DPI
+func labelMargin(input BarcodeInput) int {
+	if input.Margins.MM == 0 {
+		return labelMarginPixels
+	}
+	return mmToPixels(input.Margins.MM, input.Dpi)
+}
+
+// validateMargins ensures the margin is not negative
+func validateMargins(margins Margins) error {
+	if margins.MM < 0 {
+		return fmt.Errorf("invalid label margin: %.1fmm. Margin must not be negative", margins.MM)
+	}
+	return nil
+}
+
+// IMb physical dimensions from USPS-B-3200: nominal symbol length and full bar height
+const (
+	imbLengthMM    = 74.0
+	imbBarHeightMM = 3.7
+)
 
 // mmToPixels converts millimeters to pixels based on the printer DPI.
-// Formula: pixels = mm * dpi / 25.4 (25.4 mm per inch)
+// Formula: pixels = mm * dpi / 25.4 (25.4 mm per inch). Partial pixels are
+// dropped, allowing for rounding error so sizes converted from inches and
+// dots land on their whole dot.
 func mmToPixels(mm float64, dpi int) int {
-	return int(mm * float64(dpi) / 25.4)
+	return int(mm*float64(dpi)/25.4 + pixelTolerance)
+}
+
+// pixelTolerance absorbs floating-point error when truncating to pixels
+const pixelTolerance = 1e-6
+
+// selfScalingBarcode is implemented by barcodes that barcode.Scale cannot
+// resize, such as height-modulated and stacked symbols.
+type selfScalingBarcode interface {
+	barcode.Barcode
+	scale(width, height int) (barcode.Barcode, error)
 }
 
 // calculateBarcodeSize determines the appropriate barcode dimensions based on type.
-// Code128: Uses full width, constrained height
+// Code128, GS1-128, ISBN/ISSN, ITF, Telepen and linear DataBar: Uses full width, constrained height
 // QR: Must be square, sized to fit with text
+// IMb: Fixed physical size defined by the USPS specification
+// Swiss QR: Square, capped at the 46mm size defined for the QR-bill
+// Stacked DataBar and imported images: Use full width, with the height left over by text
 func calculateBarcodeSize(input BarcodeInput, labelWidth, labelHeight int) image.Point {
-	if input.BarcodeType == BarcodeTypeCode128 {
-		return calculateCode128Size(labelWidth, labelHeight)
+	if input.AutoHeight {
+		return autoHeightBarcodeSize(input, labelWidth)
 	}
-	return calculateQRSize(input, labelWidth, labelHeight)
+	switch input.BarcodeType {
+	case BarcodeTypeCode128, BarcodeTypeGS1128, BarcodeTypeISBN, BarcodeTypeISSN, BarcodeTypeITF,
+		BarcodeTypeTelepen, BarcodeTypeTelepenNumeric, BarcodeTypeDataBarOmni, BarcodeTypeDataBarExpanded:
+		return calculateCode128Size(labelWidth, labelHeight, labelMargin(input))
+	case BarcodeTypeIMb:
+		return calculateIMbSize(input.Dpi, labelWidth, labelHeight, labelMargin(input))
+	case BarcodeTypeDataBarExpandedStacked, BarcodeTypeImage:
+		return calculateStackedSize(input, labelWidth, labelHeight)
+	case BarcodeTypeSwissQR:
+		return calculateSwissQRSize(input, labelWidth, labelHeight)
+	default:
+		return calculateQRSize(input, labelWidth, labelHeight)
+	}
+}
+
+// calculateRotatedBarcodeSize determines the barcode dimensions before the
+// barcode is rotated. Quarter-turned barcodes are laid out along the label
+// height, less the height of the text that stays horizontal.
+func calculateRotatedBarcodeSize(input BarcodeInput, labelWidth, labelHeight int) image.Point {
+	if input.BarcodeRotation%180 == 0 {
+		return calculateBarcodeSize(input, labelWidth, labelHeight)
+	}
+
+	textHeight := int(calculateTextHeight(input))
+	sideways := input
+	sideways.TextLines, sideways.TextBlocks, sideways.Paragraphs = nil, nil, nil
+	return calculateBarcodeSize(sideways, labelHeight-textHeight, labelWidth)
+}
+
+// fitQuietZone shrinks the barcode size to a whole number of modules that
+// leaves the symbology's quiet zone clear on each side within the size, when
+// Margins.QuietZone is set. The size is before BarcodeRotation, so the quiet
+// zone runs along the length of the symbol; QR codes keep it on all sides.
+func fitQuietZone(input BarcodeInput, bc barcode.Barcode, size image.Point) image.Point {
+	quietZone, ok := quietZoneModules[input.BarcodeType]
+	if !input.Margins.QuietZone || !ok {
+		return size
+	}
+
+	modules := bc.Bounds().Dx()
+	moduleWidth := size.X / (modules + quietZone*2)
+	if input.BarcodeType == BarcodeTypeQR {
+		return image.Pt(modules*moduleWidth, modules*moduleWidth)
+	}
+	return image.Pt(modules*moduleWidth, size.Y)
 }
 
 // calculateCode128Size determines dimensions for Code128 barcodes.
 // Code128 can be rectangular, so we use full label width and constrain height.
-func calculateCode128Size(labelWidth, labelHeight int) image.Point {
-	barcodeWidth := labelWidth - (labelMarginPixels * 2)
+func calculateCode128Size(labelWidth, labelHeight, margin int) image.Point {
+	barcodeWidth := labelWidth - (margin * 2)
 	barcodeHeight := int(math.Min(float64(labelHeight/2), 200))
 	return image.Pt(barcodeWidth, barcodeHeight)
 }
 
+// calculateIMbSize determines dimensions for Intelligent Mail barcodes.
+// IMb has a fixed physical size, so it is only shrunk when the label is too small.
+func calculateIMbSize(dpi, labelWidth, labelHeight, margin int) image.Point {
+	barcodeWidth := int(math.Min(float64(mmToPixels(imbLengthMM, dpi)), float64(labelWidth-(margin*2))))
+	barcodeHeight := int(math.Min(float64(mmToPixels(imbBarHeightMM, dpi)), float64(labelHeight/2)))
+	return image.Pt(barcodeWidth, barcodeHeight)
+}
+
+// calculateSwissQRSize determines dimensions for Swiss QR-bill codes.
+// The symbol is printed at 46mm, and only shrunk when the label is too small.
+func calculateSwissQRSize(input BarcodeInput, labelWidth, labelHeight int) image.Point {
+	size := int(math.Min(float64(mmToPixels(swissQRSizeMM, input.Dpi)), float64(calculateQRSize(input, labelWidth, labelHeight).X)))
+	return image.Pt(size, size)
+}
+
+// calculateStackedSize determines the area available to stacked barcodes.
+// The barcode picks the largest module width that fits and shortens its rows
+// if the height is constrained, so the full area below the text is offered.
+func calculateStackedSize(input BarcodeInput, labelWidth, labelHeight int) image.Point {
+	margin := labelMargin(input)
+	barcodeWidth := labelWidth - (margin * 2)
+	barcodeHeight := labelHeight - int(calculateTextHeight(input)) - (margin * 2)
+	return image.Pt(barcodeWidth, barcodeHeight)
+}
+
 // calculateQRSize determines dimensions for QR codes.
 // QR codes must be square, so we calculate the largest square that fits.
 func calculateQRSize(input BarcodeInput, labelWidth, labelHeight int) image.Point {
@@ -50,29 +178,123 @@ func calculateQRSize(input BarcodeInput, labelWidth, labelHeight int) image.Poin
 	return image.Pt(finalSize, finalSize)
 }
 
-// calculateTextHeight returns the total pixel height needed for all text lines.
+// calculateTextHeight returns the total pixel height needed for the text rows
+// above and below the barcode. Side columns take width instead.
 func calculateTextHeight(input BarcodeInput) float64 {
 	totalHeight := 0.0
-	for _, textLine := range input.TextLines {
-		_, height := getFontSize(textLine.Size, input.Dpi, 200)
-		totalHeight += height * 2
+	for _, row := range layoutTextRows(labelTextLines(input), textLineHeight(input.Dpi, input.FontScaling.withDefaults().Min)) {
+		if !isSideTextPosition(row.position) {
+			totalHeight += row.height * 2
+		}
 	}
 	return totalHeight
 }
 
+// validateRenderSize ensures the label and the space left for the barcode are
+// non-empty before any image is allocated, since the scaling and font
+// libraries do not handle zero or negative sizes gracefully.
+func validateRenderSize(labelWidth, labelHeight int, barcodeSize image.Point) error {
+	if labelWidth <= 0 || labelHeight <= 0 {
+		return errorf(ErrLabelTooSmall, "label too small: %dx%d pixels", labelWidth, labelHeight)
+	}
+	if barcodeSize.X <= 0 || barcodeSize.Y <= 0 {
+		return errorf(ErrLabelTooSmall, "label too small: no room left for the barcode (%dx%d pixels available)", barcodeSize.X, barcodeSize.Y)
+	}
+	return nil
+}
+
+// scaleSizeToDPI converts a pixel size laid out at one DPI to the same physical size at another DPI.
+func scaleSizeToDPI(size image.Point, fromDpi, toDpi int) image.Point {
+	if fromDpi == toDpi {
+		return size
+	}
+	return image.Pt(size.X*toDpi/fromDpi, size.Y*toDpi/fromDpi)
+}
+
 // scaleBarcodeToFit resizes a barcode to the specified dimensions.
 func scaleBarcodeToFit(bc barcode.Barcode, size image.Point) (barcode.Barcode, error) {
+	if selfScaling, ok := bc.(selfScalingBarcode); ok {
+		scaled, err := selfScaling.scale(size.X, size.Y)
+		return scaled, withKind(ErrLabelTooSmall, err)
+	}
+
 	scaled, err := barcode.Scale(bc, size.X, size.Y)
 	if err != nil {
-		return nil, err
+		return nil, withKind(ErrLabelTooSmall, err)
 	}
 	return scaled, nil
 }
 
+// calculateTextLineOffsets returns the vertical offset of each text line from
+// its position's base Y. Rows above the barcode stack upwards so the last one
+// is nearest the barcode; rows below and beside it stack downwards in order,
+// with side columns centered on the barcode. Lines on the same row share its
+// offset.
+func calculateTextLineOffsets(textLines []TextLine, dpi int, fontScale float64) []int {
+	offsets := make([]int, len(textLines))
+	rows := layoutTextRows(textLines, textLineHeight(dpi, fontScale))
+
+	above := 0
+	for r := len(rows) - 1; r >= 0; r-- {
+		if rows[r].position != TextPositionAbove {
+			continue
+		}
+		for _, i := range rows[r].lines {
+			offsets[i] = -above
+		}
+		above += int(rows[r].height)
+	}
+
+	stacked := map[TextPosition]int{}
+	for _, row := range rows {
+		if row.position == TextPositionAbove {
+			continue
+		}
+		for _, i := range row.lines {
+			offsets[i] = stacked[row.position]
+		}
+		stacked[row.position] += int(row.height)
+	}
+
+	for i, textLine := range textLines {
+		if isSideTextPosition(textLine.Position) {
+			offsets[i] -= stacked[textLine.Position] / 2
+		}
+	}
+	return offsets
+}
+
+// calculateTextBlockShift returns how far to move the barcode down so the
+// barcode and its stacked text are centered together rather than the barcode
+// alone. Side columns do not move the barcode.
+func calculateTextBlockShift(textLines []TextLine, dpi int, fontScale float64) int {
+	above, below := 0, 0
+	for _, row := range layoutTextRows(textLines, textLineHeight(dpi, fontScale)) {
+		switch row.position {
+		case TextPositionAbove:
+			above += int(row.height)
+		case TextPositionBelow:
+			below += int(row.height)
+		}
+	}
+	return (above - below) / 2
+}
+
+// textLineHeight returns a function giving the pixel height of a text line,
+// which for rotated lines is their length
+func textLineHeight(dpi int, fontScale float64) func(TextLine) float64 {
+	return func(textLine TextLine) float64 {
+		if isRotatedText(textLine) {
+			return rotatedTextLength(textLine, dpi, fontScale)
+		}
+		_, height := getTextLineFontSize(textLine, dpi, fontScale)
+		return height
+	}
+}
+
 // centerBarcodeOnLabel calculates the position to center a barcode on the label.
 // Returns the bounding rectangle where the barcode should be drawn.
-func centerBarcodeOnLabel(img *image.RGBA, bc barcode.Barcode) image.Rectangle {
-	imgBounds := img.Bounds()
+func centerBarcodeOnLabel(imgBounds image.Rectangle, bc barcode.Barcode) image.Rectangle {
 	bcBounds := bc.Bounds()
 
 	offsetX := (imgBounds.Dx() - bcBounds.Dx()) / 2
@@ -82,9 +304,13 @@ func centerBarcodeOnLabel(img *image.RGBA, bc barcode.Barcode) image.Rectangle {
 }
 
 // calculateTextYPosition determines the Y coordinate for text based on position relative to barcode.
+// Side columns are centered on the middle of the barcode.
 func calculateTextYPosition(barcodeRect image.Rectangle, position TextPosition) int {
 	if position == TextPositionAbove {
 		return barcodeRect.Min.Y
 	}
+	if isSideTextPosition(position) {
+		return (barcodeRect.Min.Y + barcodeRect.Max.Y) / 2
+	}
 	return barcodeRect.Max.Y
 }
@@ -0,0 +1,178 @@
+package barcode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"math"
+	"strings"
+
+	"github.com/boombuler/barcode"
+)
+
+// DPL control codes and limits
+const (
+	dplSTX            = "\x02"
+	dplMaxMultiplier  = 9   // Largest font multiplier accepted in both directions
+	dplMaxModuleWidth = 9   // Largest wide or narrow bar width in dots
+	dplMaxHeight      = 999 // Largest barcode height in 0.1mm
+)
+
+// dplFonts lists resident bitmap fonts 0-6 with their cells in dots. Their
+// dot size is the same at every head density.
+var dplFonts = []residentFont{{5, 7}, {7, 13}, {10, 18}, {14, 27}, {18, 36}, {18, 52}, {34, 64}}
+
+// dplLabel collects the image downloads and records of a DPL label. DPL
+// measures positions in 0.1mm from the bottom left of the label, and images
+// must be stored in printer memory before the label format references them.
+type dplLabel struct {
+	downloads strings.Builder
+	records   strings.Builder
+	dpi       int
+	height    int
+	images    int
+}
+
+// generateDPL converts the label to DPL for Datamax-O'Neil printers. Text and
+// the linear barcodes DPL supports are sent as native records laid out like
+// the print image. Other symbols, and text no resident font fits, are
+// downloaded as images cropped from the print image. When native is false,
+// the whole print image is sent as one image.
+func generateDPL(input BarcodeInput, bc barcode.Barcode, printImg *image.RGBA, native bool) (string, error) {
+	label := &dplLabel{dpi: input.Dpi, height: printImg.Bounds().Dy()}
+
+	if !native {
+		label.addGraphic(printImg, printImg.Bounds())
+		return label.String(), nil
+	}
+
+	layout, err := layoutLabel(input, bc, input.Dpi)
+	if err != nil {
+		return "", err
+	}
+	if !label.addBarcode(input, bc, layout) {
+		label.addGraphic(printImg, layout.barcodeRect)
+	}
+
+	for _, line := range layoutNativeTextLines(input, printImg, layout) {
+		font, multiplier, ok := selectResidentFont(dplFonts, dplMaxMultiplier, line, input.Dpi)
+		if !ok {
+			label.addGraphic(printImg, textBand(line, input.Dpi, layout.width))
+			continue
+		}
+
+		pos := residentTextPosition(line, dplFonts[font], multiplier)
+		bottom := pos.Y + dplFonts[font].height*multiplier
+		label.addRecord(fmt.Sprintf("%d%d%d000", font, multiplier, multiplier), pos.X, bottom, line.Text)
+	}
+	return label.String(), nil
+}
+
+// addBarcode adds the native record for barcodes DPL can print identically:
+// unrotated Code128 without function characters, ITF, and ISBN or ISSN
+// without an add-on. Code128 uses the printer's automatic subset switching,
+// so it is only native in the automatic code set.
+func (l *dplLabel) addBarcode(input BarcodeInput, bc barcode.Barcode, layout labelLayout) bool {
+	height := l.toMetric(layout.barcodeRect.Dy())
+	if input.BarcodeRotation != 0 || height > dplMaxHeight {
+		return false
+	}
+	origin, moduleWidth, ok := nativeModuleLayout(bc, layout)
+	if !ok || moduleWidth > dplMaxModuleWidth {
+		return false
+	}
+
+	var id, data string
+	wideWidth := moduleWidth * 2
+	switch input.BarcodeType {
+	case BarcodeTypeCode128:
+		if input.Code128.CodeSet != Code128CodeSetAuto || !isPrintableASCII(input.BarcodeData) {
+			return false
+		}
+		id = "E"
+		data = input.BarcodeData
+	case BarcodeTypeITF:
+		id = "D"
+		data = bc.Content()
+		wideWidth = moduleWidth * 3
+	case BarcodeTypeISBN, BarcodeTypeISSN:
+		if input.ISBN.AddOn != "" {
+			return false
+		}
+		id = "F"
+		data = bc.Content()[:12] // The printer adds the check digit
+	default:
+		return false
+	}
+	if wideWidth > dplMaxModuleWidth {
+		return false
+	}
+
+	// Barcode records give the wide then narrow bar width in dots, then the height
+	l.addRecord(fmt.Sprintf("%s%d%d%03d", id, wideWidth, moduleWidth, height), origin.X, layout.barcodeRect.Max.Y, data)
+	return true
+}
+
+// addGraphic downloads the area of img as a monochrome BMP and adds a record
+// printing it
+func (l *dplLabel) addGraphic(img *image.RGBA, area image.Rectangle) {
+	area = area.Intersect(img.Bounds())
+	if area.Empty() {
+		return
+	}
+
+	name := fmt.Sprintf("LBL%d", l.images)
+	l.images++
+	fmt.Fprintf(&l.downloads, "%sIDB%s\r", dplSTX, name)
+	l.downloads.Write(monochromeBMP(img, area))
+	l.addRecord("Y11000", area.Min.X, area.Max.Y, name)
+}
+
+// addRecord adds a rotation 0 record for an object whose bottom left corner
+// is at x, bottom in print image dots
+func (l *dplLabel) addRecord(object string, x, bottom int, data string) {
+	fmt.Fprintf(&l.records, "1%s%04d%04d%s\r", object, l.toMetric(l.height-bottom), l.toMetric(x), data)
+}
+
+// toMetric converts dots to 0.1mm
+func (l *dplLabel) toMetric(dots int) int {
+	return int(math.Round(float64(dots) * 254 / float64(l.dpi)))
+}
+
+// String returns the downloads followed by the label format, printing one label
+func (l *dplLabel) String() string {
+	return l.downloads.String() + dplSTX + "m" + dplSTX + "L\rD11\r" + l.records.String() + "E\r"
+}
+
+// monochromeBMP encodes the area of img as a 1-bit BMP with a black and white
+// palette
+func monochromeBMP(img *image.RGBA, area image.Rectangle) []byte {
+	rowBytes, data := packMonochrome(img, area)
+	stride := (rowBytes + 3) &^ 3
+	const headerSize = 14 + 40 + 8
+	size := headerSize + stride*area.Dy()
+
+	bmp := make([]byte, headerSize, size)
+	copy(bmp, "BM")
+	binary.LittleEndian.PutUint32(bmp[2:], uint32(size))
+	binary.LittleEndian.PutUint32(bmp[10:], headerSize)
+	binary.LittleEndian.PutUint32(bmp[14:], 40)
+	binary.LittleEndian.PutUint32(bmp[18:], uint32(area.Dx()))
+	binary.LittleEndian.PutUint32(bmp[22:], uint32(area.Dy()))
+	binary.LittleEndian.PutUint16(bmp[26:], 1) // Planes
+	binary.LittleEndian.PutUint16(bmp[28:], 1) // Bits per pixel
+	binary.LittleEndian.PutUint32(bmp[34:], uint32(stride*area.Dy()))
+	binary.LittleEndian.PutUint32(bmp[46:], 2)     // Palette colors
+	copy(bmp[58:], []byte{0xFF, 0xFF, 0xFF, 0x00}) // Index 1 is white, index 0 stays black
+
+	// BMP rows are stored bottom-up and padded to 4 bytes
+	padding := make([]byte, stride-rowBytes)
+	for i := range padding {
+		padding[i] = 0xFF
+	}
+	for y := area.Dy() - 1; y >= 0; y-- {
+		bmp = append(bmp, data[y*rowBytes:(y+1)*rowBytes]...)
+		bmp = append(bmp, padding...)
+	}
+	return bmp
+}
@@ -0,0 +1,89 @@
+package barcode
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateBarcode_DPL verifies supported barcodes and text use native records
+func TestGenerateBarcode_DPL(t *testing.T) {
+	output, err := GenerateBarcode(BarcodeInput{
+		BarcodeData:   "LOC-A1-B2-C3",
+		BarcodeType:   BarcodeTypeCode128,
+		Width:         50,
+		Height:        25,
+		Dpi:           203,
+		OutputFormats: []OutputFormat{OutputFormatDPL},
+		TextLines:     []TextLine{{Text: "LOC-A1-B2-C3", Position: TextPositionBelow, Size: TextSizeMedium}},
+	})
+	require.NoError(t, err)
+
+	lines := strings.Split(output.DPL, "\r")
+	require.Len(t, lines, 6)
+	assert.Equal(t, []string{"\x02m\x02L", "D11"}, lines[:2])
+	assert.Regexp(t, `^1E42\d{3}\d{4}\d{4}LOC-A1-B2-C3$`, lines[2])
+	assert.Regexp(t, `^1[0-6]\d\d000\d{4}\d{4}LOC-A1-B2-C3$`, lines[3])
+	assert.Equal(t, []string{"E", ""}, lines[4:])
+}
+
+// TestGenerateBarcode_DPLBarcodes verifies the barcode selection for each native type
+func TestGenerateBarcode_DPLBarcodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    BarcodeInput
+		expected string
+	}{
+		{"ITF", BarcodeInput{BarcodeData: "12345678", BarcodeType: BarcodeTypeITF}, `^1D93\d{11}12345678$`},
+		{"ISBN", BarcodeInput{BarcodeData: "978-0-306-40615-7", BarcodeType: BarcodeTypeISBN}, `^1F\d{13}978030640615$`},
+		{"QR", BarcodeInput{BarcodeData: "https://example.com", BarcodeType: BarcodeTypeQR}, `^1Y11000\d{8}LBL0$`},
+		{"Code128SetC", BarcodeInput{BarcodeData: "123456", BarcodeType: BarcodeTypeCode128, Code128: Code128Options{CodeSet: Code128CodeSetC}}, `^1Y11000`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input.Width, tt.input.Height, tt.input.Dpi = 40, 30, 203
+			tt.input.OutputFormats = []OutputFormat{OutputFormatDPL}
+			output, err := GenerateBarcode(tt.input)
+			require.NoError(t, err)
+
+			format := output.DPL[strings.Index(output.DPL, "\x02m\x02L"):]
+			assert.Regexp(t, tt.expected, strings.Split(format, "\r")[2])
+		})
+	}
+}
+
+// TestGenerateBarcode_DPLMirror verifies mirrored labels are downloaded as a single image
+func TestGenerateBarcode_DPLMirror(t *testing.T) {
+	output, err := GenerateBarcode(BarcodeInput{
+		BarcodeData:   "LOC-A1-B2-C3",
+		BarcodeType:   BarcodeTypeCode128,
+		Width:         50,
+		Height:        25,
+		Dpi:           203,
+		OutputFormats: []OutputFormat{OutputFormatDPL},
+		Mirror:        true,
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(output.DPL, "\x02IDBLBL0\rBM"))
+	assert.True(t, strings.HasSuffix(output.DPL, "\x02m\x02L\rD11\r1Y1100000000000LBL0\rE\r"))
+}
+
+// TestMonochromeBMP verifies rows are stored bottom-up, padded white, with dark pixels as index 0
+func TestMonochromeBMP(t *testing.T) {
+	img := createBlankLabel(10, 2)
+	img.Set(0, 0, color.Black)
+	img.Set(9, 1, color.Black)
+
+	bmp := monochromeBMP(img, image.Rect(0, 0, 10, 2))
+	require.Len(t, bmp, 62+8)
+	assert.Equal(t, "BM", string(bmp[:2]))
+	assert.Equal(t, uint32(70), binary.LittleEndian.Uint32(bmp[2:]))
+	assert.Equal(t, uint32(10), binary.LittleEndian.Uint32(bmp[18:]))
+	assert.Equal(t, []byte{0xFF, 0xBF, 0xFF, 0xFF, 0x7F, 0xFF, 0xFF, 0xFF}, bmp[62:])
+}
@@ -0,0 +1,134 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/boombuler/barcode"
+)
+
+// EPL2 layout constants
+const (
+	eplLabelGapMM    = 3.0 // Gap between die-cut labels
+	eplMaxMultiplier = 6   // Largest font multiplier accepted in both directions
+)
+
+// eplFonts lists resident fonts 1-5 by printer DPI
+var eplFonts = map[int][]residentFont{
+	203: {{8, 12}, {10, 16}, {12, 20}, {14, 24}, {32, 48}},
+	300: {{12, 20}, {16, 28}, {20, 36}, {24, 44}, {48, 80}},
+}
+
+// generateEPL converts the label to EPL2 for older Zebra printers such as the
+// LP2844. Text and the linear barcodes EPL2 supports are sent as native
+// commands laid out like the print image. Other symbols, and text no resident
+// font fits, are sent as GW graphics cropped from the print image. When
+// native is false, e.g. for mirrored or post-processed labels, the whole
+// print image is sent as one graphic.
+func generateEPL(input BarcodeInput, bc barcode.Barcode, printImg *image.RGBA, native bool) (string, error) {
+	var epl strings.Builder
+	bounds := printImg.Bounds()
+	fmt.Fprintf(&epl, "\nN\nq%d\nQ%d,%d\n", bounds.Dx(), bounds.Dy(), mmToPixels(eplLabelGapMM, input.Dpi))
+
+	if !native {
+		writeEPLGraphic(&epl, printImg, bounds)
+		epl.WriteString("P1\n")
+		return epl.String(), nil
+	}
+
+	layout, err := layoutLabel(input, bc, input.Dpi)
+	if err != nil {
+		return "", err
+	}
+	if command, ok := eplBarcodeCommand(input, bc, layout); ok {
+		epl.WriteString(command)
+	} else {
+		writeEPLGraphic(&epl, printImg, layout.barcodeRect)
+	}
+
+	writeEPLTextLines(&epl, input, printImg, layout)
+	epl.WriteString("P1\n")
+	return epl.String(), nil
+}
+
+// eplBarcodeCommand returns the native B command for barcodes EPL2 can
+// print identically: unrotated Code128 in a forced code set without function
+// characters, ITF, and ISBN or ISSN without an add-on. EPL2 has no automatic
+// Code128 subset; its type 1 is UCC shipping container code, so Code128 in
+// the automatic code set is sent as a graphic.
+func eplBarcodeCommand(input BarcodeInput, bc barcode.Barcode, layout labelLayout) (string, bool) {
+	if input.BarcodeRotation != 0 {
+		return "", false
+	}
+	origin, moduleWidth, ok := nativeModuleLayout(bc, layout)
+	if !ok {
+		return "", false
+	}
+
+	var selection, data string
+	wideWidth := moduleWidth * 2
+	switch input.BarcodeType {
+	case BarcodeTypeCode128:
+		codeSet := input.Code128.CodeSet
+		if codeSet == Code128CodeSetAuto || !isPrintableASCII(input.BarcodeData) {
+			return "", false
+		}
+		// Subsets 1A, 1B and 1C encode the whole symbol in one code set, as the encoder does
+		if _, err := code128Values(input.BarcodeData, codeSet); err != nil {
+			return "", false
+		}
+		selection = "1" + string(codeSet)
+		data = input.BarcodeData
+	case BarcodeTypeITF:
+		selection = "2"
+		data = bc.Content()
+		wideWidth = moduleWidth * 3
+	case BarcodeTypeISBN, BarcodeTypeISSN:
+		if input.ISBN.AddOn != "" {
+			return "", false
+		}
+		selection = "E30"
+		data = bc.Content()[:12] // The printer adds the check digit
+	default:
+		return "", false
+	}
+
+	return fmt.Sprintf("B%d,%d,0,%s,%d,%d,%d,N,%s\n", origin.X, origin.Y, selection,
+		moduleWidth, wideWidth, layout.barcodeRect.Dy(), quoteEPL(data)), true
+}
+
+// writeEPLTextLines writes each text line as a native A command in the
+// largest resident font that fits its rendered size, falling back to a
+// graphic of the line when none fits
+func writeEPLTextLines(epl *strings.Builder, input BarcodeInput, printImg *image.RGBA, layout labelLayout) {
+	fonts := eplFonts[input.Dpi]
+	for _, line := range layoutNativeTextLines(input, printImg, layout) {
+		font, multiplier, ok := selectResidentFont(fonts, eplMaxMultiplier, line, input.Dpi)
+		if !ok {
+			writeEPLGraphic(epl, printImg, textBand(line, input.Dpi, layout.width))
+			continue
+		}
+
+		pos := residentTextPosition(line, fonts[font], multiplier)
+		fmt.Fprintf(epl, "A%d,%d,0,%d,%d,%d,N,%s\n", pos.X, pos.Y, font+1, multiplier, multiplier, quoteEPL(line.Text))
+	}
+}
+
+// writeEPLGraphic writes the area of img as a GW command
+func writeEPLGraphic(epl *strings.Builder, img *image.RGBA, area image.Rectangle) {
+	area = area.Intersect(img.Bounds())
+	if area.Empty() {
+		return
+	}
+
+	rowBytes, data := packMonochrome(img, area)
+	fmt.Fprintf(epl, "GW%d,%d,%d,%d,", area.Min.X, area.Min.Y, rowBytes, area.Dy())
+	epl.Write(data)
+	epl.WriteString("\n")
+}
+
+// quoteEPL quotes a data field, escaping backslashes and double quotes
+func quoteEPL(data string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(data) + `"`
+}
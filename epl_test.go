@@ -0,0 +1,97 @@
+package barcode
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateBarcode_EPL verifies supported barcodes and text use native commands
+func TestGenerateBarcode_EPL(t *testing.T) {
+	output, err := GenerateBarcode(BarcodeInput{
+		BarcodeData:   "LOC-A1-B2-C3",
+		BarcodeType:   BarcodeTypeCode128,
+		Code128:       Code128Options{CodeSet: Code128CodeSetB},
+		Width:         50,
+		Height:        25,
+		Dpi:           203,
+		OutputFormats: []OutputFormat{OutputFormatEPL},
+		TextLines:     []TextLine{{Text: "LOC-A1-B2-C3", Position: TextPositionBelow, Size: TextSizeMedium}},
+	})
+	require.NoError(t, err)
+
+	lines := strings.Split(output.EPL, "\n")
+	require.Len(t, lines, 8)
+	assert.Equal(t, []string{"", "N", "q399", "Q199,23"}, lines[:4])
+	assert.Regexp(t, `^B\d+,\d+,0,1B,2,4,\d+,N,"LOC-A1-B2-C3"$`, lines[4])
+	assert.Regexp(t, `^A\d+,\d+,0,[1-5],\d,\d,N,"LOC-A1-B2-C3"$`, lines[5])
+	assert.Equal(t, []string{"P1", ""}, lines[6:])
+}
+
+// TestGenerateBarcode_EPLBarcodes verifies the barcode selection for each native type
+func TestGenerateBarcode_EPLBarcodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    BarcodeInput
+		expected string
+	}{
+		{"Code128SetA", BarcodeInput{BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeCode128, Code128: Code128Options{CodeSet: Code128CodeSetA}}, `,0,1A,`},
+		{"Code128SetB", BarcodeInput{BarcodeData: "loc-a1", BarcodeType: BarcodeTypeCode128, Code128: Code128Options{CodeSet: Code128CodeSetB}}, `,0,1B,`},
+		{"Code128SetC", BarcodeInput{BarcodeData: "123456", BarcodeType: BarcodeTypeCode128, Code128: Code128Options{CodeSet: Code128CodeSetC}}, `,0,1C,`},
+		{"Code128Auto", BarcodeInput{BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeCode128}, "GW"},
+		{"ITF", BarcodeInput{BarcodeData: "12345678", BarcodeType: BarcodeTypeITF}, `,0,2,`},
+		{"ISBN", BarcodeInput{BarcodeData: "978-0-306-40615-7", BarcodeType: BarcodeTypeISBN}, `,0,E30,`},
+		{"QR", BarcodeInput{BarcodeData: "https://example.com", BarcodeType: BarcodeTypeQR}, "GW"},
+		{"ISBNAddOn", BarcodeInput{BarcodeData: "9780306406157", BarcodeType: BarcodeTypeISBN, ISBN: ISBNOptions{AddOn: "51299"}}, "GW"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input.Width, tt.input.Height, tt.input.Dpi = 50, 30, 203
+			tt.input.OutputFormats = []OutputFormat{OutputFormatEPL}
+			output, err := GenerateBarcode(tt.input)
+			require.NoError(t, err)
+			assert.Contains(t, strings.Split(output.EPL, "\n")[4], tt.expected)
+		})
+	}
+}
+
+// TestGenerateBarcode_EPLMirror verifies mirrored labels are sent as a single graphic
+func TestGenerateBarcode_EPLMirror(t *testing.T) {
+	output, err := GenerateBarcode(BarcodeInput{
+		BarcodeData:   "LOC-A1-B2-C3",
+		BarcodeType:   BarcodeTypeCode128,
+		Width:         50,
+		Height:        25,
+		Dpi:           203,
+		OutputFormats: []OutputFormat{OutputFormatEPL},
+		Mirror:        true,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, output.EPL, "\nGW0,0,50,199,")
+	assert.NotContains(t, output.EPL, "\nB")
+
+	output, err = GenerateBarcode(BarcodeInput{BarcodeData: "LOC", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203})
+	require.NoError(t, err)
+	assert.Empty(t, output.EPL)
+}
+
+// TestWriteEPLGraphic verifies dark pixels are cleared bits and rows are padded white
+func TestWriteEPLGraphic(t *testing.T) {
+	img := createBlankLabel(10, 2)
+	img.Set(0, 0, color.Black)
+	img.Set(9, 1, color.Black)
+
+	var epl strings.Builder
+	writeEPLGraphic(&epl, img, image.Rect(0, 0, 20, 2))
+	assert.Equal(t, "GW0,0,2,2,\x7f\xff\xff\xbf\n", epl.String())
+}
+
+// TestQuoteEPL verifies quotes and backslashes are escaped
+func TestQuoteEPL(t *testing.T) {
+	assert.Equal(t, `"A\"B\\C"`, quoteEPL(`A"B\C`))
+}
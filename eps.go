@@ -0,0 +1,104 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/boombuler/barcode"
+)
+
+// epsRect is a filled rectangle of the symbol in printer dots, with Y
+// growing downwards as in the label image
+type epsRect struct {
+	x, y, width, height int
+}
+
+// generateEPS returns the barcode as Encapsulated PostScript for placing in
+// packaging artwork. The bars are vector rectangles with the module widths of
+// the label at the printer DPI, so they keep their exact widths at any print
+// resolution. The bounding box is cropped to the bars; text lines, label
+// graphics and quiet zones are left to the artwork.
+func generateEPS(input BarcodeInput, bc barcode.Barcode) (string, error) {
+	layout, err := layoutLabel(input, bc, input.Dpi)
+	if err != nil {
+		return "", err
+	}
+	rects := traceSymbol(layout.barcode)
+	var bounds image.Rectangle
+	for _, rect := range rects {
+		bounds = bounds.Union(image.Rect(rect.x, rect.y, rect.x+rect.width, rect.y+rect.height))
+	}
+
+	pointsPerDot := 72 / float64(input.Dpi)
+	width, height := float64(bounds.Dx())*pointsPerDot, float64(bounds.Dy())*pointsPerDot
+
+	var eps strings.Builder
+	eps.WriteString("%!PS-Adobe-3.0 EPSF-3.0\n")
+	fmt.Fprintf(&eps, "%%%%BoundingBox: 0 0 %d %d\n", int(math.Ceil(width)), int(math.Ceil(height)))
+	fmt.Fprintf(&eps, "%%%%HiResBoundingBox: 0 0 %s %s\n", epsNumber(width), epsNumber(height))
+	eps.WriteString("%%Creator: barcode-generator\n%%EndComments\n0 setgray\n")
+	for _, rect := range rects {
+		// PostScript places the origin at the bottom left
+		fmt.Fprintf(&eps, "%s %s %s %s rectfill\n",
+			epsNumber(float64(rect.x-bounds.Min.X)*pointsPerDot), epsNumber(float64(bounds.Max.Y-rect.y-rect.height)*pointsPerDot),
+			epsNumber(float64(rect.width)*pointsPerDot), epsNumber(float64(rect.height)*pointsPerDot))
+	}
+	eps.WriteString("showpage\n%%EOF\n")
+	return eps.String(), nil
+}
+
+// traceSymbol converts the dark pixels of a scaled symbol into rectangles:
+// one per run of dark pixels in a row, merged with the runs of identical
+// rows below it, so each bar of a 1D symbol is a single rectangle
+func traceSymbol(symbol barcode.Barcode) []epsRect {
+	bounds := symbol.Bounds()
+	var rects []epsRect
+	var previous []epsRect // Runs of the band above, extended downwards while rows repeat
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		var runs []epsRect
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if !isDark(symbol.At(x, y)) {
+				continue
+			}
+			start := x
+			for x < bounds.Max.X && isDark(symbol.At(x, y)) {
+				x++
+			}
+			runs = append(runs, epsRect{x: start - bounds.Min.X, y: y - bounds.Min.Y, width: x - start, height: 1})
+		}
+
+		if sameRuns(previous, runs) {
+			for i := range previous {
+				previous[i].height++
+			}
+			continue
+		}
+		rects = append(rects, previous...)
+		previous = runs
+	}
+	return append(rects, previous...)
+}
+
+// sameRuns reports whether two rows have dark runs at the same positions
+func sameRuns(a, b []epsRect) bool {
+	return slices.EqualFunc(a, b, func(a, b epsRect) bool {
+		return a.x == b.x && a.width == b.width
+	})
+}
+
+// isDark reports whether a symbol pixel is printed
+func isDark(c color.Color) bool {
+	return color.GrayModel.Convert(c).(color.Gray).Y < 128
+}
+
+// epsNumber formats a coordinate in points to three decimals, without
+// trailing zeros
+func epsNumber(points float64) string {
+	s := strings.TrimRight(fmt.Sprintf("%.3f", points), "0")
+	return strings.TrimSuffix(s, ".")
+}
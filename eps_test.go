@@ -0,0 +1,75 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTraceSymbol verifies the traced rectangles cover exactly the dark pixels of each symbol
+func TestTraceSymbol(t *testing.T) {
+	tests := []BarcodeInput{
+		{BarcodeData: "LOC-A1-B2-C3", BarcodeType: BarcodeTypeCode128},
+		{BarcodeData: "https://example.com", BarcodeType: BarcodeTypeQR},
+		{BarcodeData: "01234567094987654321", BarcodeType: BarcodeTypeIMb},
+		{BarcodeData: "LOC-A1-B2-C3", BarcodeType: BarcodeTypeCode128, BarcodeRotation: 90},
+	}
+
+	for _, input := range tests {
+		t.Run(fmt.Sprintf("%s_%d", input.BarcodeType, input.BarcodeRotation), func(t *testing.T) {
+			input.Width, input.Height, input.Dpi = 100, 50, 203
+			bc, err := encodeBarcode(input)
+			require.NoError(t, err)
+			layout, err := layoutLabel(input, bc, input.Dpi)
+			require.NoError(t, err)
+			symbol := layout.barcode
+			bounds := symbol.Bounds()
+
+			covered := make(map[image.Point]bool)
+			for _, rect := range traceSymbol(symbol) {
+				for y := rect.y; y < rect.y+rect.height; y++ {
+					for x := rect.x; x < rect.x+rect.width; x++ {
+						assert.False(t, covered[image.Pt(x, y)], "pixel %d,%d traced twice", x, y)
+						covered[image.Pt(x, y)] = true
+					}
+				}
+			}
+			for y := 0; y < bounds.Dy(); y++ {
+				for x := 0; x < bounds.Dx(); x++ {
+					require.Equal(t, isDark(symbol.At(bounds.Min.X+x, bounds.Min.Y+y)), covered[image.Pt(x, y)], "pixel %d,%d", x, y)
+				}
+			}
+		})
+	}
+}
+
+// TestGenerateBarcode_EPS verifies the EPS is only produced on request, with one full-height rectangle per bar
+func TestGenerateBarcode_EPS(t *testing.T) {
+	input := BarcodeInput{BarcodeData: "LOC-A1-B2-C3", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203}
+
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Empty(t, output.EPS)
+
+	input.OutputFormats = []OutputFormat{OutputFormatEPS}
+	output, err = GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(output.EPS, "%!PS-Adobe-3.0 EPSF-3.0\n%%BoundingBox: 0 0 "))
+	assert.True(t, strings.HasSuffix(output.EPS, "showpage\n%%EOF\n"))
+
+	// Code128 has 3 bars per symbol character: start, 12 data characters, check and stop (4 bars)
+	bars := strings.Count(output.EPS, "rectfill")
+	assert.Equal(t, 3*14+4, bars)
+	assert.Regexp(t, `\n0 0 [\d.]+ [\d.]+ rectfill\n`, output.EPS, "the first bar should start at the origin")
+}
+
+// TestEPSNumber verifies coordinates are written without trailing zeros
+func TestEPSNumber(t *testing.T) {
+	assert.Equal(t, "0", epsNumber(0))
+	assert.Equal(t, "0.709", epsNumber(2*72/203.0))
+	assert.Equal(t, "12.5", epsNumber(12.5))
+}
@@ -0,0 +1,80 @@
+package barcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by the generator match these with errors.Is, so callers can
+// translate them for their own clients without matching messages. The
+// messages keep the details, such as the value that was rejected.
+var (
+	ErrInvalidInput        = errors.New("invalid input")            // The input was rejected by validation; also matched by the kinds below
+	ErrInvalidDPI          = errors.New("invalid dpi")              // The printer or preview DPI is not supported
+	ErrUnsupportedType     = errors.New("unsupported barcode type") // The barcode type is not supported
+	ErrInvalidDimensions   = errors.New("invalid label dimensions") // The label size is not positive or leaves no room within the margins
+	ErrInvalidOutputFormat = errors.New("invalid output format")    // An output format is not supported
+	ErrInvalidData         = errors.New("invalid barcode data")     // The data breaks the symbology's rules, such as its characters, length or check digit
+	ErrLabelTooSmall       = errors.New("label too small")          // The barcode does not fit on the label
+	ErrNotScannable        = errors.New("label may not scan")       // StrictScannability rejected the label
+)
+
+// EncodingError reports barcode data that could not be encoded in its
+// symbology. Its message is that of the encoder error it wraps.
+type EncodingError struct {
+	Type BarcodeType // Barcode type the data was encoded as
+	Data string      // Barcode data that failed
+	Err  error       // Error returned by the encoder
+}
+
+func (e *EncodingError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *EncodingError) Unwrap() error {
+	return e.Err
+}
+
+// DataTooLongError reports barcode data longer than its symbology holds
+type DataTooLongError struct {
+	Length int    // Length of the data
+	Max    int    // Longest data the symbology holds
+	Unit   string // What the lengths count, such as characters or bytes
+}
+
+func (e *DataTooLongError) Error() string {
+	return fmt.Sprintf("data too long: %d %s, maximum is %d", e.Length, e.Unit, e.Max)
+}
+
+// Is makes data that is too long match ErrInvalidData
+func (e *DataTooLongError) Is(target error) bool {
+	return target == ErrInvalidData
+}
+
+// kindError is an error that keeps its own message and also matches a
+// sentinel error
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// errorf formats an error like fmt.Errorf that also matches kind
+func errorf(kind error, format string, args ...interface{}) error {
+	return &kindError{kind: kind, err: fmt.Errorf(format, args...)}
+}
+
+// withKind makes err also match kind, keeping its message. Nil stays nil.
+func withKind(kind, err error) error {
+	if err == nil || errors.Is(err, kind) {
+		return err
+	}
+	return &kindError{kind: kind, err: err}
+}
@@ -0,0 +1,99 @@
+package barcode
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateBarcode_ErrorKinds verifies errors match the sentinel for
+// their kind of problem and keep their messages
+func TestGenerateBarcode_ErrorKinds(t *testing.T) {
+	valid := BarcodeInput{BarcodeData: "LOC-A1-B2-C3", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203}
+	for _, test := range []struct {
+		name   string
+		change func(*BarcodeInput)
+		kind   error
+		err    string
+	}{
+		{"dpi", func(input *BarcodeInput) { input.Dpi = 150 }, ErrInvalidDPI, "invalid dpi value: 150"},
+		{"preview dpi", func(input *BarcodeInput) { input.PreviewDpi = -1 }, ErrInvalidDPI, "invalid preview dpi value"},
+		{"type", func(input *BarcodeInput) { input.BarcodeType = "PDF417" }, ErrUnsupportedType, "invalid barcode type: PDF417"},
+		{"width", func(input *BarcodeInput) { input.Width = 0 }, ErrInvalidDimensions, "invalid label width"},
+		{"format", func(input *BarcodeInput) { input.OutputFormats = []OutputFormat{"SVG"} }, ErrInvalidOutputFormat, "invalid output format: SVG"},
+		{"units", func(input *BarcodeInput) { input.Units = "FURLONG" }, ErrInvalidInput, "invalid units"},
+		{"preset", func(input *BarcodeInput) { input.LabelPreset = "nope" }, ErrInvalidInput, "invalid label preset"},
+		{"preset and size", func(input *BarcodeInput) { input.LabelPreset = LabelPreset4x6 }, ErrInvalidInput, "Set either LabelPreset or Width and Height"},
+		{"auto height", func(input *BarcodeInput) { input.AutoHeight, input.BarcodeRotation = true, 90 }, ErrInvalidInput, "invalid auto height"},
+		{"check digit", func(input *BarcodeInput) { input.BarcodeType, input.BarcodeData = BarcodeTypeISBN, "978-0-306-40615-8" }, ErrInvalidData, "invalid ISBN"},
+		{"too small", func(input *BarcodeInput) { input.Width, input.XDimensionMils = 20, 20 }, ErrLabelTooSmall, "barcode does not fit"},
+		{"scale", func(input *BarcodeInput) { input.Width = 15 }, ErrLabelTooSmall, "can not scale barcode"},
+		{"scannability", func(input *BarcodeInput) { input.Width, input.StrictScannability = 25, true }, ErrNotScannable, "label may not scan"},
+	} {
+		input := valid
+		test.change(&input)
+		_, err := GenerateBarcode(input)
+		assert.ErrorIs(t, err, test.kind, test.name)
+		assert.ErrorContains(t, err, test.err, test.name)
+	}
+
+	_, err := GenerateBarcode(BarcodeInput{BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 150})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.False(t, errors.Is(err, ErrInvalidData))
+}
+
+// TestGenerateBarcode_EncodingError verifies encoder failures report the type
+// and data, and data too long for the symbology reports the maximum
+func TestGenerateBarcode_EncodingError(t *testing.T) {
+	data := strings.Repeat("a", 3000)
+	_, err := GenerateBarcode(BarcodeInput{BarcodeData: data, BarcodeType: BarcodeTypeQR, Width: 100, Height: 100, Dpi: 203, QR: QROptions{ErrorCorrection: QRErrorCorrectionL}})
+
+	var encodingErr *EncodingError
+	require.True(t, errors.As(err, &encodingErr))
+	assert.Equal(t, BarcodeTypeQR, encodingErr.Type)
+	assert.Equal(t, data, encodingErr.Data)
+
+	var tooLong *DataTooLongError
+	require.True(t, errors.As(err, &tooLong))
+	assert.Equal(t, DataTooLongError{Length: 3000, Max: 2953, Unit: "bytes"}, *tooLong)
+	assert.ErrorIs(t, err, ErrInvalidData)
+	assert.EqualError(t, err, "failed to encode QR code: data too long: 3000 bytes, maximum is 2953")
+
+	_, err = GenerateBarcode(BarcodeInput{BarcodeData: data, BarcodeType: BarcodeTypeQR, Width: 100, Height: 100, Dpi: 203, QR: QROptions{ErrorCorrection: QRErrorCorrectionL, MinVersion: 10}})
+	require.True(t, errors.As(err, &tooLong))
+	assert.Equal(t, 2953, tooLong.Max)
+}
+
+// TestValidators_ErrorKinds verifies validators that are also reached outside
+// GenerateBarcode match the sentinel for their kind of problem
+func TestValidators_ErrorKinds(t *testing.T) {
+	_, measureDPIErr := MeasureText("SKU", TextSizeSmall, 0, 200)
+	_, measureWidthErr := MeasureText("SKU", TextSizeSmall, 203, 0)
+	_, fontDownloadErr := ZPLFontDownload("NOTOSANS", nil)
+	_, formatNameErr := GenerateZPLStoredFormat(BarcodeInput{BarcodeData: "LOC-1", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203}, "LABEL")
+	for _, test := range []struct {
+		name string
+		err  error
+		kind error
+		msg  string
+	}{
+		{"barcode rotation", validateBarcodeRotation(45), ErrInvalidInput, "invalid barcode rotation: 45"},
+		{"label rotation", validateLabelRotation(45), ErrInvalidInput, "invalid label rotation: 45"},
+		{"QR level", validateQROptions(QROptions{ErrorCorrection: "X"}), ErrInvalidInput, "invalid QR error correction level"},
+		{"QR mode", validateQROptions(QROptions{Mode: "KANJI"}), ErrInvalidInput, "invalid QR mode"},
+		{"QR version", validateQROptions(QROptions{MinVersion: 41}), ErrInvalidInput, "invalid QR minimum version"},
+		{"binary data", validateBarcodeDataBytes(BarcodeInput{BarcodeType: BarcodeTypeCode128}), ErrInvalidData, "binary data is only supported"},
+		{"ZPL darkness", validateZPLOptions(ZPLOptions{Darkness: 100}), ErrInvalidInput, "invalid ZPL darkness"},
+		{"ZPL font download", fontDownloadErr, ErrInvalidInput, "invalid ZPL font"},
+		{"ZPL format name", formatNameErr, ErrInvalidInput, "invalid ZPL format name"},
+		{"font scaling", validateFontScaling(FontScaling{Min: -1}), ErrInvalidInput, "invalid font scaling"},
+		{"measure dpi", measureDPIErr, ErrInvalidDPI, "invalid dpi value: 0"},
+		{"measure width", measureWidthErr, ErrInvalidDimensions, "invalid text width: 0"},
+	} {
+		assert.ErrorIs(t, test.err, test.kind, test.name)
+		assert.ErrorContains(t, test.err, test.msg, test.name)
+	}
+}
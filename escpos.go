@@ -0,0 +1,141 @@
+package barcode
+
+import (
+	"image"
+	"strconv"
+	"strings"
+
+	"github.com/boombuler/barcode"
+)
+
+// ESC/POS limits
+const (
+	escposMinModuleWidth = 2   // Narrowest module width GS w accepts
+	escposMaxModuleWidth = 6   // Widest module width GS w accepts
+	escposMaxHeight      = 255 // Tallest barcode GS h accepts
+)
+
+// escposFeedAndCut feeds the label past the cutter and cuts it
+const escposFeedAndCut = "\x1dVB\x00"
+
+// escposCode128CodeSets maps forced Code128 code sets to the code set
+// selection prefixed to GS k data
+var escposCode128CodeSets = map[Code128CodeSet]string{
+	Code128CodeSetA: "{A",
+	Code128CodeSetB: "{B",
+	Code128CodeSetC: "{C",
+}
+
+// generateESCPOS converts the label to ESC/POS for receipt printers such as
+// the Epson TM series. The label is printed top to bottom: the rows above and
+// below the barcode are sent as raster graphics, and linear barcodes ESC/POS
+// supports are sent as native GS k commands in between, indented to the same
+// position. Other symbols, and labels with text beside the barcode or
+// elements at fixed positions, are part of a single raster graphic. When
+// native is false, the whole print image is sent as one graphic.
+func generateESCPOS(input BarcodeInput, bc barcode.Barcode, printImg *image.RGBA, native bool) (string, error) {
+	var escpos strings.Builder
+	escpos.WriteString("\x1b@")
+	bounds := printImg.Bounds()
+
+	if native && !hasSideText(input) && !hasAbsolutePlacement(input) {
+		layout, err := layoutLabel(input, bc, input.Dpi)
+		if err != nil {
+			return "", err
+		}
+		if command, ok := escposBarcodeCommand(input, bc, layout); ok {
+			writeESCPOSRaster(&escpos, printImg, image.Rect(bounds.Min.X, bounds.Min.Y, bounds.Max.X, layout.barcodeRect.Min.Y))
+			escpos.WriteString(command)
+			writeESCPOSRaster(&escpos, printImg, image.Rect(bounds.Min.X, layout.barcodeRect.Max.Y, bounds.Max.X, bounds.Max.Y))
+			escpos.WriteString(escposFeedAndCut)
+			return escpos.String(), nil
+		}
+	}
+
+	writeESCPOSRaster(&escpos, printImg, bounds)
+	escpos.WriteString(escposFeedAndCut)
+	return escpos.String(), nil
+}
+
+// escposBarcodeCommand returns the native GS k command for barcodes ESC/POS
+// can print identically: unrotated Code128 in a forced code set, ITF, and
+// ISBN or ISSN without an add-on. The command sets the left margin to the
+// barcode position and resets it afterwards.
+func escposBarcodeCommand(input BarcodeInput, bc barcode.Barcode, layout labelLayout) (string, bool) {
+	height := layout.barcodeRect.Dy()
+	if input.BarcodeRotation != 0 || height > escposMaxHeight {
+		return "", false
+	}
+	origin, moduleWidth, ok := nativeModuleLayout(bc, layout)
+	if !ok || moduleWidth < escposMinModuleWidth || moduleWidth > escposMaxModuleWidth {
+		return "", false
+	}
+
+	var system byte
+	var data string
+	switch input.BarcodeType {
+	case BarcodeTypeCode128:
+		codeSet, ok := escposCode128CodeSets[input.Code128.CodeSet]
+		if !ok || !isPrintableASCII(input.BarcodeData) {
+			return "", false
+		}
+		system = 73
+		data = codeSet + escposCode128Data(input.BarcodeData, input.Code128.CodeSet)
+	case BarcodeTypeITF:
+		system = 70
+		data = bc.Content()
+	case BarcodeTypeISBN, BarcodeTypeISSN:
+		if input.ISBN.AddOn != "" {
+			return "", false
+		}
+		system = 67
+		data = bc.Content()[:12] // The printer adds the check digit
+	default:
+		return "", false
+	}
+	if len(data) > 255 {
+		return "", false
+	}
+
+	var command strings.Builder
+	writeESCPOSLeftMargin(&command, origin.X)
+	command.Write([]byte{0x1d, 'h', byte(height), 0x1d, 'w', byte(moduleWidth), 0x1d, 'H', 0, 0x1d, 'k', system, byte(len(data))})
+	command.WriteString(data)
+	writeESCPOSLeftMargin(&command, 0)
+	return command.String(), true
+}
+
+// escposCode128Data returns Code128 data as GS k expects it. Code set C
+// takes each digit pair as a single byte.
+func escposCode128Data(data string, codeSet Code128CodeSet) string {
+	if codeSet != Code128CodeSetC {
+		return data
+	}
+
+	var pairs strings.Builder
+	for i := 0; i+1 < len(data); i += 2 {
+		value, _ := strconv.Atoi(data[i : i+2])
+		pairs.WriteByte(byte(value))
+	}
+	return pairs.String()
+}
+
+// writeESCPOSLeftMargin writes a GS L command setting the left margin in dots
+func writeESCPOSLeftMargin(escpos *strings.Builder, dots int) {
+	escpos.Write([]byte{0x1d, 'L', byte(dots), byte(dots >> 8)})
+}
+
+// writeESCPOSRaster writes the area of img as a GS v 0 raster graphic, with
+// set bits printed
+func writeESCPOSRaster(escpos *strings.Builder, img *image.RGBA, area image.Rectangle) {
+	area = area.Intersect(img.Bounds())
+	if area.Empty() {
+		return
+	}
+
+	rowBytes, data := packMonochrome(img, area)
+	escpos.Write([]byte{0x1d, 'v', '0', 0, byte(rowBytes), byte(rowBytes >> 8), byte(area.Dy()), byte(area.Dy() >> 8)})
+	for _, b := range data {
+		escpos.WriteByte(^b)
+	}
+}
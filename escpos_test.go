@@ -0,0 +1,83 @@
+package barcode
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateBarcode_ESCPOS verifies supported barcodes are printed natively between raster graphics
+func TestGenerateBarcode_ESCPOS(t *testing.T) {
+	output, err := GenerateBarcode(BarcodeInput{
+		BarcodeData:   "LOC-A1-B2-C3",
+		BarcodeType:   BarcodeTypeCode128,
+		Width:         50,
+		Height:        25,
+		Dpi:           203,
+		OutputFormats: []OutputFormat{OutputFormatESCPOS},
+		Code128:       Code128Options{CodeSet: Code128CodeSetB},
+		TextLines:     []TextLine{{Text: "LOC-A1-B2-C3", Position: TextPositionBelow, Size: TextSizeMedium}},
+	})
+	require.NoError(t, err)
+
+	escpos := output.ESCPOS
+	assert.True(t, strings.HasPrefix(escpos, "\x1b@\x1dv0\x00\x32\x00"))
+	assert.Contains(t, escpos, "\x1dw\x02\x1dH\x00\x1dk\x49\x0e{BLOC-A1-B2-C3\x1dL\x00\x00\x1dv0\x00")
+	assert.True(t, strings.HasSuffix(escpos, "\x1dVB\x00"))
+}
+
+// TestGenerateBarcode_ESCPOSBarcodes verifies the barcode selection for each native type
+func TestGenerateBarcode_ESCPOSBarcodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    BarcodeInput
+		expected string
+	}{
+		{"Code128SetC", BarcodeInput{BarcodeData: "123456", BarcodeType: BarcodeTypeCode128, Code128: Code128Options{CodeSet: Code128CodeSetC}}, "\x1dk\x49\x05{C\x0c\x22\x38"},
+		{"ITF", BarcodeInput{BarcodeData: "12345678", BarcodeType: BarcodeTypeITF}, "\x1dk\x46\x0812345678"},
+		{"ISBN", BarcodeInput{BarcodeData: "978-0-306-40615-7", BarcodeType: BarcodeTypeISBN}, "\x1dk\x43\x0c978030640615"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input.Width, tt.input.Height, tt.input.Dpi = 50, 30, 203
+			tt.input.OutputFormats = []OutputFormat{OutputFormatESCPOS}
+			output, err := GenerateBarcode(tt.input)
+			require.NoError(t, err)
+			assert.Contains(t, output.ESCPOS, tt.expected)
+		})
+	}
+}
+
+// TestGenerateBarcode_ESCPOSRaster verifies other symbols and mirrored labels are sent as one graphic
+func TestGenerateBarcode_ESCPOSRaster(t *testing.T) {
+	inputs := []BarcodeInput{
+		{BarcodeData: "https://example.com", BarcodeType: BarcodeTypeQR},
+		{BarcodeData: "LOC-1", BarcodeType: BarcodeTypeCode128},
+		{BarcodeData: "LOC-1", BarcodeType: BarcodeTypeCode128, Code128: Code128Options{CodeSet: Code128CodeSetB}, Mirror: true},
+	}
+
+	for _, input := range inputs {
+		input.Width, input.Height, input.Dpi = 50, 30, 203
+		input.OutputFormats = []OutputFormat{OutputFormatESCPOS}
+		output, err := GenerateBarcode(input)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(output.ESCPOS, "\x1b@\x1dv0\x00\x32\x00\xef\x00"))
+		assert.NotContains(t, output.ESCPOS, "\x1dk")
+	}
+}
+
+// TestWriteESCPOSRaster verifies dark pixels are set bits and rows are padded white
+func TestWriteESCPOSRaster(t *testing.T) {
+	img := createBlankLabel(10, 2)
+	img.Set(0, 0, color.Black)
+	img.Set(9, 1, color.Black)
+
+	var escpos strings.Builder
+	writeESCPOSRaster(&escpos, img, image.Rect(0, 0, 20, 2))
+	assert.Equal(t, "\x1dv0\x00\x02\x00\x02\x00\x80\x00\x00\x40", escpos.String())
+}
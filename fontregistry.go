@@ -0,0 +1,172 @@
+package barcode
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// fontRegistry holds the fonts registered with RegisterFont by name, and the
+// names of the fallback fonts in the order they are tried
+var fontRegistry = struct {
+	sync.RWMutex
+	fonts     map[string]*truetype.Font
+	fallbacks []string
+}{fonts: map[string]*truetype.Font{}}
+
+// RegisterFont parses a TrueType font, or an OpenType font with TrueType
+// outlines, and makes it available to text lines that set FontName to name,
+// e.g. a corporate typeface or a condensed font for long SKUs. Fonts are
+// shared by every label, so register them once at startup. Registering a name
+// again replaces its font; cache keys only include the name, so use a new name
+// when a font changes and labels are cached.
+func RegisterFont(name string, data []byte) error {
+	parsed, err := parseFont(name, data)
+	if err != nil {
+		return err
+	}
+
+	fontRegistry.Lock()
+	defer fontRegistry.Unlock()
+	fontRegistry.fonts[name] = parsed
+	return nil
+}
+
+// parseFont parses a font to be used under the name
+func parseFont(name string, data []byte) (*truetype.Font, error) {
+	if name == "" {
+		return nil, fmt.Errorf("invalid font name: name must not be empty")
+	}
+	parsed, err := truetype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid font %q: %w. Only TrueType outlines are supported", name, err)
+	}
+	return parsed, nil
+}
+
+// fontRef is the font a text line is drawn in: its FontName, and the font a
+// Generator holds under that name, which is used before the registry
+type fontRef struct {
+	name string
+	font *truetype.Font
+}
+
+// fontRef returns the font the line is drawn in
+func (t TextLine) fontRef() fontRef {
+	return fontRef{name: t.FontName, font: t.font}
+}
+
+// textFont returns the font a Generator resolved for the line, the
+// registered font with the name, or the label font when the name is empty
+func textFont(ref fontRef) (*truetype.Font, error) {
+	if ref.font != nil {
+		return ref.font, nil
+	}
+	if ref.name == "" {
+		return labelFont()
+	}
+
+	fontRegistry.RLock()
+	defer fontRegistry.RUnlock()
+	if parsed, ok := fontRegistry.fonts[ref.name]; ok {
+		return parsed, nil
+	}
+	return nil, fmt.Errorf("invalid font name: %q. Register the font with RegisterFont or a Generator before using it", ref.name)
+}
+
+// SetFallbackFonts sets the registered fonts, in order, that draw characters
+// missing from a line's font, e.g. a CJK font for Chinese and Japanese product
+// names, which Go Regular would print as empty boxes. No CJK font is embedded,
+// so register one such as Noto Sans CJK first. Calling it again replaces the
+// fallbacks; calling it with no names removes them.
+func SetFallbackFonts(names ...string) error {
+	for _, name := range names {
+		if name == "" {
+			return fmt.Errorf("invalid fallback font: name must not be empty")
+		}
+		if err := validateFontName(fontRef{name: name}); err != nil {
+			return err
+		}
+	}
+
+	fontRegistry.Lock()
+	defer fontRegistry.Unlock()
+	fontRegistry.fallbacks = append([]string(nil), names...)
+	return nil
+}
+
+// textRun is part of a text line drawn in one font
+type textRun struct {
+	font *truetype.Font
+	text string
+}
+
+// textRuns splits text, in visual order, into runs in the named font, with
+// characters the font has no glyph for in the first fallback font that has
+// one. Characters no font has stay in the named font.
+func textRuns(ref fontRef, text string) ([]textRun, error) {
+	primary, err := textFont(ref)
+	if err != nil {
+		return nil, err
+	}
+	text = visualText(text)
+
+	fontRegistry.RLock()
+	fallbacks := make([]*truetype.Font, 0, len(fontRegistry.fallbacks))
+	for _, name := range fontRegistry.fallbacks {
+		fallbacks = append(fallbacks, fontRegistry.fonts[name])
+	}
+	fontRegistry.RUnlock()
+	if len(fallbacks) == 0 {
+		return []textRun{{font: primary, text: text}}, nil
+	}
+
+	var runs []textRun
+	for _, r := range text {
+		runFont := primary
+		if primary.Index(r) == 0 {
+			for _, fallback := range fallbacks {
+				if fallback.Index(r) != 0 {
+					runFont = fallback
+					break
+				}
+			}
+		}
+		if n := len(runs); n > 0 && runs[n-1].font == runFont {
+			runs[n-1].text += string(r)
+			continue
+		}
+		runs = append(runs, textRun{font: runFont, text: string(r)})
+	}
+	return runs, nil
+}
+
+// measureRuns returns the width of text runs in pixels at the font size and DPI
+func measureRuns(runs []textRun, fontSize, dpi float64) int {
+	var width fixed.Int26_6
+	for _, run := range runs {
+		face := truetype.NewFace(run.font, &truetype.Options{Size: fontSize, DPI: dpi})
+		width += font.MeasureString(face, run.text)
+	}
+	return width.Ceil()
+}
+
+// measureTextWidth returns the width of text in the named font and its
+// fallbacks in pixels at the font size and DPI
+func measureTextWidth(ref fontRef, text string, fontSize, dpi float64) (int, error) {
+	runs, err := textRuns(ref, text)
+	if err != nil {
+		return 0, err
+	}
+	return measureRuns(runs, fontSize, dpi), nil
+}
+
+// validateFontName ensures a text line's font has been registered or is
+// held by its Generator
+func validateFontName(ref fontRef) error {
+	_, err := textFont(ref)
+	return err
+}
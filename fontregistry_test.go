@@ -0,0 +1,78 @@
+package barcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/font/gofont/gomono"
+)
+
+// TestRegisterFont verifies fonts are registered by name and bad names and
+// data are rejected
+func TestRegisterFont(t *testing.T) {
+	require.NoError(t, RegisterFont("test-mono", gomono.TTF))
+	registered, err := textFont(fontRef{name: "test-mono"})
+	require.NoError(t, err)
+	regular, err := textFont(fontRef{})
+	require.NoError(t, err)
+	assert.NotSame(t, regular, registered)
+
+	assert.ErrorContains(t, RegisterFont("", gomono.TTF), "invalid font name")
+	assert.ErrorContains(t, RegisterFont("broken", []byte("not a font")), `invalid font "broken"`)
+	_, err = textFont(fontRef{name: "missing"})
+	assert.ErrorContains(t, err, "RegisterFont")
+}
+
+// TestGenerateBarcode_RegisteredFont verifies lines are fitted in their
+// font, sent to printers as graphics, and unknown fonts are rejected
+func TestGenerateBarcode_RegisteredFont(t *testing.T) {
+	require.NoError(t, RegisterFont("test-mono", gomono.TTF))
+	text := strings.Repeat("SKU-10442 ", 3)
+	regular := fitFontSize(fontRef{}, text, 10, 203, 200)
+	mono := fitFontSize(fontRef{name: "test-mono"}, text, 10, 203, 200)
+	assert.NotEqual(t, regular, mono, "Monospaced glyphs have different widths")
+
+	input := BarcodeInput{
+		BarcodeData: "10442",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50.0,
+		Height:      25.0,
+		Dpi:         203,
+		TextLines:   []TextLine{{Text: "SKU 10442", Position: TextPositionBelow, Size: TextSizeMedium, FontName: "test-mono"}},
+		ZPL:         ZPLOptions{NativeCommands: true},
+	}
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.NotContains(t, output.ZPL, "^FDSKU 10442^FS")
+
+	_, err = GenerateZPLStoredFormat(input, "E:LABEL.ZPL")
+	assert.ErrorContains(t, err, "Registered fonts")
+
+	input.TextLines[0].FontName = "missing"
+	_, err = GenerateBarcode(input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid font name")
+}
+
+// TestSetFallbackFonts verifies fallbacks must be registered and characters
+// no font has stay in the line's font
+func TestSetFallbackFonts(t *testing.T) {
+	assert.ErrorContains(t, SetFallbackFonts("missing"), "invalid font name")
+	assert.ErrorContains(t, SetFallbackFonts(""), "invalid fallback font")
+
+	require.NoError(t, RegisterFont("test-mono", gomono.TTF))
+	require.NoError(t, SetFallbackFonts("test-mono"))
+	defer SetFallbackFonts()
+
+	regular, err := textFont(fontRef{})
+	require.NoError(t, err)
+	runs, err := textRuns(fontRef{}, "SKU 日本")
+	require.NoError(t, err)
+	assert.Equal(t, []textRun{{font: regular, text: "SKU 日本"}}, runs)
+
+	width, err := measureTextWidth(fontRef{}, "SKU", 10, 203)
+	require.NoError(t, err)
+	assert.Greater(t, width, 0)
+}
@@ -1,26 +1,117 @@
 package barcode
 
 import (
+	"fmt"
 	"image"
 	"image/color"
+	"math"
+	"sync"
 
 	"github.com/golang/freetype"
 	"github.com/golang/freetype/truetype"
-	"golang.org/x/image/font"
 	"golang.org/x/image/font/gofont/goregular"
 )
 
-// getFontSize calculates the appropriate font size in points and pixel height.
-// It scales the font proportionally for larger labels to maintain readability.
-func getFontSize(size TextSize, dpi int, labelWidth int) (float64, float64) {
+// labelFont returns the parsed label font. The font is parsed once, on first
+// use, and shared by every label since rendering only reads it.
+var labelFont = sync.OnceValues(func() (*truetype.Font, error) {
+	return truetype.Parse(goregular.TTF)
+})
+
+// Warm loads the label font ahead of the first label. Serverless deployments
+// can call it during initialization so a cold start does not delay the first
+// GenerateBarcode call.
+func Warm() error {
+	if _, err := labelFont(); err != nil {
+		return fmt.Errorf("failed to load font: %w", err)
+	}
+	return nil
+}
+
+// FontScaling grows text on wider labels to keep it in proportion. Text is
+// scaled by 1 + PerMM * (label width - BaselineMM), clamped between Min and
+// Max. The label width is measured in millimeters, so text is the same
+// physical size at every printer DPI. Zero fields use the defaults, which
+// match the previous pixel-based scaling at 203 DPI; set Min and Max to 1 to
+// turn scaling off.
+type FontScaling struct {
+	BaselineMM float64 // Optional label width at which text is not scaled (defaults to 25mm)
+	PerMM      float64 // Optional scale added per millimeter of label width (defaults to 0.008)
+	Min        float64 // Optional smallest scale (defaults to 1.1)
+	Max        float64 // Optional largest scale (defaults to 2.0)
+}
+
+// defaultFontScaling is used for zero FontScaling fields
+var defaultFontScaling = FontScaling{BaselineMM: 25, PerMM: 0.008, Min: 1.1, Max: 2.0}
+
+// withDefaults returns the scaling with zero fields set to their defaults
+func (s FontScaling) withDefaults() FontScaling {
+	if s.BaselineMM == 0 {
+		s.BaselineMM = defaultFontScaling.BaselineMM
+	}
+	if s.PerMM == 0 {
+		s.PerMM = defaultFontScaling.PerMM
+	}
+	if s.Min == 0 {
+		s.Min = defaultFontScaling.Min
+	}
+	if s.Max == 0 {
+		s.Max = defaultFontScaling.Max
+	}
+	return s
+}
+
+// scale returns the font scale for a label of the given width in millimeters
+func (s FontScaling) scale(labelWidthMM float64) float64 {
+	s = s.withDefaults()
+	return math.Min(math.Max(1+s.PerMM*(labelWidthMM-s.BaselineMM), s.Min), s.Max)
+}
+
+// labelFontScale returns the font scale for the label's physical width
+func labelFontScale(input BarcodeInput) float64 {
+	return input.FontScaling.scale(input.Width)
+}
+
+// validateFontScaling ensures the scaling parameters are not negative and
+// the clamp range is not inverted
+func validateFontScaling(scaling FontScaling) error {
+	if scaling.BaselineMM < 0 || scaling.PerMM < 0 || scaling.Min < 0 || scaling.Max < 0 {
+		return errorf(ErrInvalidInput, "invalid font scaling: %+v. Values must not be negative", scaling)
+	}
+	if resolved := scaling.withDefaults(); resolved.Min > resolved.Max {
+		return errorf(ErrInvalidInput, "invalid font scaling: minimum %g is larger than maximum %g", resolved.Min, resolved.Max)
+	}
+	return nil
+}
+
+// getFontSize calculates the appropriate font size in points and pixel height,
+// with the base size multiplied by the label's font scale.
+func getFontSize(size TextSize, dpi int, fontScale float64) (float64, float64) {
 	baseFontSize := getBaseFontSize(size)
-	scaledFontSize := scaleFontByLabelWidth(baseFontSize, labelWidth)
+	scaledFontSize := baseFontSize * fontScale
 
-	fontHeight := calculateFontHeight(scaledFontSize, dpi)
+	fontHeight := calculateFontHeight(fontRef{}, scaledFontSize, dpi)
 
 	return scaledFontSize, fontHeight
 }
 
+// getTextLineFontSize returns the font size and pixel height of a text line,
+// applying its optional Scale and measured in its font. An exact FontSizePt
+// replaces the size and the label's font scale.
+func getTextLineFontSize(textLine TextLine, dpi int, fontScale float64) (float64, float64) {
+	fontSize, fontHeight := getFontSize(textLine.Size, dpi, fontScale)
+	if textLine.FontSizePt > 0 {
+		fontSize, fontHeight = textLine.FontSizePt, calculateFontHeight(textLine.fontRef(), textLine.FontSizePt, dpi)
+	}
+	if (textLine.Scale == 0 || textLine.Scale == 1) && textLine.FontName == "" {
+		return fontSize, fontHeight
+	}
+	if textLine.Scale != 0 {
+		fontSize *= textLine.Scale
+	}
+	return fontSize, calculateFontHeight(textLine.fontRef(), fontSize, dpi)
+}
+
 // getBaseFontSize returns the base font size in points for the given text size enum.
 func getBaseFontSize(size TextSize) float64 {
 	switch size {
@@ -35,25 +126,10 @@ func getBaseFontSize(size TextSize) float64 {
 	}
 }
 
-// scaleFontByLabelWidth adjusts font size proportionally to label width.
-// Larger labels get larger fonts to maintain visual balance.
-func scaleFontByLabelWidth(fontSize float64, labelWidth int) float64 {
-	// Scale factor based on deviation from 200px baseline
-	scaleFactor := 1.0 + float64(labelWidth-200)/1000
-
-	// Clamp scale factor to reasonable bounds
-	if scaleFactor > 2.0 {
-		scaleFactor = 2.0
-	} else if scaleFactor < 1.1 {
-		scaleFactor = 1.1
-	}
-
-	return fontSize * scaleFactor
-}
-
-// calculateFontHeight returns the pixel height of text at the given font size and DPI.
-func calculateFontHeight(fontSize float64, dpi int) float64 {
-	fontData, err := truetype.Parse(goregular.TTF)
+// calculateFontHeight returns the pixel height of text in the named font at
+// the given font size and DPI. An empty name is the label font.
+func calculateFontHeight(ref fontRef, fontSize float64, dpi int) float64 {
+	fontData, err := textFont(ref)
 	if err != nil {
 		return 0
 	}
@@ -69,70 +145,166 @@ func calculateFontHeight(fontSize float64, dpi int) float64 {
 // addTextLine renders a text string on the label image at the specified position.
 // It uses a recursive approach: if the text is too wide for the label, it reduces
 // the font size by 0.1 points and tries again. This ensures text always fits.
-func addTextLine(img *image.RGBA, text string, centerX, baseY int, size TextSize, dpi float64, position TextPosition) {
-	fontSize, fontHeight := getFontSize(size, int(dpi), img.Bounds().Dx())
-	addTextLineRecursive(img, text, centerX, baseY, fontSize, fontHeight, dpi, position)
+//
+// maxWidth is the width available for text, as returned by textArea.maxWidth.
+func addTextLine(img *image.RGBA, textLine TextLine, anchor textAnchor, baseY int, dpi float64, maxWidth int, fontScale float64) {
+	fontSize, fontHeight := getTextLineFontSize(textLine, int(dpi), fontScale)
+	addTextLineRecursive(img, textLine.fontRef(), textLine.Text, anchor, baseY, fontSize, fontHeight, dpi, textLine.Position, maxWidth)
+}
+
+// addScaledTextLine renders a text string with its font size multiplied by scale.
+// Used for fit groups, where the scale has already been chosen so every line fits.
+func addScaledTextLine(img *image.RGBA, textLine TextLine, anchor textAnchor, baseY int, dpi float64, fontScale, scale float64) {
+	fontSize, _ := getTextLineFontSize(textLine, int(dpi), fontScale)
+	fontSize *= scale
+	fontHeight := calculateFontHeight(textLine.fontRef(), fontSize, int(dpi))
+	drawText(img, textLine.fontRef(), textLine.Text, anchor, baseY, fontSize, fontHeight, dpi, textLine.Position, color.Black)
+}
+
+// calculateFitGroupScales returns the shared shrink factor for each fit group.
+// A group's factor is the smallest one any of its lines needs to fit, so all
+// lines in the group keep the same relative sizes.
+func calculateFitGroupScales(textLines []TextLine, area textArea, dpi float64, fontScale float64) map[string]float64 {
+	scales := make(map[string]float64)
+	for _, textLine := range textLines {
+		if textLine.FitGroup == "" {
+			continue
+		}
+
+		fontSize, _ := getTextLineFontSize(textLine, int(dpi), fontScale)
+		scale := fitFontSize(textLine.fontRef(), textLine.Text, fontSize, dpi, area.maxLength(textLine)) / fontSize
+
+		if current, ok := scales[textLine.FitGroup]; !ok || scale < current {
+			scales[textLine.FitGroup] = scale
+		}
+	}
+	return scales
+}
+
+// fittedFontSize returns the font size a text line is drawn at: scaled by its
+// fit group's factor, or shrunk on its own to fit the length available to it
+func fittedFontSize(textLine TextLine, area textArea, groupScales map[string]float64, dpi int, fontScale float64) float64 {
+	fontSize, _ := getTextLineFontSize(textLine, dpi, fontScale)
+	if scale, ok := groupScales[textLine.FitGroup]; ok {
+		return fontSize * scale
+	}
+	return fitFontSize(textLine.fontRef(), textLine.Text, fontSize, float64(dpi), area.maxLength(textLine))
+}
+
+// fitFontSize returns the font size at which text in the named font and its
+// fallbacks fits within maxWidth, reducing by 0.1 points at a time like
+// addTextLineRecursive.
+func fitFontSize(ref fontRef, text string, fontSize, dpi float64, maxWidth int) float64 {
+	runs, err := textRuns(ref, text)
+	if err != nil {
+		return fontSize
+	}
+
+	for fontSize > 0.1 {
+		if measureRuns(runs, fontSize, dpi) <= maxWidth {
+			break
+		}
+		fontSize -= 0.1
+	}
+	return fontSize
+}
+
+// TextMeasurement describes how a text line fits in the width available to it
+type TextMeasurement struct {
+	FontSize float64 // Font size in points after fitting
+	Width    int     // Rendered text width in pixels
+	Height   int     // Line height in pixels
+	Reduced  bool    // True when the font was shrunk to fit maxWidth
+}
+
+// MeasureText returns the font size and pixel box a text line is rendered
+// with. maxWidth is the width available for text in pixels at dpi, which is
+// the label width less its margins. Text that is too wide is shrunk exactly as
+// during generation, so UIs can warn about reduced text while users type.
+// Text is measured with the default FontScaling.
+func MeasureText(text string, size TextSize, dpi int, maxWidth int) (TextMeasurement, error) {
+	if dpi <= 0 {
+		return TextMeasurement{}, errorf(ErrInvalidDPI, "invalid dpi value: %d. Dpi must be positive", dpi)
+	}
+	if maxWidth <= 0 {
+		return TextMeasurement{}, errorf(ErrInvalidDimensions, "invalid text width: %d. Width must be positive", maxWidth)
+	}
+
+	layoutWidthMM := float64(maxWidth+labelMarginPixels*2) * 25.4 / float64(dpi)
+	fontSize, _ := getFontSize(size, dpi, defaultFontScaling.scale(layoutWidthMM))
+	fitted := fitFontSize(fontRef{}, text, fontSize, float64(dpi), maxWidth)
+
+	width, err := measureTextWidth(fontRef{}, text, fitted, float64(dpi))
+	if err != nil {
+		return TextMeasurement{}, fmt.Errorf("failed to load font: %w", err)
+	}
+
+	return TextMeasurement{
+		FontSize: fitted,
+		Width:    width,
+		Height:   int(calculateFontHeight(fontRef{}, fitted, dpi)),
+		Reduced:  fitted < fontSize,
+	}, nil
 }
 
 // addTextLineRecursive is the internal recursive function that handles text rendering
 // with automatic font size reduction if text doesn't fit.
-func addTextLineRecursive(img *image.RGBA, text string, centerX, baseY int, fontSize, fontHeight, dpi float64, position TextPosition) {
-	fontData, err := truetype.Parse(goregular.TTF)
+func addTextLineRecursive(img *image.RGBA, ref fontRef, text string, anchor textAnchor, baseY int, fontSize, fontHeight, dpi float64, position TextPosition, maxWidth int) {
+	// Measure text width at current font size
+	textWidth, err := measureTextWidth(ref, text, fontSize, dpi)
 	if err != nil {
 		return
 	}
 
-	// Measure text width at current font size
-	face := truetype.NewFace(fontData, &truetype.Options{
-		Size: fontSize,
-		DPI:  dpi,
-	})
-
-	textWidth := font.MeasureString(face, text).Ceil()
-
 	// If text is too wide, reduce font size and retry
-	maxWidth := img.Bounds().Dx() - labelMarginPixels*2
 	if textWidth > maxWidth {
-		newFontHeight := calculateFontHeight(fontSize-0.1, int(dpi))
-		addTextLineRecursive(img, text, centerX, baseY, fontSize-0.1, newFontHeight, dpi, position)
+		newFontHeight := calculateFontHeight(ref, fontSize-0.1, int(dpi))
+		addTextLineRecursive(img, ref, text, anchor, baseY, fontSize-0.1, newFontHeight, dpi, position, maxWidth)
 		return
 	}
 
 	// Draw the text
-	drawText(img, text, centerX, baseY, fontSize, fontHeight, dpi, position, color.Black)
+	drawText(img, ref, text, anchor, baseY, fontSize, fontHeight, dpi, position, color.Black)
 }
 
-// drawText renders the actual text on the image.
-func drawText(img *image.RGBA, text string, centerX, baseY int, fontSize, fontHeight, dpi float64, position TextPosition, col color.Color) {
-	fontData, _ := truetype.Parse(goregular.TTF)
+// drawText renders the actual text on the image in the named font, aligned to
+// the anchor. Characters the font lacks are drawn in the fallback fonts.
+func drawText(img *image.RGBA, ref fontRef, text string, anchor textAnchor, baseY int, fontSize, fontHeight, dpi float64, position TextPosition, col color.Color) {
+	runs, err := textRuns(ref, text)
+	if err != nil {
+		return
+	}
 
 	c := freetype.NewContext()
 	c.SetDPI(dpi)
-	c.SetFont(fontData)
 	c.SetFontSize(fontSize)
 	c.SetClip(img.Bounds())
 	c.SetDst(img)
 	c.SetSrc(image.NewUniform(col))
 
 	// Calculate text position
-	face := truetype.NewFace(fontData, &truetype.Options{
-		Size: fontSize,
-		DPI:  dpi,
-	})
-
-	textWidth := font.MeasureString(face, text).Ceil()
-	adjustedX := centerX - (textWidth / 2)
+	textWidth := measureRuns(runs, fontSize, dpi)
+	pt := freetype.Pt(anchor.left(textWidth), textBaselineY(baseY, fontHeight, position))
+	for _, run := range runs {
+		c.SetFont(run.font)
+		if pt, err = c.DrawString(run.text, pt); err != nil {
+			return
+		}
+	}
+}
 
-	// Adjust Y position based on text position (above/below barcode)
-	adjustedY := baseY
+// textBaselineY returns the baseline of a text line, adjusted from its base Y
+// based on its position above, below or beside the barcode. Side and
+// absolute lines hang from their base Y.
+func textBaselineY(baseY int, fontHeight float64, position TextPosition) int {
 	margin := int(fontHeight) / 2
 
 	if position == TextPositionAbove {
-		adjustedY = baseY - margin
+		return baseY - margin
 	} else if position == TextPositionBelow {
-		adjustedY = baseY + margin*2 + 5
+		return baseY + margin*2 + 5
+	} else if isSideTextPosition(position) || position == TextPositionAbsolute {
+		return baseY + int(fontHeight)*3/4
 	}
-
-	pt := freetype.Pt(adjustedX, adjustedY)
-	c.DrawString(text, pt)
+	return baseY
 }
@@ -1,23 +1,82 @@
 package barcode
 
 import (
-	"bytes"
-	"encoding/base64"
+	"encoding/binary"
+	"hash/crc32"
 	"image"
+	"image/color"
 	"image/png"
+	"io"
+	"math"
 
 	"simonwaldherr.de/go/zplgfa"
 )
 
-// imageToBase64 converts an image to a base64-encoded PNG string.
-// This allows the image to be easily transmitted in JSON or HTML data URLs.
-func imageToBase64(img image.Image) (string, error) {
-	var buf bytes.Buffer
-	err := png.Encode(&buf, img)
-	if err != nil {
-		return "", err
+// pngIHDREnd is the offset after the PNG signature and IHDR chunk, where
+// the pHYs chunk is inserted
+const pngIHDREnd = 8 + 12 + 13
+
+// writePNG encodes an image as a PNG to w, with a pHYs chunk after the IHDR
+// chunk recording the DPI so design tools import it at its physical size.
+// The chunk stores pixels per meter, since PNG has no per-inch unit.
+func writePNG(w io.Writer, img image.Image, dpi int) error {
+	pixelsPerMeter := uint32(math.Round(float64(dpi) / 0.0254))
+
+	chunk := make([]byte, 4+4+9+4)
+	binary.BigEndian.PutUint32(chunk[0:], 9)
+	copy(chunk[4:], "pHYs")
+	binary.BigEndian.PutUint32(chunk[8:], pixelsPerMeter)
+	binary.BigEndian.PutUint32(chunk[12:], pixelsPerMeter)
+	chunk[16] = 1 // Unit is the meter
+	binary.BigEndian.PutUint32(chunk[17:], crc32.ChecksumIEEE(chunk[4:17]))
+
+	return png.Encode(&pngChunkWriter{w: w, chunk: chunk}, img)
+}
+
+// pngChunkWriter passes an encoded PNG through, inserting a chunk once the
+// signature and IHDR chunk have been written
+type pngChunkWriter struct {
+	w       io.Writer
+	chunk   []byte // Nil once inserted
+	written int
+}
+
+func (p *pngChunkWriter) Write(data []byte) (int, error) {
+	if p.chunk == nil || p.written+len(data) < pngIHDREnd {
+		n, err := p.w.Write(data)
+		p.written += n
+		return n, err
+	}
+
+	head := pngIHDREnd - p.written
+	if n, err := p.w.Write(data[:head]); err != nil {
+		return n, err
+	}
+	if _, err := p.w.Write(p.chunk); err != nil {
+		return head, err
+	}
+	p.chunk = nil
+	n, err := p.w.Write(data[head:])
+	return head + n, err
+}
+
+// monochromePalette holds the two colours of a bilevel image, which the PNG
+// encoder writes with 1 bit per pixel
+var monochromePalette = color.Palette{color.Black, color.White}
+
+// toMonochrome thresholds img to a black and white paletted image, with the
+// same cut-off as the printer language graphics
+func toMonochrome(img *image.RGBA) *image.Paletted {
+	bounds := img.Bounds()
+	mono := image.NewPaletted(bounds, monochromePalette)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if color.GrayModel.Convert(img.RGBAAt(x, y)).(color.Gray).Y >= 128 {
+				mono.SetColorIndex(x, y, 1)
+			}
+		}
 	}
-	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+	return mono
 }
 
 // imageToZPL converts an image to ZPL (Zebra Programming Language) commands.
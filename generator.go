@@ -0,0 +1,176 @@
+package barcode
+
+import (
+	"context"
+	"image"
+	"io"
+
+	"github.com/golang/freetype/truetype"
+)
+
+// Generator generates labels with site-specific settings. The zero value
+// behaves exactly like GenerateBarcode. A Generator is safe for concurrent
+// use, so high-volume services build one at startup and share it.
+type Generator struct {
+	PostProcessors []PostProcessor // Applied in order to each rendered label before PNG and ZPL encoding
+	Features       Features        // Capabilities switched off for this deployment
+	Printer        PrinterProfile  // Printer settings for labels that leave them unset
+	OutputFormats  []OutputFormat  // Formats for labels that do not list their own
+
+	fonts map[string]*truetype.Font // Fonts from GeneratorConfig, used before the registry
+}
+
+// PrinterProfile describes the printer a Generator's labels are printed on.
+// Zero fields leave the label's own settings alone.
+type PrinterProfile struct {
+	Dpi                 int               // Resolution for labels without a Dpi
+	AllowNonStandardDPI bool              // Dpi is outside the standard thermal printer values
+	UpsideDown          bool              // The printer is mounted inverted
+	BarWidthReduction   BarWidthReduction // Bar width reduction for labels without one, to offset this printer's bleed
+}
+
+// validatePrinterProfile ensures a profile's settings would be accepted on a
+// label, so a bad site configuration fails when the generator is built
+func validatePrinterProfile(profile PrinterProfile) error {
+	if profile.Dpi != 0 {
+		validate := validateDPI
+		if profile.AllowNonStandardDPI {
+			validate = validateNonStandardDPI
+		}
+		if err := validate(profile.Dpi); err != nil {
+			return err
+		}
+	}
+	return validateBarWidthReduction(profile.BarWidthReduction)
+}
+
+// applyDefaults gives a label the generator's printer settings and output
+// formats where it leaves them unset, and its text lines the generator's
+// fonts
+func (g *Generator) applyDefaults(input BarcodeInput) BarcodeInput {
+	if input.Dpi == 0 && g.Printer.Dpi != 0 {
+		input.Dpi, input.AllowNonStandardDPI = g.Printer.Dpi, g.Printer.AllowNonStandardDPI
+	}
+	if g.Printer.UpsideDown {
+		input.UpsideDown = true
+	}
+	if input.BarWidthReduction == (BarWidthReduction{}) {
+		input.BarWidthReduction = g.Printer.BarWidthReduction
+	}
+	if len(input.OutputFormats) == 0 {
+		input.OutputFormats = g.OutputFormats
+	}
+	if len(g.fonts) > 0 {
+		textLines := make([]TextLine, len(input.TextLines))
+		for i, textLine := range input.TextLines {
+			textLine.font = g.fonts[textLine.FontName]
+			textLines[i] = textLine
+		}
+		input.TextLines = textLines
+	}
+	return input
+}
+
+// Features switches off optional capabilities per deployment, so platform
+// teams can roll risky ones out gradually across printer fleets. The zero
+// value enables everything. Labels that request a disabled capability fall
+// back to the established output instead of failing.
+type Features struct {
+	DisableNativeZPL   bool // Rasterize barcodes in ZPL instead of using native barcode commands
+	DisableStyledQR    bool // Render QR codes without logos or styling
+	DisableColorOutput bool // Render black on white regardless of requested colors
+}
+
+// apply switches off the disabled capabilities requested by a label
+func (f Features) apply(input BarcodeInput) BarcodeInput {
+	if f.DisableNativeZPL {
+		input.ZPL.NativeCommands = false
+	}
+	if f.DisableStyledQR {
+		input.QR.Logo, input.QR.LogoPNG = nil, nil
+	}
+	return input
+}
+
+// GeneratorConfig describes a Generator, e.g. as loaded from a JSON site
+// configuration file
+type GeneratorConfig struct {
+	PostProcessors []PostProcessorConfig
+	Features       Features
+	Printer        PrinterProfile
+	OutputFormats  []OutputFormat
+	Fonts          map[string][]byte // TrueType fonts by name for this generator's text lines, base64 in JSON
+}
+
+// NewGenerator builds a Generator from its configuration. Its settings are
+// validated and its fonts parsed once here rather than for every label, and
+// the label font is loaded so the first label is not delayed. The fonts are
+// held by the generator: text lines of its labels find them by name before
+// the fonts added with RegisterFont, and other generators do not see them.
+func NewGenerator(config GeneratorConfig) (*Generator, error) {
+	processors, err := NewPostProcessors(config.PostProcessors)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePrinterProfile(config.Printer); err != nil {
+		return nil, err
+	}
+	if err := validateOutputFormats(config.OutputFormats); err != nil {
+		return nil, err
+	}
+	fonts := make(map[string]*truetype.Font, len(config.Fonts))
+	for name, data := range config.Fonts {
+		parsed, err := parseFont(name, data)
+		if err != nil {
+			return nil, err
+		}
+		fonts[name] = parsed
+	}
+	if err := Warm(); err != nil {
+		return nil, err
+	}
+	return &Generator{
+		PostProcessors: processors,
+		Features:       config.Features,
+		Printer:        config.Printer,
+		OutputFormats:  config.OutputFormats,
+		fonts:          fonts,
+	}, nil
+}
+
+// Generate creates a barcode label like GenerateBarcode with the generator's
+// printer settings and output formats, then applies its post-processors to
+// the print and preview images.
+func (g *Generator) Generate(input BarcodeInput) (*BarcodeOutput, error) {
+	return generateBarcode(context.Background(), input, g)
+}
+
+// GenerateCtx creates a barcode label like Generate, but stops between
+// pipeline stages once ctx is done
+func (g *Generator) GenerateCtx(ctx context.Context, input BarcodeInput) (*BarcodeOutput, error) {
+	return generateBarcode(ctx, input, g)
+}
+
+// GenerateImage renders a label like GenerateBarcodeImage, then applies the
+// generator's post-processors to the image.
+func (g *Generator) GenerateImage(input BarcodeInput) (image.Image, LabelLayout, error) {
+	return generateBarcodeImage(context.Background(), input, g)
+}
+
+// GenerateImageCtx renders a label like GenerateImage, but stops between
+// pipeline stages once ctx is done
+func (g *Generator) GenerateImageCtx(ctx context.Context, input BarcodeInput) (image.Image, LabelLayout, error) {
+	return generateBarcodeImage(ctx, input, g)
+}
+
+// GenerateTo writes one format of a label to w like GenerateBarcodeTo, with
+// the generator's post-processors and features applied.
+func (g *Generator) GenerateTo(w io.Writer, input BarcodeInput, format OutputFormat) error {
+	return generateBarcodeTo(context.Background(), w, input, format, g)
+}
+
+// GenerateToCtx writes one format of a label to w like GenerateTo, but stops
+// between pipeline stages once ctx is done
+func (g *Generator) GenerateToCtx(ctx context.Context, w io.Writer, input BarcodeInput, format OutputFormat) error {
+	return generateBarcodeTo(ctx, w, input, format, g)
+}
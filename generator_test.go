@@ -0,0 +1,138 @@
+package barcode
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gomono"
+)
+
+// TestNewGenerator_Features verifies feature flags load from a site configuration file
+func TestNewGenerator_Features(t *testing.T) {
+	var config GeneratorConfig
+	require.NoError(t, json.Unmarshal([]byte(`{"Features": {"DisableNativeZPL": true, "DisableColorOutput": true}}`), &config))
+
+	generator, err := NewGenerator(config)
+	require.NoError(t, err)
+	assert.Equal(t, Features{DisableNativeZPL: true, DisableColorOutput: true}, generator.Features)
+}
+
+// TestGenerator_ZeroValue verifies the zero value matches GenerateBarcode
+func TestGenerator_ZeroValue(t *testing.T) {
+	input := BarcodeInput{BarcodeData: "LOC-A1-B2-C3", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203}
+
+	expected, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	output, err := (&Generator{}).Generate(input)
+	require.NoError(t, err)
+	assert.Equal(t, expected, output)
+}
+
+// TestGenerator_PrinterProfile verifies the profile and output formats fill
+// in what a label leaves unset and do not override what it sets
+func TestGenerator_PrinterProfile(t *testing.T) {
+	generator, err := NewGenerator(GeneratorConfig{
+		Printer:       PrinterProfile{Dpi: 300, UpsideDown: true, BarWidthReduction: BarWidthReduction{Dots: 1}},
+		OutputFormats: []OutputFormat{OutputFormatZPL},
+	})
+	require.NoError(t, err)
+
+	input := BarcodeInput{BarcodeData: "LOC-A1-B2-C3", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25}
+	output, err := generator.Generate(input)
+	require.NoError(t, err)
+	expectedInput := input
+	expectedInput.Dpi, expectedInput.UpsideDown, expectedInput.BarWidthReduction = 300, true, BarWidthReduction{Dots: 1}
+	expectedInput.OutputFormats = []OutputFormat{OutputFormatZPL}
+	expected, err := GenerateBarcode(expectedInput)
+	require.NoError(t, err)
+	assert.Equal(t, expected, output)
+	assert.Empty(t, output.ImageBase64)
+
+	input.Dpi = 203
+	input.OutputFormats = []OutputFormat{OutputFormatPNG}
+	output, err = generator.Generate(input)
+	require.NoError(t, err)
+	assert.NotEmpty(t, output.ImageBase64)
+	assert.Empty(t, output.ZPL)
+	assert.Equal(t, 203, output.Layout.Dpi)
+}
+
+// TestNewGenerator_InvalidConfig verifies configuration problems are
+// reported when the generator is built
+func TestNewGenerator_InvalidConfig(t *testing.T) {
+	_, err := NewGenerator(GeneratorConfig{Printer: PrinterProfile{Dpi: 400}})
+	assert.ErrorContains(t, err, "invalid dpi value: 400")
+
+	_, err = NewGenerator(GeneratorConfig{Printer: PrinterProfile{Dpi: 400, AllowNonStandardDPI: true}})
+	assert.NoError(t, err)
+
+	_, err = NewGenerator(GeneratorConfig{OutputFormats: []OutputFormat{"SVG"}})
+	assert.ErrorContains(t, err, "invalid output format: SVG")
+
+	_, err = NewGenerator(GeneratorConfig{Fonts: map[string][]byte{"Broken": []byte("not a font")}})
+	assert.ErrorContains(t, err, `invalid font "Broken"`)
+}
+
+// TestNewGenerator_Fonts verifies configured fonts are parsed once and
+// available to text lines
+func TestNewGenerator_Fonts(t *testing.T) {
+	generator, err := NewGenerator(GeneratorConfig{Fonts: map[string][]byte{"generator-mono": gomono.TTF}})
+	require.NoError(t, err)
+	_, err = generator.Generate(BarcodeInput{
+		BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203,
+		TextLines: []TextLine{{Text: "LOC-A1", Position: TextPositionBelow, Size: TextSizeSmall, FontName: "generator-mono"}},
+	})
+	assert.NoError(t, err)
+}
+
+// TestNewGenerator_FontsPerGenerator verifies generators keep their fonts to
+// themselves, so two sites can use the same name for different fonts
+func TestNewGenerator_FontsPerGenerator(t *testing.T) {
+	mono, err := NewGenerator(GeneratorConfig{Fonts: map[string][]byte{"brand": gomono.TTF}})
+	require.NoError(t, err)
+	bold, err := NewGenerator(GeneratorConfig{Fonts: map[string][]byte{"brand": gobold.TTF}})
+	require.NoError(t, err)
+	_, err = textFont(fontRef{name: "brand"})
+	assert.ErrorContains(t, err, `invalid font name: "brand"`)
+
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203,
+		TextLines: []TextLine{{Text: "illicit", Position: TextPositionBelow, Size: TextSizeSmall, FontName: "brand"}},
+	}
+	_, monoLayout, err := mono.GenerateImage(input)
+	require.NoError(t, err)
+	_, boldLayout, err := bold.GenerateImage(input)
+	require.NoError(t, err)
+	assert.NotEqual(t, monoLayout.TextLines[0].Rect.Dx(), boldLayout.TextLines[0].Rect.Dx())
+
+	_, err = GenerateBarcode(input)
+	assert.ErrorContains(t, err, `invalid font name: "brand"`)
+}
+
+// cancelProcessor cancels a context when a label reaches post-processing
+type cancelProcessor struct{ cancel context.CancelFunc }
+
+func (p cancelProcessor) Process(img *image.RGBA, dpi int) *image.RGBA {
+	p.cancel()
+	return img
+}
+
+// TestGenerator_GenerateCtx verifies a label stops at the next stage once
+// its context is cancelled mid-render
+func TestGenerator_GenerateCtx(t *testing.T) {
+	input := BarcodeInput{BarcodeData: "LOC-A1-B2-C3", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203}
+	ctx, cancel := context.WithCancel(context.Background())
+	generator := &Generator{PostProcessors: []PostProcessor{cancelProcessor{cancel}}}
+	output, err := generator.GenerateCtx(ctx, input)
+	assert.Nil(t, output)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorContains(t, err, "label generation stopped")
+
+	_, err = generator.GenerateCtx(context.Background(), input)
+	assert.NoError(t, err)
+}
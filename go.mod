@@ -7,6 +7,7 @@ require (
 	github.com/golang/freetype v0.0.0-20170609003375-371a4ebc86f8
 	github.com/stretchr/testify v1.9.0
 	golang.org/x/image v0.15.0
+	gopkg.in/yaml.v3 v3.0.1
 	simonwaldherr.de/go/zplgfa v0.0.0-20220610162202-d0a6abccac01
 )
 
@@ -14,5 +15,4 @@ require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/stretchr/objx v0.5.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,120 @@
+package barcode
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"regexp"
+)
+
+// labelGraphicNamePattern matches graphic names, which are also their file
+// names in printer memory
+var labelGraphicNamePattern = regexp.MustCompile(`^[A-Z0-9_]{1,8}$`)
+
+// LabelGraphic is static artwork, such as a company logo or a compliance
+// mark, drawn at a fixed position on the label
+type LabelGraphic struct {
+	Name     string  // Identifies the graphic and names it in printer memory (1-8 capital letters, digits or underscores)
+	PNG      []byte  // PNG image, thresholded to black and white
+	XMM      float64 // Left edge, in millimeters from the left of the label
+	YMM      float64 // Top edge, in millimeters from the top of the label
+	WidthMM  float64 // Printed width in millimeters; the height keeps the aspect ratio
+	HeightMM float64 // Optional maximum printed height; taller images are scaled down to it, keeping the aspect ratio
+}
+
+// validateLabelGraphics ensures each graphic has a unique name, a readable
+// PNG and a position on the label
+func validateLabelGraphics(input BarcodeInput) error {
+	names := make(map[string]bool, len(input.Graphics))
+	for _, graphic := range input.Graphics {
+		if err := validateLabelGraphic(graphic); err != nil {
+			return err
+		}
+		if names[graphic.Name] {
+			return fmt.Errorf("invalid label graphic: %q is used more than once", graphic.Name)
+		}
+		names[graphic.Name] = true
+
+		if graphic.XMM+graphic.WidthMM > input.Width || graphic.YMM >= input.Height || graphic.YMM+graphic.HeightMM > input.Height {
+			return fmt.Errorf("invalid label graphic: %q at %gx%gmm does not fit on a %gx%gmm label", graphic.Name, graphic.XMM, graphic.YMM, input.Width, input.Height)
+		}
+	}
+	return nil
+}
+
+// validateLabelGraphic checks a graphic independently of the label it is placed on
+func validateLabelGraphic(graphic LabelGraphic) error {
+	if !labelGraphicNamePattern.MatchString(graphic.Name) {
+		return fmt.Errorf("invalid label graphic name: %q. Use 1-8 capital letters, digits or underscores", graphic.Name)
+	}
+	if graphic.WidthMM <= 0 || graphic.XMM < 0 || graphic.YMM < 0 {
+		return fmt.Errorf("invalid label graphic: %q needs a positive width and a position inside the label", graphic.Name)
+	}
+	if graphic.HeightMM < 0 {
+		return fmt.Errorf("invalid label graphic: %q has a height of %gmm. Height must not be negative", graphic.Name, graphic.HeightMM)
+	}
+	if _, err := png.DecodeConfig(bytes.NewReader(graphic.PNG)); err != nil {
+		return fmt.Errorf("invalid label graphic: %q: %w", graphic.Name, err)
+	}
+	return nil
+}
+
+// rasterizeLabelGraphic scales a graphic to its printed size at the DPI with
+// nearest-neighbour sampling and thresholds it to black on white. The size is
+// WidthMM wide, or narrower when the image would be taller than HeightMM.
+// Transparent pixels are white.
+func rasterizeLabelGraphic(graphic LabelGraphic, dpi int) (*image.RGBA, error) {
+	src, err := png.Decode(bytes.NewReader(graphic.PNG))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode label graphic %q: %w", graphic.Name, err)
+	}
+
+	bounds := src.Bounds()
+	width := max(1, mmToPixels(graphic.WidthMM, dpi))
+	if maxHeight := mmToPixels(graphic.HeightMM, dpi); maxHeight > 0 && width*bounds.Dy()/bounds.Dx() > maxHeight {
+		width = max(1, maxHeight*bounds.Dx()/bounds.Dy())
+	}
+	height := max(1, width*bounds.Dy()/bounds.Dx())
+	img := createBlankLabel(width, height)
+	for y := 0; y < height; y++ {
+		srcY := bounds.Min.Y + y*bounds.Dy()/height
+		for x := 0; x < width; x++ {
+			c := src.At(bounds.Min.X+x*bounds.Dx()/width, srcY)
+			gray := color.Gray16Model.Convert(c).(color.Gray16)
+			if _, _, _, alpha := c.RGBA(); alpha > 0x7FFF && gray.Y < 0x8000 {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+	return img, nil
+}
+
+// labelGraphicRect returns the area a rasterized graphic covers on the label
+func labelGraphicRect(graphic LabelGraphic, img *image.RGBA, dpi int) image.Rectangle {
+	origin := image.Pt(mmToPixels(graphic.XMM, dpi), mmToPixels(graphic.YMM, dpi))
+	return img.Bounds().Add(origin)
+}
+
+// drawLabelGraphics draws the dark pixels of each graphic onto the label,
+// leaving what is below its white pixels visible
+func drawLabelGraphics(label *image.RGBA, graphics []LabelGraphic, dpi int) error {
+	for _, graphic := range graphics {
+		img, err := rasterizeLabelGraphic(graphic, dpi)
+		if err != nil {
+			return err
+		}
+
+		rect := labelGraphicRect(graphic, img, dpi)
+		area := rect.Intersect(label.Bounds())
+		for y := area.Min.Y; y < area.Max.Y; y++ {
+			for x := area.Min.X; x < area.Max.X; x++ {
+				if img.RGBAAt(x-rect.Min.X, y-rect.Min.Y).R == 0 {
+					label.Set(x, y, color.Black)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package barcode
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testLogoPNG renders a 10x5 logo whose left half is black and right half transparent
+func testLogoPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 5))
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 5; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// TestRasterizeLabelGraphic verifies graphics are scaled to their width and transparent pixels are white
+func TestRasterizeLabelGraphic(t *testing.T) {
+	img, err := rasterizeLabelGraphic(LabelGraphic{Name: "LOGO", PNG: testLogoPNG(t), WidthMM: 10}, 203)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 79, 39), img.Bounds())
+	assert.Equal(t, color.RGBA{A: 255}, img.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, img.RGBAAt(78, 38))
+
+	img, err = rasterizeLabelGraphic(LabelGraphic{Name: "LOGO", PNG: testLogoPNG(t), WidthMM: 10, HeightMM: 2.5}, 203)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 38, 19), img.Bounds())
+}
+
+// TestValidateLabelGraphics verifies names, images and positions are checked
+func TestValidateLabelGraphics(t *testing.T) {
+	logo := LabelGraphic{Name: "LOGO", PNG: testLogoPNG(t), XMM: 2, YMM: 2, WidthMM: 10}
+	input := BarcodeInput{Width: 50, Height: 25}
+
+	tests := []struct {
+		name     string
+		graphics []LabelGraphic
+		message  string
+	}{
+		{"Valid", []LabelGraphic{logo}, ""},
+		{"Name", []LabelGraphic{{Name: "logo.png", PNG: logo.PNG, WidthMM: 10}}, "invalid label graphic name"},
+		{"Duplicate", []LabelGraphic{logo, logo}, "used more than once"},
+		{"Width", []LabelGraphic{{Name: "LOGO", PNG: logo.PNG}}, "positive width"},
+		{"Image", []LabelGraphic{{Name: "LOGO", PNG: []byte("GIF89a"), WidthMM: 10}}, "invalid label graphic"},
+		{"OffLabel", []LabelGraphic{{Name: "LOGO", PNG: logo.PNG, XMM: 45, WidthMM: 10}}, "does not fit"},
+		{"Height", []LabelGraphic{{Name: "LOGO", PNG: logo.PNG, WidthMM: 10, HeightMM: -1}}, "must not be negative"},
+		{"TooTall", []LabelGraphic{{Name: "LOGO", PNG: logo.PNG, YMM: 20, WidthMM: 10, HeightMM: 10}}, "does not fit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input.Graphics = tt.graphics
+			err := validateLabelGraphics(input)
+			if tt.message == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+// TestGenerateBarcode_Graphics verifies graphics are drawn on the label and stored graphics are recalled in ZPL
+func TestGenerateBarcode_Graphics(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:   "LOC-A1-B2-C3",
+		BarcodeType:   BarcodeTypeCode128,
+		Width:         50,
+		Height:        25,
+		Dpi:           203,
+		Graphics:      []LabelGraphic{{Name: "LOGO", PNG: testLogoPNG(t), XMM: 1, YMM: 1, WidthMM: 4}},
+		OutputFormats: []OutputFormat{OutputFormatZPL, OutputFormatTSPL},
+		ZPL:           ZPLOptions{NativeCommands: true, StoredGraphics: true},
+	}
+
+	bc, err := encodeBarcode(input)
+	require.NoError(t, err)
+	img, err := renderLabelImage(input, bc, input.Dpi)
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{A: 255}, img.RGBAAt(8, 8))
+
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Contains(t, output.ZPL, "^BCN,")
+	assert.Contains(t, output.ZPL, "^FO7,7^XGE:LOGO.GRF,1,1^FS\n")
+	assert.NotContains(t, output.ZPL, "^GF")
+	assert.Contains(t, output.TSPL, "BITMAP 0,0,50,199,0,")
+
+	input.ZPL.StoredGraphics = false
+	output, err = GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Contains(t, output.ZPL, "^FO7,7^GFA,")
+}
+
+// TestZPLGraphicDownload verifies the graphic is stored at its printed size with set bits as black
+func TestZPLGraphicDownload(t *testing.T) {
+	download, err := ZPLGraphicDownload(LabelGraphic{Name: "LOGO", PNG: testLogoPNG(t), WidthMM: 2}, 203)
+	require.NoError(t, err)
+
+	// 2mm at 203 DPI is 15 dots: 2 bytes per row and 7 rows, with the left 8 dots black
+	assert.True(t, strings.HasPrefix(download, "~DGE:LOGO.GRF,14,2,FF00FF00"))
+
+	_, err = ZPLGraphicDownload(LabelGraphic{Name: "LOGO", PNG: testLogoPNG(t), WidthMM: 2}, 150)
+	assert.Error(t, err)
+}
@@ -0,0 +1,147 @@
+package barcode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+)
+
+// gs1Element is a single Application Identifier and its value
+type gs1Element struct {
+	AI    string
+	Value string
+}
+
+// AI prefixes whose data fields have a predefined length and so never need an
+// FNC1 separator. Maps the first two AI digits to the total length (AI + data).
+var gs1PredefinedLengths = map[string]int{
+	"00": 20, "01": 16, "02": 16, "03": 16, "04": 18,
+	"11": 8, "12": 8, "13": 8, "14": 8, "15": 8, "16": 8, "17": 8, "18": 8, "19": 8,
+	"20": 4,
+	"31": 10, "32": 10, "33": 10, "34": 10, "35": 10, "36": 10,
+	"41": 16,
+}
+
+// parseGS1ElementString parses a GS1 element string in bracketed form,
+// e.g. "(01)09501101530003(3103)000123(10)ABC".
+func parseGS1ElementString(data string) ([]gs1Element, error) {
+	if !strings.HasPrefix(data, "(") {
+		return nil, fmt.Errorf("invalid GS1 data: %q. Data must start with a bracketed AI, e.g. (01)", data)
+	}
+
+	var elements []gs1Element
+	rest := data
+	for rest != "" {
+		end := strings.IndexByte(rest, ')')
+		if !strings.HasPrefix(rest, "(") || end < 0 {
+			return nil, fmt.Errorf("invalid GS1 data: %q. Unterminated AI bracket", data)
+		}
+
+		ai := rest[1:end]
+		if len(ai) < 2 || len(ai) > 4 || !isNumeric(ai) {
+			return nil, fmt.Errorf("invalid GS1 AI: (%s). AIs must be 2-4 digits", ai)
+		}
+
+		rest = rest[end+1:]
+		next := strings.IndexByte(rest, '(')
+		if next < 0 {
+			next = len(rest)
+		}
+		value := rest[:next]
+		rest = rest[next:]
+
+		if value == "" {
+			return nil, fmt.Errorf("invalid GS1 data: AI (%s) has no value", ai)
+		}
+		if total, ok := gs1PredefinedLengths[ai[:2]]; ok && len(ai)+len(value) != total {
+			return nil, fmt.Errorf("invalid GS1 data: AI (%s) requires %d data characters, got %d", ai, total-len(ai), len(value))
+		}
+
+		elements = append(elements, gs1Element{AI: ai, Value: value})
+	}
+
+	return elements, nil
+}
+
+// gs1NeedsSeparator reports whether an FNC1 must follow the element when
+// another element comes after it.
+func gs1NeedsSeparator(element gs1Element) bool {
+	_, predefined := gs1PredefinedLengths[element.AI[:2]]
+	return !predefined
+}
+
+// gs1CheckDigit calculates the GS1 mod-10 check digit for a string of digits
+// (GTIN, SSCC, GLN) excluding the check digit itself.
+func gs1CheckDigit(digits string) byte {
+	sum := 0
+	for i := range digits {
+		digit := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// validateGTIN ensures a 14-digit GTIN is numeric with a correct check digit
+func validateGTIN(gtin string) error {
+	if len(gtin) != 14 || !isNumeric(gtin) {
+		return fmt.Errorf("invalid GTIN: %q. GTIN must be 14 digits", gtin)
+	}
+	if expected := gs1CheckDigit(gtin[:13]); gtin[13] != expected {
+		return fmt.Errorf("invalid GTIN check digit: %q. Expected check digit %c", gtin, expected)
+	}
+	return nil
+}
+
+// AIs whose values end in a GS1 mod-10 check digit
+var gs1CheckDigitAIs = map[string]bool{"00": true, "01": true, "02": true}
+
+// validateGS1128Data ensures the data is a GS1 element string with valid
+// check digits on its SSCC and GTIN fields.
+func validateGS1128Data(data string) error {
+	elements, err := parseGS1ElementString(data)
+	if err != nil {
+		return err
+	}
+	for _, element := range elements {
+		if !gs1CheckDigitAIs[element.AI] {
+			continue
+		}
+		value := element.Value
+		if !isNumeric(value) {
+			return fmt.Errorf("invalid GS1 data: AI (%s) must contain only digits", element.AI)
+		}
+		if expected := gs1CheckDigit(value[:len(value)-1]); value[len(value)-1] != expected {
+			return fmt.Errorf("invalid GS1 check digit: (%s)%s. Expected check digit %c", element.AI, value, expected)
+		}
+	}
+	return nil
+}
+
+// encodeGS1128 creates a GS1-128 barcode from a bracketed GS1 element string.
+// The symbol starts with FNC1, and FNC1 separates variable-length fields.
+func encodeGS1128(data string) (barcode.Barcode, error) {
+	if err := validateGS1128Data(data); err != nil {
+		return nil, fmt.Errorf("failed to encode GS1-128 barcode: %w", err)
+	}
+	elements, _ := parseGS1ElementString(data)
+
+	var content strings.Builder
+	content.WriteRune(code128.FNC1)
+	for i, element := range elements {
+		content.WriteString(element.AI + element.Value)
+		if i < len(elements)-1 && gs1NeedsSeparator(element) {
+			content.WriteRune(code128.FNC1)
+		}
+	}
+
+	bc, err := code128.Encode(content.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode GS1-128 barcode: %w", err)
+	}
+	return bc, nil
+}
@@ -0,0 +1,119 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"slices"
+
+	"github.com/boombuler/barcode"
+)
+
+// EAN-13 module positions for the human-readable interpretation, from the
+// left of the main symbol
+const (
+	eanModules           = 95 // Main symbol, without an add-on
+	eanLeftDataStart     = 3  // After the left guard
+	eanLeftDataEnd       = 45 // Before the centre guard
+	eanRightDataStart    = 50 // After the centre guard
+	eanRightDataEnd      = 92 // Before the right guard
+	eanLeadingDigitWidth = 7  // Modules left of the symbol for the first digit
+	eanDigitBandModules  = 9  // Height of the digit band, in modules
+)
+
+// humanReadableTypes lists the linear types that can print their data below the symbol
+var humanReadableTypes = []BarcodeType{
+	BarcodeTypeCode128, BarcodeTypeGS1128, BarcodeTypeITF, BarcodeTypeTelepen,
+	BarcodeTypeTelepenNumeric, BarcodeTypeISBN, BarcodeTypeISSN,
+}
+
+// validateHumanReadable ensures the interpretation line is only requested
+// for linear types that have one
+func validateHumanReadable(input BarcodeInput) error {
+	if input.HumanReadable && !slices.Contains(humanReadableTypes, input.BarcodeType) {
+		return fmt.Errorf("invalid human-readable option: not supported for %s. Supported types: %v", input.BarcodeType, humanReadableTypes)
+	}
+	return nil
+}
+
+// isHumanReadableEAN reports whether the digits are drawn into an EAN-13
+// symbol between its guard bars
+func isHumanReadableEAN(input BarcodeInput) bool {
+	return input.HumanReadable && (input.BarcodeType == BarcodeTypeISBN || input.BarcodeType == BarcodeTypeISSN)
+}
+
+// humanReadableLine returns the interpretation line printed directly below
+// the other linear symbols: the data as encoded, including any check digit
+// the encoder adds, and GS1 data with its bracketed AIs
+func humanReadableLine(input BarcodeInput) (TextLine, bool) {
+	if !input.HumanReadable || isHumanReadableEAN(input) {
+		return TextLine{}, false
+	}
+	text := input.BarcodeData
+	if input.BarcodeType == BarcodeTypeITF {
+		if content, err := itfContent(input.BarcodeData, input.ITF); err == nil {
+			text = content
+		}
+	}
+	return TextLine{Text: text, Position: TextPositionBelow, Size: TextSizeSmall}, true
+}
+
+// humanReadableEAN is a scaled EAN-13 symbol with its digits drawn in the
+// standard positions: the first digit left of the symbol, six digits under
+// each half between the guard bars, which extend below the data bars, and
+// any add-on digits above the add-on.
+type humanReadableEAN struct {
+	barcode.Barcode // The unscaled symbol, for its metadata and content
+	img             *image.RGBA
+}
+
+func (bc *humanReadableEAN) ColorModel() color.Model { return bc.img.ColorModel() }
+func (bc *humanReadableEAN) Bounds() image.Rectangle { return bc.img.Bounds() }
+func (bc *humanReadableEAN) At(x, y int) color.Color { return bc.img.At(x, y) }
+
+// scaleEANWithHumanReadable scales an EAN-13 symbol to whole modules that fit
+// the size together with its leading digit, narrows its bars by the
+// reduction in pixels and draws its digits
+func scaleEANWithHumanReadable(bc barcode.Barcode, size image.Point, dpi, reduction int) (barcode.Barcode, error) {
+	modules := bc.Bounds().Dx()
+	moduleWidth := size.X / (modules + eanLeadingDigitWidth)
+	band := eanDigitBandModules * moduleWidth
+	if moduleWidth < 1 || size.Y <= band*2 {
+		return nil, errorf(ErrLabelTooSmall, "label too small: no room for the human-readable digits of the %s symbol", bc.Metadata().CodeKind)
+	}
+	scaled, err := barcode.Scale(bc, modules*moduleWidth, size.Y)
+	if err != nil {
+		return nil, err
+	}
+	scaled = reduceBarWidths(scaled, reduction)
+
+	img := createBlankLabel((modules+eanLeadingDigitWidth)*moduleWidth, size.Y)
+	symbolX := eanLeadingDigitWidth * moduleWidth
+	draw.Draw(img, scaled.Bounds().Add(image.Pt(symbolX, 0)), scaled, scaled.Bounds().Min, draw.Src)
+
+	// Shorten the data bars so the guard bars extend below them
+	white := &image.Uniform{color.White}
+	moduleX := func(module int) int { return symbolX + module*moduleWidth }
+	for _, data := range [][2]int{{eanLeftDataStart, eanLeftDataEnd}, {eanRightDataStart, eanRightDataEnd}} {
+		draw.Draw(img, image.Rect(moduleX(data[0]), size.Y-band, moduleX(data[1]), size.Y), white, image.Point{}, draw.Src)
+	}
+
+	// Digits fill most of the band, with their baseline just above its bottom
+	fontSize, fontHeight := float64(band)*72/float64(dpi), float64(band)
+	drawDigits := func(digits string, centerX, baseline int) {
+		drawText(img, fontRef{}, digits, textAnchor{TextAlignCenter, centerX}, baseline, fontSize, fontHeight, float64(dpi), "", color.Black)
+	}
+	content := bc.Content()
+	drawDigits(content[:1], symbolX/2, size.Y-band/8)
+	drawDigits(content[1:7], moduleX((eanLeftDataStart+eanLeftDataEnd)/2), size.Y-band/8)
+	drawDigits(content[7:13], moduleX((eanRightDataStart+eanRightDataEnd)/2), size.Y-band/8)
+
+	// Add-on digits are printed above the add-on, whose bars are shortened instead
+	if addOn := content[13:]; addOn != "" {
+		addOnStart := moduleX(eanModules + eanAddOnGap)
+		draw.Draw(img, image.Rect(addOnStart, 0, img.Bounds().Max.X, band), white, image.Point{}, draw.Src)
+		drawDigits(addOn[1:], (addOnStart+img.Bounds().Max.X)/2, band-band/8)
+	}
+	return &humanReadableEAN{Barcode: bc, img: img}, nil
+}
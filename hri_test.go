@@ -0,0 +1,71 @@
+package barcode
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestScaleEANWithHumanReadable verifies the symbol is scaled to whole
+// modules after the leading digit, with the guard bars extending below the
+// shortened data bars
+func TestScaleEANWithHumanReadable(t *testing.T) {
+	bc, err := encodeISBN("9780306406157", ISBNOptions{})
+	require.NoError(t, err)
+
+	scaled, err := scaleEANWithHumanReadable(bc, image.Pt(400, 150), 203, 0)
+	require.NoError(t, err)
+	moduleWidth := 400 / (eanModules + eanLeadingDigitWidth)
+	assert.Equal(t, image.Rect(0, 0, (eanModules+eanLeadingDigitWidth)*moduleWidth, 150), scaled.Bounds())
+	assert.Equal(t, bc.Content(), scaled.Content())
+
+	symbolX := eanLeadingDigitWidth * moduleWidth
+	bottom := 149
+	assert.True(t, isDark(scaled.At(symbolX, bottom)), "The left guard should reach the bottom")
+	assert.True(t, isDark(scaled.At(symbolX+46*moduleWidth, bottom)), "The centre guard should reach the bottom")
+	assert.True(t, isDark(scaled.At(symbolX, 0)))
+
+	for module := eanLeftDataStart; module < eanLeftDataEnd; module++ {
+		assert.False(t, isDark(scaled.At(symbolX+module*moduleWidth, bottom)), "module %d", module)
+	}
+
+	_, err = scaleEANWithHumanReadable(bc, image.Pt(90, 150), 203, 0)
+	assert.ErrorContains(t, err, "label too small")
+}
+
+// TestGenerateBarcode_HumanReadable verifies the data is printed as the
+// first line below Code128, while EAN-13 digits are drawn into the symbol
+// and sent to printers as a graphic
+func TestGenerateBarcode_HumanReadable(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:   "LOC-A1-B2",
+		BarcodeType:   BarcodeTypeCode128,
+		Width:         50.0,
+		Height:        25.0,
+		Dpi:           203,
+		HumanReadable: true,
+		TextLines:     []TextLine{{Text: "AISLE 1", Position: TextPositionBelow, Size: TextSizeMedium}},
+		ZPL:           ZPLOptions{NativeCommands: true},
+	}
+	lines := labelTextLines(input)
+	require.Len(t, lines, 2)
+	assert.Equal(t, TextLine{Text: "LOC-A1-B2", Position: TextPositionBelow, Size: TextSizeSmall}, lines[0])
+
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Contains(t, output.ZPL, "^FDLOC-A1-B2^FS")
+
+	input.BarcodeType, input.BarcodeData, input.TextLines = BarcodeTypeISBN, "978-0-306-40615-7", nil
+	assert.Empty(t, labelTextLines(input))
+	output, err = GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.NotContains(t, output.ZPL, "^BE")
+	assert.Contains(t, output.ZPL, "^GF")
+
+	input.BarcodeType, input.BarcodeData = BarcodeTypeQR, "LOC-A1"
+	_, err = GenerateBarcode(input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid human-readable option")
+}
@@ -0,0 +1,337 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math/big"
+
+	"github.com/boombuler/barcode"
+)
+
+// Intelligent Mail barcode (USPS-B-3200) constants
+const (
+	imbTrackingLength = 20
+	imbBarCount       = 65
+	imbCodeKind       = "Intelligent Mail"
+)
+
+// Supported IMb data lengths: 20-digit tracking code plus optional 5, 9 or 11 digit routing code
+var imbDataLengths = []int{20, 25, 29, 31}
+
+// imbBarTable maps each of the 65 bars to the character bits driving its
+// descender and ascender: {descender char, bit, ascender char, bit}.
+// Taken from USPS-B-3200 Appendix D, Table IV.
+var imbBarTable = [imbBarCount][4]uint8{
+	{7, 2, 4, 3}, {1, 10, 0, 0}, {9, 12, 2, 8}, {5, 5, 6, 11}, {8, 9, 3, 1},
+	{0, 1, 5, 12}, {2, 5, 1, 8}, {4, 4, 9, 11}, {6, 3, 8, 10}, {3, 9, 7, 6},
+	{5, 11, 1, 4}, {8, 5, 2, 12}, {9, 10, 0, 2}, {7, 1, 6, 7}, {3, 6, 4, 9},
+	{0, 3, 8, 6}, {6, 4, 2, 7}, {1, 1, 9, 9}, {7, 10, 5, 2}, {4, 0, 3, 8},
+	{6, 2, 0, 4}, {8, 11, 1, 0}, {9, 8, 3, 12}, {2, 6, 7, 7}, {5, 1, 4, 10},
+	{1, 12, 6, 9}, {7, 3, 8, 0}, {5, 8, 9, 7}, {4, 6, 2, 10}, {3, 4, 0, 5},
+	{8, 4, 5, 7}, {7, 11, 1, 9}, {6, 0, 9, 6}, {0, 6, 4, 8}, {2, 1, 3, 2},
+	{5, 9, 8, 12}, {4, 11, 6, 1}, {9, 5, 7, 4}, {3, 3, 1, 2}, {0, 7, 2, 0},
+	{1, 3, 4, 1}, {6, 10, 3, 5}, {8, 7, 9, 4}, {2, 11, 5, 6}, {0, 8, 7, 12},
+	{4, 2, 8, 1}, {5, 10, 3, 0}, {9, 3, 0, 9}, {6, 5, 2, 4}, {7, 8, 1, 7},
+	{5, 0, 4, 5}, {2, 3, 0, 10}, {6, 12, 9, 2}, {3, 11, 1, 6}, {8, 8, 7, 9},
+	{5, 4, 0, 11}, {1, 5, 2, 2}, {9, 1, 4, 12}, {8, 3, 6, 6}, {7, 0, 3, 7},
+	{4, 7, 7, 5}, {0, 12, 1, 11}, {2, 9, 9, 0}, {6, 8, 5, 3}, {3, 10, 8, 2},
+}
+
+// Character lookup tables from USPS-B-3200 Appendix D, Tables I and II
+var (
+	imbTable5of13 = buildNof13Table(5, 1287)
+	imbTable2of13 = buildNof13Table(2, 78)
+)
+
+// barState is the state of a single bar in a four-state barcode
+type barState byte
+
+const (
+	barTracker barState = iota
+	barAscender
+	barDescender
+	barFull
+)
+
+// fourStateBarcode is a height-modulated barcode such as IMb.
+// The unscaled symbol is one pixel per bar or gap and three pixels tall
+// (ascender, tracker and descender regions).
+type fourStateBarcode struct {
+	content string
+	kind    string
+	bars    []barState
+	rect    image.Rectangle
+}
+
+func (bc *fourStateBarcode) Content() string { return bc.content }
+
+func (bc *fourStateBarcode) Metadata() barcode.Metadata {
+	return barcode.Metadata{CodeKind: bc.kind, Dimensions: 2}
+}
+
+func (bc *fourStateBarcode) ColorModel() color.Model { return color.Gray16Model }
+
+func (bc *fourStateBarcode) Bounds() image.Rectangle { return bc.rect }
+
+// At maps the pixel onto a bar slot (bar followed by an equal width gap) and
+// a vertical third of the symbol, returning black where the bar extends.
+func (bc *fourStateBarcode) At(x, y int) color.Color {
+	slots := len(bc.bars)*2 - 1
+	unit := bc.rect.Dx() / slots
+	if unit == 0 {
+		return color.White
+	}
+	offsetX := (bc.rect.Dx() - unit*slots) / 2
+
+	slot := (x - offsetX) / unit
+	if x < offsetX || slot >= slots || slot%2 == 1 {
+		return color.White
+	}
+
+	third := y * 3 / bc.rect.Dy()
+	switch bc.bars[slot/2] {
+	case barFull:
+		return color.Black
+	case barAscender:
+		if third <= 1 {
+			return color.Black
+		}
+	case barDescender:
+		if third >= 1 {
+			return color.Black
+		}
+	case barTracker:
+		if third == 1 {
+			return color.Black
+		}
+	}
+	return color.White
+}
+
+// scale returns a copy of the barcode rendered at the given size.
+// Four-state codes are height-modulated, so the generic 1D scaler, which
+// samples a single row, cannot be used.
+func (bc *fourStateBarcode) scale(width, height int) (barcode.Barcode, error) {
+	minWidth := len(bc.bars)*2 - 1
+	if width < minWidth || height < 3 {
+		return nil, fmt.Errorf("can not scale barcode to an image smaller than %dx3", minWidth)
+	}
+	return &fourStateBarcode{
+		content: bc.content,
+		kind:    bc.kind,
+		bars:    bc.bars,
+		rect:    image.Rect(0, 0, width, height),
+	}, nil
+}
+
+// validateIMbData ensures the data is a 20-digit tracking code with an optional routing code
+func validateIMbData(data string) error {
+	if !isNumeric(data) {
+		return fmt.Errorf("invalid IMb data: %q. Tracking and routing codes must contain only digits", data)
+	}
+
+	validLength := false
+	for _, length := range imbDataLengths {
+		if len(data) == length {
+			validLength = true
+			break
+		}
+	}
+	if !validLength {
+		return fmt.Errorf("invalid IMb data length: %d. Supported lengths are: %v", len(data), imbDataLengths)
+	}
+
+	// The second digit of the barcode identifier is restricted to 0-4
+	if data[1] > '4' {
+		return fmt.Errorf("invalid IMb barcode identifier: %s. Second digit must be 0-4", data[:2])
+	}
+	return nil
+}
+
+// encodeIMb creates a USPS Intelligent Mail barcode from a tracking code
+// followed by an optional routing (ZIP) code.
+func encodeIMb(data string) (barcode.Barcode, error) {
+	if err := validateIMbData(data); err != nil {
+		return nil, fmt.Errorf("failed to encode IMb barcode: %w", err)
+	}
+
+	value := imbBinaryValue(data[:imbTrackingLength], data[imbTrackingLength:])
+	fcs := imbFrameCheckSequence(value)
+	codewords := imbCodewords(value, fcs)
+	characters := imbCharacters(codewords, fcs)
+
+	bars := make([]barState, imbBarCount)
+	for i, entry := range imbBarTable {
+		descender := characters[entry[0]]>>entry[1]&1 == 1
+		ascender := characters[entry[2]]>>entry[3]&1 == 1
+		switch {
+		case descender && ascender:
+			bars[i] = barFull
+		case descender:
+			bars[i] = barDescender
+		case ascender:
+			bars[i] = barAscender
+		default:
+			bars[i] = barTracker
+		}
+	}
+
+	return &fourStateBarcode{
+		content: data,
+		kind:    imbCodeKind,
+		bars:    bars,
+		rect:    image.Rect(0, 0, imbBarCount*2-1, 3),
+	}, nil
+}
+
+// imbBinaryValue packs the routing and tracking codes into the 102-bit data field.
+func imbBinaryValue(tracking, routing string) *big.Int {
+	value := new(big.Int)
+	if routing != "" {
+		value.SetString(routing, 10)
+		// Each routing length occupies its own range so they stay distinguishable
+		switch len(routing) {
+		case 5:
+			value.Add(value, big.NewInt(1))
+		case 9:
+			value.Add(value, big.NewInt(100000+1))
+		case 11:
+			value.Add(value, big.NewInt(1000000000+100000+1))
+		}
+	}
+
+	// The second tracking digit is base 5, all others are base 10
+	value.Mul(value, big.NewInt(10))
+	value.Add(value, big.NewInt(int64(tracking[0]-'0')))
+	value.Mul(value, big.NewInt(5))
+	value.Add(value, big.NewInt(int64(tracking[1]-'0')))
+	for _, digit := range tracking[2:] {
+		value.Mul(value, big.NewInt(10))
+		value.Add(value, big.NewInt(int64(digit-'0')))
+	}
+	return value
+}
+
+// imbFrameCheckSequence computes the 11-bit CRC over the 102-bit data field.
+func imbFrameCheckSequence(value *big.Int) uint16 {
+	const generator = 0x0F35
+	var data [13]byte
+	value.FillBytes(data[:])
+
+	fcs := uint16(0x07FF)
+	step := func(bits uint16, count int) {
+		for i := 0; i < count; i++ {
+			if (fcs^bits)&0x400 != 0 {
+				fcs = (fcs << 1) ^ generator
+			} else {
+				fcs <<= 1
+			}
+			fcs &= 0x7FF
+			bits <<= 1
+		}
+	}
+
+	// Only the low 6 bits of the first byte are part of the data field
+	step(uint16(data[0])<<5, 6)
+	for _, b := range data[1:] {
+		step(uint16(b)<<3, 8)
+	}
+	return fcs
+}
+
+// imbCodewords splits the data field into the ten codewords A-J.
+func imbCodewords(value *big.Int, fcs uint16) [10]int {
+	var codewords [10]int
+	remaining := new(big.Int).Set(value)
+	mod := new(big.Int)
+
+	remaining.DivMod(remaining, big.NewInt(636), mod)
+	codewords[9] = int(mod.Int64())
+	for i := 8; i >= 1; i-- {
+		remaining.DivMod(remaining, big.NewInt(1365), mod)
+		codewords[i] = int(mod.Int64())
+	}
+	codewords[0] = int(remaining.Int64())
+
+	// Codeword J carries the orientation bit, codeword A the top FCS bit
+	codewords[9] *= 2
+	if fcs&0x400 != 0 {
+		codewords[0] += 659
+	}
+	return codewords
+}
+
+// imbCharacters converts codewords to 13-bit characters, inverting each one
+// whose corresponding FCS bit is set.
+func imbCharacters(codewords [10]int, fcs uint16) [10]uint16 {
+	var characters [10]uint16
+	for i, codeword := range codewords {
+		if codeword < len(imbTable5of13) {
+			characters[i] = imbTable5of13[codeword]
+		} else {
+			characters[i] = imbTable2of13[codeword-len(imbTable5of13)]
+		}
+		if fcs&(1<<uint(i)) != 0 {
+			characters[i] = ^characters[i] & 0x1FFF
+		}
+	}
+	return characters
+}
+
+// buildNof13Table generates the table of 13-bit characters with exactly n bits set.
+// Characters are stored alongside their bit-reversal, with palindromes at the end.
+func buildNof13Table(n, length int) []uint16 {
+	table := make([]uint16, length)
+	lower, upper := 0, length-1
+
+	for count := uint16(0); count < 1<<13; count++ {
+		if bitCount(count) != n {
+			continue
+		}
+		reverse := reverse13(count)
+		if reverse < count {
+			continue
+		}
+		if reverse == count {
+			table[upper] = count
+			upper--
+		} else {
+			table[lower] = count
+			table[lower+1] = reverse
+			lower += 2
+		}
+	}
+	return table
+}
+
+// bitCount returns the number of set bits
+func bitCount(v uint16) int {
+	count := 0
+	for ; v != 0; v &= v - 1 {
+		count++
+	}
+	return count
+}
+
+// reverse13 reverses the low 13 bits
+func reverse13(v uint16) uint16 {
+	var reversed uint16
+	for i := 0; i < 13; i++ {
+		reversed = reversed<<1 | v>>uint(i)&1
+	}
+	return reversed
+}
+
+// isNumeric reports whether s is non-empty and contains only ASCII digits
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package barcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// imbBarString renders the bar states using the USPS letters (T, A, D, F)
+func imbBarString(bc *fourStateBarcode) string {
+	var sb strings.Builder
+	for _, bar := range bc.bars {
+		sb.WriteByte("TADF"[bar])
+	}
+	return sb.String()
+}
+
+// TestEncodeIMb_SpecExamples verifies the encoder against USPS-B-3200 Appendix C
+func TestEncodeIMb_SpecExamples(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"NoRouting", "01234567094987654321", "ATTFATTDTTADTAATTDTDTATTDAFDDFADFDFTFFFFFTATFAAAATDFFTDAADFTFDTDT"},
+		{"ZIP5", "0123456709498765432101234", "DTTAFADDTTFTDTFTFDTDDADADAFADFATDDFTAAAFDTTADFAAATDFDTDFADDDTDFFT"},
+		{"ZIP9", "01234567094987654321012345678", "ADFTTAFDTTTTFATTADTAAATFTFTATDAAAFDDADATATDTDTTDFDTDATADADTDFFTFA"},
+		{"ZIP11", "0123456709498765432101234567891", "AADTFFDFTDADTAADAATFDTDDAAADDTDTTDAFADADDDTFFFDDTTTADFAAADFTDAADA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bc, err := encodeIMb(tt.data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, imbBarString(bc.(*fourStateBarcode)))
+		})
+	}
+}
+
+// TestValidateIMbData_Invalid ensures malformed tracking/routing codes are rejected
+func TestValidateIMbData_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		expectedErr string
+	}{
+		{"TooShort", "0123456709498765432", "invalid IMb data length"},
+		{"BadRoutingLength", "012345670949876543210123", "invalid IMb data length"},
+		{"NonNumeric", "0123456709498765432A", "must contain only digits"},
+		{"BadIdentifier", "05234567094987654321", "invalid IMb barcode identifier"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateIMbData(tt.data)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+// TestGenerateBarcode_IMb_Success verifies IMb renders on a thermal label
+func TestGenerateBarcode_IMb_Success(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "0123456709498765432101234",
+		BarcodeType: BarcodeTypeIMb,
+		Width:       100.0,
+		Height:      30.0,
+		Dpi:         203,
+		TextLines: []TextLine{
+			{
+				Text:     "01234-5678",
+				Position: TextPositionBelow,
+				Size:     TextSizeSmall,
+			},
+		},
+	}
+
+	output, err := GenerateBarcode(input)
+
+	require.NoError(t, err, "Should successfully generate IMb barcode")
+	assert.NotEmpty(t, output.ImageBase64, "Image base64 should not be empty")
+	assert.Contains(t, output.ZPL, "^XA", "ZPL should contain valid ZPL commands")
+}
+
+// TestFourStateBarcode_Scale verifies bar heights survive scaling
+func TestFourStateBarcode_Scale(t *testing.T) {
+	bc, err := encodeIMb("01234567094987654321")
+	require.NoError(t, err)
+
+	scaled, err := scaleBarcodeToFit(bc, calculateIMbSize(300, 2000, 400, labelMarginPixels))
+	require.NoError(t, err)
+
+	bounds := scaled.Bounds()
+	assert.Equal(t, mmToPixels(imbLengthMM, 300), bounds.Dx())
+	assert.Equal(t, mmToPixels(imbBarHeightMM, 300), bounds.Dy())
+
+	// The first bar is an ascender: black at the top, white at the bottom
+	unit := bounds.Dx() / (imbBarCount*2 - 1)
+	offsetX := (bounds.Dx() - unit*(imbBarCount*2-1)) / 2
+	r, _, _, _ := scaled.At(offsetX, 0).RGBA()
+	assert.Equal(t, uint32(0), r, "Ascender should be black at the top")
+	r, _, _, _ = scaled.At(offsetX, bounds.Dy()-1).RGBA()
+	assert.NotEqual(t, uint32(0), r, "Ascender should be white at the bottom")
+}
@@ -0,0 +1,170 @@
+package barcode
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+
+	"github.com/boombuler/barcode"
+)
+
+// importedCodeKind identifies barcodes supplied as images by external systems
+const importedCodeKind = "Image"
+
+// importedBarcode is a pre-rendered symbol reduced to its module grid.
+// It scales itself so modules stay square and evenly sized.
+type importedBarcode struct {
+	modules []bool // Dark modules, row by row
+	width   int
+	height  int
+	content string
+}
+
+func (bc *importedBarcode) Content() string {
+	return bc.content
+}
+
+func (bc *importedBarcode) Metadata() barcode.Metadata {
+	return barcode.Metadata{CodeKind: importedCodeKind, Dimensions: 2}
+}
+
+func (bc *importedBarcode) ColorModel() color.Model {
+	return color.Gray16Model
+}
+
+func (bc *importedBarcode) Bounds() image.Rectangle {
+	return image.Rect(0, 0, bc.width, bc.height)
+}
+
+func (bc *importedBarcode) At(x, y int) color.Color {
+	if x >= 0 && y >= 0 && x < bc.width && y < bc.height && bc.modules[y*bc.width+x] {
+		return color.Black
+	}
+	return color.White
+}
+
+// scale enlarges the modules by the largest whole factor that fits. Symbols
+// larger than the area are resampled to fit, keeping their aspect ratio.
+func (bc *importedBarcode) scale(width, height int) (barcode.Barcode, error) {
+	factor := min(width/bc.width, height/bc.height)
+	if factor >= 1 {
+		return bc.resample(bc.width*factor, bc.height*factor), nil
+	}
+
+	ratio := min(float64(width)/float64(bc.width), float64(height)/float64(bc.height))
+	scaledWidth, scaledHeight := int(float64(bc.width)*ratio), int(float64(bc.height)*ratio)
+	if scaledWidth < 1 || scaledHeight < 1 {
+		return nil, fmt.Errorf("can not scale barcode to an image smaller than %dx%d", width, height)
+	}
+	return bc.resample(scaledWidth, scaledHeight), nil
+}
+
+// resample returns the symbol at the given size using nearest-neighbour sampling
+func (bc *importedBarcode) resample(width, height int) *importedBarcode {
+	modules := make([]bool, width*height)
+	for y := 0; y < height; y++ {
+		srcY := y * bc.height / height
+		for x := 0; x < width; x++ {
+			modules[y*width+x] = bc.modules[srcY*bc.width+x*bc.width/width]
+		}
+	}
+	return &importedBarcode{modules: modules, width: width, height: height, content: bc.content}
+}
+
+// validateBarcodeImage ensures an image is supplied for IMAGE barcodes only
+// and is a readable PNG
+func validateBarcodeImage(input BarcodeInput) error {
+	if input.BarcodeType != BarcodeTypeImage {
+		if len(input.BarcodeImage) > 0 {
+			return fmt.Errorf("invalid barcode image: images are only used with barcode type %s", BarcodeTypeImage)
+		}
+		return nil
+	}
+	if len(input.BarcodeImage) == 0 {
+		return fmt.Errorf("invalid barcode image: BarcodeImage is required for barcode type %s", BarcodeTypeImage)
+	}
+	if _, err := png.DecodeConfig(bytes.NewReader(input.BarcodeImage)); err != nil {
+		return fmt.Errorf("invalid barcode image: %w", err)
+	}
+	return nil
+}
+
+// encodeImportedBarcode decodes a PNG symbol, crops its quiet zone and
+// reduces it to one pixel per module
+func encodeImportedBarcode(data []byte, content string) (barcode.Barcode, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode barcode image: %w", err)
+	}
+
+	dark := func(x, y int) bool {
+		gray := color.Gray16Model.Convert(img.At(x, y)).(color.Gray16)
+		_, _, _, alpha := img.At(x, y).RGBA()
+		return alpha > 0x7FFF && gray.Y < 0x8000
+	}
+
+	// Crop to the dark modules, dropping the quiet zone
+	bounds := img.Bounds()
+	crop := image.Rectangle{}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if dark(x, y) {
+				crop = crop.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	if crop.Empty() {
+		return nil, fmt.Errorf("failed to decode barcode image: image contains no dark modules")
+	}
+
+	pixels := make([]bool, crop.Dx()*crop.Dy())
+	for y := 0; y < crop.Dy(); y++ {
+		for x := 0; x < crop.Dx(); x++ {
+			pixels[y*crop.Dx()+x] = dark(crop.Min.X+x, crop.Min.Y+y)
+		}
+	}
+
+	bc := &importedBarcode{modules: pixels, width: crop.Dx(), height: crop.Dy(), content: content}
+	if moduleSize := importedModuleSize(bc); moduleSize > 1 {
+		bc = bc.resample(bc.width/moduleSize, bc.height/moduleSize)
+	}
+	return bc, nil
+}
+
+// importedModuleSize returns the pixels per module: the greatest common
+// divisor of every run of same-coloured pixels. Images that were resampled
+// by the sender share no divisor and are kept at one module per pixel.
+func importedModuleSize(bc *importedBarcode) int {
+	size := 0
+	addRun := func(run int) {
+		for run != 0 {
+			size, run = run, size%run
+		}
+	}
+
+	for y := 0; y < bc.height; y++ {
+		run := 1
+		for x := 1; x <= bc.width; x++ {
+			if x < bc.width && bc.modules[y*bc.width+x] == bc.modules[y*bc.width+x-1] {
+				run++
+				continue
+			}
+			addRun(run)
+			run = 1
+		}
+	}
+	for x := 0; x < bc.width; x++ {
+		run := 1
+		for y := 1; y <= bc.height; y++ {
+			if y < bc.height && bc.modules[y*bc.width+x] == bc.modules[(y-1)*bc.width+x] {
+				run++
+				continue
+			}
+			addRun(run)
+			run = 1
+		}
+	}
+	return size
+}
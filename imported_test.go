@@ -0,0 +1,109 @@
+package barcode
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSymbolPNG renders a 3x3 checkerboard of 4px modules inside an 8px quiet zone
+func testSymbolPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, 28, 28))
+	for y := 0; y < 28; y++ {
+		for x := 0; x < 28; x++ {
+			img.SetGray(x, y, color.Gray{Y: 255})
+			mx, my := x-8, y-8
+			if mx >= 0 && my >= 0 && mx < 12 && my < 12 && (mx/4+my/4)%2 == 0 {
+				img.SetGray(x, y, color.Gray{Y: 0})
+			}
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// TestEncodeImportedBarcode verifies the quiet zone is cropped and modules reduced to one pixel
+func TestEncodeImportedBarcode(t *testing.T) {
+	bc, err := encodeImportedBarcode(testSymbolPNG(t), "PARTNER-123")
+	require.NoError(t, err)
+
+	assert.Equal(t, image.Rect(0, 0, 3, 3), bc.Bounds())
+	assert.Equal(t, "PARTNER-123", bc.Content())
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			expected := color.Color(color.White)
+			if (x+y)%2 == 0 {
+				expected = color.Black
+			}
+			assert.Equal(t, expected, bc.At(x, y), "module %d,%d", x, y)
+		}
+	}
+}
+
+// TestImportedBarcode_Scale verifies modules stay square and evenly sized
+func TestImportedBarcode_Scale(t *testing.T) {
+	bc, err := encodeImportedBarcode(testSymbolPNG(t), "")
+	require.NoError(t, err)
+
+	scaled, err := bc.(selfScalingBarcode).scale(100, 50)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 48, 48), scaled.Bounds())
+	assert.Equal(t, color.Black, scaled.At(15, 15))
+	assert.Equal(t, color.White, scaled.At(16, 15))
+
+	shrunk, err := bc.(selfScalingBarcode).scale(2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 2, 2), shrunk.Bounds())
+}
+
+// TestValidateBarcodeImage ensures images are required for, and limited to, the IMAGE type
+func TestValidateBarcodeImage(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       BarcodeInput
+		expectedErr string
+	}{
+		{"Missing", BarcodeInput{BarcodeType: BarcodeTypeImage}, "BarcodeImage is required"},
+		{"NotPNG", BarcodeInput{BarcodeType: BarcodeTypeImage, BarcodeImage: []byte("GIF89a")}, "invalid barcode image"},
+		{"WrongType", BarcodeInput{BarcodeType: BarcodeTypeQR, BarcodeImage: testSymbolPNG(t)}, "only used with barcode type IMAGE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBarcodeData(tt.input)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+// TestGenerateBarcode_Image verifies an imported symbol is framed with text and exported as ZPL
+func TestGenerateBarcode_Image(t *testing.T) {
+	output, err := GenerateBarcode(BarcodeInput{
+		BarcodeType:  BarcodeTypeImage,
+		BarcodeImage: testSymbolPNG(t),
+		Width:        30,
+		Height:       30,
+		Dpi:          203,
+		TextLines:    []TextLine{{Text: "PARTNER-123", Position: TextPositionBelow, Size: TextSizeSmall}},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, output.ImageBase64)
+	assert.Contains(t, output.ZPL, "^GFA")
+
+	_, err = GenerateBarcode(BarcodeInput{
+		BarcodeType:  BarcodeTypeImage,
+		BarcodeImage: []byte("not a png"),
+		Width:        30,
+		Height:       30,
+		Dpi:          203,
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,198 @@
+package barcode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/utils"
+)
+
+// EAN add-on constants
+const (
+	eanAddOnGap      = 9 // Light modules between the main symbol and the add-on (7-12 allowed)
+	isbnCodeKind     = "ISBN"
+	issnCodeKind     = "ISSN"
+	issnDefaultIssue = "00"
+)
+
+// ISBNOptions configures ISBN and ISSN barcodes
+type ISBNOptions struct {
+	AddOn string // Optional 2 or 5 digit add-on, e.g. a 5-digit price or 2-digit issue number
+}
+
+// EAN digit patterns: odd parity (L) and even parity (G) sets
+var (
+	eanLPatterns = [10]string{"0001101", "0011001", "0010011", "0111101", "0100011", "0110001", "0101111", "0111011", "0110111", "0001011"}
+	eanGPatterns = [10]string{"0100111", "0110011", "0011011", "0100001", "0011101", "0111001", "0000101", "0010001", "0001001", "0010111"}
+)
+
+// EAN-5 parity patterns, indexed by the add-on checksum
+var ean5Parity = [10]string{"GGLLL", "GLGLL", "GLLGL", "GLLLG", "LGGLL", "LLGGL", "LLLGG", "LGLGL", "LGLLG", "LLGLG"}
+
+// EAN-2 parity patterns, indexed by the add-on value modulo 4
+var ean2Parity = [4]string{"LL", "LG", "GL", "GG"}
+
+// normalizeISBN accepts an ISBN-10 or ISBN-13, with or without hyphens and
+// spaces, and returns the 13-digit EAN.
+func normalizeISBN(isbn string) (string, error) {
+	digits := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(isbn))
+
+	switch len(digits) {
+	case 10:
+		if !isNumeric(digits[:9]) || !(isNumeric(digits[9:]) || digits[9] == 'X') {
+			return "", fmt.Errorf("invalid ISBN-10: %q. Must be 9 digits followed by a digit or X", isbn)
+		}
+		if expected := mod11CheckDigit(digits[:9], 10); digits[9] != expected {
+			return "", fmt.Errorf("invalid ISBN-10 check digit: %q. Expected check digit %c", isbn, expected)
+		}
+		ean := "978" + digits[:9]
+		return ean + string(gs1CheckDigit(ean)), nil
+	case 13:
+		if !isNumeric(digits) || (!strings.HasPrefix(digits, "978") && !strings.HasPrefix(digits, "979")) {
+			return "", fmt.Errorf("invalid ISBN-13: %q. Must be 13 digits starting with 978 or 979", isbn)
+		}
+		if expected := gs1CheckDigit(digits[:12]); digits[12] != expected {
+			return "", fmt.Errorf("invalid ISBN-13 check digit: %q. Expected check digit %c", isbn, expected)
+		}
+		return digits, nil
+	default:
+		return "", fmt.Errorf("invalid ISBN: %q. Must be an ISBN-10 or ISBN-13", isbn)
+	}
+}
+
+// normalizeISSN accepts an 8 character ISSN (e.g. 0317-8471) or its 13-digit
+// EAN and returns the EAN. ISSNs map to 977, the first 7 digits and issue variant 00.
+func normalizeISSN(issn string) (string, error) {
+	digits := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(issn))
+
+	switch len(digits) {
+	case 8:
+		if !isNumeric(digits[:7]) || !(isNumeric(digits[7:]) || digits[7] == 'X') {
+			return "", fmt.Errorf("invalid ISSN: %q. Must be 7 digits followed by a digit or X", issn)
+		}
+		if expected := mod11CheckDigit(digits[:7], 8); digits[7] != expected {
+			return "", fmt.Errorf("invalid ISSN check digit: %q. Expected check digit %c", issn, expected)
+		}
+		ean := "977" + digits[:7] + issnDefaultIssue
+		return ean + string(gs1CheckDigit(ean)), nil
+	case 13:
+		if !isNumeric(digits) || !strings.HasPrefix(digits, "977") {
+			return "", fmt.Errorf("invalid ISSN EAN: %q. Must be 13 digits starting with 977", issn)
+		}
+		if expected := gs1CheckDigit(digits[:12]); digits[12] != expected {
+			return "", fmt.Errorf("invalid ISSN EAN check digit: %q. Expected check digit %c", issn, expected)
+		}
+		return digits, nil
+	default:
+		return "", fmt.Errorf("invalid ISSN: %q. Must be 8 characters or a 13-digit EAN", issn)
+	}
+}
+
+// mod11CheckDigit calculates the ISBN-10/ISSN check digit, weighting digits
+// from firstWeight down to 2. A value of 10 is written as X.
+func mod11CheckDigit(digits string, firstWeight int) byte {
+	sum := 0
+	for i := range digits {
+		sum += int(digits[i]-'0') * (firstWeight - i)
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+	return byte('0' + check)
+}
+
+// validateEANAddOn ensures the optional add-on is 2 or 5 digits
+func validateEANAddOn(addOn string) error {
+	if addOn == "" {
+		return nil
+	}
+	if (len(addOn) != 2 && len(addOn) != 5) || !isNumeric(addOn) {
+		return fmt.Errorf("invalid add-on: %q. Add-on must be 2 or 5 digits", addOn)
+	}
+	return nil
+}
+
+// encodeISBN creates the EAN-13 barcode of an ISBN with an optional add-on
+func encodeISBN(data string, options ISBNOptions) (barcode.Barcode, error) {
+	code, err := normalizeISBN(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ISBN barcode: %w", err)
+	}
+	return encodeEANWithAddOn(isbnCodeKind, code, options.AddOn)
+}
+
+// encodeISSN creates the EAN-13 barcode of an ISSN with an optional add-on
+func encodeISSN(data string, options ISBNOptions) (barcode.Barcode, error) {
+	code, err := normalizeISSN(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ISSN barcode: %w", err)
+	}
+	return encodeEANWithAddOn(issnCodeKind, code, options.AddOn)
+}
+
+// encodeEANWithAddOn encodes an EAN-13 and appends the add-on symbol to its right
+func encodeEANWithAddOn(kind, code, addOn string) (barcode.Barcode, error) {
+	if err := validateEANAddOn(addOn); err != nil {
+		return nil, fmt.Errorf("failed to encode %s barcode: %w", kind, err)
+	}
+
+	main, err := ean.Encode(code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s barcode: %w", kind, err)
+	}
+
+	bits := new(utils.BitList)
+	for x := 0; x < main.Bounds().Dx(); x++ {
+		r, _, _, _ := main.At(x, 0).RGBA()
+		bits.AddBit(r == 0)
+	}
+
+	content := code
+	if addOn != "" {
+		for i := 0; i < eanAddOnGap; i++ {
+			bits.AddBit(false)
+		}
+		for _, module := range eanAddOnModules(addOn) {
+			bits.AddBit(module == '1')
+		}
+		content += " " + addOn
+	}
+	return utils.New1DCode(kind, content, bits), nil
+}
+
+// eanAddOnModules returns the EAN-2 or EAN-5 add-on: a 1011 start guard, then
+// each digit in the parity set chosen by the add-on's checksum, separated by 01.
+func eanAddOnModules(addOn string) string {
+	var parity string
+	if len(addOn) == 2 {
+		parity = ean2Parity[(int(addOn[0]-'0')*10+int(addOn[1]-'0'))%4]
+	} else {
+		sum := 0
+		for i := range addOn {
+			weight := 3
+			if i%2 == 1 {
+				weight = 9
+			}
+			sum += int(addOn[i]-'0') * weight
+		}
+		parity = ean5Parity[sum%10]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("1011")
+	for i := range addOn {
+		if i > 0 {
+			sb.WriteString("01")
+		}
+		digit := addOn[i] - '0'
+		if parity[i] == 'L' {
+			sb.WriteString(eanLPatterns[digit])
+		} else {
+			sb.WriteString(eanGPatterns[digit])
+		}
+	}
+	return sb.String()
+}
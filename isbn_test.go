@@ -0,0 +1,104 @@
+package barcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNormalizeISBN verifies ISBN-10 conversion and ISBN-13 validation
+func TestNormalizeISBN(t *testing.T) {
+	tests := []struct {
+		name     string
+		isbn     string
+		expected string
+	}{
+		{"ISBN10", "0-306-40615-2", "9780306406157"},
+		{"ISBN10CheckX", "080442957X", "9780804429573"},
+		{"ISBN13", "978-0-306-40615-7", "9780306406157"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ean, err := normalizeISBN(tt.isbn)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ean)
+		})
+	}
+}
+
+// TestNormalizeISBN_Invalid ensures bad check digits and lengths are rejected
+func TestNormalizeISBN_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		isbn        string
+		expectedErr string
+	}{
+		{"BadISBN10Check", "0-306-40615-3", "invalid ISBN-10 check digit"},
+		{"BadISBN13Check", "978-0-306-40615-8", "invalid ISBN-13 check digit"},
+		{"BadPrefix", "9770306406157", "starting with 978 or 979"},
+		{"BadLength", "12345", "Must be an ISBN-10 or ISBN-13"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := normalizeISBN(tt.isbn)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+// TestNormalizeISSN verifies ISSN conversion to its 977 EAN
+func TestNormalizeISSN(t *testing.T) {
+	ean, err := normalizeISSN("0317-8471")
+	require.NoError(t, err)
+	assert.Equal(t, "9770317847001", ean)
+
+	_, err = normalizeISSN("0317-8472")
+	assert.Error(t, err)
+}
+
+// TestEANAddOnModules verifies add-on parity and width
+func TestEANAddOnModules(t *testing.T) {
+	// 52495 has checksum 1, giving parity GLGLL
+	modules := eanAddOnModules("52495")
+	assert.Len(t, modules, 47)
+	assert.Equal(t, "1011"+eanGPatterns[5]+"01"+eanLPatterns[2]+"01"+eanGPatterns[4]+"01"+eanLPatterns[9]+"01"+eanLPatterns[5], modules)
+
+	// 12 mod 4 is 0, giving parity LL
+	assert.Equal(t, "1011"+eanLPatterns[1]+"01"+eanLPatterns[2], eanAddOnModules("12"))
+}
+
+// TestEncodeISBN_AddOn verifies the add-on follows the main symbol
+func TestEncodeISBN_AddOn(t *testing.T) {
+	bc, err := encodeISBN("0-306-40615-2", ISBNOptions{AddOn: "52495"})
+	require.NoError(t, err)
+	assert.Equal(t, 95+eanAddOnGap+47, bc.Bounds().Dx())
+	assert.Equal(t, "9780306406157 52495", bc.Content())
+
+	_, err = encodeISBN("0-306-40615-2", ISBNOptions{AddOn: "123"})
+	assert.Error(t, err)
+}
+
+// TestGenerateBarcode_ISBN_Success verifies a book label renders
+func TestGenerateBarcode_ISBN_Success(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "978-0-306-40615-7",
+		BarcodeType: BarcodeTypeISBN,
+		Width:       60.0,
+		Height:      30.0,
+		Dpi:         300,
+		ISBN:        ISBNOptions{AddOn: "51995"},
+		TextLines: []TextLine{
+			{Text: "ISBN 978-0-306-40615-7", Position: TextPositionAbove, Size: TextSizeSmall},
+		},
+	}
+
+	output, err := GenerateBarcode(input)
+
+	require.NoError(t, err, "Should successfully generate ISBN barcode")
+	assert.NotEmpty(t, output.ImageBase64)
+	assert.Contains(t, output.ZPL, "^XA")
+}
@@ -0,0 +1,52 @@
+package barcode
+
+import (
+	"fmt"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/twooffive"
+)
+
+// ITFOptions configures Interleaved 2 of 5 barcodes
+type ITFOptions struct {
+	CheckDigit   bool // Append a mod-10 check digit
+	PadOddLength bool // Prefix a zero when the digit count is odd instead of rejecting the data
+}
+
+// itfContent returns the digits to encode, including the optional check digit.
+// Interleaved 2 of 5 encodes digits in pairs, so the total must be even.
+func itfContent(data string, options ITFOptions) (string, error) {
+	if !isNumeric(data) {
+		return "", fmt.Errorf("invalid ITF data: %q. Interleaved 2 of 5 encodes digits only", data)
+	}
+
+	length := len(data)
+	if options.CheckDigit {
+		length++
+	}
+	if length%2 == 1 {
+		if !options.PadOddLength {
+			return "", fmt.Errorf("invalid ITF data length: %d. Interleaved 2 of 5 requires an even number of digits including the check digit", length)
+		}
+		data = "0" + data
+	}
+
+	if options.CheckDigit {
+		return twooffive.AddCheckSum(data)
+	}
+	return data, nil
+}
+
+// encodeITF creates an Interleaved 2 of 5 barcode
+func encodeITF(data string, options ITFOptions) (barcode.Barcode, error) {
+	content, err := itfContent(data, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ITF barcode: %w", err)
+	}
+
+	bc, err := twooffive.Encode(content, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ITF barcode: %w", err)
+	}
+	return bc, nil
+}
@@ -0,0 +1,75 @@
+package barcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestITFContent verifies padding and check digit handling
+func TestITFContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		options  ITFOptions
+		expected string
+	}{
+		{"Even", "1234", ITFOptions{}, "1234"},
+		{"PaddedOdd", "123", ITFOptions{PadOddLength: true}, "0123"},
+		{"CheckDigit", "1234567", ITFOptions{CheckDigit: true}, "12345670"},
+		{"PaddedCheckDigit", "123456", ITFOptions{CheckDigit: true, PadOddLength: true}, "01234565"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := itfContent(tt.data, tt.options)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, content)
+		})
+	}
+}
+
+// TestITFContent_Invalid ensures non-numeric and odd-length data are rejected
+func TestITFContent_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		options     ITFOptions
+		expectedErr string
+	}{
+		{"NonNumeric", "12A4", ITFOptions{}, "encodes digits only"},
+		{"Empty", "", ITFOptions{}, "encodes digits only"},
+		{"Odd", "123", ITFOptions{}, "requires an even number of digits"},
+		{"OddWithCheckDigit", "1234", ITFOptions{CheckDigit: true}, "requires an even number of digits"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := itfContent(tt.data, tt.options)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+// TestGenerateBarcode_ITF_Success verifies a tote ID renders on a label
+func TestGenerateBarcode_ITF_Success(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "0042137",
+		BarcodeType: BarcodeTypeITF,
+		Width:       75.0,
+		Height:      25.0,
+		Dpi:         203,
+		ITF:         ITFOptions{CheckDigit: true},
+		TextLines: []TextLine{
+			{Text: "TOTE 0042137", Position: TextPositionBelow, Size: TextSizeMedium},
+		},
+	}
+
+	output, err := GenerateBarcode(input)
+
+	require.NoError(t, err, "Should successfully generate ITF barcode")
+	assert.NotEmpty(t, output.ImageBase64)
+	assert.Contains(t, output.ZPL, "^XA")
+}
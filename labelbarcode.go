@@ -0,0 +1,105 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/boombuler/barcode"
+)
+
+// LabelBarcode is an additional barcode at a fixed position on the label,
+// e.g. a QR code for mobile apps beside the main Code128 for long-range
+// scanners. It is encoded with the default options of its type.
+type LabelBarcode struct {
+	BarcodeData string      // Data to encode
+	BarcodeType BarcodeType // Type of barcode (see supportedBarcodeTypes, except IMAGE)
+	XMM         float64     // Left edge, in millimeters from the left of the label
+	YMM         float64     // Top edge, in millimeters from the top of the label
+	WidthMM     float64     // Width of the area the barcode is scaled to fit
+	HeightMM    float64     // Height of the area; QR codes are squares on its smaller side
+}
+
+// input returns the barcode as the input of a label of its own size, so it
+// is validated and encoded like the main barcode
+func (b LabelBarcode) input(dpi int) BarcodeInput {
+	return BarcodeInput{BarcodeData: b.BarcodeData, BarcodeType: b.BarcodeType, Width: b.WidthMM, Height: b.HeightMM, Dpi: dpi}
+}
+
+// validateLabelBarcodes ensures each additional barcode has valid data for
+// its type and an area on the label
+func validateLabelBarcodes(input BarcodeInput) error {
+	for i, b := range input.Barcodes {
+		if b.BarcodeType == BarcodeTypeImage {
+			return fmt.Errorf("invalid label barcode %d: %s symbols are only supported as the main barcode", i+1, BarcodeTypeImage)
+		}
+		if err := validateBarcodeType(b.BarcodeType); err != nil {
+			return fmt.Errorf("invalid label barcode %d: %w", i+1, err)
+		}
+		if err := validateBarcodeData(b.input(input.Dpi)); err != nil {
+			return fmt.Errorf("invalid label barcode %d: %w", i+1, err)
+		}
+		if b.WidthMM <= 0 || b.HeightMM <= 0 || b.XMM < 0 || b.YMM < 0 {
+			return fmt.Errorf("invalid label barcode %d: needs a positive size and a position inside the label", i+1)
+		}
+		if b.XMM+b.WidthMM > input.Width || b.YMM+b.HeightMM > input.Height {
+			return fmt.Errorf("invalid label barcode %d: %gx%gmm at %g,%gmm does not fit on a %gx%gmm label", i+1, b.WidthMM, b.HeightMM, b.XMM, b.YMM, input.Width, input.Height)
+		}
+	}
+	return nil
+}
+
+// layoutLabelBarcode encodes an additional barcode and scales it to fit its
+// area at the DPI, centered in the area. The layout has no label size.
+func layoutLabelBarcode(b LabelBarcode, dpi int) (barcode.Barcode, labelLayout, error) {
+	bc, err := encodeBarcode(b.input(dpi))
+	if err != nil {
+		return nil, labelLayout{}, err
+	}
+
+	area := image.Rect(0, 0, mmToPixels(b.WidthMM, dpi), mmToPixels(b.HeightMM, dpi))
+	size := area.Size()
+	if b.BarcodeType == BarcodeTypeQR || b.BarcodeType == BarcodeTypeSwissQR {
+		side := min(size.X, size.Y)
+		size = image.Pt(side, side)
+	}
+	scaled, err := scaleBarcodeToFit(bc, size)
+	if err != nil {
+		return nil, labelLayout{}, fmt.Errorf("invalid label barcode: %s %q does not fit in %gx%gmm at %d dpi: %w", b.BarcodeType, b.BarcodeData, b.WidthMM, b.HeightMM, dpi, err)
+	}
+
+	rect := centerBarcodeOnLabel(area, scaled).Add(image.Pt(mmToPixels(b.XMM, dpi), mmToPixels(b.YMM, dpi)))
+	return bc, labelLayout{barcode: scaled, barcodeRect: rect}, nil
+}
+
+// drawLabelBarcodes draws each additional barcode onto the label and returns
+// the areas they cover
+func drawLabelBarcodes(label *image.RGBA, barcodes []LabelBarcode, dpi int) ([]image.Rectangle, error) {
+	rects := make([]image.Rectangle, 0, len(barcodes))
+	for _, b := range barcodes {
+		_, layout, err := layoutLabelBarcode(b, dpi)
+		if err != nil {
+			return nil, err
+		}
+		drawBarcodeOnLabel(label, layout.barcode, layout.barcodeRect)
+		rects = append(rects, layout.barcodeRect)
+	}
+	return rects, nil
+}
+
+// writeZPLLabelBarcodes writes each additional barcode as a native barcode
+// command when ZPL can print it identically, and otherwise as a ^GF graphic
+// of its area
+func writeZPLLabelBarcodes(zpl *strings.Builder, input BarcodeInput, printImg *image.RGBA) {
+	for _, b := range input.Barcodes {
+		bc, layout, err := layoutLabelBarcode(b, input.Dpi)
+		if err != nil {
+			continue // Validated with the input and already drawn in the print image
+		}
+		if command, ok := zplBarcodeCommand(b.input(input.Dpi), bc, layout); ok {
+			zpl.WriteString(command)
+		} else {
+			writeZPLGraphic(zpl, printImg, layout.barcodeRect, input.ZPL.CompressZ64)
+		}
+	}
+}
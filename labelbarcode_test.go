@@ -0,0 +1,86 @@
+package barcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLayoutLabelBarcode verifies QR codes are square and centered in their area
+func TestLayoutLabelBarcode(t *testing.T) {
+	b := LabelBarcode{BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeQR, XMM: 10, YMM: 5, WidthMM: 30, HeightMM: 20}
+	_, layout, err := layoutLabelBarcode(b, 203)
+	require.NoError(t, err)
+
+	rect := layout.barcodeRect
+	assert.Equal(t, rect.Dx(), rect.Dy())
+	assert.Equal(t, mmToPixels(20, 203), rect.Dy())
+	assert.Equal(t, mmToPixels(5, 203), rect.Min.Y)
+	assert.Equal(t, mmToPixels(10, 203)+(mmToPixels(30, 203)-rect.Dx())/2, rect.Min.X)
+}
+
+// TestGenerateBarcode_LabelBarcodes verifies both barcodes are sent natively
+// in ZPL, while other languages fall back to a single graphic
+func TestGenerateBarcode_LabelBarcodes(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1-B2",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       100.0,
+		Height:      50.0,
+		Dpi:         203,
+		Barcodes: []LabelBarcode{
+			{BarcodeData: "https://example.com/loc/A1-B2", BarcodeType: BarcodeTypeQR, XMM: 80, YMM: 2, WidthMM: 18, HeightMM: 18},
+		},
+		OutputFormats: []OutputFormat{OutputFormatPNG, OutputFormatZPL, OutputFormatEPL},
+		ZPL:           ZPLOptions{NativeCommands: true},
+	}
+	single := input
+	single.Barcodes = nil
+
+	plain, err := GenerateBarcode(single)
+	require.NoError(t, err)
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, plain.ImageBase64, output.ImageBase64)
+	assert.Contains(t, output.ZPL, "^BC")
+	assert.Contains(t, output.ZPL, "^BQ")
+	assert.Equal(t, 1, strings.Count(output.EPL, "GW"))
+	assert.NotContains(t, output.EPL, "\nB")
+
+	input.Mirror = true
+	_, err = GenerateBarcode(input)
+	require.NoError(t, err)
+}
+
+// TestValidateLabelBarcodes verifies invalid data, types and positions are rejected
+func TestValidateLabelBarcodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		barcode LabelBarcode
+		errMsg  string
+	}{
+		{"invalid type", LabelBarcode{BarcodeData: "A1", BarcodeType: "AZTEC", WidthMM: 10, HeightMM: 10}, "invalid barcode type"},
+		{"image type", LabelBarcode{BarcodeData: "A1", BarcodeType: BarcodeTypeImage, WidthMM: 10, HeightMM: 10}, "only supported as the main barcode"},
+		{"invalid data", LabelBarcode{BarcodeData: "12AB", BarcodeType: BarcodeTypeITF, WidthMM: 10, HeightMM: 10}, "invalid label barcode 1"},
+		{"no size", LabelBarcode{BarcodeData: "A1", BarcodeType: BarcodeTypeQR}, "positive size"},
+		{"off label", LabelBarcode{BarcodeData: "A1", BarcodeType: BarcodeTypeQR, XMM: 90, WidthMM: 18, HeightMM: 18}, "does not fit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateBarcode(BarcodeInput{
+				BarcodeData: "LOC-A1-B2",
+				BarcodeType: BarcodeTypeCode128,
+				Width:       100.0,
+				Height:      50.0,
+				Dpi:         203,
+				Barcodes:    []LabelBarcode{tt.barcode},
+			})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package barcode
+
+import (
+	"image"
+
+	"github.com/boombuler/barcode"
+)
+
+// TextLineLayout describes where a text line landed on the label
+type TextLineLayout struct {
+	Text     string          // Text as drawn, after TRUNCATE or ELLIPSIS overflow
+	Rect     image.Rectangle // Box of the text in pixels, from the ascent to the descent of its font
+	FontSize float64         // Font size in points the line was drawn at
+	Reduced  bool            // The font size was reduced to fit the line's width or FitGroup
+}
+
+// describeLabelLayout returns where the barcode and text lines of a label
+// composed at dpi landed, in the coordinates of the label once it is
+// mirrored and rotated
+func describeLabelLayout(input BarcodeInput, bc barcode.Barcode, labelImg *image.RGBA, barcodeRect image.Rectangle, dpi int) LabelLayout {
+	bounds := labelImg.Bounds()
+	orient := func(rect image.Rectangle) image.Rectangle {
+		if input.Mirror {
+			rect = mirrorRect(bounds, rect)
+		}
+		return rotateRect(bounds.Size(), rect, input.Rotation)
+	}
+
+	size := orient(bounds).Size()
+	layout := LabelLayout{
+		Dpi:         dpi,
+		Width:       size.X,
+		Height:      size.Y,
+		BarcodeRect: orient(barcodeRect),
+		ModuleWidth: barcodeModuleWidth(input, bc, barcodeRect),
+	}
+	fontScale := labelFontScale(input)
+	for _, line := range layoutTextLines(input, labelImg, barcodeRect, dpi) {
+		fontSize, _ := getTextLineFontSize(line.TextLine, dpi, fontScale)
+		layout.TextLines = append(layout.TextLines, TextLineLayout{
+			Text:     line.Text,
+			Rect:     orient(textLineRect(line, dpi)),
+			FontSize: line.fontSize,
+			Reduced:  line.fontSize < fontSize,
+		})
+	}
+	return layout
+}
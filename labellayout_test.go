@@ -0,0 +1,93 @@
+package barcode
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateBarcode_Layout verifies the output describes the label size,
+// the barcode and its module width, and each text line with the font size
+// it was drawn at
+func TestGenerateBarcode_Layout(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1-B2-C3",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       40,
+		Height:      30,
+		Dpi:         203,
+		TextLines: []TextLine{
+			{Text: "WAREHOUSE 7 AISLE 12 BAY 4", Position: TextPositionAbove, Size: TextSizeLarge},
+			{Text: "LOC-A1-B2-C3", Position: TextPositionBelow, Size: TextSizeSmall},
+		},
+		OutputFormats: []OutputFormat{OutputFormatZPL},
+	}
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	layout := output.Layout
+	assert.Equal(t, 203, layout.Dpi)
+	assert.Equal(t, mmToPixels(40, 203), layout.Width)
+	assert.Equal(t, mmToPixels(30, 203), layout.Height)
+
+	bc, err := encodeBarcode(input)
+	require.NoError(t, err)
+	assert.Equal(t, layout.BarcodeRect.Dx()/bc.Bounds().Dx(), layout.ModuleWidth)
+	assert.Positive(t, layout.ModuleWidth)
+
+	require.Len(t, layout.TextLines, 2)
+	above, below := layout.TextLines[0], layout.TextLines[1]
+	largeSize, _ := getTextLineFontSize(input.TextLines[0], input.Dpi, labelFontScale(input))
+	smallSize, _ := getTextLineFontSize(input.TextLines[1], input.Dpi, labelFontScale(input))
+	assert.Equal(t, "WAREHOUSE 7 AISLE 12 BAY 4", above.Text)
+	assert.True(t, above.Reduced)
+	assert.Less(t, above.FontSize, largeSize)
+	assert.False(t, below.Reduced)
+	assert.Equal(t, smallSize, below.FontSize)
+
+	bounds := image.Rect(0, 0, layout.Width, layout.Height)
+	assert.True(t, above.Rect.In(bounds))
+	assert.LessOrEqual(t, above.Rect.Max.Y, layout.BarcodeRect.Min.Y)
+	assert.GreaterOrEqual(t, below.Rect.Min.Y, layout.BarcodeRect.Max.Y)
+
+	img, imageLayout, err := GenerateBarcodeImage(input)
+	require.NoError(t, err)
+	assert.Equal(t, layout, imageLayout)
+	for _, line := range layout.TextLines {
+		assert.True(t, hasDarkPixel(img.(*image.RGBA), line.Rect), line.Text)
+	}
+}
+
+// TestGenerateBarcodeImage_LayoutRotated verifies the layout of a rotated
+// label is given in the coordinates of the rotated image
+func TestGenerateBarcodeImage_LayoutRotated(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1-B2-C3",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       40,
+		Height:      30,
+		Dpi:         203,
+		TextLines: []TextLine{
+			{Text: "WAREHOUSE 7 AISLE 12 BAY 4", Position: TextPositionAbove, Size: TextSizeLarge},
+			{Text: "LOC-A1-B2-C3", Position: TextPositionBelow, Size: TextSizeSmall},
+		},
+	}
+	_, upright, err := GenerateBarcodeImage(input)
+	require.NoError(t, err)
+
+	input.Rotation = 90
+	img, layout, err := GenerateBarcodeImage(input)
+	require.NoError(t, err)
+	assert.Equal(t, img.Bounds().Dx(), layout.Width)
+	assert.Equal(t, upright.Height, layout.Width)
+	assert.Equal(t, upright.Width, layout.Height)
+
+	size := image.Pt(upright.Width, upright.Height)
+	assert.Equal(t, rotateRect(size, upright.BarcodeRect, 90), layout.BarcodeRect)
+	require.Len(t, layout.TextLines, 2)
+	for i, line := range layout.TextLines {
+		assert.Equal(t, rotateRect(size, upright.TextLines[i].Rect, 90), line.Rect)
+		assert.True(t, hasDarkPixel(img.(*image.RGBA), line.Rect), line.Text)
+	}
+}
@@ -0,0 +1,91 @@
+package barcode
+
+import "sort"
+
+// LabelPreset names a common label stock size, used instead of Width and
+// Height
+type LabelPreset string
+
+const (
+	LabelPreset4x6       LabelPreset = "4X6"        // 4×6" shipping label
+	LabelPreset4x4       LabelPreset = "4X4"        // 4×4" carton label
+	LabelPreset4x3       LabelPreset = "4X3"        // 4×3" carton label
+	LabelPreset4x2       LabelPreset = "4X2"        // 4×2" shelf and bin label
+	LabelPreset3x2       LabelPreset = "3X2"        // 3×2" product label
+	LabelPreset3x1       LabelPreset = "3X1"        // 3×1" product label
+	LabelPreset2x1       LabelPreset = "2X1"        // 2×1" barcode label
+	LabelPreset2_25x1_25 LabelPreset = "2.25X1.25"  // 2.25×1.25" barcode label, common on desktop printers
+	LabelPreset102x152mm LabelPreset = "102X152MM"  // 102×152mm metric shipping label
+	LabelPreset100x150mm LabelPreset = "100X150MM"  // 100×150mm carrier label, e.g. DHL and DPD
+	LabelPreset100x50mm  LabelPreset = "100X50MM"   // 100×50mm logistics label
+	LabelPreset58x40mm   LabelPreset = "58X40MM"    // 58×40mm retail label
+	LabelPresetDymo99012 LabelPreset = "DYMO_99012" // DYMO LabelWriter large address label, 89×36mm
+	LabelPresetDymo99010 LabelPreset = "DYMO_99010" // DYMO LabelWriter address label, 89×28mm
+	LabelPresetDymo11354 LabelPreset = "DYMO_11354" // DYMO LabelWriter multi-purpose label, 57×32mm
+)
+
+// LabelSize is the size of a label stock in millimeters, with the margin
+// that keeps content clear of the die-cut edge
+type LabelSize struct {
+	Width    float64 // Label width in millimeters
+	Height   float64 // Label height in millimeters
+	MarginMM float64 // Margin on each side of the label in millimeters
+}
+
+// labelPresets holds the size of every preset. Inch sizes are converted
+// exactly; larger labels get wider margins since they are usually printed on
+// printers with wider registration tolerance.
+var labelPresets = map[LabelPreset]LabelSize{
+	LabelPreset4x6:       {Width: 101.6, Height: 152.4, MarginMM: 3},
+	LabelPreset4x4:       {Width: 101.6, Height: 101.6, MarginMM: 3},
+	LabelPreset4x3:       {Width: 101.6, Height: 76.2, MarginMM: 3},
+	LabelPreset4x2:       {Width: 101.6, Height: 50.8, MarginMM: 2},
+	LabelPreset3x2:       {Width: 76.2, Height: 50.8, MarginMM: 2},
+	LabelPreset3x1:       {Width: 76.2, Height: 25.4, MarginMM: 1.5},
+	LabelPreset2x1:       {Width: 50.8, Height: 25.4, MarginMM: 1.5},
+	LabelPreset2_25x1_25: {Width: 57.15, Height: 31.75, MarginMM: 1.5},
+	LabelPreset102x152mm: {Width: 102, Height: 152, MarginMM: 3},
+	LabelPreset100x150mm: {Width: 100, Height: 150, MarginMM: 3},
+	LabelPreset100x50mm:  {Width: 100, Height: 50, MarginMM: 2},
+	LabelPreset58x40mm:   {Width: 58, Height: 40, MarginMM: 1.5},
+	LabelPresetDymo99012: {Width: 89, Height: 36, MarginMM: 2},
+	LabelPresetDymo99010: {Width: 89, Height: 28, MarginMM: 2},
+	LabelPresetDymo11354: {Width: 57, Height: 32, MarginMM: 2},
+}
+
+// LabelPresets returns the names of the supported label presets in
+// alphabetical order, e.g. for a size picker
+func LabelPresets() []LabelPreset {
+	presets := make([]LabelPreset, 0, len(labelPresets))
+	for preset := range labelPresets {
+		presets = append(presets, preset)
+	}
+	sort.Slice(presets, func(i, j int) bool { return presets[i] < presets[j] })
+	return presets
+}
+
+// LookupLabelPreset returns the size of a label preset and whether it exists
+func LookupLabelPreset(preset LabelPreset) (LabelSize, bool) {
+	size, ok := labelPresets[preset]
+	return size, ok
+}
+
+// applyLabelPreset sets the label size from the input's preset, and its
+// margin unless the input sets one. Inputs without a preset are unchanged.
+func applyLabelPreset(input BarcodeInput) (BarcodeInput, error) {
+	if input.LabelPreset == "" {
+		return input, nil
+	}
+	size, ok := labelPresets[input.LabelPreset]
+	if !ok {
+		return BarcodeInput{}, errorf(ErrInvalidInput, "invalid label preset: %q. Supported presets: %v", input.LabelPreset, LabelPresets())
+	}
+	if input.Width != 0 || input.Height != 0 {
+		return BarcodeInput{}, errorf(ErrInvalidInput, "invalid label preset: %q. Set either LabelPreset or Width and Height", input.LabelPreset)
+	}
+	input.Width, input.Height = size.Width, size.Height
+	if input.Margins.MM == 0 {
+		input.Margins.MM = size.MarginMM
+	}
+	return input, nil
+}
@@ -0,0 +1,72 @@
+package barcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestApplyLabelPreset verifies presets set the size and a margin the input
+// can override, and are not mixed with an explicit size
+func TestApplyLabelPreset(t *testing.T) {
+	input, err := applyLabelPreset(BarcodeInput{LabelPreset: LabelPreset4x6})
+	require.NoError(t, err)
+	assert.Equal(t, 101.6, input.Width)
+	assert.Equal(t, 152.4, input.Height)
+	assert.Equal(t, 3.0, input.Margins.MM)
+
+	input, err = applyLabelPreset(BarcodeInput{LabelPreset: LabelPresetDymo99012, Margins: Margins{MM: 1}})
+	require.NoError(t, err)
+	assert.Equal(t, 89.0, input.Width)
+	assert.Equal(t, 1.0, input.Margins.MM)
+
+	input, err = applyLabelPreset(BarcodeInput{Width: 50, Height: 25})
+	require.NoError(t, err)
+	assert.Equal(t, BarcodeInput{Width: 50, Height: 25}, input)
+
+	_, err = applyLabelPreset(BarcodeInput{LabelPreset: "A4"})
+	assert.ErrorContains(t, err, `invalid label preset: "A4". Supported presets:`)
+
+	_, err = applyLabelPreset(BarcodeInput{LabelPreset: LabelPreset2x1, Width: 50})
+	assert.ErrorContains(t, err, "Set either LabelPreset or Width and Height")
+}
+
+// TestLabelPresets verifies every preset is listed once in order and has a
+// usable size
+func TestLabelPresets(t *testing.T) {
+	presets := LabelPresets()
+	require.Len(t, presets, len(labelPresets))
+	for i, preset := range presets {
+		if i > 0 {
+			assert.True(t, presets[i-1] < preset, "%s before %s", presets[i-1], preset)
+		}
+		size, ok := LookupLabelPreset(preset)
+		require.True(t, ok)
+		assert.NoError(t, validateDimensions(size.Width, size.Height, 203, mmToPixels(size.MarginMM, 203)), preset)
+	}
+
+	_, ok := LookupLabelPreset("A4")
+	assert.False(t, ok)
+}
+
+// TestGenerateBarcode_LabelPreset verifies labels generated from a preset
+// have its size at the printer DPI
+func TestGenerateBarcode_LabelPreset(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1-B2-C3",
+		BarcodeType: BarcodeTypeCode128,
+		LabelPreset: LabelPreset2_25x1_25,
+		Dpi:         203,
+	}
+	img, _, err := GenerateBarcodeImage(input)
+	require.NoError(t, err)
+	assert.Equal(t, mmToPixels(57.15, 203), img.Bounds().Dx())
+	assert.Equal(t, mmToPixels(31.75, 203), img.Bounds().Dy())
+
+	input.OutputFormats = []OutputFormat{OutputFormatZPL}
+	input.ZPL = ZPLOptions{SetPrintWidth: true}
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Contains(t, output.ZPL, "^PW456")
+}
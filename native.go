@@ -0,0 +1,238 @@
+package barcode
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/boombuler/barcode"
+	"github.com/golang/freetype/truetype"
+)
+
+// residentFontAscent is the share of a resident font cell above the baseline
+const residentFontAscent = 0.8
+
+// residentFont is the character cell of a printer-resident bitmap font in dots
+type residentFont struct {
+	width, height int
+}
+
+// nativeTextLine is a text line with the size and baseline it is rendered at
+// in the print image, for printer languages that draw text natively
+type nativeTextLine struct {
+	TextLine
+	fontSize float64 // Font size in points after fitting
+	baseline int
+	anchor   textAnchor
+	maxWidth int // Width available to the line
+
+	rotatedRect image.Rectangle // Where a rotated line is drawn
+}
+
+// generatePrinterLanguages adds the requested printer language outputs.
+// When native is false, e.g. for mirrored or post-processed labels, each
+// language sends the print image as a single graphic. Only ZPL places label
+// graphics itself and inverts labels with ^POI, so other languages send
+// labels with graphics as a single graphic, and upside-down labels as the
+// print image turned 180 degrees. Additional barcodes, shapes and reverse
+// regions are likewise only placed by ZPL.
+func generatePrinterLanguages(output *BarcodeOutput, input BarcodeInput, formats map[OutputFormat]bool, bc barcode.Barcode, printImg *image.RGBA, native bool) error {
+	var err error
+	if formats[OutputFormatZPL] {
+		if output.ZPL, err = generateZPL(input, bc, printImg, native); err != nil {
+			return err
+		}
+	}
+	native = native && len(input.Graphics) == 0 && len(input.Barcodes) == 0 && len(input.Shapes) == 0 && len(input.ReverseRegions) == 0
+	if input.UpsideDown {
+		printImg = rotateLabel180(printImg)
+		native = false
+	}
+	if formats[OutputFormatEPL] {
+		if output.EPL, err = generateEPL(input, bc, printImg, native); err != nil {
+			return err
+		}
+	}
+	if formats[OutputFormatTSPL] {
+		if output.TSPL, err = generateTSPL(input, bc, printImg, native); err != nil {
+			return err
+		}
+	}
+	if formats[OutputFormatSBPL] {
+		if output.SBPL, err = generateSBPL(input, bc, printImg, native); err != nil {
+			return err
+		}
+	}
+	if formats[OutputFormatESCPOS] {
+		if output.ESCPOS, err = generateESCPOS(input, bc, printImg, native); err != nil {
+			return err
+		}
+	}
+	if formats[OutputFormatDPL] {
+		if output.DPL, err = generateDPL(input, bc, printImg, native); err != nil {
+			return err
+		}
+	}
+	if formats[OutputFormatCPCL] {
+		if output.CPCL, err = generateCPCL(input, bc, printImg, native); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// layoutNativeTextLines returns the text lines with the font size and
+// baseline renderTextLines draws them at in the print image
+func layoutNativeTextLines(input BarcodeInput, printImg *image.RGBA, layout labelLayout) []nativeTextLine {
+	return layoutTextLines(input, printImg, layout.barcodeRect, input.Dpi)
+}
+
+// layoutTextLines returns the text lines with the font size and baseline
+// renderTextLines draws them at on a label image rendered at dpi
+func layoutTextLines(input BarcodeInput, img *image.RGBA, barcodeRect image.Rectangle, dpi int) []nativeTextLine {
+	area := labelTextArea(input, img)
+	fontScale := labelFontScale(input)
+	// Lines that fail on overflow have already failed rendering the print image
+	textLines, _ := applyTextOverflow(labelTextLines(input), area, dpi, fontScale)
+	groupScales := calculateFitGroupScales(textLines, area, float64(dpi), fontScale)
+	offsets := calculateTextLineOffsets(textLines, dpi, fontScale)
+
+	lines := make([]nativeTextLine, len(textLines))
+	for i, textLine := range textLines {
+		fontSize := fittedFontSize(textLine, area, groupScales, dpi, fontScale)
+		baseY := textLineBaseY(textLine, barcodeRect, offsets[i], dpi)
+		anchor := textLineAnchor(textLine, area, dpi)
+		lines[i] = nativeTextLine{
+			TextLine: textLine,
+			fontSize: fontSize,
+			baseline: textBaselineY(baseY, calculateFontHeight(textLine.fontRef(), fontSize, dpi), textLine.Position),
+			anchor:   anchor,
+			maxWidth: area.maxLength(textLine),
+		}
+		if isRotatedText(textLine) {
+			lines[i].rotatedRect = rotatedTextRect(textLine, anchor, baseY, fontSize, dpi, fontScale)
+		}
+	}
+	return lines
+}
+
+// selectResidentFont picks the font and multiplier whose cell height is
+// closest to, without exceeding, the rendered text height and whose width
+// fits. Text other than printable ASCII, lines in a registered font, and
+// rotated lines are not supported by resident fonts.
+func selectResidentFont(fonts []residentFont, maxMultiplier int, line nativeTextLine, dpi int) (int, int, bool) {
+	text, emHeight, maxWidth := line.Text, line.fontSize*float64(dpi)/72, line.maxWidth
+	if len(fonts) == 0 || text == "" || !isPrintableASCII(text) || line.FontName != "" || isRotatedText(line.TextLine) {
+		return 0, 0, false
+	}
+
+	best, bestMultiplier, bestHeight := -1, 0, 0
+	for i, font := range fonts {
+		for multiplier := 1; multiplier <= maxMultiplier; multiplier++ {
+			height := font.height * multiplier
+			if len(text)*font.width*multiplier > maxWidth {
+				break
+			}
+			// The first font is used when the text is smaller than any cell
+			fits := float64(height) <= emHeight || (i == 0 && multiplier == 1)
+			if fits && height > bestHeight {
+				best, bestMultiplier, bestHeight = i, multiplier, height
+			}
+		}
+	}
+	return best, bestMultiplier, best >= 0
+}
+
+// residentTextPosition returns the top-left corner of a text line drawn in a
+// resident font, aligned like the rendered line
+func residentTextPosition(line nativeTextLine, font residentFont, multiplier int) image.Point {
+	width := len(line.Text) * font.width * multiplier
+	top := line.baseline - int(float64(font.height*multiplier)*residentFontAscent)
+	return image.Pt(max(0, line.anchor.left(width)), max(0, top))
+}
+
+// textBand returns the area covered by a text line, tall enough for the
+// tallest of its fonts: the full width for lines centered on the label, and
+// the text itself for aligned lines, which may share their row with other
+// lines, and the turned text for rotated lines
+func textBand(line nativeTextLine, dpi, labelWidth int) image.Rectangle {
+	rect := textLineRect(line, dpi)
+	if isRotatedText(line.TextLine) {
+		return rect
+	}
+	band := image.Rect(0, rect.Min.Y, labelWidth, rect.Max.Y)
+	if line.anchor == (textAnchor{TextAlignCenter, labelWidth / 2}) {
+		return band
+	}
+	return band.Intersect(rect)
+}
+
+// textLineRect returns the box of the text of a line, from the ascent to the
+// descent of the tallest of its fonts, or the turned text for rotated lines
+func textLineRect(line nativeTextLine, dpi int) image.Rectangle {
+	if isRotatedText(line.TextLine) {
+		return line.rotatedRect
+	}
+	runs, err := textRuns(line.fontRef(), line.Text)
+	if err != nil {
+		return image.Rectangle{}
+	}
+	ascent, descent := 0, 0
+	for _, run := range runs {
+		metrics := truetype.NewFace(run.font, &truetype.Options{Size: line.fontSize, DPI: float64(dpi)}).Metrics()
+		ascent, descent = max(ascent, metrics.Ascent.Ceil()), max(descent, metrics.Descent.Ceil())
+	}
+	width := measureRuns(runs, line.fontSize, float64(dpi))
+	left := line.anchor.left(width)
+	return image.Rect(left, line.baseline-ascent, left+width, line.baseline+descent)
+}
+
+// nativeModuleLayout returns the module width of a scaled barcode and where
+// its first module is drawn, matching how barcode.Scale centers a symbol
+// within the scaled area
+func nativeModuleLayout(bc barcode.Barcode, layout labelLayout) (image.Point, int, bool) {
+	switch layout.barcode.(type) {
+	case *humanReadableEAN:
+		return image.Point{}, 0, false // Printers place EAN digits differently
+	case *reducedBarcode:
+		return image.Point{}, 0, false // Native barcode commands print full-width bars
+	}
+	size := bc.Bounds().Size()
+	moduleWidth := layout.barcodeRect.Dx() / size.X
+	if bc.Metadata().Dimensions == 2 {
+		moduleWidth = min(moduleWidth, layout.barcodeRect.Dy()/size.Y)
+		offset := image.Pt((layout.barcodeRect.Dx()-size.X*moduleWidth)/2, (layout.barcodeRect.Dy()-size.Y*moduleWidth)/2)
+		return layout.barcodeRect.Min.Add(offset), moduleWidth, moduleWidth >= 1
+	}
+	offset := image.Pt((layout.barcodeRect.Dx()-size.X*moduleWidth)/2, 0)
+	return layout.barcodeRect.Min.Add(offset), moduleWidth, moduleWidth >= 1
+}
+
+// packMonochrome packs the area of img into rows of bits, most significant
+// bit first, with dark pixels as 0 bits and rows padded with 1 bits, as
+// EPL2 and TSPL graphics expect
+func packMonochrome(img *image.RGBA, area image.Rectangle) (int, []byte) {
+	rowBytes := (area.Dx() + 7) / 8
+	data := make([]byte, rowBytes*area.Dy())
+	for i := range data {
+		data[i] = 0xFF
+	}
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		row := data[(y-area.Min.Y)*rowBytes:]
+		for x := area.Min.X; x < area.Max.X; x++ {
+			if color.GrayModel.Convert(img.RGBAAt(x, y)).(color.Gray).Y < 128 {
+				offset := x - area.Min.X
+				row[offset/8] &^= 0x80 >> (offset % 8)
+			}
+		}
+	}
+	return rowBytes, data
+}
+
+func isPrintableASCII(s string) bool {
+	for _, r := range s {
+		if r < 32 || r > 126 {
+			return false
+		}
+	}
+	return true
+}
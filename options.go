@@ -0,0 +1,163 @@
+package barcode
+
+// Default label settings of Generate, those of the barcodegen command
+const (
+	defaultOptionsType   = BarcodeTypeCode128
+	defaultOptionsWidth  = 50.0
+	defaultOptionsHeight = 25.0
+	defaultOptionsDPI    = 203
+)
+
+// Option configures a label built by Generate or NewInput. Options check
+// their own arguments, so a bad value is reported by the option that set it;
+// the label as a whole is validated when it is generated.
+type Option func(*BarcodeInput) error
+
+// Generate creates a barcode label for data from options, as an alternative
+// to filling in a BarcodeInput for GenerateBarcode. Without options the label
+// is a 50×25mm Code128 label at 203 DPI, output as PNG and ZPL.
+//
+//	output, err := barcode.Generate("LOC-A1-B2-C3",
+//		barcode.WithSize(75, 40), barcode.WithDPI(300),
+//		barcode.WithText("LOC-A1-B2-C3", barcode.TextPositionBelow, barcode.TextSizeMedium))
+func Generate(data string, options ...Option) (*BarcodeOutput, error) {
+	input, err := NewInput(data, options...)
+	if err != nil {
+		return nil, err
+	}
+	return GenerateBarcode(input)
+}
+
+// NewInput returns the BarcodeInput that Generate would generate, e.g. for a
+// Generator or a cache
+func NewInput(data string, options ...Option) (BarcodeInput, error) {
+	input := BarcodeInput{
+		BarcodeData: data,
+		BarcodeType: defaultOptionsType,
+		Width:       defaultOptionsWidth,
+		Height:      defaultOptionsHeight,
+		Dpi:         defaultOptionsDPI,
+	}
+	for _, option := range options {
+		if err := option(&input); err != nil {
+			return BarcodeInput{}, err
+		}
+	}
+	return input, nil
+}
+
+// WithType sets the barcode symbology
+func WithType(barcodeType BarcodeType) Option {
+	return func(input *BarcodeInput) error {
+		if err := validateBarcodeType(barcodeType); err != nil {
+			return err
+		}
+		input.BarcodeType = barcodeType
+		return nil
+	}
+}
+
+// WithSize sets the label width and height in millimeters, replacing any
+// preset
+func WithSize(width, height float64) Option {
+	return func(input *BarcodeInput) error {
+		if !(width > 0) || !(height > 0) {
+			return errorf(ErrInvalidDimensions, "invalid label size: %.1f x %.1fmm. Width and height must be positive", width, height)
+		}
+		input.Width, input.Height, input.LabelPreset = width, height, ""
+		return nil
+	}
+}
+
+// WithPreset sizes the label from a label stock preset, replacing any size
+func WithPreset(preset LabelPreset) Option {
+	return func(input *BarcodeInput) error {
+		if _, ok := labelPresets[preset]; !ok {
+			return errorf(ErrInvalidInput, "invalid label preset: %q. Supported presets: %v", preset, LabelPresets())
+		}
+		input.LabelPreset, input.Width, input.Height = preset, 0, 0
+		return nil
+	}
+}
+
+// WithDPI sets the printer resolution, one of the standard thermal printer
+// DPI values
+func WithDPI(dpi int) Option {
+	return func(input *BarcodeInput) error {
+		if err := validateDPI(dpi); err != nil {
+			return err
+		}
+		input.Dpi, input.AllowNonStandardDPI = dpi, false
+		return nil
+	}
+}
+
+// WithNonStandardDPI sets a printer resolution outside the standard values,
+// such as 600 DPI on some print-and-apply engines
+func WithNonStandardDPI(dpi int) Option {
+	return func(input *BarcodeInput) error {
+		if err := validateNonStandardDPI(dpi); err != nil {
+			return err
+		}
+		input.Dpi, input.AllowNonStandardDPI = dpi, true
+		return nil
+	}
+}
+
+// WithText adds a text line at a position and size. Use WithTextLine for
+// alignment, fonts and the other text line settings.
+func WithText(text string, position TextPosition, size TextSize) Option {
+	return WithTextLine(TextLine{Text: text, Position: position, Size: size})
+}
+
+// WithTextLine adds a text line
+func WithTextLine(line TextLine) Option {
+	return func(input *BarcodeInput) error {
+		input.TextLines = append(input.TextLines, line)
+		return nil
+	}
+}
+
+// WithMargin sets the margin on each side of the label in millimeters
+func WithMargin(mm float64) Option {
+	return func(input *BarcodeInput) error {
+		margins := input.Margins
+		margins.MM = mm
+		if err := validateMargins(margins); err != nil {
+			return err
+		}
+		input.Margins = margins
+		return nil
+	}
+}
+
+// WithRotation turns the whole label clockwise by 0, 90, 180 or 270 degrees
+func WithRotation(degrees int) Option {
+	return func(input *BarcodeInput) error {
+		if err := validateLabelRotation(degrees); err != nil {
+			return err
+		}
+		input.Rotation = degrees
+		return nil
+	}
+}
+
+// WithOutputFormats sets the formats to produce instead of PNG and ZPL
+func WithOutputFormats(formats ...OutputFormat) Option {
+	return func(input *BarcodeInput) error {
+		if err := validateOutputFormats(formats); err != nil {
+			return err
+		}
+		input.OutputFormats = formats
+		return nil
+	}
+}
+
+// WithInput changes any other setting of the input, for features without an
+// option of their own
+func WithInput(configure func(*BarcodeInput)) Option {
+	return func(input *BarcodeInput) error {
+		configure(input)
+		return nil
+	}
+}
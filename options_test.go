@@ -0,0 +1,79 @@
+package barcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerate_Options verifies options build the same label as the
+// equivalent BarcodeInput
+func TestGenerate_Options(t *testing.T) {
+	output, err := Generate("LOC-A1-B2-C3",
+		WithSize(75, 40),
+		WithDPI(300),
+		WithText("Warehouse A", TextPositionAbove, TextSizeLarge),
+		WithText("LOC-A1-B2-C3", TextPositionBelow, TextSizeMedium),
+		WithOutputFormats(OutputFormatZPL),
+	)
+	require.NoError(t, err)
+
+	expected, err := GenerateBarcode(BarcodeInput{
+		BarcodeData: "LOC-A1-B2-C3",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       75,
+		Height:      40,
+		Dpi:         300,
+		TextLines: []TextLine{
+			{Text: "Warehouse A", Position: TextPositionAbove, Size: TextSizeLarge},
+			{Text: "LOC-A1-B2-C3", Position: TextPositionBelow, Size: TextSizeMedium},
+		},
+		OutputFormats: []OutputFormat{OutputFormatZPL},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, expected, output)
+}
+
+// TestNewInput_Defaults verifies the defaults form a valid label and later
+// options replace earlier ones
+func TestNewInput_Defaults(t *testing.T) {
+	input, err := NewInput("LOC-A1")
+	require.NoError(t, err)
+	assert.Equal(t, BarcodeInput{BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203}, input)
+	assert.NoError(t, validateInput(input))
+
+	input, err = NewInput("LOC-A1", WithPreset(LabelPreset4x6))
+	require.NoError(t, err)
+	assert.Equal(t, LabelPreset4x6, input.LabelPreset)
+	assert.Zero(t, input.Width)
+	_, err = Generate("LOC-A1", WithPreset(LabelPreset4x6), WithOutputFormats(OutputFormatZPL))
+	assert.NoError(t, err)
+
+	input, err = NewInput("LOC-A1", WithPreset(LabelPreset4x6), WithSize(60, 30), WithNonStandardDPI(400), WithInput(func(input *BarcodeInput) { input.Mirror = true }))
+	require.NoError(t, err)
+	assert.Empty(t, input.LabelPreset)
+	assert.Equal(t, 60.0, input.Width)
+	assert.True(t, input.AllowNonStandardDPI)
+	assert.True(t, input.Mirror)
+}
+
+// TestNewInput_InvalidOptions verifies each option rejects a bad argument
+func TestNewInput_InvalidOptions(t *testing.T) {
+	for _, test := range []struct {
+		option Option
+		err    string
+	}{
+		{WithType("PDF417"), "invalid barcode type: PDF417"},
+		{WithSize(0, 30), "invalid label size: 0.0 x 30.0mm"},
+		{WithPreset("A4"), "invalid label preset: \"A4\""},
+		{WithDPI(400), "invalid dpi value: 400"},
+		{WithNonStandardDPI(50), "Non-standard dpi values must be between 100 and 1200"},
+		{WithMargin(-1), "invalid label margin: -1.0mm"},
+		{WithRotation(45), "invalid label rotation: 45"},
+		{WithOutputFormats("SVG"), "invalid output format: SVG"},
+	} {
+		_, err := NewInput("LOC-A1", test.option)
+		assert.ErrorContains(t, err, test.err)
+	}
+}
@@ -0,0 +1,131 @@
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// GS1 logistic label defaults: A6 portrait, the most common pallet label stock
+const (
+	palletLabelWidthMM  = 105.0
+	palletLabelHeightMM = 148.0
+)
+
+// PalletLabel holds the structured fields of a GS1 logistic label
+type PalletLabel struct {
+	SSCC       string   // Serial Shipping Container Code, 17 digits or 18 with check digit
+	ShipFrom   []string // Optional ship-from name and address lines
+	ShipTo     []string // Ship-to name and address lines
+	Content    string   // Optional GTIN-14 of the contained trade items (AI 02)
+	Count      int      // Number of trade items, required with Content (AI 37)
+	BatchLot   string   // Optional batch or lot number (AI 10)
+	BestBefore string   // Optional best before date as YYMMDD (AI 15)
+	Width      float64  // Label width in millimeters (defaults to 105)
+	Height     float64  // Label height in millimeters (defaults to 148)
+	Dpi        int      // Printer DPI (203, 300, or 600)
+	PreviewDpi int      // Optional DPI for the PNG image (defaults to Dpi)
+}
+
+// GeneratePalletLabel creates a GS1 logistic label: address blocks and the
+// human-readable AI data above an SSCC GS1-128 barcode.
+func GeneratePalletLabel(label PalletLabel) (*BarcodeOutput, error) {
+	sscc, err := normalizeSSCC(label.SSCC)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePalletLabel(label); err != nil {
+		return nil, err
+	}
+
+	width, height := label.Width, label.Height
+	if width == 0 && height == 0 {
+		width, height = palletLabelWidthMM, palletLabelHeightMM
+	}
+
+	return GenerateBarcode(BarcodeInput{
+		BarcodeData: "(00)" + sscc,
+		BarcodeType: BarcodeTypeGS1128,
+		Width:       width,
+		Height:      height,
+		Dpi:         label.Dpi,
+		PreviewDpi:  label.PreviewDpi,
+		TextLines:   palletLabelTextLines(label, sscc),
+	})
+}
+
+// normalizeSSCC accepts a 17 or 18 digit SSCC and returns it with its check digit
+func normalizeSSCC(sscc string) (string, error) {
+	if len(sscc) == 17 && isNumeric(sscc) {
+		sscc += string(gs1CheckDigit(sscc))
+	}
+	if len(sscc) != 18 || !isNumeric(sscc) {
+		return "", fmt.Errorf("invalid SSCC: %q. SSCC must be 17 or 18 digits", sscc)
+	}
+	if expected := gs1CheckDigit(sscc[:17]); sscc[17] != expected {
+		return "", fmt.Errorf("invalid SSCC check digit: %q. Expected check digit %c", sscc, expected)
+	}
+	return sscc, nil
+}
+
+// validatePalletLabel checks the optional fields of a logistic label
+func validatePalletLabel(label PalletLabel) error {
+	if len(label.ShipTo) == 0 {
+		return errors.New("invalid pallet label: ship-to address is required")
+	}
+
+	if label.Content != "" {
+		if err := validateGTIN(label.Content); err != nil {
+			return err
+		}
+		if label.Count <= 0 {
+			return fmt.Errorf("invalid pallet label count: %d. Count is required with content and must be positive", label.Count)
+		}
+	}
+
+	if label.BestBefore != "" && (len(label.BestBefore) != 6 || !isNumeric(label.BestBefore)) {
+		return fmt.Errorf("invalid best before date: %q. Date must be YYMMDD", label.BestBefore)
+	}
+
+	if len(label.BatchLot) > 20 {
+		return fmt.Errorf("invalid batch/lot: %q. Maximum length is 20 characters", label.BatchLot)
+	}
+	return nil
+}
+
+// palletLabelTextLines lays out the label sections from top to bottom:
+// ship-from, ship-to, AI data blocks, then the SSCC interpretation line.
+func palletLabelTextLines(label PalletLabel, sscc string) []TextLine {
+	var lines []TextLine
+	above := func(text string, size TextSize, group string) {
+		lines = append(lines, TextLine{Text: text, Position: TextPositionAbove, Size: size, FitGroup: group})
+	}
+
+	if len(label.ShipFrom) > 0 {
+		above("FROM", TextSizeSmall, "")
+		for _, line := range label.ShipFrom {
+			above(line, TextSizeSmall, "shipFrom")
+		}
+	}
+
+	above("SHIP TO", TextSizeSmall, "")
+	for _, line := range label.ShipTo {
+		above(line, TextSizeLarge, "shipTo")
+	}
+
+	above("SSCC "+sscc, TextSizeMedium, "data")
+	if label.Content != "" {
+		above("CONTENT "+label.Content, TextSizeMedium, "data")
+		above("COUNT "+strconv.Itoa(label.Count), TextSizeMedium, "data")
+	}
+	if label.BestBefore != "" {
+		date := label.BestBefore
+		above("BEST BEFORE "+date[4:6]+"."+date[2:4]+"."+date[0:2], TextSizeMedium, "data")
+	}
+	if label.BatchLot != "" {
+		above("BATCH/LOT "+label.BatchLot, TextSizeMedium, "data")
+	}
+
+	lines = append(lines, TextLine{Text: "(00) " + sscc, Position: TextPositionBelow, Size: TextSizeMedium})
+	return lines
+}
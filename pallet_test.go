@@ -0,0 +1,81 @@
+package barcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGeneratePalletLabel_Success verifies a complete logistic label renders
+func TestGeneratePalletLabel_Success(t *testing.T) {
+	label := PalletLabel{
+		SSCC:       "30614141123456789",
+		ShipFrom:   []string{"OptiWMS Warehouse", "1 Dock Road, Springfield"},
+		ShipTo:     []string{"ACME Retail DC 4", "200 Distribution Way", "Shelbyville 12345"},
+		Content:    "09501101530003",
+		Count:      48,
+		BatchLot:   "L2024-07",
+		BestBefore: "251231",
+		Dpi:        203,
+	}
+
+	output, err := GeneratePalletLabel(label)
+
+	require.NoError(t, err, "Should successfully generate pallet label")
+	assert.NotEmpty(t, output.ImageBase64)
+	assert.Contains(t, output.ZPL, "^XA")
+}
+
+// TestPalletLabelTextLines verifies section order and the SSCC interpretation line
+func TestPalletLabelTextLines(t *testing.T) {
+	lines := palletLabelTextLines(PalletLabel{
+		ShipTo:     []string{"ACME Retail DC 4"},
+		BestBefore: "251231",
+	}, "306141411234567891")
+
+	require.Len(t, lines, 5)
+	assert.Equal(t, "SHIP TO", lines[0].Text)
+	assert.Equal(t, "SSCC 306141411234567891", lines[2].Text)
+	assert.Equal(t, "BEST BEFORE 31.12.25", lines[3].Text)
+	assert.Equal(t, "(00) 306141411234567891", lines[4].Text)
+	assert.Equal(t, TextPositionBelow, lines[4].Position)
+}
+
+// TestGeneratePalletLabel_Invalid ensures malformed fields are rejected
+func TestGeneratePalletLabel_Invalid(t *testing.T) {
+	valid := PalletLabel{SSCC: "306141411234567891", ShipTo: []string{"ACME"}, Dpi: 203}
+
+	tests := []struct {
+		name        string
+		modify      func(*PalletLabel)
+		expectedErr string
+	}{
+		{"BadSSCCLength", func(l *PalletLabel) { l.SSCC = "1234" }, "SSCC must be 17 or 18 digits"},
+		{"BadSSCCCheckDigit", func(l *PalletLabel) { l.SSCC = "306141411234567890" }, "invalid SSCC check digit"},
+		{"NoShipTo", func(l *PalletLabel) { l.ShipTo = nil }, "ship-to address is required"},
+		{"ContentWithoutCount", func(l *PalletLabel) { l.Content = "09501101530003" }, "Count is required"},
+		{"BadBestBefore", func(l *PalletLabel) { l.BestBefore = "2025-12" }, "Date must be YYMMDD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			label := valid
+			tt.modify(&label)
+			_, err := GeneratePalletLabel(label)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+// TestEncodeGS1128 verifies FNC1 placement and check digit validation
+func TestEncodeGS1128(t *testing.T) {
+	bc, err := encodeGS1128("(01)09501101530003(10)ABC(00)306141411234567891")
+	require.NoError(t, err)
+	assert.Equal(t, "ñ010950110153000310ABCñ00306141411234567891", bc.Content())
+
+	_, err = encodeGS1128("(00)306141411234567890")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid GS1 check digit")
+}
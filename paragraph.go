@@ -0,0 +1,139 @@
+package barcode
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// paragraphEllipsis ends the last line of a paragraph that does not fit. It
+// is plain ASCII so printers keep the line in a resident font.
+const paragraphEllipsis = "..."
+
+// TextParagraph is long text such as a product description, wrapped at word
+// boundaries across as many lines as it needs instead of being shrunk onto
+// one line. Text that does not fit within MaxHeightMM is cut, and its last
+// line ends in an ellipsis.
+type TextParagraph struct {
+	Text        string        // Text to wrap; line breaks in it start a new line
+	Position    TextPosition  // ABOVE or BELOW the barcode
+	Size        TextSize      // Size of every line
+	Alignment   TextAlignment // Optional alignment of every line (defaults to CENTER)
+	MaxWidthMM  float64       // Optional width to wrap within (defaults to the width between the label margins)
+	MaxHeightMM float64       // Optional height of the lines; text beyond it is cut with an ellipsis
+}
+
+// paragraphLines wraps a paragraph into text lines at the printer DPI and the
+// label's font scale. Lines are measured in the label font at the size they
+// are drawn, so they fit their width without being shrunk.
+func paragraphLines(paragraph TextParagraph, input BarcodeInput) []TextLine {
+	line := TextLine{Position: paragraph.Position, Size: paragraph.Size, Alignment: paragraph.Alignment}
+	fontSize, fontHeight := getTextLineFontSize(line, input.Dpi, labelFontScale(input))
+
+	maxWidth := mmToPixels(input.Width, input.Dpi) - labelMargin(input)*2
+	if paragraph.MaxWidthMM > 0 {
+		maxWidth = min(maxWidth, mmToPixels(paragraph.MaxWidthMM, input.Dpi))
+	}
+	maxLines := 0
+	if paragraph.MaxHeightMM > 0 && fontHeight > 0 {
+		maxLines = max(1, int(float64(mmToPixels(paragraph.MaxHeightMM, input.Dpi))/fontHeight))
+	}
+
+	fits := func(text string) bool {
+		width, err := measureTextWidth(fontRef{}, text, fontSize, float64(input.Dpi))
+		return err == nil && width <= maxWidth
+	}
+	wrapped := wrapText(paragraph.Text, fits)
+	if maxLines > 0 && len(wrapped) > maxLines {
+		wrapped = wrapped[:maxLines]
+		wrapped[maxLines-1] = truncateWithEllipsis(wrapped[maxLines-1], fits)
+	}
+
+	lines := make([]TextLine, len(wrapped))
+	for i, text := range wrapped {
+		line.Text = text
+		lines[i] = line
+	}
+	return lines
+}
+
+// wrapText breaks text into lines at spaces, filling each line with as many
+// words as fit. A word too long for a line on its own is broken between
+// characters.
+func wrapText(text string, fits func(string) bool) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		current := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if current != "" {
+				candidate = current + " " + word
+			}
+			if fits(candidate) {
+				current = candidate
+				continue
+			}
+			if current != "" {
+				lines = append(lines, current)
+			}
+			current = word
+			for !fits(current) {
+				head := longestFittingPrefix(current, fits)
+				lines = append(lines, head)
+				current = current[len(head):]
+			}
+		}
+		if current != "" {
+			lines = append(lines, current)
+		}
+	}
+	return lines
+}
+
+// longestFittingPrefix returns the longest prefix of text that fits, and at
+// least its first character so wrapping always moves on
+func longestFittingPrefix(text string, fits func(string) bool) string {
+	runes := []rune(text)
+	n := 1
+	for n < len(runes) && fits(string(runes[:n+1])) {
+		n++
+	}
+	return string(runes[:n])
+}
+
+// truncateWithEllipsis drops characters from the end of a line until it fits
+// with the ellipsis appended, trimming trailing spaces and punctuation so the
+// ellipsis follows a word
+func truncateWithEllipsis(text string, fits func(string) bool) string {
+	runes := []rune(text)
+	for len(runes) > 0 {
+		trimmed := strings.TrimRightFunc(string(runes), func(r rune) bool {
+			return unicode.IsSpace(r) || unicode.IsPunct(r)
+		})
+		if trimmed != "" && fits(trimmed+paragraphEllipsis) {
+			return trimmed + paragraphEllipsis
+		}
+		runes = runes[:len(runes)-1]
+	}
+	return paragraphEllipsis
+}
+
+// validateTextParagraphs ensures paragraphs are above or below the barcode,
+// their sizes and alignments are known, and their limits are not negative
+func validateTextParagraphs(paragraphs []TextParagraph) error {
+	for _, paragraph := range paragraphs {
+		if paragraph.Position != TextPositionAbove && paragraph.Position != TextPositionBelow {
+			return fmt.Errorf("invalid text paragraph position: %q. Paragraphs are placed %s or %s the barcode", paragraph.Position, TextPositionAbove, TextPositionBelow)
+		}
+		if err := validateTextSize(paragraph.Size); err != nil {
+			return err
+		}
+		if err := validateTextAlignment(paragraph.Alignment); err != nil {
+			return err
+		}
+		if paragraph.MaxWidthMM < 0 || paragraph.MaxHeightMM < 0 {
+			return fmt.Errorf("invalid text paragraph size: %gx%gmm. Maximum width and height must not be negative", paragraph.MaxWidthMM, paragraph.MaxHeightMM)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package barcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWrapText verifies words fill lines, line breaks are kept and long
+// words are broken between characters
+func TestWrapText(t *testing.T) {
+	fits := func(text string) bool { return len(text) <= 10 }
+	assert.Equal(t, []string{"hex bolts", "M8 x 40 mm", "zinc"}, wrapText("hex bolts M8 x 40 mm zinc", fits))
+	assert.Equal(t, []string{"hex", "bolts"}, wrapText("hex\nbolts", fits))
+	assert.Equal(t, []string{"ABCDEFGHIJ", "KLMN zinc"}, wrapText("ABCDEFGHIJKLMN zinc", fits))
+	assert.Equal(t, "M8 x 40...", truncateWithEllipsis("M8 x 40 mm", fits))
+}
+
+// TestParagraphLines verifies every line fits the label at full size and
+// overflowing text is cut with an ellipsis
+func TestParagraphLines(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "10442",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50.0,
+		Height:      40.0,
+		Dpi:         203,
+		Paragraphs: []TextParagraph{{
+			Text:     "Stainless steel hex bolts, M8 x 40 mm, fully threaded, zinc plated for outdoor use, pack of 50",
+			Position: TextPositionBelow,
+			Size:     TextSizeMedium,
+		}},
+	}
+	lines := paragraphLines(input.Paragraphs[0], input)
+	require.Greater(t, len(lines), 1)
+	maxWidth := mmToPixels(input.Width, input.Dpi) - labelMargin(input)*2
+	fontSize, _ := getFontSize(TextSizeMedium, input.Dpi, labelFontScale(input))
+	for _, line := range lines {
+		width, err := measureTextWidth(fontRef{}, line.Text, fontSize, float64(input.Dpi))
+		require.NoError(t, err)
+		assert.LessOrEqual(t, width, maxWidth)
+	}
+	assert.True(t, strings.HasSuffix(lines[len(lines)-1].Text, "pack of 50"))
+
+	input.Paragraphs[0].MaxHeightMM = 8
+	cut := paragraphLines(input.Paragraphs[0], input)
+	assert.Less(t, len(cut), len(lines))
+	assert.True(t, strings.HasSuffix(cut[len(cut)-1].Text, paragraphEllipsis))
+}
+
+// TestGenerateBarcode_Paragraph verifies wrapped lines print natively in ZPL
+// and bad paragraphs are rejected
+func TestGenerateBarcode_Paragraph(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "10442",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50.0,
+		Height:      40.0,
+		Dpi:         203,
+		Paragraphs: []TextParagraph{{
+			Text:     "Stainless steel hex bolts, M8 x 40 mm, fully threaded, zinc plated for outdoor use, pack of 50",
+			Position: TextPositionBelow,
+			Size:     TextSizeMedium,
+		}},
+		ZPL: ZPLOptions{NativeCommands: true},
+	}
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Contains(t, output.ZPL, "^FDStainless steel")
+	assert.Greater(t, strings.Count(output.ZPL, "^A0N"), 1)
+
+	input.Paragraphs[0].Position = TextPositionLeft
+	_, err = GenerateBarcode(input)
+	assert.ErrorContains(t, err, "invalid text paragraph position")
+
+	input.Paragraphs[0].Position, input.Paragraphs[0].MaxHeightMM = TextPositionBelow, -1
+	_, err = GenerateBarcode(input)
+	assert.ErrorContains(t, err, "invalid text paragraph size")
+}
@@ -0,0 +1,63 @@
+package barcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestImageToPDF verifies the page matches the label size and the xref offsets are correct
+func TestImageToPDF(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	img.Set(10, 10, color.Black)
+
+	pdf, err := imageToPDF(img, 50.8, 25.4)
+	require.NoError(t, err)
+
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+	assert.Contains(t, string(pdf), "/MediaBox [0 0 144.00 72.00]")
+	assert.Contains(t, string(pdf), "/Width 400 /Height 200")
+
+	// Every xref entry must point at its object
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf, -1)
+	require.Len(t, entries, 5)
+	for i, entry := range entries {
+		offset, err := strconv.Atoi(string(entry[1]))
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(pdf[offset:], []byte(strconv.Itoa(i+1)+" 0 obj\n")), "object %d", i+1)
+	}
+
+	again, err := imageToPDF(img, 50.8, 25.4)
+	require.NoError(t, err)
+	assert.Equal(t, pdf, again, "PDF output should be deterministic")
+}
+
+// TestGenerateBarcode_PDF verifies the PDF is only produced on request
+func TestGenerateBarcode_PDF(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1-B2-C3",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       100,
+		Height:      50,
+		Dpi:         203,
+	}
+
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Empty(t, output.PDFBase64)
+
+	input.OutputFormats = []OutputFormat{OutputFormatPDF}
+	output, err = GenerateBarcode(input)
+	require.NoError(t, err)
+	pdf, err := base64.StdEncoding.DecodeString(output.PDFBase64)
+	require.NoError(t, err)
+	assert.Contains(t, string(pdf), "/MediaBox [0 0 283.46 141.73]")
+}
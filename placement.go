@@ -0,0 +1,93 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+)
+
+// Placement is a fixed area on the label, in millimeters from its top-left
+// corner, for label designs specified to the millimeter
+type Placement struct {
+	XMM      float64 // Left edge, in millimeters from the left of the label
+	YMM      float64 // Top edge, in millimeters from the top of the label
+	WidthMM  float64 // Width of the area the barcode is scaled to fit
+	HeightMM float64 // Height of the area; QR codes are squares on its smaller side
+}
+
+// isSet reports whether the placement has been given, rather than left to
+// the automatic layout
+func (p Placement) isSet() bool {
+	return p != Placement{}
+}
+
+// rect returns the placement area in pixels at the DPI
+func (p Placement) rect(dpi int) image.Rectangle {
+	origin := image.Pt(mmToPixels(p.XMM, dpi), mmToPixels(p.YMM, dpi))
+	return image.Rectangle{Min: origin, Max: origin.Add(image.Pt(mmToPixels(p.WidthMM, dpi), mmToPixels(p.HeightMM, dpi)))}
+}
+
+// hasAbsolutePlacement reports whether the barcode or any text line is placed
+// at fixed coordinates instead of by the automatic layout
+func hasAbsolutePlacement(input BarcodeInput) bool {
+	if input.BarcodePlacement.isSet() {
+		return true
+	}
+	for _, textLine := range input.TextLines {
+		if textLine.Position == TextPositionAbsolute {
+			return true
+		}
+	}
+	return false
+}
+
+// placedBarcodeSize returns the size a placed barcode is scaled to at the
+// printer DPI, before BarcodeRotation: its area, turned for quarter turns, and
+// the largest square in it for QR codes
+func placedBarcodeSize(input BarcodeInput) image.Point {
+	size := input.BarcodePlacement.rect(input.Dpi).Size()
+	if input.BarcodeRotation%180 != 0 {
+		size = image.Pt(size.Y, size.X)
+	}
+	if input.BarcodeType == BarcodeTypeQR || input.BarcodeType == BarcodeTypeSwissQR {
+		side := min(size.X, size.Y)
+		size = image.Pt(side, side)
+	}
+	return size
+}
+
+// textLineBaseY returns the Y a text line hangs from or stacks on: YMM for
+// absolute lines, and otherwise its offset from its position around the
+// barcode
+func textLineBaseY(textLine TextLine, barcodeRect image.Rectangle, offset, dpi int) int {
+	if textLine.Position == TextPositionAbsolute {
+		return mmToPixels(textLine.YMM, dpi)
+	}
+	return calculateTextYPosition(barcodeRect, textLine.Position) + offset
+}
+
+// validateBarcodePlacement ensures a placed barcode has a positive size
+// inside the label
+func validateBarcodePlacement(input BarcodeInput) error {
+	if p := input.BarcodePlacement; p.isSet() {
+		if p.WidthMM <= 0 || p.HeightMM <= 0 || p.XMM < 0 || p.YMM < 0 {
+			return fmt.Errorf("invalid barcode placement: needs a positive size and a position inside the label")
+		}
+		if p.XMM+p.WidthMM > input.Width || p.YMM+p.HeightMM > input.Height {
+			return fmt.Errorf("invalid barcode placement: %gx%gmm at %g,%gmm does not fit on a %gx%gmm label", p.WidthMM, p.HeightMM, p.XMM, p.YMM, input.Width, input.Height)
+		}
+	}
+	return nil
+}
+
+// validateTextPlacements ensures absolute text lines start on the label
+func validateTextPlacements(input BarcodeInput) error {
+	for _, textLine := range input.TextLines {
+		if textLine.Position != TextPositionAbsolute {
+			continue
+		}
+		if textLine.XMM < 0 || textLine.YMM < 0 || textLine.XMM > input.Width || textLine.YMM > input.Height {
+			return fmt.Errorf("invalid text placement for %q: %g,%gmm is outside the %gx%gmm label", textLine.Text, textLine.XMM, textLine.YMM, input.Width, input.Height)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package barcode
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLayoutLabel_Placement verifies the barcode is scaled into its area at
+// every DPI and absolute lines do not move it
+func TestLayoutLabel_Placement(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:      "10442",
+		BarcodeType:      BarcodeTypeCode128,
+		Width:            100.0,
+		Height:           50.0,
+		Dpi:              203,
+		BarcodePlacement: Placement{XMM: 5, YMM: 20, WidthMM: 60, HeightMM: 25},
+		TextLines: []TextLine{
+			{Text: "SHIP TO", Position: TextPositionAbsolute, Size: TextSizeSmall, XMM: 5, YMM: 4},
+			{Text: "QTY 12", Position: TextPositionAbsolute, Size: TextSizeMedium, XMM: 95, YMM: 4, Alignment: TextAlignRight},
+		},
+		ZPL: ZPLOptions{NativeCommands: true},
+	}
+	for _, dpi := range []int{203, 300} {
+		bc, err := encodeBarcode(input)
+		require.NoError(t, err)
+		layout, err := layoutLabel(input, bc, dpi)
+		require.NoError(t, err)
+		area := input.BarcodePlacement.rect(dpi)
+		assert.True(t, layout.barcodeRect.In(area), fmt.Sprintf("%v in %v at %d dpi", layout.barcodeRect, area, dpi))
+		assert.InDelta(t, area.Dy(), layout.barcodeRect.Dy(), 1)
+	}
+
+	auto := BarcodeInput{
+		BarcodeData: "10442",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       100.0,
+		Height:      50.0,
+		Dpi:         203,
+		TextLines:   input.TextLines,
+	}
+	bc, err := encodeBarcode(auto)
+	require.NoError(t, err)
+	withText, err := layoutLabel(auto, bc, auto.Dpi)
+	require.NoError(t, err)
+	auto.TextLines = nil
+	withoutText, err := layoutLabel(auto, bc, auto.Dpi)
+	require.NoError(t, err)
+	assert.Equal(t, withoutText.barcodeRect, withText.barcodeRect)
+}
+
+// TestLayoutNativeTextLines_Absolute verifies absolute lines hang from their
+// YMM and align to their XMM
+func TestLayoutNativeTextLines_Absolute(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:      "10442",
+		BarcodeType:      BarcodeTypeCode128,
+		Width:            100.0,
+		Height:           50.0,
+		Dpi:              203,
+		BarcodePlacement: Placement{XMM: 5, YMM: 20, WidthMM: 60, HeightMM: 25},
+		TextLines: []TextLine{
+			{Text: "SHIP TO", Position: TextPositionAbsolute, Size: TextSizeSmall, XMM: 5, YMM: 4},
+			{Text: "QTY 12", Position: TextPositionAbsolute, Size: TextSizeMedium, XMM: 95, YMM: 4, Alignment: TextAlignRight},
+		},
+		ZPL: ZPLOptions{NativeCommands: true},
+	}
+	bc, err := encodeBarcode(input)
+	require.NoError(t, err)
+	layout, err := layoutLabel(input, bc, input.Dpi)
+	require.NoError(t, err)
+
+	lines := layoutNativeTextLines(input, createBlankLabel(layout.width, layout.height), layout)
+	require.Len(t, lines, 2)
+	assert.Equal(t, textAnchor{TextAlignLeft, mmToPixels(5, 203)}, lines[0].anchor)
+	assert.Equal(t, textAnchor{TextAlignRight, mmToPixels(95, 203)}, lines[1].anchor)
+	assert.Greater(t, lines[0].baseline, mmToPixels(4, 203))
+	assert.Less(t, lines[0].baseline, layout.barcodeRect.Min.Y)
+
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Contains(t, output.ZPL, fmt.Sprintf("^FO%d,", mmToPixels(5, 203)))
+	assert.Contains(t, output.ZPL, "^FDSHIP TO^FS")
+}
+
+// TestGenerateBarcode_Placement verifies placements must fit on the label
+func TestGenerateBarcode_Placement(t *testing.T) {
+	tests := []struct {
+		name      string
+		placement Placement
+		textYMM   float64
+		errMsg    string
+	}{
+		{"barcode too wide", Placement{XMM: 5, YMM: 20, WidthMM: 120, HeightMM: 25}, 4, "invalid barcode placement"},
+		{"barcode without height", Placement{XMM: 5, YMM: 20, WidthMM: 60}, 4, "needs a positive size"},
+		{"text off label", Placement{XMM: 5, YMM: 20, WidthMM: 60, HeightMM: 25}, 60, "invalid text placement"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateBarcode(BarcodeInput{
+				BarcodeData:      "10442",
+				BarcodeType:      BarcodeTypeCode128,
+				Width:            100.0,
+				Height:           50.0,
+				Dpi:              203,
+				BarcodePlacement: tt.placement,
+				TextLines:        []TextLine{{Text: "SHIP TO", Position: TextPositionAbsolute, Size: TextSizeSmall, XMM: 5, YMM: tt.textYMM}},
+			})
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
+// TestGenerateBarcode_PlacementESCPOS verifies ESC/POS sends placed labels as
+// one raster graphic
+func TestGenerateBarcode_PlacementESCPOS(t *testing.T) {
+	output, err := GenerateBarcode(BarcodeInput{
+		BarcodeData:      "10442",
+		BarcodeType:      BarcodeTypeCode128,
+		Width:            100.0,
+		Height:           50.0,
+		Dpi:              203,
+		BarcodePlacement: Placement{XMM: 5, YMM: 20, WidthMM: 60, HeightMM: 25},
+		TextLines:        []TextLine{{Text: "SHIP TO", Position: TextPositionAbsolute, Size: TextSizeSmall, XMM: 5, YMM: 4}},
+		OutputFormats:    []OutputFormat{OutputFormatPNG, OutputFormatESCPOS},
+		Code128:          Code128Options{CodeSet: Code128CodeSetB},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, output.ImageBase64)
+	assert.NotContains(t, output.ESCPOS, "\x1dk")
+}
@@ -0,0 +1,203 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// PostProcessor adjusts a rendered label before it is encoded as PNG and ZPL.
+// dpi is the resolution the label was rendered at, so processors can work in
+// physical units. Implementations may modify img in place or return a new image.
+type PostProcessor interface {
+	Process(img *image.RGBA, dpi int) *image.RGBA
+}
+
+// Post-processor types accepted in PostProcessorConfig
+const (
+	PostProcessSharpen   = "sharpen"
+	PostProcessThreshold = "threshold"
+	PostProcessInvert    = "invert"
+	PostProcessBorder    = "border"
+)
+
+// Default post-processor settings
+const (
+	defaultSharpenAmount  = 1.0
+	defaultThresholdLevel = 128
+	defaultBorderWidthMM  = 0.5
+)
+
+// PostProcessorConfig describes one step of a post-processing chain, so
+// chains can be loaded from site configuration files.
+type PostProcessorConfig struct {
+	Type    string  // sharpen, threshold, invert or border
+	Amount  float64 // sharpen: strength (defaults to 1)
+	Level   int     // threshold: luminance 1-255 below which pixels turn black (defaults to 128)
+	WidthMM float64 // border: line width in millimeters (defaults to 0.5)
+}
+
+// NewPostProcessors builds the post-processing chain described by configs
+func NewPostProcessors(configs []PostProcessorConfig) ([]PostProcessor, error) {
+	processors := make([]PostProcessor, 0, len(configs))
+	for _, config := range configs {
+		processor, err := newPostProcessor(config)
+		if err != nil {
+			return nil, err
+		}
+		processors = append(processors, processor)
+	}
+	return processors, nil
+}
+
+func newPostProcessor(config PostProcessorConfig) (PostProcessor, error) {
+	switch config.Type {
+	case PostProcessSharpen:
+		if config.Amount < 0 {
+			return nil, fmt.Errorf("invalid sharpen amount: %g. Amount must be positive", config.Amount)
+		}
+		if config.Amount == 0 {
+			config.Amount = defaultSharpenAmount
+		}
+		return Sharpen{Amount: config.Amount}, nil
+	case PostProcessThreshold:
+		if config.Level < 0 || config.Level > 255 {
+			return nil, fmt.Errorf("invalid threshold level: %d. Level must be from 1 to 255", config.Level)
+		}
+		if config.Level == 0 {
+			config.Level = defaultThresholdLevel
+		}
+		return Threshold{Level: uint8(config.Level)}, nil
+	case PostProcessInvert:
+		return Invert{}, nil
+	case PostProcessBorder:
+		if config.WidthMM < 0 {
+			return nil, fmt.Errorf("invalid border width: %.1fmm. Width must be positive", config.WidthMM)
+		}
+		if config.WidthMM == 0 {
+			config.WidthMM = defaultBorderWidthMM
+		}
+		return Border{WidthMM: config.WidthMM}, nil
+	default:
+		return nil, fmt.Errorf("invalid post-processor type: %q. Supported types are %s, %s, %s and %s",
+			config.Type, PostProcessSharpen, PostProcessThreshold, PostProcessInvert, PostProcessBorder)
+	}
+}
+
+// applyPostProcessors runs the chain in order
+func applyPostProcessors(img *image.RGBA, processors []PostProcessor, dpi int) *image.RGBA {
+	for _, processor := range processors {
+		img = processor.Process(img, dpi)
+	}
+	return img
+}
+
+// Sharpen strengthens edges with a 3x3 unsharp kernel, for printers that
+// blur fine text
+type Sharpen struct {
+	Amount float64
+}
+
+// Process returns a sharpened copy of img
+func (s Sharpen) Process(img *image.RGBA, dpi int) *image.RGBA {
+	bounds := img.Bounds()
+	result := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			center := img.RGBAAt(x, y)
+			var neighbours [3]float64
+			for _, offset := range []image.Point{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+				p := image.Pt(x, y).Add(offset)
+				if !p.In(bounds) {
+					p = image.Pt(x, y)
+				}
+				c := img.RGBAAt(p.X, p.Y)
+				neighbours[0] += float64(c.R)
+				neighbours[1] += float64(c.G)
+				neighbours[2] += float64(c.B)
+			}
+			sharpen := func(v uint8, sum float64) uint8 {
+				return clampUint8(float64(v) + s.Amount*(float64(v)*4-sum)/4)
+			}
+			result.SetRGBA(x, y, color.RGBA{
+				R: sharpen(center.R, neighbours[0]),
+				G: sharpen(center.G, neighbours[1]),
+				B: sharpen(center.B, neighbours[2]),
+				A: center.A,
+			})
+		}
+	}
+	return result
+}
+
+// Threshold converts the label to pure black and white, removing the grey
+// anti-aliasing that some printers render as speckles
+type Threshold struct {
+	Level uint8
+}
+
+// Process thresholds img in place
+func (t Threshold) Process(img *image.RGBA, dpi int) *image.RGBA {
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if color.GrayModel.Convert(img.RGBAAt(x, y)).(color.Gray).Y < t.Level {
+				img.SetRGBA(x, y, color.RGBA{A: 255})
+			} else {
+				img.SetRGBA(x, y, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+// Invert swaps black and white, for printers loaded with dark media
+type Invert struct{}
+
+// Process inverts img in place
+func (Invert) Process(img *image.RGBA, dpi int) *image.RGBA {
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			img.SetRGBA(x, y, color.RGBA{R: 255 - c.R, G: 255 - c.G, B: 255 - c.B, A: c.A})
+		}
+	}
+	return img
+}
+
+// Border draws a black frame around the label edge
+type Border struct {
+	WidthMM float64
+}
+
+// Process draws the border onto img in place
+func (b Border) Process(img *image.RGBA, dpi int) *image.RGBA {
+	bounds := img.Bounds()
+	width := mmToPixels(b.WidthMM, dpi)
+	if width < 1 {
+		width = 1
+	}
+
+	black := &image.Uniform{color.Black}
+	for _, edge := range []image.Rectangle{
+		image.Rect(bounds.Min.X, bounds.Min.Y, bounds.Max.X, bounds.Min.Y+width),
+		image.Rect(bounds.Min.X, bounds.Max.Y-width, bounds.Max.X, bounds.Max.Y),
+		image.Rect(bounds.Min.X, bounds.Min.Y, bounds.Min.X+width, bounds.Max.Y),
+		image.Rect(bounds.Max.X-width, bounds.Min.Y, bounds.Max.X, bounds.Max.Y),
+	} {
+		draw.Draw(img, edge.Intersect(bounds), black, image.Point{}, draw.Src)
+	}
+	return img
+}
+
+func clampUint8(v float64) uint8 {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
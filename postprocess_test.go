@@ -0,0 +1,133 @@
+package barcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testBlack = color.RGBA{A: 255}
+	testWhite = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+)
+
+// TestNewPostProcessors verifies configs build the chain with defaults
+func TestNewPostProcessors(t *testing.T) {
+	var config GeneratorConfig
+	require.NoError(t, json.Unmarshal([]byte(`{"PostProcessors": [
+		{"Type": "threshold"},
+		{"Type": "border", "WidthMM": 1},
+		{"Type": "sharpen", "Amount": 0.5},
+		{"Type": "invert"}
+	]}`), &config))
+
+	processors, err := NewPostProcessors(config.PostProcessors)
+	require.NoError(t, err)
+	assert.Equal(t, []PostProcessor{
+		Threshold{Level: defaultThresholdLevel},
+		Border{WidthMM: 1},
+		Sharpen{Amount: 0.5},
+		Invert{},
+	}, processors)
+}
+
+// TestNewPostProcessors_Invalid ensures unknown types and bad settings are rejected
+func TestNewPostProcessors_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      PostProcessorConfig
+		expectedErr string
+	}{
+		{"UnknownType", PostProcessorConfig{Type: "blur"}, "invalid post-processor type"},
+		{"NegativeAmount", PostProcessorConfig{Type: PostProcessSharpen, Amount: -1}, "invalid sharpen amount"},
+		{"LevelTooHigh", PostProcessorConfig{Type: PostProcessThreshold, Level: 256}, "invalid threshold level"},
+		{"NegativeBorder", PostProcessorConfig{Type: PostProcessBorder, WidthMM: -1}, "invalid border width"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPostProcessors([]PostProcessorConfig{tt.config})
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+// TestPostProcessors verifies each built-in on a small image
+func TestPostProcessors(t *testing.T) {
+	img := createBlankLabel(20, 20)
+	img.SetRGBA(10, 10, color.RGBA{R: 100, G: 100, B: 100, A: 255})
+	img.SetRGBA(11, 10, color.RGBA{R: 200, G: 200, B: 200, A: 255})
+
+	sharpened := Sharpen{Amount: 1}.Process(img, 203)
+	assert.Less(t, sharpened.RGBAAt(10, 10).R, uint8(100), "Dark pixel next to light ones should get darker")
+	assert.Equal(t, testWhite, sharpened.RGBAAt(0, 0))
+
+	thresholded := Threshold{Level: 128}.Process(img, 203)
+	assert.Equal(t, testBlack, thresholded.RGBAAt(10, 10))
+	assert.Equal(t, testWhite, thresholded.RGBAAt(11, 10))
+
+	inverted := Invert{}.Process(thresholded, 203)
+	assert.Equal(t, testWhite, inverted.RGBAAt(10, 10))
+	assert.Equal(t, testBlack, inverted.RGBAAt(0, 0))
+
+	bordered := Border{WidthMM: 0.5}.Process(createBlankLabel(40, 40), 203)
+	width := mmToPixels(0.5, 203)
+	assert.Equal(t, testBlack, bordered.RGBAAt(0, 20))
+	assert.Equal(t, testBlack, bordered.RGBAAt(39, 20))
+	assert.Equal(t, testBlack, bordered.RGBAAt(20, width-1))
+	assert.Equal(t, testWhite, bordered.RGBAAt(20, width))
+}
+
+// TestGenerator_Generate verifies post-processors are applied to the output
+func TestGenerator_Generate(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50.0,
+		Height:      25.0,
+		Dpi:         203,
+	}
+
+	generator, err := NewGenerator(GeneratorConfig{PostProcessors: []PostProcessorConfig{{Type: PostProcessBorder}}})
+	require.NoError(t, err)
+
+	output, err := generator.Generate(input)
+	require.NoError(t, err)
+
+	data, err := base64.StdEncoding.DecodeString(output.ImageBase64)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	r, _, _, _ := img.At(0, img.Bounds().Dy()/2).RGBA()
+	assert.Equal(t, uint32(0), r, "Label edge should be black")
+
+	plain, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.NotEqual(t, plain.ZPL, output.ZPL)
+
+	// The zero value behaves like GenerateBarcode
+	zero, err := (&Generator{}).Generate(input)
+	require.NoError(t, err)
+	assert.Equal(t, plain, zero)
+}
+
+// TestGenerator_GenerateImage verifies post-processors are applied to the returned image
+func TestGenerator_GenerateImage(t *testing.T) {
+	input := BarcodeInput{BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeCode128, Width: 50.0, Height: 25.0, Dpi: 203}
+
+	generator, err := NewGenerator(GeneratorConfig{PostProcessors: []PostProcessorConfig{{Type: PostProcessBorder}}})
+	require.NoError(t, err)
+
+	img, _, err := generator.GenerateImage(input)
+	require.NoError(t, err)
+	r, _, _, _ := img.At(0, img.Bounds().Dy()/2).RGBA()
+	assert.Equal(t, uint32(0), r, "Label edge should be black")
+}
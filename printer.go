@@ -0,0 +1,213 @@
+package barcode
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Raw printing and discovery constants
+const (
+	printerRawPort       = "9100" // Zebra and most label printers accept raw ZPL on this port
+	printerSendTimeout   = 10 * time.Second
+	mdnsAddress          = "224.0.0.251:5353"
+	mdnsPrinterService   = "_pdl-datastream._tcp.local"
+	dnsTypeA             = 1
+	dnsTypePTR           = 12
+	dnsTypeSRV           = 33
+	dnsClassINUnicast    = 0x8001 // IN class with the mDNS unicast-response bit
+	dnsMaxNamePointers   = 16
+	dnsHeaderLength      = 12
+	dnsRecordFixedLength = 10
+)
+
+// Printer is a label printer found on the network
+type Printer struct {
+	Name    string // mDNS instance name, e.g. "ZD421 Pack Station 3"
+	Address string // host:port accepting raw ZPL
+}
+
+// SendZPL sends ZPL to a network printer over a raw TCP connection.
+// The port defaults to 9100 when address is a bare host.
+func SendZPL(address, zpl string) error {
+	if address == "" {
+		return errors.New("invalid printer address: address must not be empty")
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, printerRawPort)
+	}
+
+	conn, err := net.DialTimeout("tcp", address, printerSendTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to printer %s: %w", address, err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(printerSendTimeout)); err != nil {
+		return fmt.Errorf("failed to send ZPL to printer %s: %w", address, err)
+	}
+	if _, err := conn.Write([]byte(zpl)); err != nil {
+		return fmt.Errorf("failed to send ZPL to printer %s: %w", address, err)
+	}
+	return nil
+}
+
+// DiscoverPrinters queries the local network over mDNS for printers that
+// advertise raw printing (_pdl-datastream._tcp), as Zebra Link-OS printers do,
+// and returns those that answer within timeout.
+func DiscoverPrinters(timeout time.Duration) ([]Printer, error) {
+	group, err := net.ResolveUDPAddr("udp4", mdnsAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover printers: %w", err)
+	}
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover printers: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.WriteToUDP(buildMDNSQuery(mdnsPrinterService), group); err != nil {
+		return nil, fmt.Errorf("failed to discover printers: %w", err)
+	}
+	if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, fmt.Errorf("failed to discover printers: %w", err)
+	}
+
+	found := make(map[string]Printer)
+	buf := make([]byte, 9000)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				break
+			}
+			return nil, fmt.Errorf("failed to discover printers: %w", err)
+		}
+		printer, ok := parseMDNSResponse(buf[:n], from.IP)
+		if ok {
+			found[printer.Address] = printer
+		}
+	}
+
+	printers := make([]Printer, 0, len(found))
+	for _, printer := range found {
+		printers = append(printers, printer)
+	}
+	sort.Slice(printers, func(i, j int) bool { return printers[i].Address < printers[j].Address })
+	return printers, nil
+}
+
+// buildMDNSQuery returns a PTR query for service requesting unicast responses
+func buildMDNSQuery(service string) []byte {
+	msg := make([]byte, dnsHeaderLength)
+	binary.BigEndian.PutUint16(msg[4:], 1) // One question
+
+	for _, label := range strings.Split(service, ".") {
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	msg = append(msg, 0)
+	msg = binary.BigEndian.AppendUint16(msg, dnsTypePTR)
+	return binary.BigEndian.AppendUint16(msg, dnsClassINUnicast)
+}
+
+// parseMDNSResponse extracts the printer from a response to the service
+// query. The address comes from an A record when present, otherwise from the
+// sender, and the port from an SRV record, defaulting to 9100.
+func parseMDNSResponse(msg []byte, sender net.IP) (Printer, bool) {
+	if len(msg) < dnsHeaderLength {
+		return Printer{}, false
+	}
+	questions := int(binary.BigEndian.Uint16(msg[4:]))
+	records := int(binary.BigEndian.Uint16(msg[6:])) + int(binary.BigEndian.Uint16(msg[8:])) + int(binary.BigEndian.Uint16(msg[10:]))
+
+	offset := dnsHeaderLength
+	for i := 0; i < questions; i++ {
+		_, next, err := readDNSName(msg, offset)
+		if err != nil || next+4 > len(msg) {
+			return Printer{}, false
+		}
+		offset = next + 4
+	}
+
+	var printer Printer
+	host, port := sender, printerRawPort
+	for i := 0; i < records; i++ {
+		name, next, err := readDNSName(msg, offset)
+		if err != nil || next+dnsRecordFixedLength > len(msg) {
+			return Printer{}, false
+		}
+		recordType := binary.BigEndian.Uint16(msg[next:])
+		dataStart := next + dnsRecordFixedLength
+		dataEnd := dataStart + int(binary.BigEndian.Uint16(msg[next+8:]))
+		if dataEnd > len(msg) {
+			return Printer{}, false
+		}
+
+		switch recordType {
+		case dnsTypePTR:
+			if strings.EqualFold(name, mdnsPrinterService) {
+				instance, _, err := readDNSName(msg, dataStart)
+				if err == nil {
+					printer.Name = strings.TrimSuffix(instance, "."+mdnsPrinterService)
+				}
+			}
+		case dnsTypeSRV:
+			if dataEnd-dataStart >= 6 {
+				port = strconv.Itoa(int(binary.BigEndian.Uint16(msg[dataStart+4:])))
+			}
+		case dnsTypeA:
+			if dataEnd-dataStart == net.IPv4len {
+				host = net.IP(msg[dataStart:dataEnd])
+			}
+		}
+		offset = dataEnd
+	}
+
+	if printer.Name == "" || host == nil {
+		return Printer{}, false
+	}
+	printer.Address = net.JoinHostPort(host.String(), port)
+	return printer, true
+}
+
+// readDNSName reads a possibly compressed name starting at offset and
+// returns it with the offset just past it in the message
+func readDNSName(msg []byte, offset int) (string, int, error) {
+	var labels []string
+	next := -1
+	for pointers := 0; ; {
+		if offset >= len(msg) {
+			return "", 0, errors.New("invalid DNS name: message truncated")
+		}
+		length := int(msg[offset])
+		switch {
+		case length == 0:
+			if next < 0 {
+				next = offset + 1
+			}
+			return strings.Join(labels, "."), next, nil
+		case length&0xC0 == 0xC0:
+			if offset+1 >= len(msg) || pointers >= dnsMaxNamePointers {
+				return "", 0, errors.New("invalid DNS name: bad compression pointer")
+			}
+			if next < 0 {
+				next = offset + 2
+			}
+			offset = int(binary.BigEndian.Uint16(msg[offset:]) & 0x3FFF)
+			pointers++
+		default:
+			if offset+1+length > len(msg) {
+				return "", 0, errors.New("invalid DNS name: message truncated")
+			}
+			labels = append(labels, string(msg[offset+1:offset+1+length]))
+			offset += 1 + length
+		}
+	}
+}
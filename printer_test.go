@@ -0,0 +1,101 @@
+package barcode
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSendZPL verifies ZPL is written to the printer connection
+func TestSendZPL(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			received <- ""
+			return
+		}
+		defer conn.Close()
+		data, _ := io.ReadAll(conn)
+		received <- string(data)
+	}()
+
+	require.NoError(t, SendZPL(listener.Addr().String(), "^XA^FDTEST^FS^XZ"))
+	assert.Equal(t, "^XA^FDTEST^FS^XZ", <-received)
+
+	assert.Error(t, SendZPL("", "^XA^XZ"))
+}
+
+// TestBuildMDNSQuery verifies the PTR question layout
+func TestBuildMDNSQuery(t *testing.T) {
+	query := buildMDNSQuery("_pdl-datastream._tcp.local")
+
+	assert.Equal(t, uint16(1), binary.BigEndian.Uint16(query[4:]))
+	name, next, err := readDNSName(query, dnsHeaderLength)
+	require.NoError(t, err)
+	assert.Equal(t, "_pdl-datastream._tcp.local", name)
+	assert.Equal(t, uint16(dnsTypePTR), binary.BigEndian.Uint16(query[next:]))
+	assert.Equal(t, uint16(dnsClassINUnicast), binary.BigEndian.Uint16(query[next+2:]))
+}
+
+// TestParseMDNSResponse verifies the printer name, address and port are read
+// from a response using name compression
+func TestParseMDNSResponse(t *testing.T) {
+	msg := make([]byte, dnsHeaderLength)
+	binary.BigEndian.PutUint16(msg[2:], 0x8400) // Authoritative response
+	binary.BigEndian.PutUint16(msg[6:], 1)      // PTR answer
+	binary.BigEndian.PutUint16(msg[10:], 2)     // SRV and A additional records
+
+	appendName := func(msg []byte, labels ...string) []byte {
+		for _, label := range labels {
+			msg = append(msg, byte(len(label)))
+			msg = append(msg, label...)
+		}
+		return append(msg, 0)
+	}
+	appendRecord := func(msg []byte, recordType uint16, data []byte) []byte {
+		msg = binary.BigEndian.AppendUint16(msg, recordType)
+		msg = binary.BigEndian.AppendUint16(msg, 1)
+		msg = binary.BigEndian.AppendUint32(msg, 120)
+		msg = binary.BigEndian.AppendUint16(msg, uint16(len(data)))
+		return append(msg, data...)
+	}
+
+	// PTR: service -> instance, where the instance points back at the service name
+	serviceOffset := len(msg)
+	msg = appendName(msg, "_pdl-datastream", "_tcp", "local")
+	pointer := []byte{0xC0 | byte(serviceOffset>>8), byte(serviceOffset)}
+	instance := append([]byte{byte(len("ZD421 Pack"))}, "ZD421 Pack"...)
+	msg = appendRecord(msg, dnsTypePTR, append(instance, pointer...))
+
+	// SRV on port 6101, then the A record
+	msg = append(msg, pointer...)
+	msg = appendRecord(msg, dnsTypeSRV, append([]byte{0, 0, 0, 0, 0x17, 0xD5}, appendName(nil, "zd421", "local")...))
+	msg = appendName(msg, "zd421", "local")
+	msg = appendRecord(msg, dnsTypeA, []byte{10, 0, 0, 5})
+
+	printer, ok := parseMDNSResponse(msg, net.IPv4(10, 0, 0, 99))
+	require.True(t, ok)
+	assert.Equal(t, "ZD421 Pack", printer.Name)
+	assert.Equal(t, "10.0.0.5:6101", printer.Address)
+
+	_, ok = parseMDNSResponse(msg[:20], net.IPv4(10, 0, 0, 99))
+	assert.False(t, ok, "Truncated responses should be ignored")
+}
+
+// TestReadDNSName_PointerLoop ensures compression loops are rejected
+func TestReadDNSName_PointerLoop(t *testing.T) {
+	msg := make([]byte, dnsHeaderLength)
+	msg = append(msg, 0xC0, dnsHeaderLength)
+
+	_, _, err := readDNSName(msg, dnsHeaderLength)
+	assert.Error(t, err)
+}
@@ -0,0 +1,221 @@
+// Package printertest provides a fake network label printer for integration
+// tests, so printer clients can be tested in CI without hardware.
+//
+// The fake accepts raw ZPL over TCP like a Zebra printer on port 9100,
+// records each connection as a job, answers ~HS host status queries and can
+// simulate faults such as paper out.
+package printertest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fault is a printer condition the fake simulates
+type Fault int
+
+const (
+	FaultNone     Fault = iota // Jobs are accepted and printed
+	FaultPaperOut              // Jobs are received but held; ~HS reports paper out
+	FaultHeadOpen              // Jobs are received but held; ~HS reports the head open
+	FaultOffline               // Connections are reset without reading any data
+)
+
+// Job is the data received over one connection
+type Job struct {
+	Data     string    // Raw data as received
+	Labels   int       // Number of ^XA...^XZ label formats
+	Commands []string  // Command names in order, e.g. "^XA", "^FO", "^GF", "~HS"
+	Err      error     // Structural problem found in the data, if any
+	Printed  bool      // False when the job was held by a fault
+	Received time.Time // When the connection closed
+}
+
+// Printer is a fake network label printer
+type Printer struct {
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu    sync.Mutex
+	fault Fault
+	jobs  []Job
+	added chan struct{} // Closed and replaced whenever a job is recorded
+}
+
+// NewPrinter starts a fake printer listening on a random local port. Callers
+// should Close it when done.
+func NewPrinter() (*Printer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start fake printer: %w", err)
+	}
+
+	p := &Printer{listener: listener, added: make(chan struct{})}
+	p.wg.Add(1)
+	go p.serve()
+	return p, nil
+}
+
+// Addr returns the host:port the printer accepts ZPL on
+func (p *Printer) Addr() string {
+	return p.listener.Addr().String()
+}
+
+// SetFault changes the simulated printer condition for later connections
+func (p *Printer) SetFault(fault Fault) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fault = fault
+}
+
+// Jobs returns the jobs received so far, oldest first
+func (p *Printer) Jobs() []Job {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Job(nil), p.jobs...)
+}
+
+// WaitForJobs waits until at least n jobs have been received and returns them
+func (p *Printer) WaitForJobs(n int, timeout time.Duration) ([]Job, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		p.mu.Lock()
+		jobs, added := append([]Job(nil), p.jobs...), p.added
+		p.mu.Unlock()
+		if len(jobs) >= n {
+			return jobs, nil
+		}
+
+		select {
+		case <-added:
+		case <-deadline.C:
+			return jobs, fmt.Errorf("timed out waiting for %d jobs, received %d", n, len(jobs))
+		}
+	}
+}
+
+// Close stops the printer and waits for open connections to finish
+func (p *Printer) Close() error {
+	err := p.listener.Close()
+	p.wg.Wait()
+	return err
+}
+
+func (p *Printer) serve() {
+	defer p.wg.Done()
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.handle(conn)
+		}()
+	}
+}
+
+// handle reads one job, answering host status queries as they arrive
+func (p *Printer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	p.mu.Lock()
+	fault := p.fault
+	p.mu.Unlock()
+	if fault == FaultOffline {
+		if tcpConn, ok := conn.(*net.TCPConn); ok {
+			tcpConn.SetLinger(0)
+		}
+		return
+	}
+
+	var data strings.Builder
+	answered := 0
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		data.Write(buf[:n])
+		for queries := strings.Count(data.String(), "~HS"); answered < queries; answered++ {
+			if _, err := io.WriteString(conn, hostStatus(fault)); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	labels, commands, parseErr := ParseZPL(data.String())
+	job := Job{
+		Data:     data.String(),
+		Labels:   labels,
+		Commands: commands,
+		Err:      parseErr,
+		Printed:  fault == FaultNone && labels > 0,
+		Received: time.Now(),
+	}
+
+	p.mu.Lock()
+	p.jobs = append(p.jobs, job)
+	close(p.added)
+	p.added = make(chan struct{})
+	p.mu.Unlock()
+}
+
+// hostStatus returns the three-line ~HS response for the fault
+func hostStatus(fault Fault) string {
+	paperOut, headOpen := 0, 0
+	switch fault {
+	case FaultPaperOut:
+		paperOut = 1
+	case FaultHeadOpen:
+		headOpen = 1
+	}
+	return fmt.Sprintf("\x02030,%d,0,1245,000,0,0,0,000,0,0,0\x03\r\n", paperOut) +
+		fmt.Sprintf("\x02001,0,%d,0,1,2,6,0,00000000,1,000\x03\r\n", headOpen) +
+		"\x021234,0\x03\r\n"
+}
+
+// ParseZPL returns the number of label formats and the command names in
+// data, and an error when the ^XA/^XZ structure is unbalanced. Command
+// arguments, including graphic and field data, are skipped.
+func ParseZPL(data string) (int, []string, error) {
+	var commands []string
+	labels, open := 0, false
+	for i := 0; i < len(data); i++ {
+		if data[i] != '^' && data[i] != '~' {
+			continue
+		}
+		if i+2 >= len(data) {
+			return labels, commands, fmt.Errorf("truncated command at offset %d", i)
+		}
+		command := strings.ToUpper(data[i : i+3])
+		commands = append(commands, command)
+		i += 2
+
+		switch command {
+		case "^XA":
+			if open {
+				return labels, commands, errors.New("^XA inside an open label format")
+			}
+			open = true
+		case "^XZ":
+			if !open {
+				return labels, commands, errors.New("^XZ without ^XA")
+			}
+			open = false
+			labels++
+		}
+	}
+	if open {
+		return labels, commands, errors.New("label format not terminated with ^XZ")
+	}
+	return labels, commands, nil
+}
@@ -0,0 +1,117 @@
+package printertest
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	barcode "github.com/mattador/barcode-generator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPrinter_RecordsJobs verifies generated labels sent with SendZPL are recorded and parsed
+func TestPrinter_RecordsJobs(t *testing.T) {
+	printer, err := NewPrinter()
+	require.NoError(t, err)
+	defer printer.Close()
+
+	output, err := barcode.GenerateBarcode(barcode.BarcodeInput{
+		BarcodeData: "LOC-A1-B2-C3",
+		BarcodeType: barcode.BarcodeTypeCode128,
+		Width:       50,
+		Height:      25,
+		Dpi:         203,
+	})
+	require.NoError(t, err)
+	require.NoError(t, barcode.SendZPL(printer.Addr(), output.ZPL))
+	_, err = printer.WaitForJobs(1, 5*time.Second)
+	require.NoError(t, err)
+	require.NoError(t, barcode.SendZPL(printer.Addr(), "^XA^FO10,10^FDONE^FS^XZ^XA^FDTWO^FS^XZ"))
+
+	jobs, err := printer.WaitForJobs(2, 5*time.Second)
+	require.NoError(t, err)
+
+	assert.True(t, output.ZPL == jobs[0].Data, "job data should match the ZPL sent")
+	assert.Equal(t, 1, jobs[0].Labels)
+	assert.Contains(t, jobs[0].Commands, "^GF")
+	assert.NoError(t, jobs[0].Err)
+	assert.True(t, jobs[0].Printed)
+
+	assert.Equal(t, 2, jobs[1].Labels)
+	assert.Equal(t, []string{"^XA", "^FO", "^FD", "^FS", "^XZ", "^XA", "^FD", "^FS", "^XZ"}, jobs[1].Commands)
+}
+
+// TestPrinter_PaperOut verifies held jobs and the ~HS paper out flag
+func TestPrinter_PaperOut(t *testing.T) {
+	printer, err := NewPrinter()
+	require.NoError(t, err)
+	defer printer.Close()
+	printer.SetFault(FaultPaperOut)
+
+	conn, err := net.Dial("tcp", printer.Addr())
+	require.NoError(t, err)
+	_, err = io.WriteString(conn, "^XA^FDHELD^FS^XZ~HS")
+	require.NoError(t, err)
+
+	status, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "\x02030,1,0,1245,000,0,0,0,000,0,0,0\x03\r\n", status)
+	require.NoError(t, conn.Close())
+
+	jobs, err := printer.WaitForJobs(1, 5*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 1, jobs[0].Labels)
+	assert.False(t, jobs[0].Printed)
+}
+
+// TestPrinter_Offline verifies offline printers read nothing and record no jobs
+func TestPrinter_Offline(t *testing.T) {
+	printer, err := NewPrinter()
+	require.NoError(t, err)
+	defer printer.Close()
+	printer.SetFault(FaultOffline)
+
+	// The reset may arrive while connecting or on the first read
+	conn, err := net.Dial("tcp", printer.Addr())
+	if err == nil {
+		defer conn.Close()
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		_, err = conn.Read(make([]byte, 1))
+	}
+	assert.Error(t, err)
+
+	_, err = printer.WaitForJobs(1, 50*time.Millisecond)
+	assert.Error(t, err)
+}
+
+// TestParseZPL verifies label formats are counted and unbalanced structure reported
+func TestParseZPL(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		labels      int
+		expectedErr string
+	}{
+		{"Empty", "", 0, ""},
+		{"TwoLabels", "^XA^FDA^FS^XZ\n^xa^fdB^fs^xz", 2, ""},
+		{"Unterminated", "^XA^FDA^FS", 0, "not terminated"},
+		{"ClosedWithoutOpen", "^FDA^FS^XZ", 0, "^XZ without ^XA"},
+		{"Nested", "^XA^XA^XZ", 0, "inside an open label"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels, _, err := ParseZPL(tt.data)
+			assert.Equal(t, tt.labels, labels)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/qr"
+)
+
+// QRErrorCorrection selects how much of a QR code can be damaged or covered
+// while it stays readable
+type QRErrorCorrection string
+
+const (
+	QRErrorCorrectionL QRErrorCorrection = "L" // ~7% recovery
+	QRErrorCorrectionM QRErrorCorrection = "M" // ~15% recovery (default)
+	QRErrorCorrectionQ QRErrorCorrection = "Q" // ~25% recovery
+	QRErrorCorrectionH QRErrorCorrection = "H" // ~30% recovery, for logos over the centre
+)
+
+// QRMode selects the QR data encoding
+type QRMode string
+
+const (
+	QRModeAuto         QRMode = ""             // Most compact mode for the data
+	QRModeNumeric      QRMode = "NUMERIC"      // Digits only
+	QRModeAlphanumeric QRMode = "ALPHANUMERIC" // Digits, uppercase letters, space and $%*+-./:
+	QRModeByte         QRMode = "BYTE"         // Any data, as bytes
+)
+
+// QROptions configures QR codes
+type QROptions struct {
+	ErrorCorrection QRErrorCorrection // Optional error correction level (defaults to M, and H with a logo)
+	Mode            QRMode            // Optional data encoding (defaults to the most compact)
+	MinVersion      int               // Optional minimum version (1-40), so a batch shares one module count
+	Logo            image.Image       // Optional logo composited into the center of the symbol
+	LogoPNG         []byte            // Optional logo as a PNG, instead of Logo
+}
+
+// qrModes maps data encodings to encoder modes
+var qrModes = map[QRMode]qr.Encoding{
+	QRModeAuto:         qr.Auto,
+	QRModeNumeric:      qr.Numeric,
+	QRModeAlphanumeric: qr.AlphaNumeric,
+	QRModeByte:         qr.Unicode,
+}
+
+// qrErrorCorrectionLevels maps error correction options to encoder levels
+var qrErrorCorrectionLevels = map[QRErrorCorrection]qr.ErrorCorrectionLevel{
+	"":                 qr.M,
+	QRErrorCorrectionL: qr.L,
+	QRErrorCorrectionM: qr.M,
+	QRErrorCorrectionQ: qr.Q,
+	QRErrorCorrectionH: qr.H,
+}
+
+// validateQROptions ensures the error correction level, mode and version are known
+func validateQROptions(options QROptions) error {
+	if _, ok := qrErrorCorrectionLevels[options.ErrorCorrection]; !ok {
+		return errorf(ErrInvalidInput, "invalid QR error correction level: %q. Supported levels are L, M, Q and H", options.ErrorCorrection)
+	}
+	if _, ok := qrModes[options.Mode]; !ok {
+		return errorf(ErrInvalidInput, "invalid QR mode: %q. Supported modes are NUMERIC, ALPHANUMERIC and BYTE", options.Mode)
+	}
+	if options.MinVersion < 0 || options.MinVersion > qrMaxVersion {
+		return errorf(ErrInvalidInput, "invalid QR minimum version: %d. Version must be from 1 to %d", options.MinVersion, qrMaxVersion)
+	}
+	return nil
+}
+
+// validateBarcodeDataBytes ensures binary data is only given for QR codes,
+// in byte mode and without text data
+func validateBarcodeDataBytes(input BarcodeInput) error {
+	if input.BarcodeType != BarcodeTypeQR {
+		return errorf(ErrInvalidData, "invalid barcode data: binary data is only supported for %s codes, not %s", BarcodeTypeQR, input.BarcodeType)
+	}
+	if input.BarcodeData != "" {
+		return errorf(ErrInvalidData, "invalid barcode data: set either BarcodeData or BarcodeDataBytes, not both")
+	}
+	if input.QR.Mode != QRModeAuto && input.QR.Mode != QRModeByte {
+		return errorf(ErrInvalidData, "invalid QR mode for binary data: %q. Binary data is encoded in BYTE mode", input.QR.Mode)
+	}
+	return nil
+}
+
+// validateQRData ensures the data can be encoded in the requested mode
+func validateQRData(data string, options QROptions) error {
+	_, err := qrSegmentBits(data, options.Mode)
+	return err
+}
+
+// encodeQRCode creates a QR code at the requested error correction level and
+// mode. The smallest version that fits is used unless MinVersion is larger.
+func encodeQRCode(data string, options QROptions) (barcode.Barcode, error) {
+	if err := validateQROptions(options); err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	level := qrErrorCorrectionLevels[options.ErrorCorrection]
+
+	if options.MinVersion > 1 {
+		bc, err := encodeQRVersion(data, int(level), options.Mode, options.MinVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode QR code: %w", err)
+		}
+		return bc, nil
+	}
+
+	if err := qrDataTooLong(data, int(level), options.Mode); err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	bc, err := qr.Encode(data, level, qrModes[options.Mode])
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	return bc, nil
+}
+
+// encodeQRBytes creates a QR code from raw binary data in byte mode, so
+// bytes that are not valid UTF-8 are encoded unchanged
+func encodeQRBytes(data []byte, options QROptions) (barcode.Barcode, error) {
+	options.Mode = QRModeByte
+	return encodeQRCode(string(data), options)
+}
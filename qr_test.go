@@ -0,0 +1,159 @@
+package barcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/boombuler/barcode/qr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEncodeQRCode_ErrorCorrection verifies higher levels need more modules
+func TestEncodeQRCode_ErrorCorrection(t *testing.T) {
+	data := "https://warehouse.example.com/locations/LOC-A1-B2-C3"
+
+	low, err := encodeQRCode(data, QROptions{ErrorCorrection: QRErrorCorrectionL})
+	require.NoError(t, err)
+	high, err := encodeQRCode(data, QROptions{ErrorCorrection: QRErrorCorrectionH})
+	require.NoError(t, err)
+	def, err := encodeQRCode(data, QROptions{})
+	require.NoError(t, err)
+	medium, err := encodeQRCode(data, QROptions{ErrorCorrection: QRErrorCorrectionM})
+	require.NoError(t, err)
+
+	assert.Greater(t, high.Bounds().Dx(), low.Bounds().Dx())
+	assert.Equal(t, medium.Bounds().Dx(), def.Bounds().Dx(), "Default level should be M")
+}
+
+// TestValidateQROptions ensures unknown levels are rejected
+func TestValidateQROptions(t *testing.T) {
+	assert.NoError(t, validateQROptions(QROptions{}))
+	assert.NoError(t, validateQROptions(QROptions{ErrorCorrection: QRErrorCorrectionQ}))
+
+	err := validateQROptions(QROptions{ErrorCorrection: "X"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid QR error correction level")
+}
+
+// TestEncodeQRVersion_MatchesLibrary verifies the versioned encoder produces
+// the same symbol as the boombuler encoder when no larger version is forced
+func TestEncodeQRVersion_MatchesLibrary(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		mode QRMode
+	}{
+		{"Numeric", "12345678901234567890", QRModeAuto},
+		{"Alphanumeric", "LOC-A1-B2-C3", QRModeAuto},
+		{"Byte", "https://warehouse.example.com/l/A1", QRModeByte},
+		{"LargeVersion", strings.Repeat("pallet ", 60), QRModeAuto},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, err := qr.Encode(tt.data, qr.Q, qrModes[tt.mode])
+			require.NoError(t, err)
+			actual, err := encodeQRVersion(tt.data, int(qr.Q), tt.mode, 1)
+			require.NoError(t, err)
+
+			require.Equal(t, expected.Bounds(), actual.Bounds())
+			for y := 0; y < expected.Bounds().Dy(); y++ {
+				for x := 0; x < expected.Bounds().Dx(); x++ {
+					require.Equal(t, expected.At(x, y), actual.At(x, y), "Module %d,%d should match", x, y)
+				}
+			}
+		})
+	}
+}
+
+// TestEncodeQRCode_MinVersion verifies a batch shares one module count
+func TestEncodeQRCode_MinVersion(t *testing.T) {
+	options := QROptions{MinVersion: 5}
+	for _, data := range []string{"1", "SSCC 00306141411234567891", "LOC-A1"} {
+		bc, err := encodeQRCode(data, options)
+		require.NoError(t, err)
+		assert.Equal(t, 5*4+17, bc.Bounds().Dx(), "%q should use version 5", data)
+		assert.Equal(t, data, bc.Content())
+	}
+
+	// Data that needs a larger version still fits
+	bc, err := encodeQRCode(strings.Repeat("x", 200), QROptions{MinVersion: 2})
+	require.NoError(t, err)
+	assert.Greater(t, bc.Bounds().Dx(), 2*4+17)
+}
+
+// TestEncodeQRCode_Mode verifies forced modes are applied and validated
+func TestEncodeQRCode_Mode(t *testing.T) {
+	numeric, err := encodeQRCode(strings.Repeat("1", 60), QROptions{Mode: QRModeNumeric})
+	require.NoError(t, err)
+	byteMode, err := encodeQRCode(strings.Repeat("1", 60), QROptions{Mode: QRModeByte})
+	require.NoError(t, err)
+	assert.Greater(t, byteMode.Bounds().Dx(), numeric.Bounds().Dx(), "Byte mode should need a larger symbol")
+
+	err = validateQRData("loc-a1", QROptions{Mode: QRModeAlphanumeric})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "alphanumeric mode")
+}
+
+// TestValidateQROptions_ModeAndVersion ensures unknown modes and versions are rejected
+func TestValidateQROptions_ModeAndVersion(t *testing.T) {
+	assert.Error(t, validateQROptions(QROptions{Mode: "KANJI"}))
+	assert.Error(t, validateQROptions(QROptions{MinVersion: 41}))
+	assert.Error(t, validateQROptions(QROptions{MinVersion: -1}))
+	assert.NoError(t, validateQROptions(QROptions{Mode: QRModeByte, MinVersion: 40}))
+}
+
+// TestEncodeQRBytes verifies binary payloads are encoded byte for byte
+func TestEncodeQRBytes(t *testing.T) {
+	payload := []byte{0x08, 0x96, 0x01, 0xff, 0xfe, 0x00, 0xc3}
+
+	bc, err := encodeQRBytes(payload, QROptions{})
+	require.NoError(t, err)
+	assert.Equal(t, string(payload), bc.Content())
+
+	expected, err := qr.Encode(string(payload), qr.M, qr.Unicode)
+	require.NoError(t, err)
+	require.Equal(t, expected.Bounds(), bc.Bounds())
+	for y := 0; y < expected.Bounds().Dy(); y++ {
+		for x := 0; x < expected.Bounds().Dx(); x++ {
+			require.Equal(t, expected.At(x, y), bc.At(x, y))
+		}
+	}
+}
+
+// TestGenerateBarcode_BinaryQR verifies binary data is accepted for QR codes only
+func TestGenerateBarcode_BinaryQR(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeDataBytes: []byte{0x0a, 0x04, 0xff, 0x80, 0x00, 0x01},
+		BarcodeType:      BarcodeTypeQR,
+		Width:            30.0,
+		Height:           30.0,
+		Dpi:              203,
+	}
+
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.NotEmpty(t, output.ZPL)
+
+	tests := []struct {
+		name        string
+		modify      func(*BarcodeInput)
+		expectedErr string
+	}{
+		{"NotQR", func(in *BarcodeInput) { in.BarcodeType = BarcodeTypeCode128 }, "only supported for QR"},
+		{"BothFields", func(in *BarcodeInput) { in.BarcodeData = "text" }, "not both"},
+		{"NumericMode", func(in *BarcodeInput) { in.QR.Mode = QRModeNumeric }, "BYTE mode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalid := input
+			tt.modify(&invalid)
+			_, err := GenerateBarcode(invalid)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
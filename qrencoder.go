@@ -0,0 +1,597 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/utils"
+)
+
+// QR symbol constants
+const (
+	qrMaxVersion       = 40
+	qrAlphanumericSet  = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+	qrFormatPolynomial = 0x537  // BCH(15,5) generator
+	qrFormatMask       = 0x5412 // XOR mask applied to format information
+	qrVersionPoly      = 0x1F25 // BCH(18,6) generator
+	qrPadByteA         = 0xEC
+	qrPadByteB         = 0x11
+)
+
+// QR segment mode indicators
+const (
+	qrModeNumeric      = 1
+	qrModeAlphanumeric = 2
+	qrModeByte         = 4
+)
+
+// qrBlockLayout describes how a version's codewords are split into
+// Reed-Solomon blocks at one error correction level
+type qrBlockLayout struct {
+	ecPerBlock   int
+	group1Blocks int
+	group1Data   int
+	group2Blocks int
+	group2Data   int
+}
+
+func (l qrBlockLayout) dataCodewords() int {
+	return l.group1Blocks*l.group1Data + l.group2Blocks*l.group2Data
+}
+
+// qrBlockLayouts is indexed by version-1 and error correction level (L, M, Q, H)
+var qrBlockLayouts = [qrMaxVersion][4]qrBlockLayout{
+	{{7, 1, 19, 0, 0}, {10, 1, 16, 0, 0}, {13, 1, 13, 0, 0}, {17, 1, 9, 0, 0}},                // 1
+	{{10, 1, 34, 0, 0}, {16, 1, 28, 0, 0}, {22, 1, 22, 0, 0}, {28, 1, 16, 0, 0}},              // 2
+	{{15, 1, 55, 0, 0}, {26, 1, 44, 0, 0}, {18, 2, 17, 0, 0}, {22, 2, 13, 0, 0}},              // 3
+	{{20, 1, 80, 0, 0}, {18, 2, 32, 0, 0}, {26, 2, 24, 0, 0}, {16, 4, 9, 0, 0}},               // 4
+	{{26, 1, 108, 0, 0}, {24, 2, 43, 0, 0}, {18, 2, 15, 2, 16}, {22, 2, 11, 2, 12}},           // 5
+	{{18, 2, 68, 0, 0}, {16, 4, 27, 0, 0}, {24, 4, 19, 0, 0}, {28, 4, 15, 0, 0}},              // 6
+	{{20, 2, 78, 0, 0}, {18, 4, 31, 0, 0}, {18, 2, 14, 4, 15}, {26, 4, 13, 1, 14}},            // 7
+	{{24, 2, 97, 0, 0}, {22, 2, 38, 2, 39}, {22, 4, 18, 2, 19}, {26, 4, 14, 2, 15}},           // 8
+	{{30, 2, 116, 0, 0}, {22, 3, 36, 2, 37}, {20, 4, 16, 4, 17}, {24, 4, 12, 4, 13}},          // 9
+	{{18, 2, 68, 2, 69}, {26, 4, 43, 1, 44}, {24, 6, 19, 2, 20}, {28, 6, 15, 2, 16}},          // 10
+	{{20, 4, 81, 0, 0}, {30, 1, 50, 4, 51}, {28, 4, 22, 4, 23}, {24, 3, 12, 8, 13}},           // 11
+	{{24, 2, 92, 2, 93}, {22, 6, 36, 2, 37}, {26, 4, 20, 6, 21}, {28, 7, 14, 4, 15}},          // 12
+	{{26, 4, 107, 0, 0}, {22, 8, 37, 1, 38}, {24, 8, 20, 4, 21}, {22, 12, 11, 4, 12}},         // 13
+	{{30, 3, 115, 1, 116}, {24, 4, 40, 5, 41}, {20, 11, 16, 5, 17}, {24, 11, 12, 5, 13}},      // 14
+	{{22, 5, 87, 1, 88}, {24, 5, 41, 5, 42}, {30, 5, 24, 7, 25}, {24, 11, 12, 7, 13}},         // 15
+	{{24, 5, 98, 1, 99}, {28, 7, 45, 3, 46}, {24, 15, 19, 2, 20}, {30, 3, 15, 13, 16}},        // 16
+	{{28, 1, 107, 5, 108}, {28, 10, 46, 1, 47}, {28, 1, 22, 15, 23}, {28, 2, 14, 17, 15}},     // 17
+	{{30, 5, 120, 1, 121}, {26, 9, 43, 4, 44}, {28, 17, 22, 1, 23}, {28, 2, 14, 19, 15}},      // 18
+	{{28, 3, 113, 4, 114}, {26, 3, 44, 11, 45}, {26, 17, 21, 4, 22}, {26, 9, 13, 16, 14}},     // 19
+	{{28, 3, 107, 5, 108}, {26, 3, 41, 13, 42}, {30, 15, 24, 5, 25}, {28, 15, 15, 10, 16}},    // 20
+	{{28, 4, 116, 4, 117}, {26, 17, 42, 0, 0}, {28, 17, 22, 6, 23}, {30, 19, 16, 6, 17}},      // 21
+	{{28, 2, 111, 7, 112}, {28, 17, 46, 0, 0}, {30, 7, 24, 16, 25}, {24, 34, 13, 0, 0}},       // 22
+	{{30, 4, 121, 5, 122}, {28, 4, 47, 14, 48}, {30, 11, 24, 14, 25}, {30, 16, 15, 14, 16}},   // 23
+	{{30, 6, 117, 4, 118}, {28, 6, 45, 14, 46}, {30, 11, 24, 16, 25}, {30, 30, 16, 2, 17}},    // 24
+	{{26, 8, 106, 4, 107}, {28, 8, 47, 13, 48}, {30, 7, 24, 22, 25}, {30, 22, 15, 13, 16}},    // 25
+	{{28, 10, 114, 2, 115}, {28, 19, 46, 4, 47}, {28, 28, 22, 6, 23}, {30, 33, 16, 4, 17}},    // 26
+	{{30, 8, 122, 4, 123}, {28, 22, 45, 3, 46}, {30, 8, 23, 26, 24}, {30, 12, 15, 28, 16}},    // 27
+	{{30, 3, 117, 10, 118}, {28, 3, 45, 23, 46}, {30, 4, 24, 31, 25}, {30, 11, 15, 31, 16}},   // 28
+	{{30, 7, 116, 7, 117}, {28, 21, 45, 7, 46}, {30, 1, 23, 37, 24}, {30, 19, 15, 26, 16}},    // 29
+	{{30, 5, 115, 10, 116}, {28, 19, 47, 10, 48}, {30, 15, 24, 25, 25}, {30, 23, 15, 25, 16}}, // 30
+	{{30, 13, 115, 3, 116}, {28, 2, 46, 29, 47}, {30, 42, 24, 1, 25}, {30, 23, 15, 28, 16}},   // 31
+	{{30, 17, 115, 0, 0}, {28, 10, 46, 23, 47}, {30, 10, 24, 35, 25}, {30, 19, 15, 35, 16}},   // 32
+	{{30, 17, 115, 1, 116}, {28, 14, 46, 21, 47}, {30, 29, 24, 19, 25}, {30, 11, 15, 46, 16}}, // 33
+	{{30, 13, 115, 6, 116}, {28, 14, 46, 23, 47}, {30, 44, 24, 7, 25}, {30, 59, 16, 1, 17}},   // 34
+	{{30, 12, 121, 7, 122}, {28, 12, 47, 26, 48}, {30, 39, 24, 14, 25}, {30, 22, 15, 41, 16}}, // 35
+	{{30, 6, 121, 14, 122}, {28, 6, 47, 34, 48}, {30, 46, 24, 10, 25}, {30, 2, 15, 64, 16}},   // 36
+	{{30, 17, 122, 4, 123}, {28, 29, 46, 14, 47}, {30, 49, 24, 10, 25}, {30, 24, 15, 46, 16}}, // 37
+	{{30, 4, 122, 18, 123}, {28, 13, 46, 32, 47}, {30, 48, 24, 14, 25}, {30, 42, 15, 32, 16}}, // 38
+	{{30, 20, 117, 4, 118}, {28, 40, 47, 7, 48}, {30, 43, 24, 22, 25}, {30, 10, 15, 67, 16}},  // 39
+	{{30, 19, 118, 6, 119}, {28, 18, 47, 31, 48}, {30, 34, 24, 34, 25}, {30, 20, 15, 61, 16}}, // 40
+}
+
+// qrFormatLevelBits are the error correction bits of the format information
+var qrFormatLevelBits = [4]int{1, 0, 3, 2}
+
+// qrSymbol is a rendered QR code without quiet zone
+type qrSymbol struct {
+	size     int
+	dark     []bool
+	function []bool // Finder, timing, alignment, format and version modules
+	content  string
+}
+
+func (s *qrSymbol) Content() string {
+	return s.content
+}
+
+func (s *qrSymbol) Metadata() barcode.Metadata {
+	return barcode.Metadata{CodeKind: barcode.TypeQR, Dimensions: 2}
+}
+
+func (s *qrSymbol) ColorModel() color.Model {
+	return color.Gray16Model
+}
+
+func (s *qrSymbol) Bounds() image.Rectangle {
+	return image.Rect(0, 0, s.size, s.size)
+}
+
+func (s *qrSymbol) At(x, y int) color.Color {
+	if s.get(x, y) {
+		return color.Black
+	}
+	return color.White
+}
+
+func (s *qrSymbol) get(x, y int) bool {
+	return s.dark[y*s.size+x]
+}
+
+func (s *qrSymbol) set(x, y int, dark bool) {
+	s.dark[y*s.size+x] = dark
+}
+
+func (s *qrSymbol) setFunction(x, y int, dark bool) {
+	s.set(x, y, dark)
+	s.function[y*s.size+x] = true
+}
+
+// encodeQRVersion encodes data in the given mode at no less than minVersion.
+// It follows the same segment, padding and mask rules as the boombuler
+// encoder, which cannot be asked for a larger version than the data needs.
+func encodeQRVersion(data string, level int, mode QRMode, minVersion int) (barcode.Barcode, error) {
+	if mode == QRModeAuto {
+		mode = qrAutoMode(data)
+	}
+
+	segmentBits, err := qrSegmentBits(data, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	version := 0
+	for v := minVersion; v <= qrMaxVersion; v++ {
+		if qrSegmentLength(mode, len(data), v, segmentBits) <= qrBlockLayouts[v-1][level].dataCodewords()*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, qrDataTooLong(data, level, mode)
+	}
+
+	layout := qrBlockLayouts[version-1][level]
+	codewords := qrDataCodewords(data, mode, version, layout)
+	return renderQRSymbol(data, qrInterleave(codewords, layout), version, level), nil
+}
+
+// qrAutoMode picks the most compact single mode, as the boombuler encoder does
+func qrAutoMode(data string) QRMode {
+	if isNumeric(data) {
+		return QRModeNumeric
+	}
+	for _, r := range data {
+		if !strings.ContainsRune(qrAlphanumericSet, r) {
+			return QRModeByte
+		}
+	}
+	return QRModeAlphanumeric
+}
+
+// qrSegmentBits returns the bit length of the encoded characters
+func qrSegmentBits(data string, mode QRMode) (int, error) {
+	switch mode {
+	case QRModeNumeric:
+		if !isNumeric(data) {
+			return 0, fmt.Errorf("invalid QR data for numeric mode: %q. Only digits are allowed", data)
+		}
+	case QRModeAlphanumeric:
+		for _, r := range data {
+			if !strings.ContainsRune(qrAlphanumericSet, r) {
+				return 0, fmt.Errorf("invalid QR data for alphanumeric mode: %q. Only digits, uppercase letters, space and $%%*+-./: are allowed", data)
+			}
+		}
+	}
+	return qrCharacterBits(mode, len(data)), nil
+}
+
+// qrCharacterBits returns the bit length of length characters in the mode
+func qrCharacterBits(mode QRMode, length int) int {
+	switch mode {
+	case QRModeNumeric:
+		return length/3*10 + [3]int{0, 4, 7}[length%3]
+	case QRModeAlphanumeric:
+		return length/2*11 + length%2*6
+	default:
+		return length * 8
+	}
+}
+
+// qrMaxLength returns the most characters a QR code of the largest version
+// holds in the mode at the error correction level
+func qrMaxLength(mode QRMode, level int) int {
+	return qrVersionMaxLength(mode, level, qrMaxVersion)
+}
+
+// qrVersionMaxLength returns the most characters a QR code of the version
+// holds in the mode at the error correction level
+func qrVersionMaxLength(mode QRMode, level, version int) int {
+	capacity := qrBlockLayouts[version-1][level].dataCodewords() * 8
+	length := 0
+	for qrSegmentLength(mode, length+1, version, qrCharacterBits(mode, length+1)) <= capacity {
+		length++
+	}
+	return length
+}
+
+// qrDataTooLong returns a *DataTooLongError when data does not fit in a QR
+// code of the largest version in the mode at the error correction level, and
+// nil otherwise
+func qrDataTooLong(data string, level int, mode QRMode) error {
+	if mode == QRModeAuto {
+		mode = qrAutoMode(data)
+	}
+	maxLength := qrMaxLength(mode, level)
+	if len(data) <= maxLength {
+		return nil
+	}
+	unit := "characters"
+	if mode == QRModeByte {
+		unit = "bytes"
+	}
+	return &DataTooLongError{Length: len(data), Max: maxLength, Unit: unit}
+}
+
+// qrSegmentLength returns the bits needed at a version: mode indicator,
+// character count and characters
+func qrSegmentLength(mode QRMode, length, version, segmentBits int) int {
+	countBits := qrCharCountBits(mode, version)
+	if length >= 1<<countBits {
+		return math.MaxInt32
+	}
+	return 4 + countBits + segmentBits
+}
+
+// qrCharCountBits returns the width of the character count field
+func qrCharCountBits(mode QRMode, version int) int {
+	sizeClass := 0
+	if version >= 27 {
+		sizeClass = 2
+	} else if version >= 10 {
+		sizeClass = 1
+	}
+	switch mode {
+	case QRModeNumeric:
+		return [3]int{10, 12, 14}[sizeClass]
+	case QRModeAlphanumeric:
+		return [3]int{9, 11, 13}[sizeClass]
+	default:
+		return [3]int{8, 16, 16}[sizeClass]
+	}
+}
+
+// qrDataCodewords builds the segment, terminator and padding codewords
+func qrDataCodewords(data string, mode QRMode, version int, layout qrBlockLayout) []byte {
+	bits := new(utils.BitList)
+	switch mode {
+	case QRModeNumeric:
+		bits.AddBits(qrModeNumeric, 4)
+		bits.AddBits(len(data), byte(qrCharCountBits(mode, version)))
+		for i := 0; i < len(data); i += 3 {
+			group := data[i:min(i+3, len(data))]
+			value := 0
+			for _, c := range group {
+				value = value*10 + int(c-'0')
+			}
+			bits.AddBits(value, byte([4]int{0, 4, 7, 10}[len(group)]))
+		}
+	case QRModeAlphanumeric:
+		bits.AddBits(qrModeAlphanumeric, 4)
+		bits.AddBits(len(data), byte(qrCharCountBits(mode, version)))
+		for i := 0; i+1 < len(data); i += 2 {
+			bits.AddBits(strings.IndexByte(qrAlphanumericSet, data[i])*45+strings.IndexByte(qrAlphanumericSet, data[i+1]), 11)
+		}
+		if len(data)%2 == 1 {
+			bits.AddBits(strings.IndexByte(qrAlphanumericSet, data[len(data)-1]), 6)
+		}
+	default:
+		bits.AddBits(qrModeByte, 4)
+		bits.AddBits(len(data), byte(qrCharCountBits(mode, version)))
+		for i := 0; i < len(data); i++ {
+			bits.AddByte(data[i])
+		}
+	}
+
+	capacity := layout.dataCodewords() * 8
+	for i := 0; i < 4 && bits.Len() < capacity; i++ {
+		bits.AddBit(false)
+	}
+	for bits.Len()%8 != 0 {
+		bits.AddBit(false)
+	}
+	for i := 0; bits.Len() < capacity; i++ {
+		if i%2 == 0 {
+			bits.AddByte(qrPadByteA)
+		} else {
+			bits.AddByte(qrPadByteB)
+		}
+	}
+	return bits.GetBytes()
+}
+
+// qrInterleave splits the data into blocks, adds Reed-Solomon codewords and
+// interleaves data then error correction codewords across the blocks
+func qrInterleave(data []byte, layout qrBlockLayout) []byte {
+	rs := utils.NewReedSolomonEncoder(utils.NewGaloisField(285, 256, 0))
+
+	var dataBlocks, ecBlocks [][]int
+	offset := 0
+	for i := 0; i < layout.group1Blocks+layout.group2Blocks; i++ {
+		length := layout.group1Data
+		if i >= layout.group1Blocks {
+			length = layout.group2Data
+		}
+		block := make([]int, length)
+		for j := range block {
+			block[j] = int(data[offset+j])
+		}
+		offset += length
+		dataBlocks = append(dataBlocks, block)
+		ecBlocks = append(ecBlocks, rs.Encode(block, layout.ecPerBlock))
+	}
+
+	var result []byte
+	for i := 0; i < max(layout.group1Data, layout.group2Data); i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, byte(block[i]))
+			}
+		}
+	}
+	for i := 0; i < layout.ecPerBlock; i++ {
+		for _, block := range ecBlocks {
+			result = append(result, byte(block[i]))
+		}
+	}
+	return result
+}
+
+// renderQRSymbol places the function patterns and codewords, then keeps the
+// mask with the lowest penalty
+func renderQRSymbol(content string, codewords []byte, version, level int) *qrSymbol {
+	size := version*4 + 17
+	base := &qrSymbol{size: size, dark: make([]bool, size*size), function: make([]bool, size*size), content: content}
+
+	drawQRFinderPatterns(base)
+	drawQRAlignmentPatterns(base, version)
+	for i := 0; i < size; i++ {
+		if !base.function[6*size+i] {
+			base.setFunction(i, 6, i%2 == 0)
+		}
+		if !base.function[i*size+6] {
+			base.setFunction(6, i, i%2 == 0)
+		}
+	}
+	base.setFunction(8, size-8, true) // Dark module
+	drawQRVersionInfo(base, version)
+	drawQRFormatInfo(base, level, 0) // Reserve the format areas
+
+	var best *qrSymbol
+	bestPenalty := math.MaxInt
+	for mask := 0; mask < 8; mask++ {
+		symbol := &qrSymbol{size: size, dark: append([]bool(nil), base.dark...), function: base.function, content: content}
+		drawQRFormatInfo(symbol, level, mask)
+		placeQRCodewords(symbol, codewords, mask)
+		if penalty := qrPenalty(symbol); penalty < bestPenalty {
+			best, bestPenalty = symbol, penalty
+		}
+	}
+	return best
+}
+
+func drawQRFinderPatterns(s *qrSymbol) {
+	for _, corner := range []image.Point{{0, 0}, {s.size - 7, 0}, {0, s.size - 7}} {
+		for dy := -1; dy <= 7; dy++ {
+			for dx := -1; dx <= 7; dx++ {
+				x, y := corner.X+dx, corner.Y+dy
+				if x < 0 || y < 0 || x >= s.size || y >= s.size {
+					continue
+				}
+				inside := dx >= 0 && dx <= 6 && dy >= 0 && dy <= 6
+				ring := dx == 0 || dx == 6 || dy == 0 || dy == 6
+				center := dx >= 2 && dx <= 4 && dy >= 2 && dy <= 4
+				s.setFunction(x, y, inside && (ring || center))
+			}
+		}
+	}
+}
+
+func drawQRAlignmentPatterns(s *qrSymbol, version int) {
+	positions := qrAlignmentPositions(version)
+	for _, cx := range positions {
+		for _, cy := range positions {
+			if s.function[cy*s.size+cx] {
+				continue // Overlaps a finder pattern
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					s.setFunction(cx+dx, cy+dy, dx == -2 || dx == 2 || dy == -2 || dy == 2 || (dx == 0 && dy == 0))
+				}
+			}
+		}
+	}
+}
+
+// qrAlignmentPositions returns the alignment pattern centre coordinates
+func qrAlignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	count := version/7 + 2
+	step := (version*4 + count*2 + 1) / (count*2 - 2) * 2
+	if version == 32 {
+		step = 26
+	}
+
+	positions := make([]int, count)
+	positions[0] = 6
+	for i, pos := count-1, version*4+10; i > 0; i, pos = i-1, pos-step {
+		positions[i] = pos
+	}
+	return positions
+}
+
+// drawQRFormatInfo writes both copies of the level and mask with their BCH code
+func drawQRFormatInfo(s *qrSymbol, level, mask int) {
+	data := qrFormatLevelBits[level]<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*qrFormatPolynomial
+	}
+	bits := (data<<10 | rem) ^ qrFormatMask
+	bit := func(i int) bool { return bits>>uint(i)&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		s.setFunction(8, i, bit(i))
+	}
+	s.setFunction(8, 7, bit(6))
+	s.setFunction(8, 8, bit(7))
+	s.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		s.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		s.setFunction(s.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		s.setFunction(8, s.size-15+i, bit(i))
+	}
+}
+
+// drawQRVersionInfo writes the version blocks used from version 7
+func drawQRVersionInfo(s *qrSymbol, version int) {
+	if version < 7 {
+		return
+	}
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = rem<<1 ^ (rem>>11)*qrVersionPoly
+	}
+	bits := version<<12 | rem
+
+	for i := 0; i < 18; i++ {
+		dark := bits>>uint(i)&1 == 1
+		a, b := s.size-11+i%3, i/3
+		s.setFunction(a, b, dark)
+		s.setFunction(b, a, dark)
+	}
+}
+
+// placeQRCodewords fills the data modules in the zigzag column pairs from the
+// bottom right, applying the mask
+func placeQRCodewords(s *qrSymbol, codewords []byte, mask int) {
+	bit := 0
+	upward := true
+	for right := s.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // Skip the vertical timing pattern
+		}
+		for i := 0; i < s.size; i++ {
+			y := i
+			if upward {
+				y = s.size - 1 - i
+			}
+			for x := right; x >= right-1; x-- {
+				if s.function[y*s.size+x] {
+					continue
+				}
+				dark := false
+				if bit < len(codewords)*8 {
+					dark = codewords[bit/8]>>uint(7-bit%8)&1 == 1
+				}
+				s.set(x, y, dark != qrMaskBit(mask, x, y))
+				bit++
+			}
+		}
+		upward = !upward
+	}
+}
+
+// qrMaskBit reports whether the mask pattern inverts the module at x, y
+func qrMaskBit(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (y/2+x/3)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// qrPenalty scores a masked symbol: long runs, 2x2 blocks, finder-like
+// patterns and dark/light imbalance
+func qrPenalty(s *qrSymbol) int {
+	penalty := 0
+	finderA := []bool{true, false, true, true, true, false, true, false, false, false, false}
+	finderB := []bool{false, false, false, false, true, false, true, true, true, false, true}
+
+	for line := 0; line < s.size; line++ {
+		for _, get := range []func(i int) bool{
+			func(i int) bool { return s.get(i, line) },
+			func(i int) bool { return s.get(line, i) },
+		} {
+			run := 1
+			for i := 1; i <= s.size; i++ {
+				if i < s.size && get(i) == get(i-1) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					penalty += run - 2
+				}
+				run = 1
+			}
+
+			for i := 0; i+len(finderA) <= s.size; i++ {
+				matchA, matchB := true, true
+				for j := range finderA {
+					v := get(i + j)
+					matchA = matchA && v == finderA[j]
+					matchB = matchB && v == finderB[j]
+				}
+				if matchA || matchB {
+					penalty += 40
+				}
+			}
+		}
+	}
+
+	dark := 0
+	for y := 0; y < s.size; y++ {
+		for x := 0; x < s.size; x++ {
+			if s.get(x, y) {
+				dark++
+			}
+			if x < s.size-1 && y < s.size-1 {
+				v := s.get(x, y)
+				if s.get(x+1, y) == v && s.get(x, y+1) == v && s.get(x+1, y+1) == v {
+					penalty += 3
+				}
+			}
+		}
+	}
+
+	percent := float64(dark) * 100 / float64(s.size*s.size)
+	deviation := math.Min(math.Abs(math.Floor(percent/5)-10), math.Abs(math.Ceil(percent/5)-10))
+	return penalty + int(deviation)*10
+}
@@ -0,0 +1,105 @@
+package barcode
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+)
+
+// qrLogoMaxCoverage is the largest share of the symbol area a logo may cover.
+// Level H recovers about 30% of the symbol, so this leaves most of it for
+// print defects and damage.
+const qrLogoMaxCoverage = 0.1
+
+// validateQRLogo ensures a logo is only given for QR codes, once, and that it
+// is a readable image
+func validateQRLogo(input BarcodeInput) error {
+	if input.QR.Logo == nil && len(input.QR.LogoPNG) == 0 {
+		return nil
+	}
+	if input.BarcodeType != BarcodeTypeQR {
+		return fmt.Errorf("invalid QR logo: logos are only supported for %s codes, not %s", BarcodeTypeQR, input.BarcodeType)
+	}
+	if input.QR.Logo != nil && len(input.QR.LogoPNG) > 0 {
+		return fmt.Errorf("invalid QR logo: set either Logo or LogoPNG, not both")
+	}
+	if input.QR.Logo != nil && input.QR.Logo.Bounds().Empty() {
+		return fmt.Errorf("invalid QR logo: the image is empty")
+	}
+	if len(input.QR.LogoPNG) > 0 {
+		if _, err := png.DecodeConfig(bytes.NewReader(input.QR.LogoPNG)); err != nil {
+			return fmt.Errorf("invalid QR logo: %w", err)
+		}
+	}
+	return nil
+}
+
+// hasQRLogo reports whether a logo is composited into the QR code
+func hasQRLogo(input BarcodeInput) bool {
+	return input.BarcodeType == BarcodeTypeQR && (input.QR.Logo != nil || len(input.QR.LogoPNG) > 0)
+}
+
+// qrLogoOptions raises error correction to H when a logo covers the center
+// of the QR code, so the covered modules can be recovered
+func qrLogoOptions(input BarcodeInput) QROptions {
+	options := input.QR
+	if hasQRLogo(input) {
+		options.ErrorCorrection = QRErrorCorrectionH
+	}
+	return options
+}
+
+// decodeQRLogo returns the logo image, decoding LogoPNG when set
+func decodeQRLogo(options QROptions) (image.Image, error) {
+	if options.Logo != nil {
+		return options.Logo, nil
+	}
+	logo, err := png.Decode(bytes.NewReader(options.LogoPNG))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode QR logo: %w", err)
+	}
+	return logo, nil
+}
+
+// drawQRLogo draws the logo over the center of the QR code at rect, on a
+// white backing that covers at most qrLogoMaxCoverage of the symbol. The logo
+// keeps its aspect ratio and is converted to greyscale over white, like the
+// rest of the label.
+func drawQRLogo(label *image.RGBA, options QROptions, rect image.Rectangle) error {
+	logo, err := decodeQRLogo(options)
+	if err != nil {
+		return err
+	}
+
+	backingSize := int(float64(rect.Dx()) * math.Sqrt(qrLogoMaxCoverage))
+	center := image.Pt((rect.Min.X+rect.Max.X)/2, (rect.Min.Y+rect.Max.Y)/2)
+	backing := image.Rect(0, 0, backingSize, backingSize).Add(center.Sub(image.Pt(backingSize/2, backingSize/2)))
+	for y := backing.Min.Y; y < backing.Max.Y; y++ {
+		for x := backing.Min.X; x < backing.Max.X; x++ {
+			label.SetRGBA(x, y, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+		}
+	}
+
+	// Fit the logo inside the backing, leaving a white border around it
+	bounds := logo.Bounds()
+	inner := backingSize - backingSize/5
+	width, height := inner, inner*bounds.Dy()/bounds.Dx()
+	if height > inner {
+		width, height = inner*bounds.Dx()/bounds.Dy(), inner
+	}
+	origin := center.Sub(image.Pt(width/2, height/2))
+	for y := 0; y < height; y++ {
+		srcY := bounds.Min.Y + y*bounds.Dy()/height
+		for x := 0; x < width; x++ {
+			r, g, b, a := logo.At(bounds.Min.X+x*bounds.Dx()/width, srcY).RGBA()
+			// Composite the premultiplied logo over white before converting to grey
+			white := 0xFFFF - a
+			gray := color.GrayModel.Convert(color.RGBA64{R: uint16(r + white), G: uint16(g + white), B: uint16(b + white), A: 0xFFFF}).(color.Gray).Y
+			label.SetRGBA(origin.X+x, origin.Y+y, color.RGBA{R: gray, G: gray, B: gray, A: 255})
+		}
+	}
+	return nil
+}
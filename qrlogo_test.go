@@ -0,0 +1,99 @@
+package barcode
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testLogo returns a solid black 20x10 logo
+func testLogo() *image.RGBA {
+	logo := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	draw.Draw(logo, logo.Bounds(), &image.Uniform{color.Black}, image.Point{}, draw.Src)
+	return logo
+}
+
+// TestDrawQRLogo verifies the logo is centered on a white backing within the
+// coverage limit and keeps its aspect ratio
+func TestDrawQRLogo(t *testing.T) {
+	label := image.NewRGBA(image.Rect(0, 0, 200, 200)) // Transparent, so untouched pixels are visible
+	rect := image.Rect(0, 0, 200, 200)
+	require.NoError(t, drawQRLogo(label, QROptions{Logo: testLogo()}, rect))
+
+	covered, dark := 0, image.Rectangle{}
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 200; x++ {
+			c := label.RGBAAt(x, y)
+			if c.A == 0 {
+				continue
+			}
+			covered++
+			if c.R == 0 {
+				dark = dark.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	assert.LessOrEqual(t, float64(covered), qrLogoMaxCoverage*200*200)
+	assert.Equal(t, image.Pt(100, 100), dark.Min.Add(dark.Max).Div(2))
+	assert.InDelta(t, dark.Dx(), dark.Dy()*2, 1)
+}
+
+// TestValidateQRLogo verifies logos are only accepted once, for QR codes, as readable images
+func TestValidateQRLogo(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, testLogo()))
+
+	input := BarcodeInput{BarcodeType: BarcodeTypeQR, QR: QROptions{LogoPNG: buf.Bytes()}}
+	assert.NoError(t, validateQRLogo(input))
+
+	input.QR.Logo = testLogo()
+	assert.ErrorContains(t, validateQRLogo(input), "either Logo or LogoPNG")
+
+	input.QR.LogoPNG = []byte("not a png")
+	input.QR.Logo = nil
+	assert.ErrorContains(t, validateQRLogo(input), "invalid QR logo")
+
+	input = BarcodeInput{BarcodeType: BarcodeTypeCode128, QR: QROptions{Logo: testLogo()}}
+	assert.ErrorContains(t, validateQRLogo(input), "only supported for QR codes")
+}
+
+// TestGenerateBarcode_QRLogo verifies a logo raises error correction to H,
+// is rasterized into the ZPL, and is dropped when styled QR codes are disabled
+func TestGenerateBarcode_QRLogo(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "https://example.com/loc/A1",
+		BarcodeType: BarcodeTypeQR,
+		Width:       20.0,
+		Height:      20.0,
+		Dpi:         203,
+		ZPL:         ZPLOptions{NativeCommands: true},
+	}
+	plain, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Contains(t, plain.ZPL, "^BQ")
+
+	input.QR.Logo = testLogo()
+	bc, err := encodeBarcode(input)
+	require.NoError(t, err)
+	expected, err := encodeQRCode(input.BarcodeData, QROptions{ErrorCorrection: QRErrorCorrectionH})
+	require.NoError(t, err)
+	assert.Equal(t, expected.Bounds(), bc.Bounds())
+
+	withLogo, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.NotContains(t, withLogo.ZPL, "^BQ")
+	assert.NotEqual(t, plain.ImageBase64, withLogo.ImageBase64)
+
+	disabled, err := (&Generator{Features: Features{DisableStyledQR: true}}).Generate(input)
+	require.NoError(t, err)
+	assert.Equal(t, plain.ZPL, disabled.ZPL)
+
+	_, err = GenerateZPLStoredFormat(input, "E:LABEL.ZPL")
+	assert.ErrorContains(t, err, "QR logos")
+}
@@ -23,3 +23,119 @@ func createBlankLabel(width, height int) *image.RGBA {
 func drawBarcodeOnLabel(label *image.RGBA, barcode barcode.Barcode, position image.Rectangle) {
 	draw.Draw(label, position, barcode, barcode.Bounds().Min, draw.Over)
 }
+
+// rotatedBarcode is a barcode turned clockwise by a number of quarter turns
+type rotatedBarcode struct {
+	barcode.Barcode
+	quarterTurns int
+}
+
+// rotateBarcode turns a barcode clockwise by 0, 90, 180 or 270 degrees
+func rotateBarcode(bc barcode.Barcode, degrees int) barcode.Barcode {
+	quarterTurns := degrees / 90 % 4
+	if quarterTurns == 0 {
+		return bc
+	}
+	return &rotatedBarcode{Barcode: bc, quarterTurns: quarterTurns}
+}
+
+func (bc *rotatedBarcode) Bounds() image.Rectangle {
+	size := bc.Barcode.Bounds().Size()
+	if bc.quarterTurns%2 == 1 {
+		return image.Rect(0, 0, size.Y, size.X)
+	}
+	return image.Rect(0, 0, size.X, size.Y)
+}
+
+func (bc *rotatedBarcode) At(x, y int) color.Color {
+	bounds := bc.Barcode.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	switch bc.quarterTurns {
+	case 1:
+		x, y = y, h-1-x
+	case 2:
+		x, y = w-1-x, h-1-y
+	case 3:
+		x, y = w-1-y, x
+	}
+	return bc.Barcode.At(bounds.Min.X+x, bounds.Min.Y+y)
+}
+
+// rotateLabel turns the label clockwise by 0, 90, 180 or 270 degrees, e.g.
+// for media loaded sideways. Quarter turns swap the width and height.
+func rotateLabel(label *image.RGBA, degrees int) *image.RGBA {
+	switch degrees {
+	case 90, 270:
+	case 180:
+		return rotateLabel180(label)
+	default:
+		return label
+	}
+
+	bounds := label.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	rotated := image.NewRGBA(image.Rect(0, 0, height, width))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := label.RGBAAt(bounds.Min.X+x, bounds.Min.Y+y)
+			if degrees == 90 {
+				rotated.SetRGBA(height-1-y, x, c)
+			} else {
+				rotated.SetRGBA(y, width-1-x, c)
+			}
+		}
+	}
+	return rotated
+}
+
+// rotateRect returns the position of rect after a label of the given size is
+// turned clockwise by 0, 90, 180 or 270 degrees with rotateLabel
+func rotateRect(size image.Point, rect image.Rectangle, degrees int) image.Rectangle {
+	switch degrees {
+	case 90:
+		return image.Rect(size.Y-rect.Max.Y, rect.Min.X, size.Y-rect.Min.Y, rect.Max.X)
+	case 180:
+		return image.Rect(size.X-rect.Max.X, size.Y-rect.Max.Y, size.X-rect.Min.X, size.Y-rect.Min.Y)
+	case 270:
+		return image.Rect(rect.Min.Y, size.X-rect.Max.X, rect.Max.Y, size.X-rect.Min.X)
+	}
+	return rect
+}
+
+// rotateLabel180 turns the label upside down
+func rotateLabel180(label *image.RGBA) *image.RGBA {
+	bounds := label.Bounds()
+	rotated := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			rotated.SetRGBA(bounds.Max.X-1-(x-bounds.Min.X), bounds.Max.Y-1-(y-bounds.Min.Y), label.RGBAAt(x, y))
+		}
+	}
+	return rotated
+}
+
+// mirrorLabel flips the label about its vertical axis for applicators that
+// apply from the reverse side. Barcodes are moved to their mirrored positions
+// but keep their original orientation so they stay scannable.
+func mirrorLabel(label *image.RGBA, barcodeRects ...image.Rectangle) *image.RGBA {
+	bounds := label.Bounds()
+	mirrored := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			mirrored.SetRGBA(bounds.Max.X-1-(x-bounds.Min.X), y, label.RGBAAt(x, y))
+		}
+	}
+
+	for _, barcodeRect := range barcodeRects {
+		draw.Draw(mirrored, mirrorRect(bounds, barcodeRect), label, barcodeRect.Min, draw.Src)
+	}
+
+	return mirrored
+}
+
+// mirrorRect returns the position of rect after the label bounds are
+// flipped about their vertical axis
+func mirrorRect(bounds, rect image.Rectangle) image.Rectangle {
+	return image.Rect(bounds.Max.X-(rect.Max.X-bounds.Min.X), rect.Min.Y,
+		bounds.Max.X-(rect.Min.X-bounds.Min.X), rect.Max.Y)
+}
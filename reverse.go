@@ -0,0 +1,58 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+// reverseLabelRegions inverts the label inside each region, so the barcode,
+// text and artwork there print white on black
+func reverseLabelRegions(label *image.RGBA, regions []Placement, dpi int) {
+	for _, region := range regions {
+		area := region.rect(dpi).Intersect(label.Bounds())
+		for y := area.Min.Y; y < area.Max.Y; y++ {
+			for x := area.Min.X; x < area.Max.X; x++ {
+				c := label.RGBAAt(x, y)
+				c.R, c.G, c.B = c.A-c.R, c.A-c.G, c.A-c.B
+				label.SetRGBA(x, y, c)
+			}
+		}
+	}
+}
+
+// zplReverseSource returns the print image as it was before its regions were
+// reversed, for the ^GF graphics that ^FR reverses again on the printer
+func zplReverseSource(input BarcodeInput, printImg *image.RGBA) *image.RGBA {
+	if len(input.ReverseRegions) == 0 {
+		return printImg
+	}
+	source := image.NewRGBA(printImg.Bounds())
+	copy(source.Pix, printImg.Pix)
+	reverseLabelRegions(source, input.ReverseRegions, input.Dpi)
+	return source
+}
+
+// writeZPLReverseRegions writes each region as a filled ^GB box with ^FR, which
+// reverses everything printed before it in the box. It comes after all other
+// fields.
+func writeZPLReverseRegions(zpl *strings.Builder, input BarcodeInput) {
+	for _, region := range input.ReverseRegions {
+		rect := region.rect(input.Dpi)
+		fmt.Fprintf(zpl, "^FO%d,%d^GB%d,%d,%d,B,0^FR^FS\n", rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy(), min(rect.Dx(), rect.Dy()))
+	}
+}
+
+// validateReverseRegions ensures each region has a positive size inside the
+// label
+func validateReverseRegions(input BarcodeInput) error {
+	for i, region := range input.ReverseRegions {
+		if region.WidthMM <= 0 || region.HeightMM <= 0 || region.XMM < 0 || region.YMM < 0 {
+			return fmt.Errorf("invalid reverse region %d: needs a positive size and a position inside the label", i+1)
+		}
+		if region.XMM+region.WidthMM > input.Width || region.YMM+region.HeightMM > input.Height {
+			return fmt.Errorf("invalid reverse region %d: %gx%gmm at %g,%gmm does not fit on a %gx%gmm label", i+1, region.WidthMM, region.HeightMM, region.XMM, region.YMM, input.Width, input.Height)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package barcode
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReverseLabelRegions verifies pixels inside a region are inverted and
+// reversing again restores them
+func TestReverseLabelRegions(t *testing.T) {
+	label := createBlankLabel(80, 80)
+	label.SetRGBA(10, 10, color.RGBA{A: 255})
+	regions := []Placement{{WidthMM: 5, HeightMM: 5}} // 39x39 dots at 203 DPI
+
+	reverseLabelRegions(label, regions, 203)
+	assert.Equal(t, color.RGBA{A: 255}, label.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, label.RGBAAt(10, 10))
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, label.RGBAAt(39, 39))
+
+	source := zplReverseSource(BarcodeInput{Dpi: 203, ReverseRegions: regions}, label)
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, source.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{A: 255}, source.RGBAAt(10, 10))
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, label.RGBAAt(10, 10), "the print image is left reversed")
+}
+
+// TestValidateReverseRegions verifies regions need a size on the label
+func TestValidateReverseRegions(t *testing.T) {
+	input := BarcodeInput{Width: 50, Height: 25}
+
+	input.ReverseRegions = []Placement{{WidthMM: 50, HeightMM: 6}}
+	assert.NoError(t, validateReverseRegions(input))
+
+	input.ReverseRegions = []Placement{{WidthMM: 50, HeightMM: 6}, {XMM: 10, WidthMM: 0, HeightMM: 6}}
+	assert.ErrorContains(t, validateReverseRegions(input), "invalid reverse region 2: needs a positive size")
+
+	input.ReverseRegions = []Placement{{YMM: 20, WidthMM: 50, HeightMM: 6}}
+	assert.ErrorContains(t, validateReverseRegions(input), "does not fit on a 50x25mm label")
+}
+
+// TestGenerateBarcode_ReverseRegions verifies a hazard band prints white on
+// black, with a reversing ^GB box after the other ZPL fields
+func TestGenerateBarcode_ReverseRegions(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:    "UN1263",
+		BarcodeType:    BarcodeTypeCode128,
+		Width:          100,
+		Height:         50,
+		Dpi:            203,
+		TextLines:      []TextLine{{Text: "FLAMMABLE", Position: TextPositionAbsolute, XMM: 50, YMM: 1, Alignment: TextAlignCenter, Size: TextSizeMedium}},
+		Graphics:       []LabelGraphic{{Name: "LOGO", PNG: testLogoPNG(t), XMM: 2, YMM: 1, WidthMM: 4}},
+		ReverseRegions: []Placement{{WidthMM: 100, HeightMM: 8}},
+		OutputFormats:  []OutputFormat{OutputFormatZPL, OutputFormatTSPL},
+		ZPL:            ZPLOptions{NativeCommands: true},
+	}
+
+	bc, err := encodeBarcode(input)
+	require.NoError(t, err)
+	img, err := renderLabelImage(input, bc, input.Dpi)
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{A: 255}, img.RGBAAt(1, 1))
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, img.RGBAAt(20, 20), "logo")
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, img.RGBAAt(1, 70))
+
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Contains(t, output.ZPL, "^FDFLAMMABLE^FS")
+	assert.Contains(t, output.TSPL, "BITMAP 0,0,")
+
+	// The logo graphic is sent as drawn, for ^FR to reverse on the printer
+	input.ReverseRegions = nil
+	plain, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Replace(plain.ZPL, "^XZ\n", "^FO0,0^GB799,63,63,B,0^FR^FS\n^XZ\n", 1), output.ZPL)
+}
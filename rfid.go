@@ -0,0 +1,86 @@
+package barcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RFID limits
+const (
+	rfidMaxDataBytes = 64 // Largest write accepted for a memory bank
+	rfidMaxRetries   = 9  // Most further labels the printer tries after a failed write
+)
+
+// RFIDMemoryBank selects the Gen 2 tag memory bank written
+type RFIDMemoryBank string
+
+const (
+	RFIDMemoryBankEPC  RFIDMemoryBank = "EPC"  // EPC bank, after the CRC and protocol control words (default)
+	RFIDMemoryBankUser RFIDMemoryBank = "USER" // User memory bank, from its start
+)
+
+// rfidMemoryBanks maps memory banks to the ZPL bank number and starting word
+var rfidMemoryBanks = map[RFIDMemoryBank]struct{ bank, startWord int }{
+	RFIDMemoryBankEPC:  {1, 2},
+	RFIDMemoryBankUser: {3, 0},
+}
+
+// RFIDOptions configures encoding of UHF Gen 2 RFID smart labels, such as on
+// ZT411R printers. Tags are only encoded in ZPL output.
+type RFIDOptions struct {
+	Data       string         // Hex data to write, e.g. a 96-bit EPC as 24 hex digits; empty disables encoding
+	MemoryBank RFIDMemoryBank // Optional memory bank to write (defaults to EPC)
+	Retries    int            // Optional: further labels to try when a tag cannot be encoded (0-9)
+}
+
+// validateRFIDOptions ensures the data is whole 16-bit words of hex and the
+// memory bank and retries are supported
+func validateRFIDOptions(options RFIDOptions) error {
+	if options.Data == "" {
+		if options.MemoryBank != "" || options.Retries != 0 {
+			return fmt.Errorf("invalid RFID options: Data is required to encode a tag")
+		}
+		return nil
+	}
+	if len(options.Data)%4 != 0 || len(options.Data)/2 > rfidMaxDataBytes || !isHex(options.Data) {
+		return fmt.Errorf("invalid RFID data: %q. Must be hex digits in whole 16-bit words, up to %d bytes", options.Data, rfidMaxDataBytes)
+	}
+	if _, ok := rfidMemoryBanks[options.MemoryBank]; !ok && options.MemoryBank != "" {
+		return fmt.Errorf("invalid RFID memory bank: %q. Supported banks are %s and %s", options.MemoryBank, RFIDMemoryBankEPC, RFIDMemoryBankUser)
+	}
+	if options.Retries < 0 || options.Retries > rfidMaxRetries {
+		return fmt.Errorf("invalid RFID retries: %d. Must be between 0 and %d", options.Retries, rfidMaxRetries)
+	}
+	return nil
+}
+
+// zplRFIDCommands returns the ^RS setup for Gen 2 tags and the ^RF command
+// that writes the data, or nothing when no data is set
+func zplRFIDCommands(options RFIDOptions) string {
+	if options.Data == "" {
+		return ""
+	}
+
+	memoryBank := options.MemoryBank
+	if memoryBank == "" {
+		memoryBank = RFIDMemoryBankEPC
+	}
+	bank := rfidMemoryBanks[memoryBank]
+
+	var rfid strings.Builder
+	rfid.WriteString("^RS8")
+	if options.Retries > 0 {
+		fmt.Fprintf(&rfid, ",,,%d", options.Retries+1)
+	}
+	fmt.Fprintf(&rfid, "\n^RFW,H,%d,%d,%d^FD%s^FS\n", bank.startWord, len(options.Data)/2, bank.bank, strings.ToUpper(options.Data))
+	return rfid.String()
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
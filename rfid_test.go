@@ -0,0 +1,62 @@
+package barcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateBarcode_RFID verifies the tag is encoded in native and rasterized ZPL
+func TestGenerateBarcode_RFID(t *testing.T) {
+	for _, nativeCommands := range []bool{false, true} {
+		output, err := GenerateBarcode(BarcodeInput{
+			BarcodeData: "LOC-A1-B2-C3",
+			BarcodeType: BarcodeTypeCode128,
+			Width:       50,
+			Height:      25,
+			Dpi:         203,
+			ZPL:         ZPLOptions{NativeCommands: nativeCommands},
+			RFID:        RFIDOptions{Data: "3034257bf7194e4000001a85", Retries: 2},
+		})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(output.ZPL, "^XA\n^RS8,,,3\n^RFW,H,2,12,1^FD3034257BF7194E4000001A85^FS\n"))
+	}
+}
+
+// TestZPLRFIDCommands verifies the memory bank selects the bank and starting word
+func TestZPLRFIDCommands(t *testing.T) {
+	assert.Equal(t, "", zplRFIDCommands(RFIDOptions{}))
+	assert.Equal(t, "^RS8\n^RFW,H,0,4,3^FD0102ABCD^FS\n", zplRFIDCommands(RFIDOptions{Data: "0102abcd", MemoryBank: RFIDMemoryBankUser}))
+}
+
+// TestValidateRFIDOptions verifies data, memory banks and retries are checked
+func TestValidateRFIDOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options RFIDOptions
+		message string
+	}{
+		{"Disabled", RFIDOptions{}, ""},
+		{"EPC", RFIDOptions{Data: "3034257BF7194E4000001A85"}, ""},
+		{"PartialWord", RFIDOptions{Data: "303425"}, "invalid RFID data"},
+		{"NotHex", RFIDOptions{Data: "EPC-1234"}, "invalid RFID data"},
+		{"TooLong", RFIDOptions{Data: strings.Repeat("00", rfidMaxDataBytes+2)}, "invalid RFID data"},
+		{"MemoryBank", RFIDOptions{Data: "0000", MemoryBank: "TID"}, "invalid RFID memory bank"},
+		{"Retries", RFIDOptions{Data: "0000", Retries: 10}, "invalid RFID retries"},
+		{"NoData", RFIDOptions{Retries: 1}, "Data is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRFIDOptions(tt.options)
+			if tt.message == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package barcode
+
+import (
+	"encoding/hex"
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/boombuler/barcode"
+)
+
+// SBPL layout constants
+const (
+	sbplEscape        = "\x1b"
+	sbplMaxMultiplier = 12  // Largest font expansion accepted in both directions
+	sbplMaxNarrow     = 12  // Largest narrow bar width in dots
+	sbplMaxHeight     = 999 // Largest barcode height in dots
+)
+
+// sbplFonts lists the resident bitmap fonts with their cells in dots,
+// including the default character pitch. Their dot size is the same at every
+// head density.
+var sbplFonts = []residentFont{{7, 9}, {10, 15}, {15, 20}, {20, 30}, {30, 52}}
+
+// sbplFontCommands selects each font in sbplFonts
+var sbplFontCommands = []string{"XU", "XS", "XM", "WB", "WL"}
+
+// sbplCode128StartCodes maps forced Code128 code sets to the start code the
+// printer expects at the beginning of the data
+var sbplCode128StartCodes = map[Code128CodeSet]string{
+	Code128CodeSetA: ">G",
+	Code128CodeSetB: ">H",
+	Code128CodeSetC: ">I",
+}
+
+// generateSBPL converts the label to SBPL for SATO printers such as the
+// CL4NX. Text and the linear barcodes SBPL supports are sent as native
+// commands laid out like the print image. Other symbols, and text no resident
+// font fits, are sent as hex graphics cropped from the print image. When
+// native is false, the whole print image is sent as one graphic.
+func generateSBPL(input BarcodeInput, bc barcode.Barcode, printImg *image.RGBA, native bool) (string, error) {
+	var sbpl strings.Builder
+	bounds := printImg.Bounds()
+	fmt.Fprintf(&sbpl, "%sA%sA1%04d%04d", sbplEscape, sbplEscape, bounds.Dy(), bounds.Dx())
+
+	if !native {
+		writeSBPLGraphic(&sbpl, printImg, bounds)
+		writeSBPLEnd(&sbpl)
+		return sbpl.String(), nil
+	}
+
+	layout, err := layoutLabel(input, bc, input.Dpi)
+	if err != nil {
+		return "", err
+	}
+	if command, ok := sbplBarcodeCommand(input, bc, layout); ok {
+		sbpl.WriteString(command)
+	} else {
+		writeSBPLGraphic(&sbpl, printImg, layout.barcodeRect)
+	}
+
+	writeSBPLTextLines(&sbpl, input, printImg, layout)
+	writeSBPLEnd(&sbpl)
+	return sbpl.String(), nil
+}
+
+// sbplBarcodeCommand returns the native barcode command for barcodes SBPL
+// can print identically: unrotated Code128 in a forced code set, ITF, and
+// ISBN or ISSN without an add-on
+func sbplBarcodeCommand(input BarcodeInput, bc barcode.Barcode, layout labelLayout) (string, bool) {
+	if input.BarcodeRotation != 0 || layout.barcodeRect.Dy() > sbplMaxHeight {
+		return "", false
+	}
+	origin, moduleWidth, ok := nativeModuleLayout(bc, layout)
+	if !ok || moduleWidth > sbplMaxNarrow {
+		return "", false
+	}
+
+	// The command selects the wide to narrow ratio: B is 3:1 and D is 2:1
+	var command, data string
+	switch input.BarcodeType {
+	case BarcodeTypeCode128:
+		startCode, ok := sbplCode128StartCodes[input.Code128.CodeSet]
+		if !ok || !isPrintableASCII(input.BarcodeData) {
+			return "", false
+		}
+		command = "BG"
+		data = startCode + input.BarcodeData
+	case BarcodeTypeITF:
+		command = "B2"
+		data = bc.Content()
+	case BarcodeTypeISBN, BarcodeTypeISSN:
+		if input.ISBN.AddOn != "" {
+			return "", false
+		}
+		command = "D3"
+		data = bc.Content()[:12] // The printer adds the check digit
+	default:
+		return "", false
+	}
+
+	return sbplPosition(origin) + fmt.Sprintf("%s%s%02d%03d%s", sbplEscape, command, moduleWidth, layout.barcodeRect.Dy(), data), true
+}
+
+// writeSBPLTextLines writes each text line in the largest resident font that
+// fits its rendered size, falling back to a graphic of the line when none fits
+func writeSBPLTextLines(sbpl *strings.Builder, input BarcodeInput, printImg *image.RGBA, layout labelLayout) {
+	for _, line := range layoutNativeTextLines(input, printImg, layout) {
+		font, multiplier, ok := selectResidentFont(sbplFonts, sbplMaxMultiplier, line, input.Dpi)
+		if !ok {
+			writeSBPLGraphic(sbpl, printImg, textBand(line, input.Dpi, layout.width))
+			continue
+		}
+
+		pos := residentTextPosition(line, sbplFonts[font], multiplier)
+		fmt.Fprintf(sbpl, "%s%sL%02d%02d%s%s%s", sbplPosition(pos), sbplEscape, multiplier, multiplier,
+			sbplEscape, sbplFontCommands[font], line.Text)
+	}
+}
+
+// writeSBPLGraphic writes the area of img as a hex graphic. SBPL graphics are
+// sent in blocks of 8 rows with set bits printed, so the area is padded with
+// white rows to a whole block.
+func writeSBPLGraphic(sbpl *strings.Builder, img *image.RGBA, area image.Rectangle) {
+	area = area.Intersect(img.Bounds())
+	if area.Empty() {
+		return
+	}
+
+	rowBytes, data := packMonochrome(img, area)
+	blocks := (area.Dy() + 7) / 8
+	padded := make([]byte, rowBytes*blocks*8)
+	for i, b := range data {
+		padded[i] = ^b
+	}
+
+	fmt.Fprintf(sbpl, "%s%sGH%03d%03d", sbplPosition(area.Min), sbplEscape, rowBytes, blocks)
+	sbpl.WriteString(strings.ToUpper(hex.EncodeToString(padded)))
+}
+
+// writeSBPLEnd prints one label and ends the job
+func writeSBPLEnd(sbpl *strings.Builder) {
+	fmt.Fprintf(sbpl, "%sQ1%sZ", sbplEscape, sbplEscape)
+}
+
+// sbplPosition returns the commands moving the print position to pt
+func sbplPosition(pt image.Point) string {
+	return fmt.Sprintf("%sH%04d%sV%04d", sbplEscape, pt.X, sbplEscape, pt.Y)
+}
@@ -0,0 +1,87 @@
+package barcode
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateBarcode_SBPL verifies supported barcodes and text use native commands
+func TestGenerateBarcode_SBPL(t *testing.T) {
+	output, err := GenerateBarcode(BarcodeInput{
+		BarcodeData:   "LOC-A1-B2-C3",
+		BarcodeType:   BarcodeTypeCode128,
+		Width:         50,
+		Height:        25,
+		Dpi:           203,
+		OutputFormats: []OutputFormat{OutputFormatSBPL},
+		Code128:       Code128Options{CodeSet: Code128CodeSetB},
+		TextLines:     []TextLine{{Text: "LOC-A1-B2-C3", Position: TextPositionBelow, Size: TextSizeMedium}},
+	})
+	require.NoError(t, err)
+
+	commands := strings.Split(output.SBPL, "\x1b")
+	require.Len(t, commands, 12)
+	assert.Equal(t, []string{"", "A", "A101990399"}, commands[:3])
+	assert.Regexp(t, `^H\d{4}$`, commands[3])
+	assert.Regexp(t, `^BG02\d{3}>HLOC-A1-B2-C3$`, commands[5])
+	assert.Regexp(t, `^L\d{4}$`, commands[8])
+	assert.Regexp(t, `^(XU|XS|XM|WB|WL)LOC-A1-B2-C3$`, commands[9])
+	assert.Equal(t, []string{"Q1", "Z"}, commands[10:])
+}
+
+// TestGenerateBarcode_SBPLBarcodes verifies the barcode selection for each native type
+func TestGenerateBarcode_SBPLBarcodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    BarcodeInput
+		expected string
+	}{
+		{"Code128SetC", BarcodeInput{BarcodeData: "123456", BarcodeType: BarcodeTypeCode128, Code128: Code128Options{CodeSet: Code128CodeSetC}}, ">I123456"},
+		{"ITF", BarcodeInput{BarcodeData: "12345678", BarcodeType: BarcodeTypeITF}, "B2"},
+		{"ISBN", BarcodeInput{BarcodeData: "978-0-306-40615-7", BarcodeType: BarcodeTypeISBN}, "D3"},
+		{"Code128Auto", BarcodeInput{BarcodeData: "LOC-1", BarcodeType: BarcodeTypeCode128}, "GH"},
+		{"QR", BarcodeInput{BarcodeData: "https://example.com", BarcodeType: BarcodeTypeQR}, "GH"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input.Width, tt.input.Height, tt.input.Dpi = 50, 30, 203
+			tt.input.OutputFormats = []OutputFormat{OutputFormatSBPL}
+			output, err := GenerateBarcode(tt.input)
+			require.NoError(t, err)
+			assert.Contains(t, strings.Split(output.SBPL, "\x1b")[5], tt.expected)
+		})
+	}
+}
+
+// TestGenerateBarcode_SBPLMirror verifies mirrored labels are sent as a single graphic
+func TestGenerateBarcode_SBPLMirror(t *testing.T) {
+	output, err := GenerateBarcode(BarcodeInput{
+		BarcodeData:   "LOC-A1-B2-C3",
+		BarcodeType:   BarcodeTypeCode128,
+		Width:         50,
+		Height:        25,
+		Dpi:           203,
+		OutputFormats: []OutputFormat{OutputFormatSBPL},
+		Mirror:        true,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, output.SBPL, "\x1bH0000\x1bV0000\x1bGH050025")
+	assert.NotContains(t, output.SBPL, "\x1bBG")
+}
+
+// TestWriteSBPLGraphic verifies dark pixels are set bits and the area is padded to 8-row blocks
+func TestWriteSBPLGraphic(t *testing.T) {
+	img := createBlankLabel(10, 2)
+	img.Set(0, 0, color.Black)
+	img.Set(9, 1, color.Black)
+
+	var sbpl strings.Builder
+	writeSBPLGraphic(&sbpl, img, image.Rect(0, 0, 20, 2))
+	assert.Equal(t, "\x1bH0000\x1bV0000\x1bGH002001"+"80000040"+strings.Repeat("0000", 6), sbpl.String())
+}
@@ -0,0 +1,112 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/boombuler/barcode"
+)
+
+// minScannableModuleDots is the narrowest module, in printer dots, that print
+// heads reproduce reliably. Narrower bars bleed into their spaces.
+const minScannableModuleDots = 2
+
+// checkScannability returns warnings for a composed label whose barcode
+// modules are narrower than minScannableModuleDots at the printer DPI, or
+// whose quiet zone has other content or the label edge in it. With
+// StrictScannability the first warning is returned as an error instead. IMb
+// bars have a fixed pitch and are not checked.
+func checkScannability(input BarcodeInput, bc barcode.Barcode, labelImg *image.RGBA, barcodeRect image.Rectangle) ([]string, error) {
+	if input.BarcodeType == BarcodeTypeIMb {
+		return nil, nil
+	}
+
+	vertical := input.BarcodeRotation%180 != 0
+	humanReadable := isHumanReadableEAN(input)
+	moduleDots := barcodeModuleWidth(input, bc, barcodeRect)
+
+	var warnings []string
+	if moduleDots < minScannableModuleDots {
+		warnings = append(warnings, fmt.Sprintf("barcode module width of %d at %d DPI is below the minimum of %d dots. Use a larger label, a higher DPI or XDimensionMils",
+			moduleDots, input.Dpi, minScannableModuleDots))
+	}
+
+	// The leading digit of EAN-13 human-readable text is printed in its quiet zone
+	if quietZone, ok := quietZoneModules[input.BarcodeType]; ok && !humanReadable && moduleDots > 0 {
+		square := input.BarcodeType == BarcodeTypeQR
+		if !quietZoneClear(labelImg, symbolRect(bc, barcodeRect, moduleDots, vertical, square), quietZone*moduleDots, vertical, square) {
+			warnings = append(warnings, fmt.Sprintf("barcode quiet zone is squeezed: %s needs %d clear modules (%.1fmm) on each side of the symbol. Set Margins.QuietZone or move nearby content",
+				input.BarcodeType, quietZone, float64(quietZone*moduleDots)*25.4/float64(input.Dpi)))
+		}
+	}
+
+	if input.StrictScannability && len(warnings) > 0 {
+		return nil, errorf(ErrNotScannable, "label may not scan: %s", warnings[0])
+	}
+	return warnings, nil
+}
+
+// barcodeModuleWidth returns the width in pixels of a module of a barcode
+// scaled to barcodeRect, counting the leading digit that EAN-13
+// human-readable text prints in the quiet zone
+func barcodeModuleWidth(input BarcodeInput, bc barcode.Barcode, barcodeRect image.Rectangle) int {
+	modules, length := bc.Bounds().Dx(), barcodeRect.Dx()
+	if input.BarcodeRotation%180 != 0 {
+		length = barcodeRect.Dy()
+	}
+	if isHumanReadableEAN(input) {
+		modules += eanLeadingDigitWidth
+	}
+	return length / modules
+}
+
+// symbolRect returns the modules of a symbol within the area it was scaled
+// to, which barcode.Scale centers in whole modules. Linear symbols span the
+// depth of the area; square symbols are as deep as they are long.
+func symbolRect(bc barcode.Barcode, barcodeRect image.Rectangle, moduleDots int, vertical, square bool) image.Rectangle {
+	along, across := bc.Bounds().Dx()*moduleDots, barcodeRect.Dy()
+	if vertical {
+		across = barcodeRect.Dx()
+	}
+	if square {
+		across = min(across, along)
+	}
+	symbol := image.Rect(0, 0, along, across)
+	if vertical {
+		symbol = image.Rect(0, 0, across, along)
+	}
+	offset := image.Pt((barcodeRect.Dx()-symbol.Dx())/2, (barcodeRect.Dy()-symbol.Dy())/2)
+	return symbol.Add(barcodeRect.Min).Add(offset)
+}
+
+// quietZoneClear reports whether the zones of the given width before and
+// after the symbol along its length, and above and below it for square
+// symbols, are inside the label and free of dark pixels
+func quietZoneClear(labelImg *image.RGBA, symbol image.Rectangle, width int, vertical, allSides bool) bool {
+	var zones []image.Rectangle
+	if !vertical || allSides {
+		zones = append(zones,
+			image.Rect(symbol.Min.X-width, symbol.Min.Y, symbol.Min.X, symbol.Max.Y),
+			image.Rect(symbol.Max.X, symbol.Min.Y, symbol.Max.X+width, symbol.Max.Y))
+	}
+	if vertical || allSides {
+		zones = append(zones,
+			image.Rect(symbol.Min.X, symbol.Min.Y-width, symbol.Max.X, symbol.Min.Y),
+			image.Rect(symbol.Min.X, symbol.Max.Y, symbol.Max.X, symbol.Max.Y+width))
+	}
+
+	for _, zone := range zones {
+		if !zone.In(labelImg.Bounds()) {
+			return false
+		}
+		for y := zone.Min.Y; y < zone.Max.Y; y++ {
+			for x := zone.Min.X; x < zone.Max.X; x++ {
+				if color.GrayModel.Convert(labelImg.RGBAAt(x, y)).(color.Gray).Y < 128 {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package barcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCheckScannability_ModuleSize verifies long data on a narrow label warns
+// about modules below two dots, and is rejected when strict
+func TestCheckScannability_ModuleSize(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1-B2-C3-D4",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       40,
+		Height:      20,
+		Dpi:         203,
+		Margins:     Margins{QuietZone: true},
+	}
+
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	require.Len(t, output.Warnings, 1)
+	assert.Contains(t, output.Warnings[0], "barcode module width of 1 at 203 DPI is below the minimum of 2 dots")
+
+	input.Dpi = 600
+	output, err = GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Empty(t, output.Warnings)
+
+	input.Dpi = 203
+	input.StrictScannability = true
+	_, err = GenerateBarcode(input)
+	assert.ErrorContains(t, err, "label may not scan: barcode module width")
+}
+
+// TestCheckScannability_QuietZone verifies content or the label edge inside
+// the quiet zone is reported, and a symbol sized for its quiet zone is not
+func TestCheckScannability_QuietZone(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1-B2-C3",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50,
+		Height:      25,
+		Dpi:         203,
+		Margins:     Margins{QuietZone: true},
+	}
+
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Empty(t, output.Warnings)
+
+	input.Shapes = []LabelShape{{Type: ShapeLine, XMM: 3, YMM: 2, HeightMM: 20}}
+	output, err = GenerateBarcode(input)
+	require.NoError(t, err)
+	require.Len(t, output.Warnings, 1)
+	assert.Contains(t, output.Warnings[0], "barcode quiet zone is squeezed: CODE128 needs 10 clear modules")
+
+	qr := BarcodeInput{BarcodeData: "https://example.com", BarcodeType: BarcodeTypeQR, Width: 50, Height: 50, Dpi: 203, Margins: Margins{QuietZone: true}}
+	output, err = GenerateBarcode(qr)
+	require.NoError(t, err)
+	assert.Empty(t, output.Warnings)
+
+	qr.TextLines = []TextLine{{Text: "SCAN ME", Position: TextPositionBelow, Size: TextSizeLarge}}
+	qr.Margins.QuietZone = false
+	output, err = GenerateBarcode(qr)
+	require.NoError(t, err)
+	require.Len(t, output.Warnings, 1)
+	assert.Contains(t, output.Warnings[0], "QR needs 4 clear modules")
+}
+
+// TestCheckScannability_Rotated verifies the quiet zone of a quarter-turned
+// linear symbol is checked above and below it
+func TestCheckScannability_Rotated(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:     "LOC-A1",
+		BarcodeType:     BarcodeTypeCode128,
+		Width:           30,
+		Height:          60,
+		Dpi:             300,
+		BarcodeRotation: 90,
+		Margins:         Margins{QuietZone: true},
+	}
+
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Empty(t, output.Warnings)
+
+	input.Shapes = []LabelShape{{Type: ShapeLine, XMM: 1, YMM: 6, WidthMM: 28}}
+	output, err = GenerateBarcode(input)
+	require.NoError(t, err)
+	require.Len(t, output.Warnings, 1)
+	assert.Contains(t, output.Warnings[0], "quiet zone is squeezed")
+}
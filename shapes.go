@@ -0,0 +1,167 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+)
+
+// ShapeType is the kind of primitive shape drawn on a label
+type ShapeType string
+
+const (
+	ShapeLine    ShapeType = "LINE"    // Horizontal or vertical rule
+	ShapeBox     ShapeType = "BOX"     // Rectangle border, or a filled box
+	ShapeCircle  ShapeType = "CIRCLE"  // Circle or ellipse in its box
+	ShapeDiamond ShapeType = "DIAMOND" // Square turned 45 degrees in its box, e.g. a GHS pictogram border
+)
+
+// defaultShapeThicknessMM is the stroke width of shapes without ThicknessMM
+const defaultShapeThicknessMM = 0.3
+
+// LabelShape is a line or outline drawn on the label, positioned in
+// millimeters from its top-left corner
+type LabelShape struct {
+	Type        ShapeType
+	XMM         float64 // Left edge of the shape's box
+	YMM         float64 // Top edge of the shape's box
+	WidthMM     float64 // Width of the box; for lines, the length of a horizontal rule
+	HeightMM    float64 // Height of the box; for lines, the length of a vertical rule
+	ThicknessMM float64 // Optional stroke width (defaults to 0.3)
+	Filled      bool    // Optional: fill boxes, circles and diamonds instead of outlining them
+}
+
+// rect returns the box a shape covers in pixels at the DPI, with lines as
+// thick as their stroke
+func (s LabelShape) rect(dpi int) image.Rectangle {
+	width, height := mmToPixels(s.WidthMM, dpi), mmToPixels(s.HeightMM, dpi)
+	if s.Type == ShapeLine {
+		if width == 0 {
+			width = s.thickness(dpi)
+		} else {
+			height = s.thickness(dpi)
+		}
+	}
+	origin := image.Pt(mmToPixels(s.XMM, dpi), mmToPixels(s.YMM, dpi))
+	return image.Rect(0, 0, width, height).Add(origin)
+}
+
+// thickness returns the stroke width in pixels at the DPI, at least one
+func (s LabelShape) thickness(dpi int) int {
+	thickness := s.ThicknessMM
+	if thickness == 0 {
+		thickness = defaultShapeThicknessMM
+	}
+	return max(1, mmToPixels(thickness, dpi))
+}
+
+// covers reports whether the pixel at x, y relative to the shape's box is
+// dark. Outlines are the stroke width measured inwards from the edge.
+func (s LabelShape) covers(x, y int, size image.Point, thickness int) bool {
+	switch s.Type {
+	case ShapeCircle, ShapeDiamond:
+		a, b := float64(size.X)/2, float64(size.Y)/2
+		dx, dy := math.Abs(float64(x)+0.5-a), math.Abs(float64(y)+0.5-b)
+		inside := func(shrink float64) bool {
+			if s.Type == ShapeCircle {
+				ra, rb := a-shrink, b-shrink
+				return ra > 0 && rb > 0 && (dx*dx)/(ra*ra)+(dy*dy)/(rb*rb) <= 1
+			}
+			// The edge x/a + y/b = 1 is a*b/hypot(a, b) from the centre
+			k := 1 - shrink*math.Hypot(a, b)/(a*b)
+			return k > 0 && dx/(k*a)+dy/(k*b) <= 1
+		}
+		return inside(0) && (s.Filled || !inside(float64(thickness)))
+	case ShapeBox:
+		if s.Filled {
+			return true
+		}
+		return x < thickness || y < thickness || x >= size.X-thickness || y >= size.Y-thickness
+	default:
+		return true
+	}
+}
+
+// drawLabelShapes draws each shape onto the label in black
+func drawLabelShapes(label *image.RGBA, shapes []LabelShape, dpi int) {
+	for _, shape := range shapes {
+		rect := shape.rect(dpi)
+		thickness := shape.thickness(dpi)
+		area := rect.Intersect(label.Bounds())
+		for y := area.Min.Y; y < area.Max.Y; y++ {
+			for x := area.Min.X; x < area.Max.X; x++ {
+				if shape.covers(x-rect.Min.X, y-rect.Min.Y, rect.Size(), thickness) {
+					label.Set(x, y, color.Black)
+				}
+			}
+		}
+	}
+}
+
+// writeZPLLabelShapes writes lines and boxes as ^GB, circles as ^GE and
+// outlined diamonds as four ^GD diagonals. Filled diamonds have no ZPL
+// command and are sent as a ^GF graphic of their box.
+func writeZPLLabelShapes(zpl *strings.Builder, input BarcodeInput, printImg *image.RGBA) {
+	for _, shape := range input.Shapes {
+		rect := shape.rect(input.Dpi)
+		thickness := shape.thickness(input.Dpi)
+		width, height := rect.Dx(), rect.Dy()
+		if shape.Filled {
+			thickness = min(width, height)
+		}
+
+		switch shape.Type {
+		case ShapeLine, ShapeBox:
+			fmt.Fprintf(zpl, "^FO%d,%d^GB%d,%d,%d,B,0^FS\n", rect.Min.X, rect.Min.Y, width, height, thickness)
+		case ShapeCircle:
+			if shape.Filled {
+				thickness = (min(width, height) + 1) / 2
+			}
+			fmt.Fprintf(zpl, "^FO%d,%d^GE%d,%d,%d,B^FS\n", rect.Min.X, rect.Min.Y, width, height, thickness)
+		case ShapeDiamond:
+			if shape.Filled {
+				writeZPLGraphic(zpl, printImg, rect, input.ZPL.CompressZ64)
+				continue
+			}
+			halfWidth, halfHeight := width/2, height/2
+			for _, edge := range []struct {
+				x, y        int
+				orientation string
+			}{
+				{rect.Min.X, rect.Min.Y, "R"},
+				{rect.Min.X + halfWidth, rect.Min.Y, "L"},
+				{rect.Min.X, rect.Min.Y + halfHeight, "L"},
+				{rect.Min.X + halfWidth, rect.Min.Y + halfHeight, "R"},
+			} {
+				fmt.Fprintf(zpl, "^FO%d,%d^GD%d,%d,%d,B,%s^FS\n", edge.x, edge.y, halfWidth, halfHeight, thickness, edge.orientation)
+			}
+		}
+	}
+}
+
+// validateLabelShapes ensures each shape has a known type, a size its type
+// can draw and a box inside the label
+func validateLabelShapes(input BarcodeInput) error {
+	for i, shape := range input.Shapes {
+		switch shape.Type {
+		case ShapeLine, ShapeBox, ShapeCircle, ShapeDiamond:
+		default:
+			return fmt.Errorf("invalid label shape %d: type %q. Supported types are %s, %s, %s and %s", i+1, shape.Type, ShapeLine, ShapeBox, ShapeCircle, ShapeDiamond)
+		}
+		if shape.XMM < 0 || shape.YMM < 0 || shape.WidthMM < 0 || shape.HeightMM < 0 || shape.ThicknessMM < 0 {
+			return fmt.Errorf("invalid label shape %d: position, size and thickness must not be negative", i+1)
+		}
+		if shape.Type == ShapeLine && (shape.WidthMM == 0) == (shape.HeightMM == 0) {
+			return fmt.Errorf("invalid label shape %d: %gx%gmm. Lines are horizontal or vertical, with one of WidthMM and HeightMM set", i+1, shape.WidthMM, shape.HeightMM)
+		}
+		if shape.Type != ShapeLine && (shape.WidthMM == 0 || shape.HeightMM == 0) {
+			return fmt.Errorf("invalid label shape %d: %gx%gmm. %s shapes need a width and a height", i+1, shape.WidthMM, shape.HeightMM, shape.Type)
+		}
+		if shape.XMM+shape.WidthMM > input.Width || shape.YMM+shape.HeightMM > input.Height {
+			return fmt.Errorf("invalid label shape %d: %gx%gmm at %g,%gmm does not fit on a %gx%gmm label", i+1, shape.WidthMM, shape.HeightMM, shape.XMM, shape.YMM, input.Width, input.Height)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package barcode
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLabelShapeRect verifies lines are as thick as their stroke and other
+// shapes fill their box
+func TestLabelShapeRect(t *testing.T) {
+	line := LabelShape{Type: ShapeLine, XMM: 2, YMM: 10, WidthMM: 50}
+	assert.Equal(t, image.Rect(15, 79, 414, 81), line.rect(203))
+
+	line.ThicknessMM = 1
+	assert.Equal(t, image.Rect(15, 79, 414, 86), line.rect(203))
+
+	box := LabelShape{Type: ShapeBox, XMM: 2, YMM: 10, WidthMM: 50, HeightMM: 20}
+	assert.Equal(t, image.Rect(15, 79, 414, 238), box.rect(203))
+}
+
+// TestLabelShapeCovers verifies outlines are drawn inwards from the edge of
+// the box and filled shapes cover their inside
+func TestLabelShapeCovers(t *testing.T) {
+	size := image.Pt(100, 100)
+	diamond := LabelShape{Type: ShapeDiamond}
+	assert.True(t, diamond.covers(50, 1, size, 4), "top corner")
+	assert.True(t, diamond.covers(1, 50, size, 4), "left corner")
+	assert.False(t, diamond.covers(50, 50, size, 4), "centre")
+	assert.False(t, diamond.covers(5, 5, size, 4), "outside the edge")
+
+	circle := LabelShape{Type: ShapeCircle}
+	assert.True(t, circle.covers(50, 1, size, 4))
+	assert.False(t, circle.covers(50, 50, size, 4))
+	assert.False(t, circle.covers(2, 2, size, 4))
+
+	circle.Filled = true
+	assert.True(t, circle.covers(50, 50, size, 4))
+	assert.False(t, circle.covers(2, 2, size, 4))
+
+	box := LabelShape{Type: ShapeBox}
+	assert.True(t, box.covers(3, 50, size, 4))
+	assert.False(t, box.covers(4, 50, size, 4))
+}
+
+// TestValidateLabelShapes verifies types, sizes and positions are checked
+func TestValidateLabelShapes(t *testing.T) {
+	input := BarcodeInput{Width: 50, Height: 25}
+
+	tests := []struct {
+		name    string
+		shape   LabelShape
+		message string
+	}{
+		{"Line", LabelShape{Type: ShapeLine, XMM: 2, YMM: 20, WidthMM: 46}, ""},
+		{"Diamond", LabelShape{Type: ShapeDiamond, XMM: 30, YMM: 2, WidthMM: 20, HeightMM: 20, Filled: true}, ""},
+		{"Type", LabelShape{Type: "TRIANGLE", WidthMM: 10, HeightMM: 10}, `type "TRIANGLE"`},
+		{"Diagonal", LabelShape{Type: ShapeLine, WidthMM: 10, HeightMM: 10}, "Lines are horizontal or vertical"},
+		{"NoLength", LabelShape{Type: ShapeLine}, "Lines are horizontal or vertical"},
+		{"Flat", LabelShape{Type: ShapeCircle, WidthMM: 10}, "need a width and a height"},
+		{"Negative", LabelShape{Type: ShapeBox, WidthMM: 10, HeightMM: 10, ThicknessMM: -1}, "must not be negative"},
+		{"OffLabel", LabelShape{Type: ShapeBox, XMM: 45, WidthMM: 10, HeightMM: 10}, "does not fit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input.Shapes = []LabelShape{tt.shape}
+			err := validateLabelShapes(input)
+			if tt.message == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid label shape 1")
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+// TestGenerateBarcode_Shapes verifies shapes are drawn on the label and sent
+// as native ZPL commands, with a GHS diamond as four diagonals
+func TestGenerateBarcode_Shapes(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:      "UN1263",
+		BarcodeType:      BarcodeTypeCode128,
+		Width:            100,
+		Height:           50,
+		Dpi:              203,
+		BarcodePlacement: Placement{XMM: 2, YMM: 5, WidthMM: 60, HeightMM: 25},
+		Shapes: []LabelShape{
+			{Type: ShapeDiamond, XMM: 70, YMM: 5, WidthMM: 25, HeightMM: 25, ThicknessMM: 1},
+			{Type: ShapeCircle, XMM: 75, YMM: 35, WidthMM: 10, HeightMM: 10, Filled: true},
+			{Type: ShapeLine, XMM: 2, YMM: 45, WidthMM: 96},
+		},
+		OutputFormats: []OutputFormat{OutputFormatZPL, OutputFormatTSPL},
+		ZPL:           ZPLOptions{NativeCommands: true},
+	}
+
+	bc, err := encodeBarcode(input)
+	require.NoError(t, err)
+	img, err := renderLabelImage(input, bc, input.Dpi)
+	require.NoError(t, err)
+	diamond := input.Shapes[0].rect(input.Dpi)
+	assert.True(t, hasDarkPixel(img, image.Rect(diamond.Min.X, diamond.Min.Y+diamond.Dy()/2-1, diamond.Min.X+3, diamond.Min.Y+diamond.Dy()/2+1)))
+	assert.False(t, hasDarkPixel(img, image.Rect(diamond.Min.X, diamond.Min.Y, diamond.Min.X+10, diamond.Min.Y+10)))
+	assert.False(t, hasDarkPixel(img, image.Rectangle{Min: diamond.Min.Add(image.Pt(90, 90)), Max: diamond.Min.Add(image.Pt(110, 110))}))
+
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Contains(t, output.ZPL, "^BCN,")
+	assert.Contains(t, output.ZPL, "^FO559,39^GD99,99,7,B,R^FS\n^FO658,39^GD99,99,7,B,L^FS\n^FO559,138^GD99,99,7,B,L^FS\n^FO658,138^GD99,99,7,B,R^FS\n")
+	assert.Contains(t, output.ZPL, "^FO599,279^GE79,79,40,B^FS\n")
+	assert.Contains(t, output.ZPL, "^FO15,359^GB767,2,2,B,0^FS\n")
+	assert.NotContains(t, output.ZPL, "^GF")
+	assert.Contains(t, output.TSPL, "BITMAP 0,0,")
+
+	input.Shapes[0].Filled = true
+	output, err = GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Contains(t, output.ZPL, "^FO559,39^GFA,")
+	assert.NotContains(t, output.ZPL, "^GD")
+}
@@ -0,0 +1,98 @@
+package barcode
+
+import "image"
+
+// maxSideColumnShare is the largest share of the width between the label
+// margins that a text column beside the barcode may take
+const maxSideColumnShare = 1.0 / 3
+
+// isSideTextPosition reports whether lines at the position are stacked in a
+// column beside the barcode rather than above or below it
+func isSideTextPosition(position TextPosition) bool {
+	return position == TextPositionLeft || position == TextPositionRight
+}
+
+// hasSideText reports whether any text line is printed beside the barcode
+func hasSideText(input BarcodeInput) bool {
+	for _, textLine := range labelTextLines(input) {
+		if isSideTextPosition(textLine.Position) {
+			return true
+		}
+	}
+	return false
+}
+
+// sideColumnWidths returns the widths of the text columns left and right of
+// the barcode in dots at the printer DPI. A column is as wide as its widest
+// line, up to maxSideColumnShare of the width between the margins, and is
+// zero when no line is printed on that side. Rotated lines are as wide as
+// their font height.
+func sideColumnWidths(input BarcodeInput) (int, int) {
+	maxColumn := int(float64(mmToPixels(input.Width, input.Dpi)-labelMargin(input)*2) * maxSideColumnShare)
+	fontScale := labelFontScale(input)
+	columns := map[TextPosition]int{}
+	for _, textLine := range labelTextLines(input) {
+		if !isSideTextPosition(textLine.Position) {
+			continue
+		}
+		fontSize, fontHeight := getTextLineFontSize(textLine, input.Dpi, fontScale)
+		if isRotatedText(textLine) {
+			columns[textLine.Position] = max(columns[textLine.Position], min(int(fontHeight), maxColumn))
+			continue
+		}
+		width, err := measureTextWidth(textLine.fontRef(), textLine.Text, fontSize, float64(input.Dpi))
+		if err != nil {
+			continue
+		}
+		columns[textLine.Position] = max(columns[textLine.Position], min(width, maxColumn))
+	}
+	return columns[TextPositionLeft], columns[TextPositionRight]
+}
+
+// sideColumnSpace returns the width a side column takes from the barcode,
+// including the margin that separates them
+func sideColumnSpace(column, margin int) int {
+	if column == 0 {
+		return 0
+	}
+	return column + margin
+}
+
+// textArea is where text is laid out across a label, in pixels at the DPI
+// the label is rendered at
+type textArea struct {
+	width, height int // Label size
+	margin        int // Margin on each side
+	left, right   int // Widths of the side columns, zero without side text
+}
+
+// labelTextArea returns the text area of a label rendered into img, with the
+// margin and side columns laid out at the printer DPI scaled to the image,
+// so text keeps its physical size at other DPIs
+func labelTextArea(input BarcodeInput, img *image.RGBA) textArea {
+	layoutWidth := mmToPixels(input.Width, input.Dpi)
+	scale := func(dots int) int { return dots * img.Bounds().Dx() / layoutWidth }
+	left, right := sideColumnWidths(input)
+	return textArea{width: img.Bounds().Dx(), height: img.Bounds().Dy(), margin: scale(labelMargin(input)), left: scale(left), right: scale(right)}
+}
+
+// maxWidth returns the width available to lines at the position
+func (a textArea) maxWidth(position TextPosition) int {
+	switch position {
+	case TextPositionLeft:
+		return a.left
+	case TextPositionRight:
+		return a.right
+	default:
+		return a.width - a.margin*2
+	}
+}
+
+// maxLength returns the length available to a line: its maximum width, or
+// the height between the margins for rotated lines
+func (a textArea) maxLength(textLine TextLine) int {
+	if isRotatedText(textLine) {
+		return a.height - a.margin*2
+	}
+	return a.maxWidth(textLine.Position)
+}
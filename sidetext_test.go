@@ -0,0 +1,99 @@
+package barcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSideColumnWidths verifies columns fit their widest line up to the
+// maximum share of the label
+func TestSideColumnWidths(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "MRN-0042117",
+		BarcodeType: BarcodeTypeQR,
+		Width:       60.0,
+		Height:      20.0,
+		Dpi:         203,
+		TextLines: []TextLine{
+			{Text: "DOE, JANE", Position: TextPositionLeft, Size: TextSizeMedium},
+			{Text: "WARD 4B", Position: TextPositionLeft, Size: TextSizeSmall},
+		},
+	}
+	left, right := sideColumnWidths(input)
+	assert.Greater(t, left, 0)
+	assert.Zero(t, right)
+
+	input.TextLines[0].Text = strings.Repeat("W", 80)
+	left, _ = sideColumnWidths(input)
+	assert.Equal(t, int(float64(mmToPixels(60, 203)-labelMarginPixels*2)*maxSideColumnShare), left)
+}
+
+// TestLayoutLabel_SideText verifies the barcode moves right of the left
+// column and the column is centered on the barcode
+func TestLayoutLabel_SideText(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "MRN-0042117",
+		BarcodeType: BarcodeTypeQR,
+		Width:       60.0,
+		Height:      20.0,
+		Dpi:         203,
+		TextLines: []TextLine{
+			{Text: "DOE, JANE", Position: TextPositionLeft, Size: TextSizeMedium},
+			{Text: "WARD 4B", Position: TextPositionLeft, Size: TextSizeSmall},
+		},
+	}
+	bc, err := encodeBarcode(input)
+	require.NoError(t, err)
+	layout, err := layoutLabel(input, bc, input.Dpi)
+	require.NoError(t, err)
+
+	left, _ := sideColumnWidths(input)
+	assert.GreaterOrEqual(t, layout.barcodeRect.Min.X, labelMarginPixels*2+left)
+
+	lines := layoutNativeTextLines(input, createBlankLabel(layout.width, layout.height), layout)
+	require.Len(t, lines, 2)
+	assert.Equal(t, textAnchor{TextAlignRight, labelMarginPixels + left}, lines[0].anchor)
+	assert.Equal(t, left, lines[0].maxWidth)
+	assert.Less(t, lines[0].baseline, lines[1].baseline)
+	center := (layout.barcodeRect.Min.Y + layout.barcodeRect.Max.Y) / 2
+	assert.Less(t, lines[0].baseline, center)
+	assert.Greater(t, lines[1].baseline, center)
+}
+
+// TestGenerateBarcode_SideText verifies side text is printed natively in ZPL,
+// right-aligned against its column, and keeps ESC/POS in one raster graphic
+func TestGenerateBarcode_SideText(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "MRN0042117",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       60.0,
+		Height:      20.0,
+		Dpi:         203,
+		TextLines: []TextLine{
+			{Text: "DOE, JANE", Position: TextPositionLeft, Size: TextSizeMedium},
+			{Text: "WARD 4B", Position: TextPositionLeft, Size: TextSizeSmall},
+		},
+		OutputFormats: []OutputFormat{OutputFormatPNG, OutputFormatZPL, OutputFormatESCPOS},
+		ZPL:           ZPLOptions{NativeCommands: true},
+		Code128:       Code128Options{CodeSet: Code128CodeSetB},
+	}
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Contains(t, output.ZPL, "^FO0,")
+	assert.Contains(t, output.ZPL, ",1,0,R,0^A0N")
+	assert.Contains(t, output.ZPL, "^FDDOE, JANE^FS")
+	assert.NotContains(t, output.ESCPOS, "\x1dk")
+
+	input.TextLines[0].Position, input.TextLines[1].Position = TextPositionBelow, TextPositionBelow
+	output, err = GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Contains(t, output.ESCPOS, "\x1dk")
+
+	input.TextLines[0].Position = "BESIDE"
+	_, err = GenerateBarcode(input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid text position")
+}
@@ -0,0 +1,151 @@
+package barcode
+
+import (
+	"context"
+	"errors"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Default soak test settings
+const defaultSoakReportInterval = 10 * time.Second
+
+// SoakOptions configures RunSoak
+type SoakOptions struct {
+	Inputs         []BarcodeInput                             // Labels generated in rotation
+	Generate       func(BarcodeInput) (*BarcodeOutput, error) // Optional: e.g. a Generator's Generate method (defaults to GenerateBarcode)
+	Workers        int                                        // Optional: concurrent generators (defaults to 1)
+	Duration       time.Duration                              // Optional: how long to run (runs until ctx is cancelled when zero)
+	ReportInterval time.Duration                              // Optional: time between reports (defaults to 10s)
+	Report         func(SoakStats)                            // Optional: called with each report and the final stats
+}
+
+// SoakStats is a snapshot of a soak test. Memory figures are read after a
+// garbage collection, so steady growth across reports points to a leak.
+type SoakStats struct {
+	Elapsed     time.Duration
+	Labels      int64  // Labels generated successfully
+	Errors      int64  // Labels that failed
+	LastError   error  // Most recent failure, if any
+	HeapAlloc   uint64 // Bytes of live heap objects
+	HeapObjects uint64 // Number of live heap objects
+	RSS         uint64 // Resident set size in bytes (0 where the OS does not report it)
+	Goroutines  int
+}
+
+// RunSoak generates labels continuously and reports memory and goroutine
+// counts at each interval, to catch slow growth over long runs. It returns
+// the final stats when the duration elapses or ctx is cancelled.
+func RunSoak(ctx context.Context, options SoakOptions) (SoakStats, error) {
+	if len(options.Inputs) == 0 {
+		return SoakStats{}, errors.New("invalid soak options: at least one input is required")
+	}
+	generate := options.Generate
+	if generate == nil {
+		generate = GenerateBarcode
+	}
+	workers := options.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	interval := options.ReportInterval
+	if interval <= 0 {
+		interval = defaultSoakReportInterval
+	}
+	if options.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Duration)
+		defer cancel()
+	}
+
+	var labels, failures atomic.Int64
+	var lastError struct {
+		sync.Mutex
+		err error
+	}
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func(next int) {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				if _, err := generate(options.Inputs[next%len(options.Inputs)]); err != nil {
+					failures.Add(1)
+					lastError.Lock()
+					lastError.err = err
+					lastError.Unlock()
+				} else {
+					labels.Add(1)
+				}
+				next++
+			}
+		}(worker)
+	}
+
+	start := time.Now()
+	snapshot := func() SoakStats {
+		stats := readSoakStats()
+		stats.Elapsed = time.Since(start)
+		stats.Labels = labels.Load()
+		stats.Errors = failures.Load()
+		lastError.Lock()
+		stats.LastError = lastError.err
+		lastError.Unlock()
+		return stats
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for running := true; running; {
+		select {
+		case <-ticker.C:
+			if options.Report != nil {
+				options.Report(snapshot())
+			}
+		case <-ctx.Done():
+			running = false
+		}
+	}
+
+	wg.Wait()
+	stats := snapshot()
+	if options.Report != nil {
+		options.Report(stats)
+	}
+	return stats, nil
+}
+
+// readSoakStats collects garbage and reads the process memory figures
+func readSoakStats() SoakStats {
+	runtime.GC()
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return SoakStats{
+		HeapAlloc:   mem.HeapAlloc,
+		HeapObjects: mem.HeapObjects,
+		RSS:         readRSS(),
+		Goroutines:  runtime.NumGoroutine(),
+	}
+}
+
+// readRSS returns the resident set size from /proc, or 0 on systems without it
+func readRSS() uint64 {
+	statm, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(statm))
+	if len(fields) < 2 {
+		return 0
+	}
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return pages * uint64(os.Getpagesize())
+}
@@ -0,0 +1,74 @@
+package barcode
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunSoak verifies labels are generated in rotation and reported periodically
+func TestRunSoak(t *testing.T) {
+	var reports []SoakStats
+	stats, err := RunSoak(context.Background(), SoakOptions{
+		Inputs: []BarcodeInput{
+			{BarcodeData: "LOC-A-01", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203},
+			{BarcodeData: "https://example.com", BarcodeType: BarcodeTypeQR, Width: 30, Height: 30, Dpi: 203},
+		},
+		Workers:        2,
+		Duration:       200 * time.Millisecond,
+		ReportInterval: 50 * time.Millisecond,
+		Report:         func(s SoakStats) { reports = append(reports, s) },
+	})
+	require.NoError(t, err)
+
+	assert.Positive(t, stats.Labels)
+	assert.Zero(t, stats.Errors)
+	assert.NoError(t, stats.LastError)
+	assert.Positive(t, stats.HeapAlloc)
+	assert.Positive(t, stats.Goroutines)
+	require.GreaterOrEqual(t, len(reports), 2)
+	assert.Equal(t, stats, reports[len(reports)-1])
+}
+
+// TestRunSoak_Errors verifies failures are counted rather than stopping the run
+func TestRunSoak_Errors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stats, err := RunSoak(ctx, SoakOptions{
+		Inputs: []BarcodeInput{{BarcodeData: "X"}},
+		Generate: func(BarcodeInput) (*BarcodeOutput, error) {
+			cancel()
+			return nil, errors.New("printer template missing")
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Errors)
+	assert.Zero(t, stats.Labels)
+	assert.EqualError(t, stats.LastError, "printer template missing")
+}
+
+// TestRunSoak_MixedErrors verifies failures of different error types are all
+// recorded without crashing the workers
+func TestRunSoak_MixedErrors(t *testing.T) {
+	stats, err := RunSoak(context.Background(), SoakOptions{
+		Inputs: []BarcodeInput{
+			{BarcodeData: "LOC-A-01", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 111},
+			{BarcodeData: "", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203},
+		},
+		Workers:  2,
+		Duration: 50 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	assert.Zero(t, stats.Labels)
+	assert.Positive(t, stats.Errors)
+	assert.Error(t, stats.LastError)
+}
+
+// TestRunSoak_NoInputs ensures a soak test needs something to generate
+func TestRunSoak_NoInputs(t *testing.T) {
+	_, err := RunSoak(context.Background(), SoakOptions{Duration: time.Millisecond})
+	assert.Error(t, err)
+}
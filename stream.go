@@ -0,0 +1,106 @@
+package barcode
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"io"
+)
+
+// GenerateBarcodeTo creates a barcode label like GenerateBarcode and writes
+// one format to w as raw bytes rather than base64, e.g. straight to an HTTP
+// response or a file. PNG is encoded directly to w at PreviewDpi when set;
+// PDF, TIFF and BMP are written black on white at the printer DPI, and EPS and printer
+// languages as their text. OutputFormats is ignored.
+func GenerateBarcodeTo(w io.Writer, input BarcodeInput, format OutputFormat) error {
+	return generateBarcodeTo(context.Background(), w, input, format, &Generator{})
+}
+
+// GenerateBarcodeToCtx writes one format of a label to w like
+// GenerateBarcodeTo, but stops between pipeline stages once ctx is done.
+// Nothing is written to w when it stops.
+func GenerateBarcodeToCtx(ctx context.Context, w io.Writer, input BarcodeInput, format OutputFormat) error {
+	return generateBarcodeTo(ctx, w, input, format, &Generator{})
+}
+
+// generateBarcodeTo runs the pipeline for one format with the generator's
+// settings and writes the result to w
+func generateBarcodeTo(ctx context.Context, w io.Writer, input BarcodeInput, format OutputFormat, g *Generator) (err error) {
+	defer recoverToError(&err)
+
+	input.OutputFormats = []OutputFormat{format}
+	switch format {
+	case OutputFormatPNG, OutputFormatPDF, OutputFormatTIFF, OutputFormatBMP:
+		if format != OutputFormatPNG {
+			input.PreviewDpi = 0
+			input.ForegroundColor, input.BackgroundColor = "", ""
+			input.TransparentBackground = false
+		}
+		img, layout, err := generateBarcodeImage(ctx, input, g)
+		if err != nil {
+			return err
+		}
+		return writeImageFormat(w, input, format, img.(*image.RGBA), layout.Dpi)
+	default:
+		output, err := generateBarcode(ctx, input, g)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, output.text(format))
+		return err
+	}
+}
+
+// writeImageFormat encodes a label image rendered at dpi to w as a PNG, PDF,
+// TIFF or BMP
+func writeImageFormat(w io.Writer, input BarcodeInput, format OutputFormat, img *image.RGBA, dpi int) error {
+	switch format {
+	case OutputFormatPNG:
+		var pngImg image.Image = img
+		if input.Monochrome {
+			pngImg = toMonochrome(img)
+		}
+		if err := writePNG(w, pngImg, dpi); err != nil {
+			return fmt.Errorf("failed to encode PNG: %w", err)
+		}
+		return nil
+	case OutputFormatPDF:
+		width, height := labelSizeMM(input)
+		pdf, err := imageToPDF(img, width, height)
+		if err != nil {
+			return fmt.Errorf("failed to convert image to PDF: %w", err)
+		}
+		_, err = w.Write(pdf)
+		return err
+	case OutputFormatTIFF:
+		_, err := w.Write(imageToTIFF(img, dpi, input.Monochrome))
+		return err
+	case OutputFormatBMP:
+		_, err := w.Write(imageToBMP(img, dpi))
+		return err
+	}
+	return fmt.Errorf("invalid image format: %s. Supported formats: %v", format, []OutputFormat{OutputFormatPNG, OutputFormatPDF, OutputFormatTIFF, OutputFormatBMP})
+}
+
+// text returns the output of a text format: EPS or a printer language
+func (o *BarcodeOutput) text(format OutputFormat) string {
+	switch format {
+	case OutputFormatEPS:
+		return o.EPS
+	case OutputFormatZPL:
+		return o.ZPL
+	case OutputFormatEPL:
+		return o.EPL
+	case OutputFormatTSPL:
+		return o.TSPL
+	case OutputFormatSBPL:
+		return o.SBPL
+	case OutputFormatESCPOS:
+		return o.ESCPOS
+	case OutputFormatDPL:
+		return o.DPL
+	case OutputFormatCPCL:
+		return o.CPCL
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package barcode
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateBarcodeTo verifies each format is written as the raw bytes GenerateBarcode returns
+func TestGenerateBarcodeTo(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:   "LOC-A1-B2-C3",
+		BarcodeType:   BarcodeTypeCode128,
+		Width:         50,
+		Height:        25,
+		Dpi:           203,
+		PreviewDpi:    300,
+		TextLines:     []TextLine{{Text: "LOC-A1-B2-C3", Position: TextPositionBelow, Size: TextSizeMedium}},
+		OutputFormats: supportedOutputFormats,
+	}
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+
+	expected := map[OutputFormat]string{
+		OutputFormatPNG:  output.ImageBase64,
+		OutputFormatPDF:  output.PDFBase64,
+		OutputFormatTIFF: output.TIFFBase64,
+		OutputFormatBMP:  output.BMPBase64,
+	}
+	for _, format := range supportedOutputFormats {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, GenerateBarcodeTo(&buf, input, format))
+			require.NotZero(t, buf.Len())
+
+			if encoded, ok := expected[format]; ok {
+				assert.Equal(t, encoded, base64.StdEncoding.EncodeToString(buf.Bytes()))
+				return
+			}
+			assert.Equal(t, output.text(format), buf.String())
+		})
+	}
+}
+
+// failingWriter fails every write after the first n bytes
+type failingWriter struct{ n int }
+
+func (w *failingWriter) Write(data []byte) (int, error) {
+	if len(data) > w.n {
+		written := w.n
+		w.n = 0
+		return written, errors.New("connection reset")
+	}
+	w.n -= len(data)
+	return len(data), nil
+}
+
+// TestGenerateBarcodeTo_Errors verifies invalid formats and input, and write failures, are returned
+func TestGenerateBarcodeTo_Errors(t *testing.T) {
+	input := BarcodeInput{BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203}
+	var buf bytes.Buffer
+
+	err := GenerateBarcodeTo(&buf, input, OutputFormat("GIF"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid output format")
+
+	input.Dpi = 150
+	assert.Error(t, GenerateBarcodeTo(&buf, input, OutputFormatPNG))
+	assert.Zero(t, buf.Len())
+
+	input.Dpi = 203
+	for _, n := range []int{0, 20, 40, 100} {
+		assert.ErrorContains(t, GenerateBarcodeTo(&failingWriter{n: n}, input, OutputFormatPNG), "connection reset")
+	}
+	assert.ErrorContains(t, GenerateBarcodeTo(&failingWriter{}, input, OutputFormatZPL), "connection reset")
+}
+
+// TestGenerateBarcodeToCtx verifies nothing is written once the context is cancelled
+func TestGenerateBarcodeToCtx(t *testing.T) {
+	input := BarcodeInput{BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203}
+	ctx, cancel := context.WithCancel(context.Background())
+	var buf bytes.Buffer
+	require.NoError(t, GenerateBarcodeToCtx(ctx, &buf, input, OutputFormatPNG))
+	assert.Positive(t, buf.Len())
+
+	cancel()
+	for _, format := range []OutputFormat{OutputFormatPNG, OutputFormatZPL} {
+		buf.Reset()
+		assert.ErrorIs(t, GenerateBarcodeToCtx(ctx, &buf, input, format), context.Canceled)
+		assert.Zero(t, buf.Len())
+	}
+}
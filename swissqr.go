@@ -0,0 +1,359 @@
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math/big"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/qr"
+)
+
+// Swiss QR-bill constants (Swiss Implementation Guidelines for the QR-bill)
+const (
+	swissQRHeader     = "SPC\n0200\n1"
+	swissQRTrailer    = "EPD"
+	swissQRMaxPayload = 997
+	swissQRSizeMM     = 46.0 // Printed symbol size, excluding the 5mm quiet zone
+	swissQRCrossMM    = 7.0  // Swiss cross logo size at the centre of the symbol
+	swissQRMaxMessage = 140
+
+	// Default label: the 46mm symbol with its 5mm quiet zone on each side
+	swissQRLabelMM = 56.0
+)
+
+// swissQRMod10Table is the recursive mod-10 table used by QR references
+var swissQRMod10Table = [10]int{0, 9, 4, 6, 8, 2, 7, 1, 3, 5}
+
+// SwissQRAddress is a structured (type S) address in a Swiss QR-bill
+type SwissQRAddress struct {
+	Name           string // Name or company, up to 70 characters
+	Street         string // Optional street, up to 70 characters
+	BuildingNumber string // Optional building number, up to 16 characters
+	PostalCode     string // Postal code, up to 16 characters
+	Town           string // Town, up to 35 characters
+	Country        string // Two-letter ISO 3166-1 country code
+}
+
+// SwissQRBill holds the structured payment fields of a Swiss QR-bill
+type SwissQRBill struct {
+	IBAN            string          // CH or LI IBAN or QR-IBAN; spaces are ignored
+	Creditor        SwissQRAddress  // Account holder receiving the payment
+	Amount          string          // Optional amount such as "1949.75"; empty lets the payer fill it in
+	Currency        string          // CHF or EUR
+	Debtor          *SwissQRAddress // Optional payer address
+	Reference       string          // QR reference (27 digits), creditor reference (RF...) or empty
+	Message         string          // Optional unstructured message
+	BillInformation string          // Optional structured bill information
+	Width           float64         // Label width in millimeters (defaults to 56)
+	Height          float64         // Label height in millimeters (defaults to 56)
+	Dpi             int             // Printer DPI (203, 300, or 600)
+	PreviewDpi      int             // Optional DPI for the PNG image (defaults to Dpi)
+	TextLines       []TextLine      // Optional text lines to render
+}
+
+// GenerateSwissQRBill validates the payment fields and renders the Swiss QR
+// code, with the Swiss cross at its centre, at its specified 46mm size.
+func GenerateSwissQRBill(bill SwissQRBill) (*BarcodeOutput, error) {
+	payload, err := buildSwissQRPayload(bill)
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := bill.Width, bill.Height
+	if width == 0 && height == 0 {
+		width, height = swissQRLabelMM, swissQRLabelMM
+	}
+
+	return GenerateBarcode(BarcodeInput{
+		BarcodeData: payload,
+		BarcodeType: BarcodeTypeSwissQR,
+		Width:       width,
+		Height:      height,
+		Dpi:         bill.Dpi,
+		PreviewDpi:  bill.PreviewDpi,
+		TextLines:   bill.TextLines,
+	})
+}
+
+// buildSwissQRPayload validates the bill and assembles the newline-separated payload
+func buildSwissQRPayload(bill SwissQRBill) (string, error) {
+	iban, err := normalizeSwissIBAN(bill.IBAN)
+	if err != nil {
+		return "", err
+	}
+	if err := validateSwissQRAddress("creditor", bill.Creditor); err != nil {
+		return "", err
+	}
+	if bill.Debtor != nil {
+		if err := validateSwissQRAddress("debtor", *bill.Debtor); err != nil {
+			return "", err
+		}
+	}
+
+	amount, err := normalizeSwissQRAmount(bill.Amount)
+	if err != nil {
+		return "", err
+	}
+	if bill.Currency != "CHF" && bill.Currency != "EUR" {
+		return "", fmt.Errorf("invalid QR-bill currency: %q. Supported currencies are CHF and EUR", bill.Currency)
+	}
+
+	referenceType, reference, err := swissQRReference(iban, bill.Reference)
+	if err != nil {
+		return "", err
+	}
+
+	if len([]rune(bill.Message))+len([]rune(bill.BillInformation)) > swissQRMaxMessage {
+		return "", fmt.Errorf("invalid QR-bill message: message and bill information together exceed %d characters", swissQRMaxMessage)
+	}
+
+	lines := []string{swissQRHeader, iban}
+	lines = append(lines, swissQRAddressLines(&bill.Creditor)...)
+	lines = append(lines, swissQRAddressLines(nil)...) // Ultimate creditor, reserved for future use
+	lines = append(lines, amount, bill.Currency)
+	lines = append(lines, swissQRAddressLines(bill.Debtor)...)
+	lines = append(lines, referenceType, reference, bill.Message, swissQRTrailer)
+	if bill.BillInformation != "" {
+		lines = append(lines, bill.BillInformation)
+	}
+
+	payload := strings.Join(lines, "\n")
+	if err := validateSwissQRPayload(payload); err != nil {
+		return "", err
+	}
+	return payload, nil
+}
+
+// validateSwissQRPayload ensures the data is a version 2 QR-bill payload that fits the symbol
+func validateSwissQRPayload(data string) error {
+	if !strings.HasPrefix(data, swissQRHeader+"\n") {
+		return errors.New("invalid QR-bill payload: must start with the SPC version 0200 header")
+	}
+	if len(data) > swissQRMaxPayload {
+		return fmt.Errorf("invalid QR-bill payload: %w", &DataTooLongError{Length: len(data), Max: swissQRMaxPayload, Unit: "bytes"})
+	}
+	return nil
+}
+
+// normalizeSwissIBAN removes spaces and checks the country and ISO 13616 check digits
+func normalizeSwissIBAN(iban string) (string, error) {
+	iban = strings.ToUpper(strings.ReplaceAll(iban, " ", ""))
+	if len(iban) != 21 || (!strings.HasPrefix(iban, "CH") && !strings.HasPrefix(iban, "LI")) {
+		return "", fmt.Errorf("invalid QR-bill IBAN: %q. Must be a 21 character CH or LI IBAN", iban)
+	}
+
+	if !validMod97(iban) {
+		return "", fmt.Errorf("invalid QR-bill IBAN check digits: %q", iban)
+	}
+	return iban, nil
+}
+
+// isSwissQRIBAN reports whether the IBAN's institution ID is in the QR-IID range 30000-31999
+func isSwissQRIBAN(iban string) bool {
+	iid := iban[4:9]
+	return iid >= "30000" && iid <= "31999"
+}
+
+// swissQRReference determines the reference type. QR-IBANs require a QR
+// reference; regular IBANs take a creditor reference or none.
+func swissQRReference(iban, reference string) (string, string, error) {
+	reference = strings.ReplaceAll(reference, " ", "")
+
+	if isSwissQRIBAN(iban) {
+		if len(reference) != 27 || !isNumeric(reference) {
+			return "", "", fmt.Errorf("invalid QR reference: %q. A QR-IBAN requires a 27 digit QR reference", reference)
+		}
+		if expected := swissQRMod10(reference[:26]); reference[26] != expected {
+			return "", "", fmt.Errorf("invalid QR reference check digit: %q. Expected check digit %c", reference, expected)
+		}
+		return "QRR", reference, nil
+	}
+
+	switch {
+	case reference == "":
+		return "NON", "", nil
+	case strings.HasPrefix(strings.ToUpper(reference), "RF"):
+		reference = strings.ToUpper(reference)
+		if err := validateCreditorReference(reference); err != nil {
+			return "", "", err
+		}
+		return "SCOR", reference, nil
+	default:
+		return "", "", fmt.Errorf("invalid QR-bill reference: %q. Regular IBANs take an RF creditor reference or none", reference)
+	}
+}
+
+// swissQRMod10 calculates the recursive mod-10 check digit of a QR reference
+func swissQRMod10(digits string) byte {
+	carry := 0
+	for _, digit := range digits {
+		carry = swissQRMod10Table[(carry+int(digit-'0'))%10]
+	}
+	return byte('0' + (10-carry)%10)
+}
+
+// validateCreditorReference checks an ISO 11649 creditor reference (RF + 2 check digits + up to 21 characters)
+func validateCreditorReference(reference string) error {
+	if len(reference) < 5 || len(reference) > 25 {
+		return fmt.Errorf("invalid creditor reference: %q. Must be 5 to 25 characters", reference)
+	}
+	if !validMod97(reference) {
+		return fmt.Errorf("invalid creditor reference check digits: %q", reference)
+	}
+	return nil
+}
+
+// validMod97 checks ISO 7064 MOD 97-10 check digits as used by IBANs and
+// creditor references: the first four characters move to the end, letters
+// become 10-35, and the resulting number must leave a remainder of 1.
+func validMod97(s string) bool {
+	var digits strings.Builder
+	for _, r := range s[4:] + s[:4] {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r >= 'A' && r <= 'Z':
+			fmt.Fprintf(&digits, "%d", r-'A'+10)
+		default:
+			return false
+		}
+	}
+	value, _ := new(big.Int).SetString(digits.String(), 10)
+	return new(big.Int).Mod(value, big.NewInt(97)).Int64() == 1
+}
+
+// normalizeSwissQRAmount checks an optional amount of 0.01 to 999999999.99
+// and formats it with two decimals.
+func normalizeSwissQRAmount(amount string) (string, error) {
+	if amount == "" {
+		return "", nil
+	}
+
+	whole, fraction, _ := strings.Cut(amount, ".")
+	if !isNumeric(whole) || len(whole) > 9 || len(fraction) > 2 || (fraction != "" && !isNumeric(fraction)) {
+		return "", fmt.Errorf("invalid QR-bill amount: %q. Must be 0.01 to 999999999.99 with up to two decimals", amount)
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+	whole = strings.TrimLeft(whole, "0")
+	if whole == "" {
+		whole = "0"
+	}
+	if whole == "0" && fraction == "00" {
+		return "", fmt.Errorf("invalid QR-bill amount: %q. Must be at least 0.01", amount)
+	}
+	return whole + "." + fraction, nil
+}
+
+// validateSwissQRAddress checks the required fields and maximum lengths of a structured address
+func validateSwissQRAddress(role string, address SwissQRAddress) error {
+	fields := []struct {
+		name     string
+		value    string
+		max      int
+		required bool
+	}{
+		{"name", address.Name, 70, true},
+		{"street", address.Street, 70, false},
+		{"building number", address.BuildingNumber, 16, false},
+		{"postal code", address.PostalCode, 16, true},
+		{"town", address.Town, 35, true},
+	}
+	for _, field := range fields {
+		length := len([]rune(field.value))
+		if field.required && length == 0 {
+			return fmt.Errorf("invalid QR-bill %s address: %s is required", role, field.name)
+		}
+		if length > field.max {
+			return fmt.Errorf("invalid QR-bill %s address: %s exceeds %d characters", role, field.name, field.max)
+		}
+	}
+
+	country := address.Country
+	if len(country) != 2 || country[0] < 'A' || country[0] > 'Z' || country[1] < 'A' || country[1] > 'Z' {
+		return fmt.Errorf("invalid QR-bill %s country: %q. Must be a two-letter ISO country code", role, country)
+	}
+	return nil
+}
+
+// swissQRAddressLines returns the seven payload lines of an address; a nil
+// address yields empty lines.
+func swissQRAddressLines(address *SwissQRAddress) []string {
+	if address == nil {
+		return make([]string, 7)
+	}
+	return []string{
+		"S",
+		address.Name,
+		address.Street,
+		address.BuildingNumber,
+		address.PostalCode,
+		address.Town,
+		address.Country,
+	}
+}
+
+// swissCrossBarcode is a QR code with the Swiss cross drawn over its centre.
+// The QR-bill uses error correction level M, which the logo area stays within.
+type swissCrossBarcode struct {
+	barcode.Barcode
+	modules    int // Side of the QR symbol in modules
+	symbolSize int // Side of the QR symbol in pixels, excluding scaling padding
+}
+
+// encodeSwissQR creates the QR code of a Swiss QR-bill payload
+func encodeSwissQR(data string) (barcode.Barcode, error) {
+	bc, err := qr.Encode(data, qr.M, qr.Unicode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Swiss QR code: %w", err)
+	}
+	size := bc.Bounds().Dx()
+	return &swissCrossBarcode{Barcode: bc, modules: size, symbolSize: size}, nil
+}
+
+// At draws the cross logo: a black square with a white border and a white
+// cross in the Swiss flag's proportions (arms 6 units wide spanning 20 of 32).
+func (bc *swissCrossBarcode) At(x, y int) color.Color {
+	bounds := bc.Bounds()
+	logo := float64(bc.symbolSize) * swissQRCrossMM / swissQRSizeMM
+	dx := abs(float64(x) - float64(bounds.Min.X+bounds.Max.X-1)/2)
+	dy := abs(float64(y) - float64(bounds.Min.Y+bounds.Max.Y-1)/2)
+
+	if dx > logo/2 || dy > logo/2 {
+		return bc.Barcode.At(x, y)
+	}
+
+	square := logo * 6 / 7 // Black square inside a white border
+	if dx > square/2 || dy > square/2 {
+		return color.White
+	}
+
+	unit := square / 32
+	if (dx <= 3*unit && dy <= 10*unit) || (dy <= 3*unit && dx <= 10*unit) {
+		return color.White
+	}
+	return color.Black
+}
+
+// scale scales the QR code and keeps the cross sized to the scaled symbol
+func (bc *swissCrossBarcode) scale(width, height int) (barcode.Barcode, error) {
+	scaled, err := barcode.Scale(bc.Barcode, width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	side := width
+	if height < side {
+		side = height
+	}
+	return &swissCrossBarcode{Barcode: scaled, modules: bc.modules, symbolSize: side / bc.modules * bc.modules}, nil
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
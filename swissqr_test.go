@@ -0,0 +1,115 @@
+package barcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// swissQRTestBill returns a valid QR-IBAN bill based on the SIX examples
+func swissQRTestBill() SwissQRBill {
+	return SwissQRBill{
+		IBAN: "CH44 3199 9123 0008 8901 2",
+		Creditor: SwissQRAddress{
+			Name:           "Robert Schneider AG",
+			Street:         "Rue du Lac",
+			BuildingNumber: "1268",
+			PostalCode:     "2501",
+			Town:           "Biel",
+			Country:        "CH",
+		},
+		Amount:    "1949.75",
+		Currency:  "CHF",
+		Reference: "21 00000 00003 13947 14300 09017",
+		Message:   "Order of 15 June 2020",
+		Dpi:       300,
+	}
+}
+
+// TestBuildSwissQRPayload verifies the payload line layout
+func TestBuildSwissQRPayload(t *testing.T) {
+	payload, err := buildSwissQRPayload(swissQRTestBill())
+	require.NoError(t, err)
+
+	lines := strings.Split(payload, "\n")
+	require.Len(t, lines, 31)
+	assert.Equal(t, []string{"SPC", "0200", "1", "CH4431999123000889012", "S", "Robert Schneider AG"}, lines[:6])
+	assert.Equal(t, "1949.75", lines[18])
+	assert.Equal(t, "CHF", lines[19])
+	assert.Equal(t, "QRR", lines[27])
+	assert.Equal(t, "210000000003139471430009017", lines[28])
+	assert.Equal(t, "EPD", lines[30])
+}
+
+// TestBuildSwissQRPayload_Invalid ensures payment fields are validated
+func TestBuildSwissQRPayload_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*SwissQRBill)
+		expectedErr string
+	}{
+		{"ForeignIBAN", func(b *SwissQRBill) { b.IBAN = "DE89370400440532013000" }, "CH or LI IBAN"},
+		{"BadIBANCheck", func(b *SwissQRBill) { b.IBAN = "CH4531999123000889012" }, "IBAN check digits"},
+		{"QRIBANWithoutQRReference", func(b *SwissQRBill) { b.Reference = "" }, "requires a 27 digit QR reference"},
+		{"BadQRReference", func(b *SwissQRBill) { b.Reference = "210000000003139471430009018" }, "QR reference check digit"},
+		{"QRReferenceOnIBAN", func(b *SwissQRBill) { b.IBAN = "CH5800791123000889012" }, "RF creditor reference or none"},
+		{"BadAmount", func(b *SwissQRBill) { b.Amount = "12.345" }, "invalid QR-bill amount"},
+		{"ZeroAmount", func(b *SwissQRBill) { b.Amount = "0.00" }, "at least 0.01"},
+		{"BadCurrency", func(b *SwissQRBill) { b.Currency = "USD" }, "CHF and EUR"},
+		{"MissingTown", func(b *SwissQRBill) { b.Creditor.Town = "" }, "town is required"},
+		{"BadCountry", func(b *SwissQRBill) { b.Creditor.Country = "che" }, "two-letter ISO country code"},
+		{"LongMessage", func(b *SwissQRBill) { b.Message = strings.Repeat("x", 141) }, "exceed 140 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bill := swissQRTestBill()
+			tt.modify(&bill)
+			_, err := buildSwissQRPayload(bill)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+// TestSwissQRReference_Creditor verifies regular IBANs take RF or no reference
+func TestSwissQRReference_Creditor(t *testing.T) {
+	refType, ref, err := swissQRReference("CH5800791123000889012", "RF18 5390 0754 7034")
+	require.NoError(t, err)
+	assert.Equal(t, "SCOR", refType)
+	assert.Equal(t, "RF18539007547034", ref)
+
+	refType, _, err = swissQRReference("CH5800791123000889012", "")
+	require.NoError(t, err)
+	assert.Equal(t, "NON", refType)
+
+	_, _, err = swissQRReference("CH5800791123000889012", "RF19539007547034")
+	assert.Error(t, err)
+}
+
+// TestGenerateSwissQRBill_Success verifies the QR renders at 46mm with the Swiss cross
+func TestGenerateSwissQRBill_Success(t *testing.T) {
+	output, err := GenerateSwissQRBill(swissQRTestBill())
+	require.NoError(t, err)
+	assert.NotEmpty(t, output.ImageBase64)
+	assert.Contains(t, output.ZPL, "^XA")
+
+	payload, _ := buildSwissQRPayload(swissQRTestBill())
+	bc, err := encodeSwissQR(payload)
+	require.NoError(t, err)
+
+	size := calculateSwissQRSize(BarcodeInput{Dpi: 300}, mmToPixels(56, 300), mmToPixels(56, 300))
+	assert.Equal(t, mmToPixels(swissQRSizeMM, 300), size.X)
+
+	scaled, err := scaleBarcodeToFit(bc, size)
+	require.NoError(t, err)
+
+	// The centre of the cross is white, just inside the logo's black square is black
+	center := scaled.Bounds().Dx() / 2
+	r, _, _, _ := scaled.At(center, center).RGBA()
+	assert.NotEqual(t, uint32(0), r, "Cross centre should be white")
+	r, _, _, _ = scaled.At(center-size.X*3/46+2, center-size.X*3/46+2).RGBA()
+	assert.Equal(t, uint32(0), r, "Logo corner should be black")
+}
@@ -0,0 +1,146 @@
+package barcode
+
+import (
+	"fmt"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/utils"
+)
+
+// Telepen constants
+const (
+	telepenCodeKind = "Telepen"
+	telepenStart    = '_'
+	telepenStop     = 'z'
+	telepenNarrow   = 1
+	telepenWide     = 3
+)
+
+// encodeTelepen creates a Telepen barcode in ASCII mode, encoding any
+// character from ASCII 0-127.
+func encodeTelepen(data string) (barcode.Barcode, error) {
+	if err := validateTelepenData(data); err != nil {
+		return nil, fmt.Errorf("failed to encode Telepen barcode: %w", err)
+	}
+	return telepenBarcode(data, []byte(data)), nil
+}
+
+// encodeTelepenNumeric creates a Telepen barcode in numeric mode, which packs
+// two digits into each character. X is allowed as the last digit of a pair.
+// Odd-length data is prefixed with a zero.
+func encodeTelepenNumeric(data string) (barcode.Barcode, error) {
+	if err := validateTelepenNumericData(data); err != nil {
+		return nil, fmt.Errorf("failed to encode Telepen barcode: %w", err)
+	}
+	if len(data)%2 == 1 {
+		data = "0" + data
+	}
+
+	glyphs := make([]byte, 0, len(data)/2)
+	for i := 0; i < len(data); i += 2 {
+		tens := data[i] - '0'
+		if data[i+1] == 'X' {
+			glyphs = append(glyphs, tens+17)
+		} else {
+			glyphs = append(glyphs, tens*10+data[i+1]-'0'+27)
+		}
+	}
+	return telepenBarcode(data, glyphs), nil
+}
+
+// validateTelepenData ensures the data is non-empty ASCII
+func validateTelepenData(data string) error {
+	if data == "" {
+		return fmt.Errorf("invalid Telepen data: data must not be empty")
+	}
+	for _, r := range data {
+		if r > 127 {
+			return fmt.Errorf("invalid Telepen data: %q. Only ASCII characters can be encoded", data)
+		}
+	}
+	return nil
+}
+
+// validateTelepenNumericData ensures the data is digits, with X only as the
+// second digit of a pair
+func validateTelepenNumericData(data string) error {
+	if data == "" {
+		return fmt.Errorf("invalid Telepen numeric data: data must not be empty")
+	}
+	offset := len(data) % 2 // Odd-length data is padded, shifting the pairs
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if c == 'X' && (i+offset)%2 == 1 {
+			continue
+		}
+		if c < '0' || c > '9' {
+			return fmt.Errorf("invalid Telepen numeric data: %q. Only digits, and X as the second digit of a pair, are allowed", data)
+		}
+	}
+	return nil
+}
+
+// telepenBarcode builds the symbol: start, glyphs, mod-127 check, stop
+func telepenBarcode(content string, glyphs []byte) barcode.Barcode {
+	sum := 0
+	for _, glyph := range glyphs {
+		sum += int(glyph)
+	}
+	check := byte((127 - sum%127) % 127)
+
+	// Every glyph starts with a bar, so bars and spaces alternate throughout
+	bits := new(utils.BitList)
+	for _, glyph := range append(append([]byte{telepenStart}, glyphs...), check, telepenStop) {
+		for i, width := range telepenGlyphWidths(glyph) {
+			for j := 0; j < width; j++ {
+				bits.AddBit(i%2 == 0)
+			}
+		}
+	}
+	return utils.New1DCode(telepenCodeKind, content, bits)
+}
+
+// telepenGlyphWidths returns alternating bar and space widths for a glyph.
+// The 7 data bits plus an even parity bit are read LSB first and coded as:
+// 1 is a narrow bar and space, 00 a wide bar and narrow space, 010 a wide bar
+// and space, and 01..10 a narrow bar and wide space at each end.
+func telepenGlyphWidths(glyph byte) []int {
+	var bitValues [8]bool
+	ones := 0
+	for i := 0; i < 7; i++ {
+		bitValues[i] = glyph>>uint(i)&1 == 1
+		if bitValues[i] {
+			ones++
+		}
+	}
+	bitValues[7] = ones%2 == 1
+
+	var widths []int
+	for i := 0; i < len(bitValues); {
+		switch {
+		case bitValues[i]:
+			widths = append(widths, telepenNarrow, telepenNarrow)
+			i++
+		case !bitValues[i+1]:
+			widths = append(widths, telepenWide, telepenNarrow)
+			i += 2
+		default:
+			// A zero followed by ones up to the next zero
+			end := i + 1
+			for bitValues[end] {
+				end++
+			}
+			if end == i+2 {
+				widths = append(widths, telepenWide, telepenWide)
+			} else {
+				widths = append(widths, telepenNarrow, telepenWide)
+				for j := i + 2; j < end-1; j++ {
+					widths = append(widths, telepenNarrow, telepenNarrow)
+				}
+				widths = append(widths, telepenNarrow, telepenWide)
+			}
+			i = end + 1
+		}
+	}
+	return widths
+}
@@ -0,0 +1,88 @@
+package barcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// telepenWidthString renders glyph widths as a digit string, e.g. "31313131"
+func telepenWidthString(glyph byte) string {
+	var sb strings.Builder
+	for _, w := range telepenGlyphWidths(glyph) {
+		sb.WriteByte(byte('0' + w))
+	}
+	return sb.String()
+}
+
+// TestTelepenGlyphWidths verifies glyph patterns against the Telepen character table
+func TestTelepenGlyphWidths(t *testing.T) {
+	expected := map[byte]string{
+		0:            "31313131",
+		1:            "1131313111",
+		2:            "33313111",
+		5:            "11333131",
+		6:            "13133131",
+		13:           "1113133111",
+		14:           "1311133111",
+		127:          "1111111111111111",
+		telepenStart: "111111111133",
+		telepenStop:  "331111111111",
+	}
+
+	for glyph, widths := range expected {
+		assert.Equal(t, widths, telepenWidthString(glyph), "glyph %d", glyph)
+	}
+
+	// Every glyph is 16 modules wide
+	for glyph := 0; glyph < 128; glyph++ {
+		assert.Equal(t, 16, sumInts(telepenGlyphWidths(byte(glyph))), "glyph %d", glyph)
+	}
+}
+
+// TestEncodeTelepen verifies symbol width: start, data, check and stop glyphs
+func TestEncodeTelepen(t *testing.T) {
+	bc, err := encodeTelepen("ABC123")
+	require.NoError(t, err)
+	assert.Equal(t, (6+3)*16, bc.Bounds().Dx())
+
+	_, err = encodeTelepen("Café")
+	assert.Error(t, err)
+}
+
+// TestEncodeTelepenNumeric verifies digit pairs, X and odd-length padding
+func TestEncodeTelepenNumeric(t *testing.T) {
+	bc, err := encodeTelepenNumeric("12345")
+	require.NoError(t, err)
+	assert.Equal(t, "012345", bc.Content())
+	assert.Equal(t, (3+3)*16, bc.Bounds().Dx())
+
+	_, err = encodeTelepenNumeric("123X")
+	assert.NoError(t, err)
+
+	_, err = encodeTelepenNumeric("12X4")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "X as the second digit of a pair")
+}
+
+// TestGenerateBarcode_Telepen_Success verifies a library asset label renders
+func TestGenerateBarcode_Telepen_Success(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LIB-004217",
+		BarcodeType: BarcodeTypeTelepen,
+		Width:       60.0,
+		Height:      25.0,
+		Dpi:         300,
+		TextLines: []TextLine{
+			{Text: "LIB-004217", Position: TextPositionBelow, Size: TextSizeSmall},
+		},
+	}
+
+	output, err := GenerateBarcode(input)
+
+	require.NoError(t, err, "Should successfully generate Telepen barcode")
+	assert.NotEmpty(t, output.ImageBase64)
+	assert.Contains(t, output.ZPL, "^XA")
+}
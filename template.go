@@ -0,0 +1,160 @@
+package barcode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TemplateElementType is the kind of element placed by a label template
+type TemplateElementType string
+
+const (
+	TemplateElementBarcode TemplateElementType = "barcode"
+	TemplateElementText    TemplateElementType = "text"
+	TemplateElementLine    TemplateElementType = "line"
+	TemplateElementBox     TemplateElementType = "box"
+	TemplateElementCircle  TemplateElementType = "circle"
+	TemplateElementDiamond TemplateElementType = "diamond"
+	TemplateElementImage   TemplateElementType = "image"
+)
+
+// templateShapes maps shape elements to the shapes they are drawn as
+var templateShapes = map[TemplateElementType]ShapeType{
+	TemplateElementLine:    ShapeLine,
+	TemplateElementBox:     ShapeBox,
+	TemplateElementCircle:  ShapeCircle,
+	TemplateElementDiamond: ShapeDiamond,
+}
+
+// LabelTemplate is a label design kept as data, e.g. in a JSON or YAML file
+// per design, instead of a BarcodeInput built in code for every label. The
+// first barcode element is the label's main barcode; the others are added
+// with Barcodes.
+type LabelTemplate struct {
+	Name          string            // Identifies the design in error messages
+	Width         float64           // Label width in millimeters
+	Height        float64           // Label height in millimeters
+	LabelPreset   LabelPreset       // Optional stock size such as 4X6, used instead of Width and Height
+	Units         Unit              // Optional unit of the label size and element positions: MM (default), INCH or DOTS at Dpi
+	Dpi           int               // Printer DPI (203, 300, or 600)
+	OutputFormats []OutputFormat    // Optional formats to produce (defaults to PNG and ZPL)
+	ZPL           ZPLOptions        // Optional settings for ZPL output
+	Elements      []TemplateElement // Elements in drawing order, with at least one barcode
+}
+
+// TemplateElement is one element of a label template, positioned in
+// millimeters from the top-left corner of the label. Data is a Go template,
+// such as "{{.SKU}}", filled in from the data passed to RenderTemplate.
+type TemplateElement struct {
+	Type        TemplateElementType // barcode, text, line, box, circle, diamond or image
+	XMM         float64             // Left edge; for text, the edge or centre set by Alignment
+	YMM         float64             // Top edge
+	WidthMM     float64             // Width of the element; line: horizontal length
+	HeightMM    float64             // Height of the element; image: optional maximum height; line: vertical length
+	Data        string              // barcode: data to encode; text: the text
+	BarcodeType BarcodeType         // barcode: type of barcode
+	Size        TextSize            // text: predefined size
+	FontSizePt  float64             // text: optional exact font size in points
+	Alignment   TextAlignment       // text: optional alignment to XMM (defaults to LEFT)
+	FontName    string              // text: optional registered font
+	ThicknessMM float64             // Shapes: optional stroke width (defaults to 0.3)
+	Filled      bool                // box, circle, diamond: optional fill instead of an outline
+	Name        string              // image: graphic name (1-8 capital letters, digits or underscores)
+	PNG         []byte              // image: PNG image, base64 in JSON and YAML
+}
+
+// ParseLabelTemplate reads a label template from JSON or YAML. Keys are the
+// field names of LabelTemplate and TemplateElement in both formats.
+func ParseLabelTemplate(data []byte) (LabelTemplate, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return LabelTemplate{}, fmt.Errorf("invalid label template: %w", err)
+	}
+	normalized, err := json.Marshal(raw)
+	if err != nil {
+		return LabelTemplate{}, fmt.Errorf("invalid label template: %w", err)
+	}
+
+	var tpl LabelTemplate
+	if err := json.Unmarshal(normalized, &tpl); err != nil {
+		return LabelTemplate{}, fmt.Errorf("invalid label template: %w", err)
+	}
+	return tpl, nil
+}
+
+// RenderTemplate fills in the template's placeholders from data, a struct or
+// map, and generates the label. Placeholders naming missing map keys are
+// errors rather than empty text.
+func RenderTemplate(tpl LabelTemplate, data interface{}) (*BarcodeOutput, error) {
+	input, err := tpl.input(data)
+	if err != nil {
+		return nil, err
+	}
+	return GenerateBarcode(input)
+}
+
+// input builds the barcode input for the template filled in with data
+func (tpl LabelTemplate) input(data interface{}) (BarcodeInput, error) {
+	input := BarcodeInput{Width: tpl.Width, Height: tpl.Height, LabelPreset: tpl.LabelPreset, Units: tpl.Units, Dpi: tpl.Dpi, OutputFormats: tpl.OutputFormats, ZPL: tpl.ZPL}
+	hasBarcode := false
+	for i, element := range tpl.Elements {
+		text, err := executeTemplateField(element.Data, data)
+		if err != nil {
+			return BarcodeInput{}, fmt.Errorf("invalid label template %q: element %d: %w", tpl.Name, i+1, err)
+		}
+
+		switch element.Type {
+		case TemplateElementBarcode:
+			placement := Placement{XMM: element.XMM, YMM: element.YMM, WidthMM: element.WidthMM, HeightMM: element.HeightMM}
+			if !hasBarcode {
+				input.BarcodeData, input.BarcodeType, input.BarcodePlacement = text, element.BarcodeType, placement
+				hasBarcode = true
+				continue
+			}
+			input.Barcodes = append(input.Barcodes, LabelBarcode{
+				BarcodeData: text, BarcodeType: element.BarcodeType,
+				XMM: placement.XMM, YMM: placement.YMM, WidthMM: placement.WidthMM, HeightMM: placement.HeightMM,
+			})
+		case TemplateElementText:
+			input.TextLines = append(input.TextLines, TextLine{
+				Text: text, Position: TextPositionAbsolute, XMM: element.XMM, YMM: element.YMM,
+				Size: element.Size, FontSizePt: element.FontSizePt, Alignment: element.Alignment, FontName: element.FontName,
+			})
+		case TemplateElementLine, TemplateElementBox, TemplateElementCircle, TemplateElementDiamond:
+			input.Shapes = append(input.Shapes, LabelShape{
+				Type: templateShapes[element.Type], XMM: element.XMM, YMM: element.YMM, WidthMM: element.WidthMM, HeightMM: element.HeightMM,
+				ThicknessMM: element.ThicknessMM, Filled: element.Filled,
+			})
+		case TemplateElementImage:
+			input.Graphics = append(input.Graphics, LabelGraphic{Name: element.Name, PNG: element.PNG, XMM: element.XMM, YMM: element.YMM, WidthMM: element.WidthMM, HeightMM: element.HeightMM})
+		default:
+			return BarcodeInput{}, fmt.Errorf("invalid label template %q: element %d has type %q. Supported types are %s, %s, %s, %s, %s, %s and %s",
+				tpl.Name, i+1, element.Type, TemplateElementBarcode, TemplateElementText, TemplateElementLine, TemplateElementBox, TemplateElementCircle, TemplateElementDiamond, TemplateElementImage)
+		}
+	}
+
+	if !hasBarcode {
+		return BarcodeInput{}, fmt.Errorf("invalid label template %q: needs a %s element", tpl.Name, TemplateElementBarcode)
+	}
+	return input, nil
+}
+
+// executeTemplateField fills in the placeholders of one element's data
+func executeTemplateField(field string, data interface{}) (string, error) {
+	if !strings.Contains(field, "{{") {
+		return field, nil
+	}
+	parsed, err := template.New("field").Option("missingkey=error").Parse(field)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	if err := parsed.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,109 @@
+package barcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shelfTemplateYAML is a shelf label design with a boxed price, as kept in a
+// template file
+const shelfTemplateYAML = `
+Name: shelf
+Width: 60
+Height: 40
+Dpi: 203
+ZPL:
+  NativeCommands: true
+Elements:
+  - Type: box
+    XMM: 2
+    YMM: 2
+    WidthMM: 56
+    HeightMM: 36
+  - Type: text
+    XMM: 5
+    YMM: 4
+    Size: MEDIUM
+    Data: "{{.Name}}"
+  - Type: line
+    XMM: 2
+    YMM: 12
+    WidthMM: 56
+  - Type: barcode
+    BarcodeType: CODE128
+    XMM: 5
+    YMM: 15
+    WidthMM: 50
+    HeightMM: 15
+    Data: "{{.SKU}}"
+  - Type: text
+    XMM: 55
+    YMM: 32
+    Size: SMALL
+    Alignment: RIGHT
+    Data: "SKU {{.SKU}}"
+`
+
+// TestParseLabelTemplate verifies JSON and YAML templates read the same
+func TestParseLabelTemplate(t *testing.T) {
+	fromYAML, err := ParseLabelTemplate([]byte(shelfTemplateYAML))
+	require.NoError(t, err)
+	assert.Equal(t, "shelf", fromYAML.Name)
+	require.Len(t, fromYAML.Elements, 5)
+	assert.Equal(t, TemplateElementBarcode, fromYAML.Elements[3].Type)
+	assert.Equal(t, "{{.SKU}}", fromYAML.Elements[3].Data)
+
+	fromJSON, err := ParseLabelTemplate([]byte(`{"Name": "shelf", "Width": 60, "Height": 40, "Dpi": 203, "Elements": [
+		{"Type": "barcode", "BarcodeType": "CODE128", "XMM": 5, "YMM": 15, "WidthMM": 50, "HeightMM": 15, "Data": "{{.SKU}}"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, fromYAML.Elements[3], fromJSON.Elements[0])
+
+	_, err = ParseLabelTemplate([]byte(`{"Width": "wide"}`))
+	assert.ErrorContains(t, err, "invalid label template")
+}
+
+// TestRenderTemplate verifies placeholders are filled in and elements become
+// the placed barcode, absolute text lines and label shapes
+func TestRenderTemplate(t *testing.T) {
+	tpl, err := ParseLabelTemplate([]byte(shelfTemplateYAML))
+	require.NoError(t, err)
+	data := map[string]string{"Name": "Oat Milk 1L", "SKU": "10442"}
+
+	input, err := tpl.input(data)
+	require.NoError(t, err)
+	assert.Equal(t, "10442", input.BarcodeData)
+	assert.Equal(t, Placement{XMM: 5, YMM: 15, WidthMM: 50, HeightMM: 15}, input.BarcodePlacement)
+	require.Len(t, input.TextLines, 2)
+	assert.Equal(t, "SKU 10442", input.TextLines[1].Text)
+	assert.Equal(t, TextPositionAbsolute, input.TextLines[1].Position)
+	require.Len(t, input.Shapes, 2)
+	assert.Equal(t, LabelShape{Type: ShapeLine, XMM: 2, YMM: 12, WidthMM: 56}, input.Shapes[1])
+
+	output, err := RenderTemplate(tpl, data)
+	require.NoError(t, err)
+	assert.Contains(t, output.ZPL, "^FDOat Milk 1L^FS")
+	assert.Contains(t, output.ZPL, "^FO15,15^GB447,287,2,B,0^FS")
+	assert.NotEmpty(t, output.ImageBase64)
+
+	_, err = RenderTemplate(tpl, map[string]string{"Name": "Oat Milk 1L"})
+	assert.ErrorContains(t, err, `invalid label template "shelf": element 4`)
+}
+
+// TestRenderTemplate_Invalid verifies unknown elements, bad shapes and
+// templates without a barcode are rejected
+func TestRenderTemplate_Invalid(t *testing.T) {
+	tpl := LabelTemplate{Name: "bad", Width: 60, Height: 40, Dpi: 203, Elements: []TemplateElement{{Type: "arrow"}}}
+	_, err := RenderTemplate(tpl, nil)
+	assert.ErrorContains(t, err, `has type "arrow"`)
+
+	barcode := TemplateElement{Type: TemplateElementBarcode, BarcodeType: BarcodeTypeCode128, XMM: 5, YMM: 15, WidthMM: 50, HeightMM: 15, Data: "10442"}
+	tpl.Elements = []TemplateElement{barcode, {Type: TemplateElementLine, WidthMM: 10, HeightMM: 10}}
+	_, err = RenderTemplate(tpl, nil)
+	assert.ErrorContains(t, err, "Lines are horizontal or vertical")
+
+	tpl.Elements = []TemplateElement{{Type: TemplateElementText, Size: TextSizeSmall, Data: "No barcode"}}
+	_, err = RenderTemplate(tpl, nil)
+	assert.ErrorContains(t, err, "needs a barcode element")
+}
@@ -0,0 +1,103 @@
+package barcode
+
+import "fmt"
+
+// TextAlignment defines how a text line is placed across the label
+type TextAlignment string
+
+const (
+	TextAlignLeft   TextAlignment = "LEFT"
+	TextAlignCenter TextAlignment = "CENTER"
+	TextAlignRight  TextAlignment = "RIGHT"
+)
+
+// validateTextAlignment ensures a line is aligned left, centered or right.
+// An empty alignment centers the line.
+func validateTextAlignment(alignment TextAlignment) error {
+	switch alignment {
+	case "", TextAlignLeft, TextAlignCenter, TextAlignRight:
+		return nil
+	default:
+		return fmt.Errorf("invalid text alignment: %q. Supported alignments are %s, %s and %s", alignment, TextAlignLeft, TextAlignCenter, TextAlignRight)
+	}
+}
+
+// textAnchor is the horizontal point a text line is aligned to: its left
+// edge, centre or right edge depending on the alignment
+type textAnchor struct {
+	alignment TextAlignment
+	x         int
+}
+
+// textLineAnchor returns where a line is anchored in the text area:
+// left-aligned lines start at the margin, right-aligned lines end at it, and
+// centered lines are centered, each moved right by XOffsetMM. Side columns
+// are aligned towards the barcode, and absolute lines to their XMM, left
+// aligned unless an alignment is set.
+func textLineAnchor(textLine TextLine, area textArea, dpi int) textAnchor {
+	offset := mmToPixels(textLine.XOffsetMM, dpi)
+	switch {
+	case textLine.Position == TextPositionAbsolute:
+		alignment := textLine.Alignment
+		if alignment == "" {
+			alignment = TextAlignLeft
+		}
+		return textAnchor{alignment, mmToPixels(textLine.XMM, dpi) + offset}
+	case textLine.Position == TextPositionLeft:
+		return textAnchor{TextAlignRight, area.margin + area.left + offset}
+	case textLine.Position == TextPositionRight:
+		return textAnchor{TextAlignLeft, area.width - area.margin - area.right + offset}
+	case textLine.Alignment == TextAlignLeft:
+		return textAnchor{TextAlignLeft, area.margin + offset}
+	case textLine.Alignment == TextAlignRight:
+		return textAnchor{TextAlignRight, area.width - area.margin + offset}
+	default:
+		return textAnchor{TextAlignCenter, area.width/2 + offset}
+	}
+}
+
+// left returns the left edge of text of the given width at the anchor
+func (a textAnchor) left(textWidth int) int {
+	switch a.alignment {
+	case TextAlignLeft:
+		return a.x
+	case TextAlignRight:
+		return a.x - textWidth
+	default:
+		return a.x - textWidth/2
+	}
+}
+
+// textRow is a set of lines printed side by side at one position
+type textRow struct {
+	position TextPosition
+	lines    []int   // Indexes into the text lines
+	height   float64 // Height of the tallest line
+}
+
+// layoutTextRows groups text lines into rows in order. Each line starts a new
+// row at its position unless SameRow adds it to the previous row there. Lines
+// without a known position are below the barcode, and absolute lines are in
+// no row.
+func layoutTextRows(textLines []TextLine, lineHeight func(TextLine) float64) []textRow {
+	var rows []textRow
+	last := map[TextPosition]int{} // Index of the last row at each position
+	for i, textLine := range textLines {
+		position := textLine.Position
+		if position == TextPositionAbsolute {
+			continue
+		}
+		if position != TextPositionAbove && !isSideTextPosition(position) {
+			position = TextPositionBelow
+		}
+		height := lineHeight(textLine)
+		if r, ok := last[position]; ok && textLine.SameRow {
+			rows[r].lines = append(rows[r].lines, i)
+			rows[r].height = max(rows[r].height, height)
+			continue
+		}
+		last[position] = len(rows)
+		rows = append(rows, textRow{position: position, lines: []int{i}, height: height})
+	}
+	return rows
+}
@@ -0,0 +1,103 @@
+package barcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// skuQuantityLines returns a left-aligned SKU and a right-aligned quantity
+// on one row below the barcode
+func skuQuantityLines() []TextLine {
+	return []TextLine{
+		{Text: "SKU 10442", Position: TextPositionBelow, Size: TextSizeMedium, Alignment: TextAlignLeft},
+		{Text: "QTY 12", Position: TextPositionBelow, Size: TextSizeMedium, Alignment: TextAlignRight, SameRow: true},
+	}
+}
+
+// TestTextLineAnchor verifies lines align to the margins and move by their offset
+func TestTextLineAnchor(t *testing.T) {
+	area := textArea{width: 400, margin: 10}
+	assert.Equal(t, textAnchor{TextAlignCenter, 200}, textLineAnchor(TextLine{}, area, 203))
+	assert.Equal(t, textAnchor{TextAlignLeft, 10}, textLineAnchor(TextLine{Alignment: TextAlignLeft}, area, 203))
+	assert.Equal(t, textAnchor{TextAlignRight, 390}, textLineAnchor(TextLine{Alignment: TextAlignRight}, area, 203))
+	assert.Equal(t, textAnchor{TextAlignLeft, 10 + mmToPixels(5, 203)}, textLineAnchor(TextLine{Alignment: TextAlignLeft, XOffsetMM: 5}, area, 203))
+
+	assert.Equal(t, 10, textAnchor{TextAlignLeft, 10}.left(50))
+	assert.Equal(t, 340, textAnchor{TextAlignRight, 390}.left(50))
+	assert.Equal(t, 175, textAnchor{TextAlignCenter, 200}.left(50))
+}
+
+// TestCalculateTextLineOffsets_SameRow verifies lines on a row share its
+// offset and the row takes the height of its tallest line
+func TestCalculateTextLineOffsets_SameRow(t *testing.T) {
+	lines := append(skuQuantityLines(), TextLine{Text: "Aisle 4", Position: TextPositionBelow, Size: TextSizeSmall})
+	lines[1].Size = TextSizeLarge
+	offsets := calculateTextLineOffsets(lines, 203, 1)
+
+	_, large := getTextLineFontSize(lines[1], 203, 1)
+	assert.Equal(t, []int{0, 0, int(large)}, offsets)
+
+	rows := layoutTextRows(lines, textLineHeight(203, 1))
+	require.Len(t, rows, 2)
+	assert.Equal(t, []int{0, 1}, rows[0].lines)
+
+	// SameRow on the first line at a position starts a row
+	lines[0].SameRow = true
+	assert.Len(t, layoutTextRows(lines, textLineHeight(203, 1)), 2)
+}
+
+// TestZPLFieldBlock verifies field blocks run from the anchor to the label edge
+func TestZPLFieldBlock(t *testing.T) {
+	tests := []struct {
+		anchor        textAnchor
+		left, width   int
+		justification string
+	}{
+		{textAnchor{TextAlignCenter, 200}, 0, 400, "C"},
+		{textAnchor{TextAlignCenter, 250}, 100, 300, "C"},
+		{textAnchor{TextAlignLeft, 10}, 10, 390, "L"},
+		{textAnchor{TextAlignRight, 390}, 0, 390, "R"},
+	}
+	for _, tt := range tests {
+		left, width, justification := zplFieldBlock(tt.anchor, 400)
+		assert.Equal(t, tt.left, left)
+		assert.Equal(t, tt.width, width)
+		assert.Equal(t, tt.justification, justification)
+	}
+}
+
+// TestGenerateBarcode_TextAlignment verifies aligned lines sharing a row are
+// printed natively at the same height, and unknown alignments are rejected
+func TestGenerateBarcode_TextAlignment(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:   "10442",
+		BarcodeType:   BarcodeTypeCode128,
+		Width:         50.0,
+		Height:        25.0,
+		Dpi:           203,
+		TextLines:     skuQuantityLines(),
+		OutputFormats: []OutputFormat{OutputFormatPNG, OutputFormatZPL},
+		ZPL:           ZPLOptions{NativeCommands: true},
+	}
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Contains(t, output.ZPL, ",1,0,L,0^A0N")
+	assert.Contains(t, output.ZPL, ",1,0,R,0^A0N")
+	assert.Contains(t, output.ZPL, "^FDSKU 10442^FS")
+	assert.Contains(t, output.ZPL, "^FDQTY 12^FS")
+
+	bc, err := encodeBarcode(input)
+	require.NoError(t, err)
+	layout, err := layoutLabel(input, bc, input.Dpi)
+	require.NoError(t, err)
+	lines := layoutNativeTextLines(input, createBlankLabel(layout.width, layout.height), layout)
+	require.Len(t, lines, 2)
+	assert.Equal(t, lines[0].baseline, lines[1].baseline)
+
+	input.TextLines[0].Alignment = "JUSTIFY"
+	_, err = GenerateBarcode(input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid text alignment")
+}
@@ -0,0 +1,139 @@
+package barcode
+
+import "fmt"
+
+// defaultSecondaryRatio is the size of secondary-language lines relative to primary lines
+const defaultSecondaryRatio = 0.75
+
+// BilingualLine pairs a primary-language line with its translation
+type BilingualLine struct {
+	Primary   string
+	Secondary string // Optional: omitted when empty
+}
+
+// TextBlock renders primary/secondary language pairs as one unit. Secondary
+// lines are drawn at SecondaryRatio of the primary size, and the whole block
+// is shrunk together so the ratio holds when space is tight.
+type TextBlock struct {
+	Lines          []BilingualLine
+	Position       TextPosition
+	Size           TextSize // Size of the primary lines
+	SecondaryRatio float64  // Optional secondary size relative to primary, up to 1 (defaults to 0.75)
+}
+
+// labelTextLines returns the text lines to render: the human-readable
+// interpretation line when requested, then TextLines followed by the lines of
+// each text block and the wrapped lines of each paragraph, in order.
+func labelTextLines(input BarcodeInput) []TextLine {
+	hri, ok := humanReadableLine(input)
+	if len(input.TextBlocks) == 0 && len(input.Paragraphs) == 0 && !ok {
+		return input.TextLines
+	}
+
+	var lines []TextLine
+	if ok {
+		lines = append(lines, hri)
+	}
+	lines = append(lines, input.TextLines...)
+	for i, block := range input.TextBlocks {
+		lines = append(lines, textBlockLines(block, i)...)
+	}
+	for _, paragraph := range input.Paragraphs {
+		lines = append(lines, paragraphLines(paragraph, input)...)
+	}
+	return lines
+}
+
+// textBlockLines expands a text block into lines sharing a fit group. The
+// group name cannot be typed as a FitGroup, so blocks never join user groups.
+func textBlockLines(block TextBlock, index int) []TextLine {
+	ratio := block.SecondaryRatio
+	if ratio == 0 {
+		ratio = defaultSecondaryRatio
+	}
+	group := fmt.Sprintf("\x00textblock-%d", index)
+
+	var lines []TextLine
+	for _, pair := range block.Lines {
+		lines = append(lines, TextLine{Text: pair.Primary, Position: block.Position, Size: block.Size, FitGroup: group})
+		if pair.Secondary != "" {
+			lines = append(lines, TextLine{Text: pair.Secondary, Position: block.Position, Size: block.Size, FitGroup: group, Scale: ratio})
+		}
+	}
+	return lines
+}
+
+// validateTextBlocks ensures block positions and sizes are known and
+// secondary ratios shrink rather than enlarge text
+func validateTextBlocks(blocks []TextBlock) error {
+	for _, block := range blocks {
+		if err := validateTextPosition(block.Position); err != nil {
+			return err
+		}
+		if err := validateTextSize(block.Size); err != nil {
+			return err
+		}
+		if block.SecondaryRatio < 0 || block.SecondaryRatio > 1 {
+			return fmt.Errorf("invalid text block secondary ratio: %g. Ratio must be greater than 0 and at most 1", block.SecondaryRatio)
+		}
+	}
+	return nil
+}
+
+// validateTextLines ensures positions, sizes, alignments, fonts, overflow
+// strategies and rotations are known and optional font sizes and scales are
+// not negative.
+// Lines with an exact font size may leave Size empty.
+func validateTextLines(textLines []TextLine) error {
+	for _, textLine := range textLines {
+		if textLine.Position != TextPositionAbsolute {
+			if err := validateTextPosition(textLine.Position); err != nil {
+				return err
+			}
+		}
+		if textLine.FontSizePt < 0 {
+			return fmt.Errorf("invalid font size for %q: %gpt. Size must be positive", textLine.Text, textLine.FontSizePt)
+		}
+		if textLine.Size != "" || textLine.FontSizePt == 0 {
+			if err := validateTextSize(textLine.Size); err != nil {
+				return err
+			}
+		}
+		if err := validateTextAlignment(textLine.Alignment); err != nil {
+			return err
+		}
+		if err := validateFontName(textLine.fontRef()); err != nil {
+			return err
+		}
+		if err := validateTextOverflow(textLine.Overflow); err != nil {
+			return err
+		}
+		if err := validateTextRotation(textLine); err != nil {
+			return err
+		}
+		if textLine.Scale < 0 {
+			return fmt.Errorf("invalid text scale for %q: %g. Scale must be positive", textLine.Text, textLine.Scale)
+		}
+	}
+	return nil
+}
+
+// validateTextPosition ensures text is placed above, below or beside the barcode
+func validateTextPosition(position TextPosition) error {
+	switch position {
+	case TextPositionAbove, TextPositionBelow, TextPositionLeft, TextPositionRight:
+		return nil
+	default:
+		return fmt.Errorf("invalid text position: %q. Supported positions are %s, %s, %s and %s", position, TextPositionAbove, TextPositionBelow, TextPositionLeft, TextPositionRight)
+	}
+}
+
+// validateTextSize ensures text uses one of the predefined sizes
+func validateTextSize(size TextSize) error {
+	switch size {
+	case TextSizeSmall, TextSizeMedium, TextSizeLarge:
+		return nil
+	default:
+		return fmt.Errorf("invalid text size: %q. Supported sizes are %s, %s and %s", size, TextSizeSmall, TextSizeMedium, TextSizeLarge)
+	}
+}
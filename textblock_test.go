@@ -0,0 +1,111 @@
+package barcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTextBlockLines verifies pairs expand in order with the secondary ratio
+func TestTextBlockLines(t *testing.T) {
+	lines := textBlockLines(TextBlock{
+		Lines: []BilingualLine{
+			{Primary: "Ingredients: wheat flour", Secondary: "Zutaten: Weizenmehl"},
+			{Primary: "Store cool and dry"},
+		},
+		Position: TextPositionBelow,
+		Size:     TextSizeMedium,
+	}, 0)
+
+	require.Len(t, lines, 3)
+	assert.Equal(t, "Ingredients: wheat flour", lines[0].Text)
+	assert.Equal(t, 0.0, lines[0].Scale)
+	assert.Equal(t, "Zutaten: Weizenmehl", lines[1].Text)
+	assert.Equal(t, defaultSecondaryRatio, lines[1].Scale)
+	assert.Equal(t, "Store cool and dry", lines[2].Text)
+
+	for _, line := range lines {
+		assert.Equal(t, lines[0].FitGroup, line.FitGroup, "Block lines should share a fit group")
+		assert.Equal(t, TextPositionBelow, line.Position)
+	}
+}
+
+// TestTextBlock_SharedFitting verifies shrinking keeps the secondary ratio
+func TestTextBlock_SharedFitting(t *testing.T) {
+	lines := textBlockLines(TextBlock{
+		Lines: []BilingualLine{
+			{Primary: "Ingredients: wheat flour, sugar, palm oil, cocoa, hazelnuts, salt", Secondary: "Zutaten: Weizenmehl"},
+		},
+		Position:       TextPositionBelow,
+		Size:           TextSizeLarge,
+		SecondaryRatio: 0.5,
+	}, 0)
+
+	scales := calculateFitGroupScales(lines, textArea{width: 400, margin: labelMarginPixels}, 203, 1.2)
+	require.Len(t, scales, 1)
+	scale := scales[lines[0].FitGroup]
+	assert.Less(t, scale, 1.0, "Long primary line should shrink the block")
+
+	primary, _ := getTextLineFontSize(lines[0], 203, 1.2)
+	secondary, _ := getTextLineFontSize(lines[1], 203, 1.2)
+	assert.InDelta(t, 0.5, secondary/primary, 0.001, "Both lines share the group scale, so the ratio holds")
+}
+
+// TestGenerateBarcode_TextBlocks verifies blocks render and ratios are validated
+func TestGenerateBarcode_TextBlocks(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       75.0,
+		Height:      50.0,
+		Dpi:         203,
+		TextBlocks: []TextBlock{{
+			Lines: []BilingualLine{
+				{Primary: "Contains nuts", Secondary: "Enthält Nüsse"},
+			},
+			Position: TextPositionAbove,
+			Size:     TextSizeMedium,
+		}},
+	}
+
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.NotEmpty(t, output.ImageBase64)
+
+	input.TextBlocks[0].SecondaryRatio = 1.5
+	_, err = GenerateBarcode(input)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "secondary ratio")
+}
+
+// TestGenerateBarcode_TextEnums verifies unknown text positions and sizes are rejected by name
+func TestGenerateBarcode_TextEnums(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     TextLine
+		block    TextBlock
+		expected string
+	}{
+		{"LinePosition", TextLine{Text: "A", Position: "BESIDE", Size: TextSizeSmall}, TextBlock{}, `invalid text position: "BESIDE". Supported positions are ABOVE, BELOW, LEFT and RIGHT`},
+		{"LineSize", TextLine{Text: "A", Position: TextPositionBelow, Size: "HUGE"}, TextBlock{}, `invalid text size: "HUGE". Supported sizes are SMALL, MEDIUM and LARGE`},
+		{"FontSize", TextLine{Text: "A", Position: TextPositionBelow, FontSizePt: -2}, TextBlock{}, `invalid font size for "A": -2pt`},
+		{"EmptyPosition", TextLine{Text: "A", Size: TextSizeSmall}, TextBlock{}, `invalid text position: ""`},
+		{"BlockSize", TextLine{}, TextBlock{Position: TextPositionAbove, Size: "medium"}, `invalid text size: "medium"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := BarcodeInput{BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeCode128, Width: 75.0, Height: 50.0, Dpi: 203}
+			if tt.line.Text != "" {
+				input.TextLines = []TextLine{tt.line}
+			} else {
+				input.TextBlocks = []TextBlock{tt.block}
+			}
+
+			_, err := GenerateBarcode(input)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package barcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TextOverflow is how a text line that is too wide for the label is handled
+type TextOverflow string
+
+const (
+	TextOverflowShrink   TextOverflow = "SHRINK"   // Reduce the font size until the line fits (default)
+	TextOverflowTruncate TextOverflow = "TRUNCATE" // Keep the font size and cut characters from the end
+	TextOverflowEllipsis TextOverflow = "ELLIPSIS" // Keep the font size and end the cut line in an ellipsis
+	TextOverflowError    TextOverflow = "ERROR"    // Fail, e.g. for lot numbers that must stay legible
+)
+
+// applyTextOverflow returns the text lines with lines that truncate on
+// overflow cut to fit their width at their full font size. Lines that set
+// TextOverflowError and do not fit return an error; other lines are left for
+// the fitter to shrink.
+func applyTextOverflow(textLines []TextLine, area textArea, dpi int, fontScale float64) ([]TextLine, error) {
+	var fitted []TextLine
+	for i, textLine := range textLines {
+		if textLine.Overflow == "" || textLine.Overflow == TextOverflowShrink {
+			continue
+		}
+
+		fontSize, _ := getTextLineFontSize(textLine, dpi, fontScale)
+		maxWidth := area.maxLength(textLine)
+		fits := func(text string) bool {
+			width, err := measureTextWidth(textLine.fontRef(), text, fontSize, float64(dpi))
+			return err == nil && width <= maxWidth
+		}
+		if fits(textLine.Text) {
+			continue
+		}
+
+		if textLine.Overflow == TextOverflowError {
+			width, _ := measureTextWidth(textLine.fontRef(), textLine.Text, fontSize, float64(dpi))
+			mm := func(pixels int) float64 { return float64(pixels) * 25.4 / float64(dpi) }
+			return nil, fmt.Errorf("text line does not fit: %q is %.1fmm wide with %.1fmm available. Shorten the text, choose a smaller size or another Overflow", textLine.Text, mm(width), mm(maxWidth))
+		}
+
+		if fitted == nil {
+			fitted = append([]TextLine(nil), textLines...)
+		}
+		if textLine.Overflow == TextOverflowEllipsis {
+			fitted[i].Text = truncateWithEllipsis(textLine.Text, fits)
+		} else {
+			fitted[i].Text = strings.TrimRight(longestFittingPrefix(textLine.Text, fits), " ")
+		}
+	}
+
+	if fitted == nil {
+		return textLines, nil
+	}
+	return fitted, nil
+}
+
+// validateTextOverflow ensures a text line uses a known overflow strategy
+func validateTextOverflow(overflow TextOverflow) error {
+	switch overflow {
+	case "", TextOverflowShrink, TextOverflowTruncate, TextOverflowEllipsis, TextOverflowError:
+		return nil
+	default:
+		return fmt.Errorf("invalid text overflow: %q. Supported values are %s, %s, %s and %s", overflow, TextOverflowShrink, TextOverflowTruncate, TextOverflowEllipsis, TextOverflowError)
+	}
+}
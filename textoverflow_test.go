@@ -0,0 +1,95 @@
+package barcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestApplyTextOverflow verifies truncated lines keep their size and fit,
+// and other lines are left to be shrunk
+func TestApplyTextOverflow(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "10442",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       30.0,
+		Height:      20.0,
+		Dpi:         203,
+		TextLines: []TextLine{
+			{Text: "LOT 2026-10-15-A0042117-REWORK", Position: TextPositionBelow, Size: TextSizeLarge, Overflow: TextOverflowEllipsis},
+		},
+	}
+	area := textArea{width: mmToPixels(input.Width, input.Dpi), margin: labelMarginPixels}
+	fontScale := labelFontScale(input)
+	fontSize, _ := getTextLineFontSize(input.TextLines[0], input.Dpi, fontScale)
+
+	for _, overflow := range []TextOverflow{TextOverflowTruncate, TextOverflowEllipsis} {
+		input.TextLines[0].Overflow = overflow
+		lines, err := applyTextOverflow(input.TextLines, area, input.Dpi, fontScale)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(input.TextLines[0].Text, strings.TrimSuffix(lines[0].Text, paragraphEllipsis)))
+		width, err := measureTextWidth(fontRef{}, lines[0].Text, fontSize, float64(input.Dpi))
+		require.NoError(t, err)
+		assert.LessOrEqual(t, width, area.maxWidth(TextPositionBelow))
+		assert.Equal(t, overflow == TextOverflowEllipsis, strings.HasSuffix(lines[0].Text, paragraphEllipsis))
+	}
+
+	input.TextLines[0].Overflow = ""
+	lines, err := applyTextOverflow(input.TextLines, area, input.Dpi, fontScale)
+	require.NoError(t, err)
+	assert.Equal(t, input.TextLines, lines)
+}
+
+// TestGenerateBarcode_TextOverflow verifies truncated lines are printed
+// natively with their ellipsis
+func TestGenerateBarcode_TextOverflow(t *testing.T) {
+	output, err := GenerateBarcode(BarcodeInput{
+		BarcodeData: "10442",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       30.0,
+		Height:      20.0,
+		Dpi:         203,
+		TextLines: []TextLine{
+			{Text: "LOT 2026-10-15-A0042117-REWORK", Position: TextPositionBelow, Size: TextSizeLarge, Overflow: TextOverflowEllipsis},
+		},
+		ZPL: ZPLOptions{NativeCommands: true},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, output.ZPL, "^FDLOT 2026")
+	assert.Contains(t, output.ZPL, "...^FS")
+}
+
+// TestGenerateBarcode_TextOverflowErrors verifies ERROR fails instead of
+// shrinking and unknown strategies are rejected
+func TestGenerateBarcode_TextOverflowErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		overflow TextOverflow
+		errMsg   string
+	}{
+		{"error when too long", "LOT 2026-10-15-A0042117-REWORK", TextOverflowError, "text line does not fit"},
+		{"error when it fits", "LOT 42", TextOverflowError, ""},
+		{"unknown strategy", "LOT 2026-10-15-A0042117-REWORK", "WRAP", "invalid text overflow"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateBarcode(BarcodeInput{
+				BarcodeData: "10442",
+				BarcodeType: BarcodeTypeCode128,
+				Width:       30.0,
+				Height:      20.0,
+				Dpi:         203,
+				TextLines:   []TextLine{{Text: tt.text, Position: TextPositionBelow, Size: TextSizeLarge, Overflow: tt.overflow}},
+			})
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.errMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// isRotatedText reports whether a text line runs vertically
+func isRotatedText(textLine TextLine) bool {
+	return textLine.Rotation == 90 || textLine.Rotation == 270
+}
+
+// rotatedTextLength returns the length of a rotated line at its full font
+// size in pixels, which is the height it takes in its side column
+func rotatedTextLength(textLine TextLine, dpi int, fontScale float64) float64 {
+	fontSize, _ := getTextLineFontSize(textLine, dpi, fontScale)
+	width, err := measureTextWidth(textLine.fontRef(), textLine.Text, fontSize, float64(dpi))
+	if err != nil {
+		return 0
+	}
+	return float64(width)
+}
+
+// rotatedTextRect returns where a rotated line at the font size is drawn: as
+// wide as the font height against its anchor, and centered vertically in the
+// length the line takes from its base Y at its full size
+func rotatedTextRect(textLine TextLine, anchor textAnchor, baseY int, fontSize float64, dpi int, fontScale float64) image.Rectangle {
+	length, _ := measureTextWidth(textLine.fontRef(), textLine.Text, fontSize, float64(dpi))
+	height := int(calculateFontHeight(textLine.fontRef(), fontSize, dpi))
+	left := anchor.left(height)
+	top := baseY + (int(rotatedTextLength(textLine, dpi, fontScale))-length)/2
+	return image.Rect(left, top, left+height, top+length)
+}
+
+// drawRotatedText draws a text line horizontally on a transparent image,
+// turns it clockwise by the line's rotation and draws it over the label at
+// rect, so 90 degrees reads top to bottom and 270 bottom to top
+func drawRotatedText(img *image.RGBA, textLine TextLine, rect image.Rectangle, fontSize float64, dpi int) {
+	if rect.Empty() {
+		return
+	}
+	horizontal := image.NewRGBA(image.Rect(0, 0, rect.Dy(), rect.Dx()))
+	drawText(horizontal, textLine.fontRef(), textLine.Text, textAnchor{TextAlignLeft, 0}, 0, fontSize, float64(rect.Dx()), float64(dpi), textLine.Position, color.Black)
+	draw.Draw(img, rect, rotateLabel(horizontal, textLine.Rotation), image.Point{}, draw.Over)
+}
+
+// validateTextRotation ensures a line is unrotated or turned a quarter turn,
+// and that rotated lines run beside the barcode
+func validateTextRotation(textLine TextLine) error {
+	switch textLine.Rotation {
+	case 0:
+		return nil
+	case 90, 270:
+		if !isSideTextPosition(textLine.Position) {
+			return fmt.Errorf("invalid text rotation for %q: %d. Rotated lines run beside the barcode at %s or %s", textLine.Text, textLine.Rotation, TextPositionLeft, TextPositionRight)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid text rotation for %q: %d. Supported rotations are 0, 90 and 270", textLine.Text, textLine.Rotation)
+	}
+}
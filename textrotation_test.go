@@ -0,0 +1,94 @@
+package barcode
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLayoutNativeTextLines_Rotated verifies a rotated line takes a column
+// as wide as its font height and is drawn upright in it, centered on the
+// barcode
+func TestLayoutNativeTextLines_Rotated(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A12-03-B",
+		BarcodeType: BarcodeTypeQR,
+		Width:       40.0,
+		Height:      80.0,
+		Dpi:         203,
+		TextLines: []TextLine{
+			{Text: "A-12-03-B", Position: TextPositionLeft, Size: TextSizeLarge, Rotation: 270},
+		},
+		ZPL: ZPLOptions{NativeCommands: true},
+	}
+	_, fontHeight := getTextLineFontSize(input.TextLines[0], input.Dpi, labelFontScale(input))
+	left, _ := sideColumnWidths(input)
+	assert.Equal(t, int(fontHeight), left)
+
+	bc, err := encodeBarcode(input)
+	require.NoError(t, err)
+	layout, err := layoutLabel(input, bc, input.Dpi)
+	require.NoError(t, err)
+	printImg := createBlankLabel(layout.width, layout.height)
+	lines := layoutNativeTextLines(input, printImg, layout)
+	require.Len(t, lines, 1)
+
+	rect := lines[0].rotatedRect
+	assert.Greater(t, rect.Dy(), rect.Dx())
+	assert.LessOrEqual(t, rect.Max.X, layout.barcodeRect.Min.X)
+	center := (layout.barcodeRect.Min.Y + layout.barcodeRect.Max.Y) / 2
+	assert.InDelta(t, center, (rect.Min.Y+rect.Max.Y)/2, 2)
+	assert.Equal(t, rect, textBand(lines[0], input.Dpi, layout.width))
+
+	require.NoError(t, renderTextLines(printImg, input, layout.barcodeRect, input.Dpi))
+	assert.True(t, hasDarkPixel(printImg, rect))
+	assert.False(t, hasDarkPixel(printImg, image.Rect(rect.Max.X, rect.Min.Y, layout.barcodeRect.Min.X, rect.Max.Y)))
+}
+
+// TestGenerateBarcode_RotatedText verifies rotated lines are sent to printers
+// as graphics and are only accepted beside the barcode
+func TestGenerateBarcode_RotatedText(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A12-03-B",
+		BarcodeType: BarcodeTypeQR,
+		Width:       40.0,
+		Height:      80.0,
+		Dpi:         203,
+		TextLines: []TextLine{
+			{Text: "A-12-03-B", Position: TextPositionLeft, Size: TextSizeLarge, Rotation: 270},
+		},
+		ZPL: ZPLOptions{NativeCommands: true},
+	}
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.NotContains(t, output.ZPL, "^FDA-12-03-B")
+	assert.Contains(t, output.ZPL, "^GF")
+
+	stored := input
+	stored.BarcodeType, stored.BarcodeData = BarcodeTypeCode128, "LOCA12030B"
+	_, err = GenerateZPLStoredFormat(stored, "E:RACK.ZPL")
+	assert.ErrorContains(t, err, "Rotated lines")
+
+	input.TextLines[0].Position = TextPositionBelow
+	_, err = GenerateBarcode(input)
+	assert.ErrorContains(t, err, "invalid text rotation")
+
+	input.TextLines[0].Position, input.TextLines[0].Rotation = TextPositionLeft, 45
+	_, err = GenerateBarcode(input)
+	assert.ErrorContains(t, err, "Supported rotations are 0, 90 and 270")
+}
+
+// hasDarkPixel reports whether any pixel in the area of img is dark
+func hasDarkPixel(img *image.RGBA, area image.Rectangle) bool {
+	area = area.Intersect(img.Bounds())
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			if img.RGBAAt(x, y).R < 128 {
+				return true
+			}
+		}
+	}
+	return false
+}
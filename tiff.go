@@ -0,0 +1,264 @@
+package barcode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+)
+
+// TIFF tag numbers, field types and values
+const (
+	tiffTagImageWidth      = 256
+	tiffTagImageLength     = 257
+	tiffTagBitsPerSample   = 258
+	tiffTagCompression     = 259
+	tiffTagPhotometric     = 262
+	tiffTagStripOffsets    = 273
+	tiffTagSamplesPerPixel = 277
+	tiffTagRowsPerStrip    = 278
+	tiffTagStripByteCounts = 279
+	tiffTagXResolution     = 282
+	tiffTagYResolution     = 283
+	tiffTagResolutionUnit  = 296
+
+	tiffTypeShort    = 3
+	tiffTypeLong     = 4
+	tiffTypeRational = 5
+
+	tiffCompressionNone   = 1
+	tiffCompressionGroup4 = 4
+	tiffWhiteIsZero       = 0
+	tiffBlackIsZero       = 1
+	tiffResolutionInch    = 2
+)
+
+// tiffEntry is one IFD entry. Rationals are stored after the IFD and
+// referenced by offset.
+type tiffEntry struct {
+	tag, fieldType uint16
+	value          uint32
+}
+
+// imageToTIFF encodes an image as a single-strip TIFF whose XResolution and
+// YResolution tags hold the DPI, so print bureaus reproduce the label at its
+// physical size. Monochrome images are bilevel with CCITT Group 4
+// compression; others are uncompressed 8-bit greyscale.
+func imageToTIFF(img *image.RGBA, dpi int, monochrome bool) []byte {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	var pixels []byte
+	bitsPerSample, compression, photometric := 8, tiffCompressionNone, tiffBlackIsZero
+	if monochrome {
+		pixels = encodeGroup4(toMonochrome(img))
+		bitsPerSample, compression, photometric = 1, tiffCompressionGroup4, tiffWhiteIsZero
+	} else {
+		pixels = make([]byte, 0, width*height)
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				pixels = append(pixels, color.GrayModel.Convert(img.RGBAAt(x, y)).(color.Gray).Y)
+			}
+		}
+	}
+
+	// Header, then the IFD, the two resolution rationals and the pixels
+	const headerSize, entryCount = 8, 12
+	ifdSize := 2 + entryCount*12 + 4
+	resolutionOffset := headerSize + ifdSize
+	pixelOffset := resolutionOffset + 16
+
+	entries := [entryCount]tiffEntry{
+		{tiffTagImageWidth, tiffTypeLong, uint32(width)},
+		{tiffTagImageLength, tiffTypeLong, uint32(height)},
+		{tiffTagBitsPerSample, tiffTypeShort, uint32(bitsPerSample)},
+		{tiffTagCompression, tiffTypeShort, uint32(compression)},
+		{tiffTagPhotometric, tiffTypeShort, uint32(photometric)},
+		{tiffTagStripOffsets, tiffTypeLong, uint32(pixelOffset)},
+		{tiffTagSamplesPerPixel, tiffTypeShort, 1},
+		{tiffTagRowsPerStrip, tiffTypeLong, uint32(height)},
+		{tiffTagStripByteCounts, tiffTypeLong, uint32(len(pixels))},
+		{tiffTagXResolution, tiffTypeRational, uint32(resolutionOffset)},
+		{tiffTagYResolution, tiffTypeRational, uint32(resolutionOffset + 8)},
+		{tiffTagResolutionUnit, tiffTypeShort, tiffResolutionInch},
+	}
+
+	var tiff bytes.Buffer
+	tiff.WriteString("II*\x00")
+	binary.Write(&tiff, binary.LittleEndian, uint32(headerSize))
+	binary.Write(&tiff, binary.LittleEndian, uint16(entryCount))
+	for _, entry := range entries {
+		binary.Write(&tiff, binary.LittleEndian, entry.tag)
+		binary.Write(&tiff, binary.LittleEndian, entry.fieldType)
+		binary.Write(&tiff, binary.LittleEndian, uint32(1))
+		// Values shorter than four bytes are left-justified
+		if entry.fieldType == tiffTypeShort {
+			binary.Write(&tiff, binary.LittleEndian, uint16(entry.value))
+			binary.Write(&tiff, binary.LittleEndian, uint16(0))
+		} else {
+			binary.Write(&tiff, binary.LittleEndian, entry.value)
+		}
+	}
+	binary.Write(&tiff, binary.LittleEndian, uint32(0)) // No further IFDs
+	binary.Write(&tiff, binary.LittleEndian, [4]uint32{uint32(dpi), 1, uint32(dpi), 1})
+	tiff.Write(pixels)
+	return tiff.Bytes()
+}
+
+// Group 4 mode codes (ITU-T T.6)
+const (
+	group4Pass       = "0001"
+	group4Horizontal = "001"
+)
+
+// group4VerticalCodes are the vertical mode codes for a1 from b1-3 to b1+3
+var group4VerticalCodes = [7]string{"0000010", "000010", "010", "1", "011", "000011", "0000011"}
+
+// encodeGroup4 compresses a black and white image with CCITT Group 4 (T.6),
+// coding each row against the one above it. Index 0 of the palette is black.
+func encodeGroup4(img *image.Paletted) []byte {
+	bounds := img.Bounds()
+	width := bounds.Dx()
+	var w bitWriter
+
+	reference := make([]bool, width) // The imaginary row above the first is white
+	row := make([]bool, width)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := range row {
+			row[x] = img.ColorIndexAt(bounds.Min.X+x, y) == 0
+		}
+
+		a0, black := -1, false
+		for a0 < width {
+			a1 := nextColorChange(row, a0+1, !black)
+			b1 := nextChangingElement(reference, a0+1, !black)
+			b2 := nextColorChange(reference, b1+1, black)
+
+			switch {
+			case b2 < a1:
+				w.writeCode(group4Pass)
+				a0 = b2
+			case a1-b1 >= -3 && a1-b1 <= 3:
+				w.writeCode(group4VerticalCodes[a1-b1+3])
+				a0, black = a1, !black
+			default:
+				a2 := nextColorChange(row, a1+1, black)
+				w.writeCode(group4Horizontal)
+				w.writeRun(a1-max(a0, 0), black)
+				w.writeRun(a2-a1, !black)
+				a0 = a2
+			}
+		}
+		reference, row = row, reference
+	}
+
+	// End of facsimile block: two EOL codes
+	w.writeCode("000000000001000000000001")
+	return w.data
+}
+
+// nextColorChange returns the first position from start where the row has
+// the colour black, or the row width when there is none
+func nextColorChange(row []bool, start int, black bool) int {
+	for x := start; x < len(row); x++ {
+		if row[x] == black {
+			return x
+		}
+	}
+	return len(row)
+}
+
+// nextChangingElement returns the first position from start where the row
+// changes to the colour black, counting the start of the row as a change
+// from white, or the row width when there is none
+func nextChangingElement(row []bool, start int, black bool) int {
+	for x := max(start, 0); x < len(row); x++ {
+		previous := x > 0 && row[x-1]
+		if row[x] == black && previous != black {
+			return x
+		}
+	}
+	return len(row)
+}
+
+// bitWriter packs codes into bytes, most significant bit first, padding the
+// last byte with 0 bits
+type bitWriter struct {
+	data  []byte
+	nbits int
+}
+
+// writeCode appends a code given as a string of '0' and '1'
+func (w *bitWriter) writeCode(code string) {
+	for _, bit := range code {
+		if w.nbits%8 == 0 {
+			w.data = append(w.data, 0)
+		}
+		if bit == '1' {
+			w.data[len(w.data)-1] |= 0x80 >> (w.nbits % 8)
+		}
+		w.nbits++
+	}
+}
+
+// writeRun appends the modified Huffman codes for a run of one colour: as
+// many 2560 makeup codes as needed, a makeup code for the remaining multiple
+// of 64 and a terminating code
+func (w *bitWriter) writeRun(run int, black bool) {
+	terminating, makeup := ccittWhiteTerminatingCodes[:], ccittWhiteMakeupCodes[:]
+	if black {
+		terminating, makeup = ccittBlackTerminatingCodes[:], ccittBlackMakeupCodes[:]
+	}
+	for run >= 2560 {
+		w.writeCode(ccittExtendedMakeupCodes[len(ccittExtendedMakeupCodes)-1])
+		run -= 2560
+	}
+	if m := run / 64; m > len(makeup) {
+		w.writeCode(ccittExtendedMakeupCodes[m-len(makeup)-1])
+	} else if m > 0 {
+		w.writeCode(makeup[m-1])
+	}
+	w.writeCode(terminating[run%64])
+}
+
+// Modified Huffman run-length codes (ITU-T T.4). Terminating codes are
+// indexed by run length 0-63 and makeup codes by run length/64 - 1.
+var (
+	ccittWhiteTerminatingCodes = [64]string{
+		"00110101", "000111", "0111", "1000", "1011", "1100", "1110", "1111",
+		"10011", "10100", "00111", "01000", "001000", "000011", "110100", "110101",
+		"101010", "101011", "0100111", "0001100", "0001000", "0010111", "0000011", "0000100",
+		"0101000", "0101011", "0010011", "0100100", "0011000", "00000010", "00000011", "00011010",
+		"00011011", "00010010", "00010011", "00010100", "00010101", "00010110", "00010111", "00101000",
+		"00101001", "00101010", "00101011", "00101100", "00101101", "00000100", "00000101", "00001010",
+		"00001011", "01010010", "01010011", "01010100", "01010101", "00100100", "00100101", "01011000",
+		"01011001", "01011010", "01011011", "01001010", "01001011", "00110010", "00110011", "00110100",
+	}
+	ccittBlackTerminatingCodes = [64]string{
+		"0000110111", "010", "11", "10", "011", "0011", "0010", "00011",
+		"000101", "000100", "0000100", "0000101", "0000111", "00000100", "00000111", "000011000",
+		"0000010111", "0000011000", "0000001000", "00001100111", "00001101000", "00001101100", "00000110111", "00000101000",
+		"00000010111", "00000011000", "000011001010", "000011001011", "000011001100", "000011001101", "000001101000", "000001101001",
+		"000001101010", "000001101011", "000011010010", "000011010011", "000011010100", "000011010101", "000011010110", "000011010111",
+		"000001101100", "000001101101", "000011011010", "000011011011", "000001010100", "000001010101", "000001010110", "000001010111",
+		"000001100100", "000001100101", "000001010010", "000001010011", "000000100100", "000000110111", "000000111000", "000000100111",
+		"000000101000", "000001011000", "000001011001", "000000101011", "000000101100", "000001011010", "000001100110", "000001100111",
+	}
+	ccittWhiteMakeupCodes = [27]string{
+		"11011", "10010", "010111", "0110111", "00110110", "00110111", "01100100", "01100101",
+		"01101000", "01100111", "011001100", "011001101", "011010010", "011010011", "011010100", "011010101",
+		"011010110", "011010111", "011011000", "011011001", "011011010", "011011011", "010011000", "010011001",
+		"010011010", "011000", "010011011",
+	}
+	ccittBlackMakeupCodes = [27]string{
+		"0000001111", "000011001000", "000011001001", "000001011011", "000000110011", "000000110100", "000000110101", "0000001101100",
+		"0000001101101", "0000001001010", "0000001001011", "0000001001100", "0000001001101", "0000001110010", "0000001110011", "0000001110100",
+		"0000001110101", "0000001110110", "0000001110111", "0000001010010", "0000001010011", "0000001010100", "0000001010101", "0000001011010",
+		"0000001011011", "0000001100100", "0000001100101",
+	}
+	// ccittExtendedMakeupCodes are shared by both colours for runs of 1792-2560
+	ccittExtendedMakeupCodes = [13]string{
+		"00000001000", "00000001100", "00000001101", "000000010010", "000000010011", "000000010100", "000000010101", "000000010110",
+		"000000010111", "000000011100", "000000011101", "000000011110", "000000011111",
+	}
+)
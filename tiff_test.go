@@ -0,0 +1,130 @@
+package barcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/ccitt"
+	"golang.org/x/image/tiff"
+)
+
+// tiffTag returns the value of a SHORT or LONG tag, or the numerator of a
+// RATIONAL tag, from the first IFD of a little-endian TIFF
+func tiffTag(t *testing.T, data []byte, tag uint16) uint32 {
+	t.Helper()
+	ifd := binary.LittleEndian.Uint32(data[4:])
+	count := int(binary.LittleEndian.Uint16(data[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := data[int(ifd)+2+i*12:]
+		if binary.LittleEndian.Uint16(entry) != tag {
+			continue
+		}
+		switch binary.LittleEndian.Uint16(entry[2:]) {
+		case tiffTypeShort:
+			return uint32(binary.LittleEndian.Uint16(entry[8:]))
+		case tiffTypeRational:
+			return binary.LittleEndian.Uint32(data[binary.LittleEndian.Uint32(entry[8:]):])
+		default:
+			return binary.LittleEndian.Uint32(entry[8:])
+		}
+	}
+	t.Fatalf("TIFF tag %d not found", tag)
+	return 0
+}
+
+// TestImageToTIFF verifies greyscale and bilevel TIFFs decode to the label and carry the DPI
+func TestImageToTIFF(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 120, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 120; x++ {
+			img.Set(x, y, color.White)
+			if (x/7+y/5)%2 == 0 {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	for _, monochrome := range []bool{false, true} {
+		data := imageToTIFF(img, 300, monochrome)
+
+		assert.Equal(t, uint32(300), tiffTag(t, data, tiffTagXResolution))
+		assert.Equal(t, uint32(300), tiffTag(t, data, tiffTagYResolution))
+		assert.Equal(t, uint32(tiffResolutionInch), tiffTag(t, data, tiffTagResolutionUnit))
+
+		decoded, err := tiff.Decode(bytes.NewReader(data))
+		require.NoError(t, err, "monochrome %v", monochrome)
+		require.Equal(t, img.Bounds(), decoded.Bounds())
+		for y := 0; y < 40; y++ {
+			for x := 0; x < 120; x++ {
+				want := color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y
+				got := color.GrayModel.Convert(decoded.At(x, y)).(color.Gray).Y
+				require.Equal(t, want, got, "pixel %d,%d with monochrome %v", x, y, monochrome)
+			}
+		}
+	}
+
+	assert.Equal(t, uint32(tiffCompressionGroup4), tiffTag(t, imageToTIFF(img, 203, true), tiffTagCompression))
+}
+
+// TestEncodeGroup4 verifies random rows and runs longer than the makeup codes decode back to the image
+func TestEncodeGroup4(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	img := image.NewPaletted(image.Rect(0, 0, 5300, 24), monochromePalette)
+	for y := 0; y < 24; y++ {
+		for x := 0; x < 5300; x++ {
+			switch {
+			case y < 8:
+				img.SetColorIndex(x, y, uint8(random.Intn(2)))
+			case y < 16:
+				img.SetColorIndex(x, y, uint8((x/(1+random.Intn(200)))%2))
+			case x > 2600:
+				img.SetColorIndex(x, y, 1) // Runs of 2699 white after 2601 black
+			}
+		}
+	}
+
+	decoded := image.NewGray(img.Bounds())
+	require.NoError(t, ccitt.DecodeIntoGray(decoded, bytes.NewReader(encodeGroup4(img)), ccitt.MSB, ccitt.Group4, nil))
+	for y := 0; y < 24; y++ {
+		for x := 0; x < 5300; x++ {
+			require.Equal(t, img.ColorIndexAt(x, y) == 1, decoded.GrayAt(x, y).Y == 0xFF, "pixel %d,%d", x, y)
+		}
+	}
+}
+
+// TestGenerateBarcode_TIFF verifies the TIFF is only produced on request, at the printer DPI
+func TestGenerateBarcode_TIFF(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1-B2-C3",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50,
+		Height:      25,
+		Dpi:         203,
+		PreviewDpi:  300,
+	}
+
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Empty(t, output.TIFFBase64)
+
+	input.OutputFormats = []OutputFormat{OutputFormatTIFF}
+	input.Monochrome = true
+	output, err = GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Empty(t, output.ImageBase64)
+
+	data, err := base64.StdEncoding.DecodeString(output.TIFFBase64)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(203), tiffTag(t, data, tiffTagXResolution))
+	decoded, err := tiff.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, mmToPixels(input.Width, input.Dpi), decoded.Bounds().Dx())
+	assert.Equal(t, mmToPixels(input.Height, input.Dpi), decoded.Bounds().Dy())
+}
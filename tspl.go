@@ -0,0 +1,145 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/boombuler/barcode"
+)
+
+// TSPL layout constants
+const (
+	tsplLabelGapMM     = 3.0 // Gap between die-cut labels
+	tsplMaxMultiplier  = 10  // Largest font multiplier accepted in both directions
+	tsplMaxQRCellWidth = 10  // Largest QR module width in dots
+)
+
+// tsplFonts lists resident fonts 1-5 by printer DPI
+var tsplFonts = map[int][]residentFont{
+	203: {{8, 12}, {12, 20}, {16, 24}, {24, 32}, {32, 48}},
+	300: {{12, 20}, {16, 28}, {24, 32}, {32, 48}, {48, 64}},
+}
+
+// generateTSPL converts the label to TSPL for TSC printers. Text, QR codes
+// and the linear barcodes TSPL supports are sent as native commands laid out
+// like the print image. Other symbols, and text no resident font fits, are
+// sent as BITMAP graphics cropped from the print image. When native is false,
+// the whole print image is sent as one graphic.
+func generateTSPL(input BarcodeInput, bc barcode.Barcode, printImg *image.RGBA, native bool) (string, error) {
+	var tspl strings.Builder
+	width, height := labelSizeMM(input)
+	fmt.Fprintf(&tspl, "SIZE %g mm,%g mm\r\nGAP %g mm,0 mm\r\nCLS\r\n", width, height, tsplLabelGapMM)
+
+	if !native {
+		writeTSPLGraphic(&tspl, printImg, printImg.Bounds())
+		tspl.WriteString("PRINT 1\r\n")
+		return tspl.String(), nil
+	}
+
+	layout, err := layoutLabel(input, bc, input.Dpi)
+	if err != nil {
+		return "", err
+	}
+	if command, ok := tsplBarcodeCommand(input, bc, layout); ok {
+		tspl.WriteString(command)
+	} else {
+		writeTSPLGraphic(&tspl, printImg, layout.barcodeRect)
+	}
+
+	writeTSPLTextLines(&tspl, input, printImg, layout)
+	tspl.WriteString("PRINT 1\r\n")
+	return tspl.String(), nil
+}
+
+// tsplBarcodeCommand returns the native BARCODE or QRCODE command for
+// barcodes TSPL can print identically: unrotated Code128 in the automatic
+// code set without function characters, ITF, ISBN or ISSN without an
+// add-on, and QR codes the printer encodes at the same version
+func tsplBarcodeCommand(input BarcodeInput, bc barcode.Barcode, layout labelLayout) (string, bool) {
+	if input.BarcodeRotation != 0 {
+		return "", false
+	}
+	origin, moduleWidth, ok := nativeModuleLayout(bc, layout)
+	if !ok {
+		return "", false
+	}
+
+	var codeType, data string
+	wideWidth := moduleWidth * 2
+	switch input.BarcodeType {
+	case BarcodeTypeCode128:
+		if input.Code128.CodeSet != Code128CodeSetAuto || !isPrintableASCII(input.BarcodeData) {
+			return "", false
+		}
+		codeType = "128"
+		data = input.BarcodeData
+	case BarcodeTypeITF:
+		codeType = "25"
+		data = bc.Content()
+		wideWidth = moduleWidth * 3
+	case BarcodeTypeISBN, BarcodeTypeISSN:
+		if input.ISBN.AddOn != "" {
+			return "", false
+		}
+		codeType = "EAN13"
+		data = bc.Content()[:12] // The printer adds the check digit
+	case BarcodeTypeQR:
+		return tsplQRCommand(input, origin, moduleWidth)
+	default:
+		return "", false
+	}
+
+	return fmt.Sprintf("BARCODE %d,%d,\"%s\",%d,0,0,%d,%d,%s\r\n", origin.X, origin.Y, codeType,
+		layout.barcodeRect.Dy(), moduleWidth, wideWidth, quoteTSPL(data)), true
+}
+
+// tsplQRCommand returns the QRCODE command for QR codes in the automatic
+// mode and version, which the printer picks the same way as the encoder
+func tsplQRCommand(input BarcodeInput, origin image.Point, cellWidth int) (string, bool) {
+	if input.BarcodeDataBytes != nil || input.QR.Mode != QRModeAuto || input.QR.MinVersion > 1 ||
+		cellWidth > tsplMaxQRCellWidth || !isPrintableASCII(input.BarcodeData) {
+		return "", false
+	}
+
+	level := input.QR.ErrorCorrection
+	if level == "" {
+		level = QRErrorCorrectionM
+	}
+	return fmt.Sprintf("QRCODE %d,%d,%s,%d,A,0,%s\r\n", origin.X, origin.Y, level, cellWidth, quoteTSPL(input.BarcodeData)), true
+}
+
+// writeTSPLTextLines writes each text line as a native TEXT command in the
+// largest resident font that fits its rendered size, falling back to a
+// graphic of the line when none fits
+func writeTSPLTextLines(tspl *strings.Builder, input BarcodeInput, printImg *image.RGBA, layout labelLayout) {
+	fonts := tsplFonts[input.Dpi]
+	for _, line := range layoutNativeTextLines(input, printImg, layout) {
+		font, multiplier, ok := selectResidentFont(fonts, tsplMaxMultiplier, line, input.Dpi)
+		if !ok {
+			writeTSPLGraphic(tspl, printImg, textBand(line, input.Dpi, layout.width))
+			continue
+		}
+
+		pos := residentTextPosition(line, fonts[font], multiplier)
+		fmt.Fprintf(tspl, "TEXT %d,%d,\"%d\",0,%d,%d,%s\r\n", pos.X, pos.Y, font+1, multiplier, multiplier, quoteTSPL(line.Text))
+	}
+}
+
+// writeTSPLGraphic writes the area of img as a BITMAP command in overwrite mode
+func writeTSPLGraphic(tspl *strings.Builder, img *image.RGBA, area image.Rectangle) {
+	area = area.Intersect(img.Bounds())
+	if area.Empty() {
+		return
+	}
+
+	rowBytes, data := packMonochrome(img, area)
+	fmt.Fprintf(tspl, "BITMAP %d,%d,%d,%d,0,", area.Min.X, area.Min.Y, rowBytes, area.Dy())
+	tspl.Write(data)
+	tspl.WriteString("\r\n")
+}
+
+// quoteTSPL quotes a string parameter, escaping double quotes as \["]
+func quoteTSPL(data string) string {
+	return `"` + strings.ReplaceAll(data, `"`, `\["]`) + `"`
+}
@@ -0,0 +1,93 @@
+package barcode
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateBarcode_TSPL verifies supported barcodes and text use native commands
+func TestGenerateBarcode_TSPL(t *testing.T) {
+	output, err := GenerateBarcode(BarcodeInput{
+		BarcodeData:   "LOC-A1-B2-C3",
+		BarcodeType:   BarcodeTypeCode128,
+		Width:         50,
+		Height:        25,
+		Dpi:           203,
+		OutputFormats: []OutputFormat{OutputFormatTSPL},
+		TextLines:     []TextLine{{Text: "LOC-A1-B2-C3", Position: TextPositionBelow, Size: TextSizeMedium}},
+	})
+	require.NoError(t, err)
+
+	lines := strings.Split(output.TSPL, "\r\n")
+	require.Len(t, lines, 7)
+	assert.Equal(t, []string{"SIZE 50 mm,25 mm", "GAP 3 mm,0 mm", "CLS"}, lines[:3])
+	assert.Regexp(t, `^BARCODE \d+,\d+,"128",\d+,0,0,2,4,"LOC-A1-B2-C3"$`, lines[3])
+	assert.Regexp(t, `^TEXT \d+,\d+,"[1-5]",0,\d+,\d+,"LOC-A1-B2-C3"$`, lines[4])
+	assert.Equal(t, []string{"PRINT 1", ""}, lines[5:])
+	assert.Empty(t, output.EPL)
+}
+
+// TestGenerateBarcode_TSPLBarcodes verifies the barcode selection for each native type
+func TestGenerateBarcode_TSPLBarcodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    BarcodeInput
+		expected string
+	}{
+		{"ITF", BarcodeInput{BarcodeData: "12345678", BarcodeType: BarcodeTypeITF}, `,"25",`},
+		{"ISBN", BarcodeInput{BarcodeData: "978-0-306-40615-7", BarcodeType: BarcodeTypeISBN}, `,"EAN13",`},
+		{"QR", BarcodeInput{BarcodeData: "https://example.com", BarcodeType: BarcodeTypeQR}, `,M,`},
+		{"QRLevelH", BarcodeInput{BarcodeData: "https://example.com", BarcodeType: BarcodeTypeQR, QR: QROptions{ErrorCorrection: QRErrorCorrectionH}}, `,H,`},
+		{"QRMinVersion", BarcodeInput{BarcodeData: "https://example.com", BarcodeType: BarcodeTypeQR, QR: QROptions{MinVersion: 10}}, "BITMAP"},
+		{"Code128SetC", BarcodeInput{BarcodeData: "123456", BarcodeType: BarcodeTypeCode128, Code128: Code128Options{CodeSet: Code128CodeSetC}}, "BITMAP"},
+		{"ISBNAddOn", BarcodeInput{BarcodeData: "9780306406157", BarcodeType: BarcodeTypeISBN, ISBN: ISBNOptions{AddOn: "51299"}}, "BITMAP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input.Width, tt.input.Height, tt.input.Dpi = 50, 30, 203
+			tt.input.OutputFormats = []OutputFormat{OutputFormatTSPL}
+			output, err := GenerateBarcode(tt.input)
+			require.NoError(t, err)
+			assert.Contains(t, strings.Split(output.TSPL, "\r\n")[3], tt.expected)
+		})
+	}
+}
+
+// TestGenerateBarcode_TSPLMirror verifies mirrored labels are sent as a single graphic
+func TestGenerateBarcode_TSPLMirror(t *testing.T) {
+	output, err := GenerateBarcode(BarcodeInput{
+		BarcodeData:   "LOC-A1-B2-C3",
+		BarcodeType:   BarcodeTypeCode128,
+		Width:         50,
+		Height:        25,
+		Dpi:           203,
+		OutputFormats: []OutputFormat{OutputFormatTSPL, OutputFormatEPL},
+		Mirror:        true,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, output.TSPL, "\r\nBITMAP 0,0,50,199,0,")
+	assert.NotContains(t, output.TSPL, "BARCODE")
+	assert.Contains(t, output.EPL, "\nGW0,0,50,199,")
+}
+
+// TestWriteTSPLGraphic verifies dark pixels are cleared bits and rows are padded white
+func TestWriteTSPLGraphic(t *testing.T) {
+	img := createBlankLabel(10, 2)
+	img.Set(0, 0, color.Black)
+	img.Set(9, 1, color.Black)
+
+	var tspl strings.Builder
+	writeTSPLGraphic(&tspl, img, image.Rect(0, 0, 20, 2))
+	assert.Equal(t, "BITMAP 0,0,2,2,0,\x7f\xff\xff\xbf\r\n", tspl.String())
+}
+
+// TestQuoteTSPL verifies double quotes use the TSPL escape
+func TestQuoteTSPL(t *testing.T) {
+	assert.Equal(t, `"A\["]B\C"`, quoteTSPL(`A"B\C`))
+}
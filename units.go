@@ -0,0 +1,68 @@
+package barcode
+
+// Unit is the unit of the label size, margins and positions of a BarcodeInput
+type Unit string
+
+const (
+	UnitMM   Unit = "MM"   // Millimeters (the default)
+	UnitInch Unit = "INCH" // Inches
+	UnitDots Unit = "DOTS" // Printer dots at Dpi
+)
+
+// applyUnits converts the lengths of an input given in inches or dots to
+// millimeters, so the rest of the pipeline only deals with millimeters.
+// XDimensionMils, BarWidthReduction and FontScaling keep their own units.
+// Slices are copied rather than changed in place, so the caller's input is
+// left as it was.
+func applyUnits(input BarcodeInput) (BarcodeInput, error) {
+	var mmPerUnit float64
+	switch input.Units {
+	case "", UnitMM:
+		return input, nil
+	case UnitInch:
+		mmPerUnit = 25.4
+	case UnitDots:
+		// An invalid DPI is reported by validateInput
+		mmPerUnit = 25.4 / float64(max(input.Dpi, 1))
+	default:
+		return BarcodeInput{}, errorf(ErrInvalidInput, "invalid units: %q. Supported units are %s, %s and %s", input.Units, UnitMM, UnitInch, UnitDots)
+	}
+
+	toMM := func(lengths ...*float64) {
+		for _, length := range lengths {
+			*length *= mmPerUnit
+		}
+	}
+	toMM(&input.Width, &input.Height, &input.Margins.MM, &input.ZPL.HomeXMM, &input.ZPL.HomeYMM)
+	placement := &input.BarcodePlacement
+	toMM(&placement.XMM, &placement.YMM, &placement.WidthMM, &placement.HeightMM)
+
+	input.TextLines = append([]TextLine(nil), input.TextLines...)
+	for i := range input.TextLines {
+		toMM(&input.TextLines[i].XOffsetMM, &input.TextLines[i].XMM, &input.TextLines[i].YMM)
+	}
+	input.Paragraphs = append([]TextParagraph(nil), input.Paragraphs...)
+	for i := range input.Paragraphs {
+		toMM(&input.Paragraphs[i].MaxWidthMM, &input.Paragraphs[i].MaxHeightMM)
+	}
+	input.Barcodes = append([]LabelBarcode(nil), input.Barcodes...)
+	for i := range input.Barcodes {
+		toMM(&input.Barcodes[i].XMM, &input.Barcodes[i].YMM, &input.Barcodes[i].WidthMM, &input.Barcodes[i].HeightMM)
+	}
+	input.Graphics = append([]LabelGraphic(nil), input.Graphics...)
+	for i := range input.Graphics {
+		toMM(&input.Graphics[i].XMM, &input.Graphics[i].YMM, &input.Graphics[i].WidthMM, &input.Graphics[i].HeightMM)
+	}
+	input.Shapes = append([]LabelShape(nil), input.Shapes...)
+	for i := range input.Shapes {
+		toMM(&input.Shapes[i].XMM, &input.Shapes[i].YMM, &input.Shapes[i].WidthMM, &input.Shapes[i].HeightMM, &input.Shapes[i].ThicknessMM)
+	}
+	input.ReverseRegions = append([]Placement(nil), input.ReverseRegions...)
+	for i := range input.ReverseRegions {
+		region := &input.ReverseRegions[i]
+		toMM(&region.XMM, &region.YMM, &region.WidthMM, &region.HeightMM)
+	}
+
+	input.Units = UnitMM
+	return input, nil
+}
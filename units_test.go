@@ -0,0 +1,73 @@
+package barcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestApplyUnits verifies inch and dot lengths are converted to millimeters
+// without changing the caller's slices
+func TestApplyUnits(t *testing.T) {
+	shapes := []LabelShape{{Type: ShapeBox, XMM: 0.5, YMM: 0.25, WidthMM: 1, HeightMM: 2, ThicknessMM: 0.02}}
+	input, err := applyUnits(BarcodeInput{Units: UnitInch, Width: 4, Height: 6, Margins: Margins{MM: 0.1}, Shapes: shapes})
+	require.NoError(t, err)
+	assert.Equal(t, UnitMM, input.Units)
+	assert.InDelta(t, 101.6, input.Width, 1e-9)
+	assert.InDelta(t, 152.4, input.Height, 1e-9)
+	assert.InDelta(t, 2.54, input.Margins.MM, 1e-9)
+	assert.InDelta(t, 12.7, input.Shapes[0].XMM, 1e-9)
+	assert.InDelta(t, 0.508, input.Shapes[0].ThicknessMM, 1e-9)
+	assert.Equal(t, 0.5, shapes[0].XMM, "the caller's shapes are unchanged")
+
+	input, err = applyUnits(BarcodeInput{Units: UnitDots, Dpi: 203, Width: 812, Height: 406, TextLines: []TextLine{{Text: "A", XMM: 20}}})
+	require.NoError(t, err)
+	assert.Equal(t, 812, mmToPixels(input.Width, 203))
+	assert.Equal(t, 406, mmToPixels(input.Height, 203))
+	assert.Equal(t, 20, mmToPixels(input.TextLines[0].XMM, 203))
+
+	input, err = applyUnits(BarcodeInput{Width: 50, Height: 25})
+	require.NoError(t, err)
+	assert.Equal(t, BarcodeInput{Width: 50, Height: 25}, input)
+
+	_, err = applyUnits(BarcodeInput{Units: "CM"})
+	assert.ErrorContains(t, err, `invalid units: "CM". Supported units are MM, INCH and DOTS`)
+}
+
+// TestGenerateBarcode_Units verifies labels sized in inches and dots match
+// the same label in millimeters, including with a preset
+func TestGenerateBarcode_Units(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:      "LOC-A1-B2-C3",
+		BarcodeType:      BarcodeTypeCode128,
+		Width:            101.6,
+		Height:           50.8,
+		Dpi:              203,
+		BarcodePlacement: Placement{XMM: 12.7, YMM: 12.7, WidthMM: 76.2, HeightMM: 25.4},
+		OutputFormats:    []OutputFormat{OutputFormatZPL},
+	}
+	want, err := GenerateBarcode(input)
+	require.NoError(t, err)
+
+	inches := input
+	inches.Units, inches.Width, inches.Height = UnitInch, 4, 2
+	inches.BarcodePlacement = Placement{XMM: 0.5, YMM: 0.5, WidthMM: 3, HeightMM: 1}
+	output, err := GenerateBarcode(inches)
+	require.NoError(t, err)
+	assert.Equal(t, want.ZPL, output.ZPL)
+
+	dots := input
+	dots.Units, dots.Width, dots.Height = UnitDots, 812, 406
+	dots.BarcodePlacement = Placement{XMM: 101, YMM: 101, WidthMM: 609, HeightMM: 203}
+	output, err = GenerateBarcode(dots)
+	require.NoError(t, err)
+	assert.Equal(t, want.ZPL, output.ZPL)
+
+	preset := input
+	preset.Units, preset.Width, preset.Height, preset.LabelPreset = UnitInch, 0, 0, LabelPreset4x2
+	preset.BarcodePlacement = inches.BarcodePlacement
+	img, _, err := GenerateBarcodeImage(preset)
+	require.NoError(t, err)
+	assert.Equal(t, 812, img.Bounds().Dx(), "presets are in millimeters whatever the units")
+}
@@ -0,0 +1,173 @@
+package barcode
+
+import (
+	"fmt"
+	"slices"
+)
+
+// FieldError is a problem with one field of a BarcodeInput. Its message is
+// that of the problem, which usually names the field already.
+type FieldError struct {
+	Field string // BarcodeInput field, such as "Dpi", "BarcodeData" or "TextLines"
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError lists every problem ValidateInput found, in the order of
+// the checks. It matches ErrInvalidInput and the kinds of its problems.
+type ValidationError struct {
+	Fields []*FieldError
+}
+
+// Error names the first problem and how many others there are
+func (e *ValidationError) Error() string {
+	if len(e.Fields) == 1 {
+		return e.Fields[0].Error()
+	}
+	return fmt.Sprintf("%v (and %d more problems)", e.Fields[0], len(e.Fields)-1)
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := []error{ErrInvalidInput}
+	for _, field := range e.Fields {
+		errs = append(errs, field)
+	}
+	return errs
+}
+
+// inputCheck validates one field of an input. Checks that read other fields
+// need them to be valid, and are skipped when they are not so one mistake is
+// not reported several times.
+type inputCheck struct {
+	field string
+	needs []string
+	check func(BarcodeInput) error
+}
+
+// inputChecks are the checks of validateInput and ValidateInput, in order
+var inputChecks = []inputCheck{
+	{field: "Dpi", check: func(input BarcodeInput) error {
+		if input.AllowNonStandardDPI {
+			return validateNonStandardDPI(input.Dpi)
+		}
+		return validateDPI(input.Dpi)
+	}},
+	{field: "PreviewDpi", check: func(input BarcodeInput) error { return validatePreviewDPI(input.PreviewDpi) }},
+	{field: "Margins", check: func(input BarcodeInput) error { return validateMargins(input.Margins) }},
+	{field: "XDimensionMils", needs: []string{"BarcodeType"}, check: validateXDimension},
+	{field: "BarWidthReduction", check: func(input BarcodeInput) error { return validateBarWidthReduction(input.BarWidthReduction) }},
+	{field: "Width", needs: []string{"Dpi", "Margins"}, check: func(input BarcodeInput) error {
+		return validateLabelWidth(input.Width, input.Dpi, labelMargin(input))
+	}},
+	{field: "Height", needs: []string{"Dpi", "Margins"}, check: func(input BarcodeInput) error {
+		return validateLabelHeight(input.Height, input.Dpi, labelMargin(input))
+	}},
+	{field: "BarcodeType", check: func(input BarcodeInput) error { return validateBarcodeType(input.BarcodeType) }},
+	{field: "BarcodeRotation", check: func(input BarcodeInput) error { return validateBarcodeRotation(input.BarcodeRotation) }},
+	{field: "Rotation", check: func(input BarcodeInput) error { return validateLabelRotation(input.Rotation) }},
+	{field: "ForegroundColor", check: func(input BarcodeInput) error { return validateLabelColor("foreground", input.ForegroundColor) }},
+	{field: "BackgroundColor", check: func(input BarcodeInput) error { return validateLabelColor("background", input.BackgroundColor) }},
+	{field: "OutputFormats", check: func(input BarcodeInput) error { return validateOutputFormats(input.OutputFormats) }},
+	{field: "BarcodeData", needs: []string{"BarcodeType"}, check: func(input BarcodeInput) error {
+		return withKind(ErrInvalidData, validateBarcodeData(input))
+	}},
+	{field: "DataBar", check: func(input BarcodeInput) error { return validateDataBarOptions(input.DataBar) }},
+	{field: "QR", check: func(input BarcodeInput) error { return validateQROptions(input.QR) }},
+	{field: "TextBlocks", check: func(input BarcodeInput) error { return validateTextBlocks(input.TextBlocks) }},
+	{field: "TextLines", check: func(input BarcodeInput) error { return validateTextLines(input.TextLines) }},
+	{field: "Paragraphs", check: func(input BarcodeInput) error { return validateTextParagraphs(input.Paragraphs) }},
+	{field: "FontScaling", check: func(input BarcodeInput) error { return validateFontScaling(input.FontScaling) }},
+	{field: "HumanReadable", needs: []string{"BarcodeType"}, check: validateHumanReadable},
+	{field: "QR", needs: []string{"BarcodeType", "QR"}, check: validateQRLogo},
+	{field: "Barcodes", needs: []string{"Width", "Height"}, check: validateLabelBarcodes},
+	{field: "BarcodePlacement", needs: []string{"Width", "Height"}, check: validateBarcodePlacement},
+	{field: "TextLines", needs: []string{"Width", "Height"}, check: validateTextPlacements},
+	{field: "Shapes", needs: []string{"Width", "Height"}, check: validateLabelShapes},
+	{field: "Graphics", needs: []string{"Width", "Height"}, check: validateLabelGraphics},
+	{field: "ReverseRegions", needs: []string{"Width", "Height"}, check: validateReverseRegions},
+	{field: "RFID", check: func(input BarcodeInput) error { return validateRFIDOptions(input.RFID) }},
+	{field: "ZPL", check: func(input BarcodeInput) error { return validateZPLOptions(input.ZPL) }},
+}
+
+// checkInputFields runs the input checks and returns a *FieldError for each
+// problem, stopping at the first unless all are wanted
+func checkInputFields(input BarcodeInput, all bool) []*FieldError {
+	var problems []*FieldError
+	var failed []string
+	for _, check := range inputChecks {
+		if slices.ContainsFunc(check.needs, func(field string) bool { return slices.Contains(failed, field) }) {
+			continue
+		}
+		if err := check.check(input); err != nil {
+			problems = append(problems, &FieldError{Field: check.field, Err: err})
+			failed = append(failed, check.field)
+			if !all {
+				break
+			}
+		}
+	}
+	return problems
+}
+
+// validateInput checks that all input parameters are valid. Its errors are
+// a *FieldError that matches ErrInvalidInput and, where there is one, the
+// kind of problem.
+func validateInput(input BarcodeInput) error {
+	if problems := checkInputFields(input, false); len(problems) > 0 {
+		return withKind(ErrInvalidInput, problems[0])
+	}
+	return nil
+}
+
+// ValidateInput checks an input as GenerateBarcode would without rendering
+// it, e.g. to validate a user's submission and report each problem against
+// its field. Besides the checks of every field, the data is encoded to apply
+// the symbology's rules for its characters, length and check digits. It
+// returns nil or a *ValidationError listing every problem found, or the
+// error GenerateBarcode returns when a check or the encoder fails
+// unexpectedly.
+func ValidateInput(input BarcodeInput) error {
+	var err error
+	if input, err = applyUnits(input); err != nil {
+		return &ValidationError{Fields: []*FieldError{{Field: "Units", Err: err}}}
+	}
+	if input, err = applyLabelPreset(input); err != nil {
+		return &ValidationError{Fields: []*FieldError{{Field: "LabelPreset", Err: err}}}
+	}
+	if input.AutoHeight {
+		if err := validateAutoHeight(input); err != nil {
+			return &ValidationError{Fields: []*FieldError{{Field: "AutoHeight", Err: err}}}
+		}
+		input.Height = autoHeightMaxMM
+	}
+
+	problems, err := checkInputEncoding(input)
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Fields: problems}
+	}
+	return nil
+}
+
+// checkInputEncoding runs every input check and, when they pass, encodes the
+// data. A panic in either is returned as an error, as during generation.
+func checkInputEncoding(input BarcodeInput) (problems []*FieldError, err error) {
+	defer recoverToError(&err)
+
+	problems = checkInputFields(input, true)
+	if len(problems) == 0 {
+		if _, err := encodeBarcode(input); err != nil {
+			problems = append(problems, &FieldError{Field: "BarcodeData", Err: withKind(ErrInvalidData, err)})
+		}
+	}
+	return problems, nil
+}
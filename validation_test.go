@@ -0,0 +1,99 @@
+package barcode
+
+import (
+	"errors"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidateInput_Valid verifies a valid input passes without rendering
+func TestValidateInput_Valid(t *testing.T) {
+	assert.NoError(t, ValidateInput(BarcodeInput{BarcodeData: "LOC-A1-B2-C3", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203}))
+	assert.NoError(t, ValidateInput(BarcodeInput{BarcodeData: "978-0-306-40615-7", BarcodeType: BarcodeTypeISBN, LabelPreset: LabelPreset4x6, Dpi: 203}))
+}
+
+// TestValidateInput_FieldErrors verifies every problem is reported against
+// its field with the message generation would return
+func TestValidateInput_FieldErrors(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:     "978-0-306-40615-8",
+		BarcodeType:     BarcodeTypeISBN,
+		Width:           0,
+		Height:          25,
+		Dpi:             150,
+		ForegroundColor: "blue",
+	}
+	err := ValidateInput(input)
+
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	var fields []string
+	for _, field := range validationErr.Fields {
+		fields = append(fields, field.Field)
+	}
+	assert.Equal(t, []string{"Dpi", "ForegroundColor", "BarcodeData"}, fields)
+	assert.ErrorContains(t, validationErr.Fields[2], "invalid ISBN")
+
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.ErrorIs(t, err, ErrInvalidDPI)
+	assert.ErrorIs(t, err, ErrInvalidData)
+	assert.EqualError(t, err, validationErr.Fields[0].Error()+" (and 2 more problems)")
+
+	_, generateErr := GenerateBarcode(input)
+	assert.EqualError(t, generateErr, validationErr.Fields[0].Error())
+}
+
+// TestValidateInput_Dependencies verifies checks that need an invalid field
+// are skipped rather than reporting the same mistake again
+func TestValidateInput_Dependencies(t *testing.T) {
+	err := ValidateInput(BarcodeInput{BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeCode128, Width: 50, Height: -1, Dpi: 203,
+		BarcodePlacement: Placement{XMM: 5, YMM: 5, WidthMM: 40, HeightMM: 10}})
+
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Len(t, validationErr.Fields, 1)
+	assert.Equal(t, "Height", validationErr.Fields[0].Field)
+	assert.ErrorIs(t, err, ErrInvalidDimensions)
+}
+
+// TestValidateInput_Encoding verifies data the field checks accept but the
+// symbology can not encode is reported against BarcodeData
+func TestValidateInput_Encoding(t *testing.T) {
+	err := ValidateInput(BarcodeInput{BarcodeData: strings.Repeat("a", 3000), BarcodeType: BarcodeTypeQR, Width: 100, Height: 100, Dpi: 203,
+		QR: QROptions{ErrorCorrection: QRErrorCorrectionL}})
+
+	var fieldErr *FieldError
+	require.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "BarcodeData", fieldErr.Field)
+	assert.ErrorIs(t, err, ErrInvalidData)
+	var tooLong *DataTooLongError
+	require.True(t, errors.As(err, &tooLong))
+	assert.Equal(t, 2953, tooLong.Max)
+}
+
+// TestValidateInput_Panic verifies an input that crashes a check fails with
+// the same error as generation instead of crashing the caller
+func TestValidateInput_Panic(t *testing.T) {
+	input := BarcodeInput{BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeQR, Width: 50, Height: 50, Dpi: 203,
+		QR: QROptions{Logo: (*image.RGBA)(nil)}}
+
+	err := ValidateInput(input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "barcode generation failed unexpectedly")
+	_, generateErr := GenerateBarcode(input)
+	assert.EqualError(t, err, generateErr.Error())
+}
+
+// TestValidateInput_Preset verifies an unknown preset is reported before the
+// fields it would have set
+func TestValidateInput_Preset(t *testing.T) {
+	err := ValidateInput(BarcodeInput{BarcodeData: "LOC-A1", BarcodeType: BarcodeTypeCode128, LabelPreset: "9x9", Dpi: 203})
+
+	var fieldErr *FieldError
+	require.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "LabelPreset", fieldErr.Field)
+}
@@ -0,0 +1,68 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/boombuler/barcode"
+)
+
+// milsPerInch is the number of mils (thousandths of an inch) in an inch
+const milsPerInch = 1000.0
+
+// xDimensionDots returns the module width in printer dots for an X-dimension
+// in mils, rounded to the nearest whole dot and at least one
+func xDimensionDots(mils float64, dpi int) int {
+	return max(1, int(math.Round(mils*float64(dpi)/milsPerInch)))
+}
+
+// fitXDimension returns the barcode size within the available size, before
+// BarcodeRotation. Without XDimensionMils the barcode fills the size, less
+// its quiet zone when Margins.QuietZone is set. With it, the symbol is its
+// module count times the X-dimension, and a symbol that does not fit is an
+// error rather than being scaled down.
+func fitXDimension(input BarcodeInput, bc barcode.Barcode, available image.Point) (image.Point, error) {
+	if input.XDimensionMils == 0 {
+		return fitQuietZone(input, bc, available), nil
+	}
+
+	dots := xDimensionDots(input.XDimensionMils, input.Dpi)
+	modules := bc.Bounds().Dx()
+	if isHumanReadableEAN(input) {
+		modules += eanLeadingDigitWidth
+	}
+	quietZone := 0
+	if input.Margins.QuietZone {
+		quietZone = quietZoneModules[input.BarcodeType]
+	}
+
+	// QR codes are square, so their width is limited by the smaller side
+	room, size := available.X, image.Pt(modules*dots, available.Y)
+	if input.BarcodeType == BarcodeTypeQR {
+		room, size.Y = min(available.X, available.Y), size.X
+	}
+	if needed := (modules + quietZone*2) * dots; needed > room {
+		mm := func(pixels int) float64 { return float64(pixels) * 25.4 / float64(input.Dpi) }
+		return image.Point{}, errorf(ErrLabelTooSmall, "barcode does not fit at an X-dimension of %gmil: the %d-module symbol is %.1fmm wide with %.1fmm available. Use a smaller X-dimension or a larger label",
+			input.XDimensionMils, modules+quietZone*2, mm(needed), mm(room))
+	}
+	return size, nil
+}
+
+// validateXDimension ensures the X-dimension is not negative and is only set
+// for symbols scaled in whole modules. IMb and Swiss QR codes are printed at
+// their specified sizes, and imported images have no known module width.
+func validateXDimension(input BarcodeInput) error {
+	if input.XDimensionMils < 0 {
+		return fmt.Errorf("invalid X-dimension: %gmil. X-dimension must not be negative", input.XDimensionMils)
+	}
+	if input.XDimensionMils == 0 {
+		return nil
+	}
+	switch input.BarcodeType {
+	case BarcodeTypeIMb, BarcodeTypeSwissQR, BarcodeTypeImage:
+		return fmt.Errorf("invalid X-dimension for %s: %s symbols are not sized by module width", input.BarcodeType, input.BarcodeType)
+	}
+	return nil
+}
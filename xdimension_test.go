@@ -0,0 +1,87 @@
+package barcode
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestXDimensionDots verifies mils are rounded to whole printer dots
+func TestXDimensionDots(t *testing.T) {
+	assert.Equal(t, 2, xDimensionDots(10, 203))
+	assert.Equal(t, 3, xDimensionDots(10, 300))
+	assert.Equal(t, 6, xDimensionDots(10, 600))
+	assert.Equal(t, 3, xDimensionDots(13, 203))
+	assert.Equal(t, 1, xDimensionDots(1, 203))
+}
+
+// TestFitXDimension verifies the symbol is sized from its modules instead of
+// the space available, and symbols that do not fit are rejected
+func TestFitXDimension(t *testing.T) {
+	input := BarcodeInput{BarcodeData: "LOC-A1-B2-C3", BarcodeType: BarcodeTypeCode128, Dpi: 300, XDimensionMils: 10}
+	bc, err := encodeBarcode(input)
+	require.NoError(t, err)
+	modules := bc.Bounds().Dx()
+
+	size, err := fitXDimension(input, bc, image.Pt(1000, 150))
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(modules*3, 150), size)
+
+	_, err = fitXDimension(input, bc, image.Pt(modules*3-1, 150))
+	assert.ErrorContains(t, err, "barcode does not fit at an X-dimension of 10mil")
+
+	input.Margins.QuietZone = true
+	_, err = fitXDimension(input, bc, image.Pt(modules*3+10, 150))
+	assert.ErrorContains(t, err, "symbol is")
+
+	qrInput := BarcodeInput{BarcodeData: "https://example.com", BarcodeType: BarcodeTypeQR, Dpi: 203, XDimensionMils: 20}
+	qrCode, err := encodeBarcode(qrInput)
+	require.NoError(t, err)
+	qrModules := qrCode.Bounds().Dx()
+	size, err = fitXDimension(qrInput, qrCode, image.Pt(500, qrModules*4))
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(qrModules*4, qrModules*4), size)
+
+	_, err = fitXDimension(qrInput, qrCode, image.Pt(500, qrModules*4-1))
+	assert.Error(t, err)
+}
+
+// TestGenerateBarcode_XDimension verifies the rendered Code128 keeps the
+// requested module width on a label with room for a wider symbol
+func TestGenerateBarcode_XDimension(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData:    "LOC-A1-B2-C3",
+		BarcodeType:    BarcodeTypeCode128,
+		Width:          100,
+		Height:         50,
+		Dpi:            203,
+		XDimensionMils: 10,
+		OutputFormats:  []OutputFormat{OutputFormatPNG, OutputFormatZPL},
+		ZPL:            ZPLOptions{NativeCommands: true},
+	}
+	bc, err := encodeBarcode(input)
+	require.NoError(t, err)
+
+	_, layout, err := GenerateBarcodeImage(input)
+	require.NoError(t, err)
+	assert.Equal(t, bc.Bounds().Dx()*2, layout.BarcodeRect.Dx())
+
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Contains(t, output.ZPL, "^BY2,")
+
+	input.Width = 20
+	_, err = GenerateBarcode(input)
+	assert.ErrorContains(t, err, "barcode does not fit at an X-dimension of 10mil")
+}
+
+// TestValidateXDimension verifies negative values and fixed-size types are
+// rejected
+func TestValidateXDimension(t *testing.T) {
+	assert.NoError(t, validateXDimension(BarcodeInput{BarcodeType: BarcodeTypeITF, XDimensionMils: 15}))
+	assert.NoError(t, validateXDimension(BarcodeInput{BarcodeType: BarcodeTypeIMb}))
+	assert.ErrorContains(t, validateXDimension(BarcodeInput{BarcodeType: BarcodeTypeCode128, XDimensionMils: -1}), "must not be negative")
+	assert.ErrorContains(t, validateXDimension(BarcodeInput{BarcodeType: BarcodeTypeIMb, XDimensionMils: 10}), "not sized by module width")
+}
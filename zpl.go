@@ -0,0 +1,489 @@
+package barcode
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/boombuler/barcode"
+	"github.com/golang/freetype/truetype"
+)
+
+// ZPL limits
+const (
+	zplMaxModuleWidth   = 10       // Largest ^BY module width in dots
+	zplMaxMagnification = 10       // Largest ^BQ magnification
+	zplMaxDarkness      = 30       // Largest ^MD darkness change in either direction
+	zplMaxPrintSpeed    = 14       // Fastest ^PR print rate in inches per second
+	zplMaxQuantity      = 99999999 // Largest ^PQ quantity
+	zplMaxLatin1        = 0xFF     // Last character resident font 0 covers with ^CI28
+	zplMaxTearOffset    = 120      // Largest ~TA rest position adjustment in dot rows
+)
+
+// ZPLPrintMode selects how the printer handles media after printing a label
+type ZPLPrintMode string
+
+const (
+	ZPLPrintModeTearOff ZPLPrintMode = "TEAR_OFF" // Advance to the tear bar
+	ZPLPrintModePeelOff ZPLPrintMode = "PEEL_OFF" // Peel the label from the liner
+	ZPLPrintModeCutter  ZPLPrintMode = "CUTTER"   // Cut after each label
+	ZPLPrintModePresent ZPLPrintMode = "PRESENT"  // Kiosk printers: cut and present the label
+	ZPLPrintModeRewind  ZPLPrintMode = "REWIND"   // Rewind printed labels onto the rewinder
+)
+
+// zplPrintModes maps print modes to their ^MM mode letters
+var zplPrintModes = map[ZPLPrintMode]string{
+	ZPLPrintModeTearOff: "T",
+	ZPLPrintModePeelOff: "P",
+	ZPLPrintModeCutter:  "C",
+	ZPLPrintModePresent: "K",
+	ZPLPrintModeRewind:  "R",
+}
+
+// zplFontPathPattern matches the printer paths of downloaded TrueType fonts
+var zplFontPathPattern = regexp.MustCompile(`^[RBEA]:[A-Z0-9_-]{1,16}\.TTF$`)
+
+// ZPLOptions configures ZPL output
+type ZPLOptions struct {
+	// Optional: send barcodes and text as native ^BC, ^B2, ^BE, ^BQ and ^A
+	// commands, so the printer draws crisp bars at its own dot pitch and the
+	// job is much smaller. Symbols ZPL cannot print identically, and text
+	// other than printable ASCII, are still sent as graphics.
+	NativeCommands bool
+
+	Darkness       int     // Optional: ^MD darkness change relative to the printer setting (-30 to 30)
+	PrintSpeed     int     // Optional: ^PR print rate in inches per second (1-14, defaults to the printer setting)
+	Quantity       int     // Optional: ^PQ number of labels to print (defaults to 1)
+	HomeXMM        float64 // Optional: ^LH label home offset from the left edge in millimeters
+	HomeYMM        float64 // Optional: ^LH label home offset from the top edge in millimeters
+	SetPrintWidth  bool    // Optional: ^PW print width set to the label width
+	SetLabelLength bool    // Optional: ^LL label length set to the label height
+
+	PrintMode     ZPLPrintMode // Optional: ^MM media handling after each label (defaults to the printer setting)
+	TearOffOffset int          // Optional: ~TA rest position adjustment in dot rows (-120 to 120)
+
+	// Optional: send graphics as zlib-compressed :Z64: data with a CRC
+	// instead of ASCII hex, typically several times smaller for high DPI
+	// labels and much faster over serial connections
+	CompressZ64 bool
+
+	// Optional: with NativeCommands, reference label graphics stored in the
+	// printer with ZPLGraphicDownload by name (^XG) instead of sending them
+	// with every label
+	StoredGraphics bool
+
+	// Optional: printer path of a TrueType font stored with ZPLFontDownload,
+	// e.g. "E:NOTOSANS.TTF", used for all native text. Resident font 0 only
+	// covers Latin-1, so text in other scripts such as CJK needs a downloaded
+	// font and is otherwise sent as a graphic.
+	Font string
+
+	// Optional: how grey pixels such as anti-aliased text edges become dots
+	// in graphics (defaults to THRESHOLD), and the grey level from 1 to 255
+	// below which pixels print in THRESHOLD mode (defaults to 128)
+	RasterMode      ZPLRasterMode
+	RasterThreshold int
+}
+
+// validateZPLOptions ensures the job settings are within the ranges ZPL accepts
+func validateZPLOptions(options ZPLOptions) error {
+	if options.Darkness < -zplMaxDarkness || options.Darkness > zplMaxDarkness {
+		return errorf(ErrInvalidInput, "invalid ZPL darkness: %d. Must be between %d and %d", options.Darkness, -zplMaxDarkness, zplMaxDarkness)
+	}
+	if options.PrintSpeed < 0 || options.PrintSpeed > zplMaxPrintSpeed {
+		return errorf(ErrInvalidInput, "invalid ZPL print speed: %d. Must be between 1 and %d inches per second", options.PrintSpeed, zplMaxPrintSpeed)
+	}
+	if options.Quantity < 0 || options.Quantity > zplMaxQuantity {
+		return errorf(ErrInvalidInput, "invalid ZPL quantity: %d. Must be between 1 and %d", options.Quantity, zplMaxQuantity)
+	}
+	if options.HomeXMM < 0 || options.HomeYMM < 0 {
+		return errorf(ErrInvalidInput, "invalid ZPL label home: %gx%gmm. Offsets must not be negative", options.HomeXMM, options.HomeYMM)
+	}
+	if _, ok := zplPrintModes[options.PrintMode]; !ok && options.PrintMode != "" {
+		return errorf(ErrInvalidInput, "invalid ZPL print mode: %q. Supported modes are %s, %s, %s, %s and %s", options.PrintMode,
+			ZPLPrintModeTearOff, ZPLPrintModePeelOff, ZPLPrintModeCutter, ZPLPrintModePresent, ZPLPrintModeRewind)
+	}
+	if options.TearOffOffset < -zplMaxTearOffset || options.TearOffOffset > zplMaxTearOffset {
+		return errorf(ErrInvalidInput, "invalid ZPL tear-off offset: %d. Must be between %d and %d dot rows", options.TearOffOffset, -zplMaxTearOffset, zplMaxTearOffset)
+	}
+	if options.Font != "" && !zplFontPathPattern.MatchString(options.Font) {
+		return errorf(ErrInvalidInput, "invalid ZPL font: %q. Expected a device, a name of up to 16 characters and .TTF, e.g. E:NOTOSANS.TTF", options.Font)
+	}
+	return validateZPLRasterOptions(options)
+}
+
+// generateZPL converts the label to ZPL for Zebra printers, with the job
+// settings in the label format. Unless native commands are requested, the
+// whole print image is sent as one compressed graphic. Otherwise the barcode
+// and text lines are sent as native commands laid out like the print image,
+// with ^GF graphics cropped from the print image for everything else. When
+// native is false, e.g. for mirrored or post-processed labels, the print
+// image is always sent as one graphic. Graphics are converted to black and
+// white with the ZPL.RasterMode.
+func generateZPL(input BarcodeInput, bc barcode.Barcode, printImg *image.RGBA, native bool) (string, error) {
+	native = native && input.ZPL.NativeCommands
+	printImg, err := zplRasterImage(printImg, input.ZPL)
+	if err != nil {
+		return "", err
+	}
+	if !native && !input.ZPL.CompressZ64 {
+		return addZPLJobSettings(imageToZPL(printImg), input, printImg.Bounds()), nil
+	}
+
+	var zpl strings.Builder
+	zpl.WriteString("^XA\n")
+	zpl.WriteString(zplSetupCommands(input, printImg.Bounds()))
+	if native {
+		printImg = zplReverseSource(input, printImg)
+		layout, err := layoutLabel(input, bc, input.Dpi)
+		if err != nil {
+			return "", err
+		}
+		if command, ok := zplBarcodeCommand(input, bc, layout); ok {
+			zpl.WriteString(command)
+		} else {
+			writeZPLGraphic(&zpl, printImg, layout.barcodeRect, input.ZPL.CompressZ64)
+		}
+		writeZPLTextLines(&zpl, input, printImg, layout)
+		writeZPLLabelBarcodes(&zpl, input, printImg)
+		writeZPLLabelShapes(&zpl, input, printImg)
+		writeZPLLabelGraphics(&zpl, input, printImg)
+		writeZPLReverseRegions(&zpl, input)
+	} else {
+		writeZPLGraphic(&zpl, printImg, printImg.Bounds(), input.ZPL.CompressZ64)
+	}
+
+	zpl.WriteString(zplQuantityCommand(input.ZPL))
+	zpl.WriteString("^XZ\n")
+	return zpl.String(), nil
+}
+
+// addZPLJobSettings adds the setup commands after the ^XA that starts a
+// rasterized label format and the quantity before the ^XZ that ends it
+func addZPLJobSettings(zpl string, input BarcodeInput, bounds image.Rectangle) string {
+	setup, quantity := zplSetupCommands(input, bounds), zplQuantityCommand(input.ZPL)
+	start, end := strings.Index(zpl, "^XA"), strings.LastIndex(zpl, "^XZ")
+	if start < 0 || end < start || setup+quantity == "" {
+		return zpl
+	}
+	start += len("^XA")
+	return zpl[:start] + "\n" + setup + zpl[start:end] + quantity + zpl[end:]
+}
+
+// zplSetupCommands returns the requested darkness, print rate, media
+// handling, orientation, label home, print width and label length commands,
+// followed by the RFID encoding, one per line
+func zplSetupCommands(input BarcodeInput, bounds image.Rectangle) string {
+	options := input.ZPL
+	var setup strings.Builder
+	if options.Darkness != 0 {
+		fmt.Fprintf(&setup, "^MD%d\n", options.Darkness)
+	}
+	if options.PrintSpeed != 0 {
+		fmt.Fprintf(&setup, "^PR%d\n", options.PrintSpeed)
+	}
+	if options.PrintMode != "" {
+		fmt.Fprintf(&setup, "^MM%s\n", zplPrintModes[options.PrintMode])
+	}
+	if options.TearOffOffset != 0 {
+		fmt.Fprintf(&setup, "~TA%03d\n", options.TearOffOffset)
+	}
+	if input.UpsideDown {
+		setup.WriteString("^POI\n")
+	}
+	if options.HomeXMM != 0 || options.HomeYMM != 0 {
+		fmt.Fprintf(&setup, "^LH%d,%d\n", mmToPixels(options.HomeXMM, input.Dpi), mmToPixels(options.HomeYMM, input.Dpi))
+	}
+	if options.SetPrintWidth {
+		fmt.Fprintf(&setup, "^PW%d\n", bounds.Dx())
+	}
+	if options.SetLabelLength {
+		fmt.Fprintf(&setup, "^LL%d\n", bounds.Dy())
+	}
+	setup.WriteString(zplRFIDCommands(input.RFID))
+	return setup.String()
+}
+
+// zplQuantityCommand returns the ^PQ command when more than one label is requested
+func zplQuantityCommand(options ZPLOptions) string {
+	if options.Quantity <= 1 {
+		return ""
+	}
+	return fmt.Sprintf("^PQ%d\n", options.Quantity)
+}
+
+// zplBarcodeCommand returns the native barcode command for barcodes ZPL can
+// print identically
+func zplBarcodeCommand(input BarcodeInput, bc barcode.Barcode, layout labelLayout) (string, bool) {
+	field, data, ok := zplBarcodeField(input, bc, layout)
+	if !ok {
+		return "", false
+	}
+	return field + zplFieldData(data) + "\n", true
+}
+
+// zplBarcodeField returns the field origin and barcode commands, and the
+// field data, for barcodes ZPL can print identically: unrotated Code128 in
+// the automatic code set without function characters, ITF, ISBN or ISSN
+// without an add-on, and QR codes the printer encodes at the same version.
+// DataMatrix is not a supported barcode type, so ^BX is never used; imported
+// DataMatrix symbols are sent as graphics.
+func zplBarcodeField(input BarcodeInput, bc barcode.Barcode, layout labelLayout) (string, string, bool) {
+	if input.BarcodeRotation != 0 {
+		return "", "", false
+	}
+	origin, moduleWidth, ok := nativeModuleLayout(bc, layout)
+	if !ok {
+		return "", "", false
+	}
+
+	height := layout.barcodeRect.Dy()
+	var command, data string
+	ratio := 2
+	switch input.BarcodeType {
+	case BarcodeTypeCode128:
+		if input.Code128.CodeSet != Code128CodeSetAuto || !isPrintableASCII(input.BarcodeData) {
+			return "", "", false
+		}
+		command = fmt.Sprintf("^BCN,%d,N,N,N,A", height)
+		data = input.BarcodeData
+	case BarcodeTypeITF:
+		command = fmt.Sprintf("^B2N,%d,N,N,N", height)
+		data = bc.Content()
+		ratio = 3
+	case BarcodeTypeISBN, BarcodeTypeISSN:
+		if input.ISBN.AddOn != "" {
+			return "", "", false
+		}
+		command = fmt.Sprintf("^BEN,%d,N,N", height)
+		data = bc.Content()[:12] // The printer adds the check digit
+	case BarcodeTypeQR:
+		return zplQRField(input, origin, moduleWidth)
+	default:
+		return "", "", false
+	}
+	if moduleWidth > zplMaxModuleWidth {
+		return "", "", false
+	}
+
+	return fmt.Sprintf("^FO%d,%d^BY%d,%d,%d%s", origin.X, origin.Y, moduleWidth, ratio, height, command), data, true
+}
+
+// zplQRField returns the model 2 ^BQ field for QR codes in the automatic
+// mode and version, which the printer picks the same way as the encoder
+func zplQRField(input BarcodeInput, origin image.Point, magnification int) (string, string, bool) {
+	if input.BarcodeDataBytes != nil || input.QR.Mode != QRModeAuto || input.QR.MinVersion > 1 ||
+		magnification > zplMaxMagnification || !isPrintableASCII(input.BarcodeData) {
+		return "", "", false
+	}
+
+	level := input.QR.ErrorCorrection
+	if level == "" {
+		level = QRErrorCorrectionM
+	}
+	return fmt.Sprintf("^FO%d,%d^BQN,2,%d", origin.X, origin.Y, magnification), string(level) + "A," + input.BarcodeData, true
+}
+
+// writeZPLTextLines writes each text line in the scalable font at its
+// rendered size, centered in a field block across the label. Text beyond
+// ASCII switches the field data to UTF-8 with ^CI28. Lines the font cannot
+// print, lines in a registered font, right-to-left lines, which are ordered
+// and shaped during rendering, and rotated lines are sent as a graphic of the
+// line.
+func writeZPLTextLines(zpl *strings.Builder, input BarcodeInput, printImg *image.RGBA, layout labelLayout) {
+	utf8 := false
+	for _, line := range layoutNativeTextLines(input, printImg, layout) {
+		if !zplTextSupported(line.Text, input.ZPL) || line.FontName != "" || hasRightToLeft(line.Text) || isRotatedText(line.TextLine) {
+			writeZPLGraphic(zpl, printImg, textBand(line, input.Dpi, layout.width), input.ZPL.CompressZ64)
+			continue
+		}
+
+		if !utf8 && !isPrintableASCII(line.Text) {
+			zpl.WriteString("^CI28\n")
+			utf8 = true
+		}
+		fmt.Fprintf(zpl, "%s%s\n", zplTextField(line, input.Dpi, layout.width, input.ZPL), zplFieldData(line.Text))
+	}
+}
+
+// zplTextField returns the field origin, field block and font commands that
+// align a text line on the label at its rendered size, in the downloaded font
+// when one is set and otherwise in font 0
+func zplTextField(line nativeTextLine, dpi, labelWidth int, options ZPLOptions) string {
+	height := max(1, int(line.fontSize*float64(dpi)/72+0.5))
+	top := max(0, line.baseline-int(float64(height)*residentFontAscent))
+	font := fmt.Sprintf("^A0N,%d,%d", height, height)
+	if options.Font != "" {
+		font = fmt.Sprintf("^A@N,%d,%d,%s", height, height, options.Font)
+	}
+	left, width, justification := zplFieldBlock(line.anchor, labelWidth)
+	return fmt.Sprintf("^FO%d,%d^FB%d,1,0,%s,0%s", left, top, width, justification, font)
+}
+
+// zplFieldBlock returns the left edge, width and justification of a field
+// block that aligns text to the anchor: from the anchor to the label edge for
+// left and right alignment, and centered on the anchor otherwise
+func zplFieldBlock(anchor textAnchor, labelWidth int) (int, int, string) {
+	x := min(max(anchor.x, 0), labelWidth)
+	switch anchor.alignment {
+	case TextAlignLeft:
+		return x, labelWidth - x, "L"
+	case TextAlignRight:
+		return 0, x, "R"
+	default:
+		if x == labelWidth/2 {
+			return 0, labelWidth, "C"
+		}
+		half := min(x, labelWidth-x)
+		return x - half, half * 2, "C"
+	}
+}
+
+// zplTextSupported reports whether text can be printed natively: printable
+// characters within Latin-1 in font 0, or any printable characters in a
+// downloaded font
+func zplTextSupported(text string, options ZPLOptions) bool {
+	if text == "" {
+		return false
+	}
+	for _, r := range text {
+		if !unicode.IsPrint(r) || (r > zplMaxLatin1 && options.Font == "") {
+			return false
+		}
+	}
+	return true
+}
+
+// ZPLFontDownload returns the ~DY command that stores a TrueType font in the
+// printer's memory at path, e.g. "E:NOTOSANS.TTF" in flash. Send it once per
+// printer and set ZPL.Font to the same path to print text in the font.
+func ZPLFontDownload(path string, ttf []byte) (string, error) {
+	if !zplFontPathPattern.MatchString(path) {
+		return "", errorf(ErrInvalidInput, "invalid ZPL font: %q. Expected a device, a name of up to 16 characters and .TTF, e.g. E:NOTOSANS.TTF", path)
+	}
+	if _, err := truetype.Parse(ttf); err != nil {
+		return "", errorf(ErrInvalidInput, "invalid TrueType font: %w", err)
+	}
+	return fmt.Sprintf("~DY%s,A,T,%d,,%s\n", strings.TrimSuffix(path, ".TTF"), len(ttf), strings.ToUpper(hex.EncodeToString(ttf))), nil
+}
+
+// writeZPLLabelGraphics writes each label graphic as an ^XG recall of the
+// stored graphic when requested, and otherwise as a ^GF graphic of its area
+func writeZPLLabelGraphics(zpl *strings.Builder, input BarcodeInput, printImg *image.RGBA) {
+	for _, graphic := range input.Graphics {
+		img, err := rasterizeLabelGraphic(graphic, input.Dpi)
+		if err != nil {
+			continue // Validated with the input and already drawn in the print image
+		}
+		area := labelGraphicRect(graphic, img, input.Dpi)
+		if input.ZPL.StoredGraphics {
+			fmt.Fprintf(zpl, "^FO%d,%d^XG%s,1,1^FS\n", area.Min.X, area.Min.Y, zplGraphicPath(graphic.Name))
+		} else {
+			writeZPLGraphic(zpl, printImg, area, input.ZPL.CompressZ64)
+		}
+	}
+}
+
+// ZPLGraphicDownload returns the ~DG command that stores a label graphic in
+// the printer's flash memory at the printed size for the DPI. Send it once
+// per printer; labels generated with ZPL.StoredGraphics then recall the
+// graphic by name.
+func ZPLGraphicDownload(graphic LabelGraphic, dpi int) (string, error) {
+	if err := validateDPI(dpi); err != nil {
+		return "", err
+	}
+	if err := validateLabelGraphic(graphic); err != nil {
+		return "", err
+	}
+	img, err := rasterizeLabelGraphic(graphic, dpi)
+	if err != nil {
+		return "", err
+	}
+
+	rowBytes, data := packMonochrome(img, img.Bounds())
+	for i := range data {
+		data[i] = ^data[i] // ~DG stores set bits as black
+	}
+	return fmt.Sprintf("~DG%s,%d,%d,%s\n", zplGraphicPath(graphic.Name), len(data), rowBytes, strings.ToUpper(hex.EncodeToString(data))), nil
+}
+
+// zplGraphicPath returns where a label graphic is stored in printer memory
+func zplGraphicPath(name string) string {
+	return "E:" + name + ".GRF"
+}
+
+// writeZPLGraphic writes the area of img as a ^GF graphic field in ASCII hex,
+// or compressed as Z64
+func writeZPLGraphic(zpl *strings.Builder, img *image.RGBA, area image.Rectangle, z64 bool) {
+	area = area.Intersect(img.Bounds())
+	if area.Empty() {
+		return
+	}
+
+	rowBytes, data := packMonochrome(img, area)
+	for i := range data {
+		data[i] = ^data[i] // ^GF prints set bits
+	}
+	encoded := strings.ToUpper(hex.EncodeToString(data))
+	if z64 {
+		encoded = encodeZ64(data)
+	}
+	fmt.Fprintf(zpl, "^FO%d,%d^GFA,%d,%d,%d,%s^FS\n", area.Min.X, area.Min.Y, len(data), len(data), rowBytes, encoded)
+}
+
+// encodeZ64 encodes graphic data as :Z64: followed by the zlib-compressed
+// data in base64 and the CRC-16 of the base64 text, which the printer checks
+// before decompressing
+func encodeZ64(data []byte) string {
+	var compressed bytes.Buffer
+	writer, _ := zlib.NewWriterLevel(&compressed, zlib.BestCompression)
+	writer.Write(data)
+	writer.Close()
+
+	encoded := base64.StdEncoding.EncodeToString(compressed.Bytes())
+	return fmt.Sprintf(":Z64:%s:%04X", encoded, crc16CCITT([]byte(encoded)))
+}
+
+// crc16CCITT computes the CRC-16/XMODEM checksum (polynomial 0x1021, initial
+// value 0) that ZPL uses for compressed graphics
+func crc16CCITT(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// zplFieldData returns the field data and field separator for data. The
+// command prefixes ^ and ~ are hex-escaped with ^FH when present.
+func zplFieldData(data string) string {
+	if !strings.ContainsAny(data, "^~") {
+		return "^FD" + data + "^FS"
+	}
+
+	var escaped strings.Builder
+	escaped.WriteString(`^FH\^FD`)
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; c {
+		case '^', '~', '\\':
+			fmt.Fprintf(&escaped, `\%02X`, c)
+		default:
+			escaped.WriteByte(c)
+		}
+	}
+	escaped.WriteString("^FS")
+	return escaped.String()
+}
@@ -0,0 +1,254 @@
+package barcode
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// TestGenerateBarcode_ZPLNative verifies supported barcodes and text use native commands
+func TestGenerateBarcode_ZPLNative(t *testing.T) {
+	output, err := GenerateBarcode(BarcodeInput{
+		BarcodeData: "LOC-A1-B2-C3",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50,
+		Height:      25,
+		Dpi:         203,
+		ZPL:         ZPLOptions{NativeCommands: true},
+		TextLines:   []TextLine{{Text: "LOC-A1-B2-C3", Position: TextPositionBelow, Size: TextSizeMedium}},
+	})
+	require.NoError(t, err)
+
+	lines := strings.Split(output.ZPL, "\n")
+	require.Len(t, lines, 5)
+	assert.Equal(t, "^XA", lines[0])
+	assert.Regexp(t, `^\^FO\d+,\d+\^BY2,2,\d+\^BCN,\d+,N,N,N,A\^FDLOC-A1-B2-C3\^FS$`, lines[1])
+	assert.Regexp(t, `^\^FO0,\d+\^FB399,1,0,C,0\^A0N,(\d+),(\d+)\^FDLOC-A1-B2-C3\^FS$`, lines[2])
+	assert.Equal(t, []string{"^XZ", ""}, lines[3:])
+	assert.NotContains(t, output.ZPL, "^GF")
+}
+
+// TestGenerateBarcode_ZPLBarcodes verifies the barcode selection for each native type
+func TestGenerateBarcode_ZPLBarcodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    BarcodeInput
+		expected string
+	}{
+		{"ITF", BarcodeInput{BarcodeData: "12345678", BarcodeType: BarcodeTypeITF}, "^B2N,"},
+		{"ISBN", BarcodeInput{BarcodeData: "978-0-306-40615-7", BarcodeType: BarcodeTypeISBN}, ",N,N^FD978030640615^FS"},
+		{"QR", BarcodeInput{BarcodeData: "https://example.com", BarcodeType: BarcodeTypeQR}, "^FDMA,https://example.com^FS"},
+		{"QRLevelH", BarcodeInput{BarcodeData: "https://example.com", BarcodeType: BarcodeTypeQR, QR: QROptions{ErrorCorrection: QRErrorCorrectionH}}, "^FDHA,"},
+		{"QRMinVersion", BarcodeInput{BarcodeData: "https://example.com", BarcodeType: BarcodeTypeQR, QR: QROptions{MinVersion: 10}}, "^GFA,"},
+		{"Code128SetC", BarcodeInput{BarcodeData: "123456", BarcodeType: BarcodeTypeCode128, Code128: Code128Options{CodeSet: Code128CodeSetC}}, "^GFA,"},
+		{"ISBNAddOn", BarcodeInput{BarcodeData: "9780306406157", BarcodeType: BarcodeTypeISBN, ISBN: ISBNOptions{AddOn: "51299"}}, "^GFA,"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input.Width, tt.input.Height, tt.input.Dpi = 50, 30, 203
+			tt.input.ZPL = ZPLOptions{NativeCommands: true}
+			output, err := GenerateBarcode(tt.input)
+			require.NoError(t, err)
+			assert.Contains(t, strings.Split(output.ZPL, "\n")[1], tt.expected)
+		})
+	}
+}
+
+// TestGenerateBarcode_ZPLRaster verifies labels are rasterized unless native
+// commands are requested, available and enabled
+func TestGenerateBarcode_ZPLRaster(t *testing.T) {
+	input := BarcodeInput{BarcodeData: "LOC-A1-B2-C3", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203}
+	raster, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.NotContains(t, raster.ZPL, "^BC")
+
+	input.ZPL.NativeCommands = true
+	disabled, err := (&Generator{Features: Features{DisableNativeZPL: true}}).Generate(input)
+	require.NoError(t, err)
+	assert.Equal(t, raster.ZPL, disabled.ZPL)
+
+	input.Mirror = true
+	mirrored, err := GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.NotContains(t, mirrored.ZPL, "^BC")
+}
+
+// TestGenerateBarcode_ZPLJobSettings verifies job settings are part of native and rasterized label formats
+func TestGenerateBarcode_ZPLJobSettings(t *testing.T) {
+	options := ZPLOptions{Darkness: -5, PrintSpeed: 4, Quantity: 3, HomeXMM: 2, HomeYMM: 1, SetPrintWidth: true, SetLabelLength: true}
+	for _, nativeCommands := range []bool{false, true} {
+		options.NativeCommands = nativeCommands
+		output, err := GenerateBarcode(BarcodeInput{
+			BarcodeData: "LOC-A1-B2-C3",
+			BarcodeType: BarcodeTypeCode128,
+			Width:       50,
+			Height:      25,
+			Dpi:         203,
+			ZPL:         options,
+		})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(output.ZPL, "^XA\n^MD-5\n^PR4\n^LH15,7\n^PW399\n^LL199\n"))
+		assert.True(t, strings.HasSuffix(output.ZPL, "^PQ3\n^XZ\n"))
+	}
+}
+
+// TestZPLSetupCommands_Media verifies print modes and tear-off offsets
+func TestZPLSetupCommands_Media(t *testing.T) {
+	tests := []struct {
+		options  ZPLOptions
+		expected string
+	}{
+		{ZPLOptions{PrintMode: ZPLPrintModeCutter}, "^MMC\n"},
+		{ZPLOptions{PrintMode: ZPLPrintModePresent}, "^MMK\n"},
+		{ZPLOptions{PrintMode: ZPLPrintModePeelOff}, "^MMP\n"},
+		{ZPLOptions{PrintMode: ZPLPrintModeTearOff, TearOffOffset: 12}, "^MMT\n~TA012\n"},
+		{ZPLOptions{TearOffOffset: -5}, "~TA-05\n"},
+	}
+
+	for _, tt := range tests {
+		input := BarcodeInput{Dpi: 203, ZPL: tt.options}
+		assert.Equal(t, tt.expected, zplSetupCommands(input, image.Rect(0, 0, 399, 199)))
+	}
+}
+
+// TestGenerateBarcode_ZPLJobSettingsValidation verifies out of range job settings are rejected
+func TestGenerateBarcode_ZPLJobSettingsValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		options ZPLOptions
+		message string
+	}{
+		{"Darkness", ZPLOptions{Darkness: 31}, "invalid ZPL darkness: 31"},
+		{"PrintSpeed", ZPLOptions{PrintSpeed: 15}, "invalid ZPL print speed: 15"},
+		{"Quantity", ZPLOptions{Quantity: -1}, "invalid ZPL quantity: -1"},
+		{"Home", ZPLOptions{HomeXMM: -1}, "invalid ZPL label home"},
+		{"PrintMode", ZPLOptions{PrintMode: "GUILLOTINE"}, "invalid ZPL print mode"},
+		{"TearOffOffset", ZPLOptions{TearOffOffset: -121}, "invalid ZPL tear-off offset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateBarcode(BarcodeInput{BarcodeData: "LOC-1", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203, ZPL: tt.options})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+// TestGenerateBarcode_ZPLInternationalText verifies Latin-1 text uses font 0 as
+// UTF-8, and other scripts need a downloaded font
+func TestGenerateBarcode_ZPLInternationalText(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-1",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50,
+		Height:      25,
+		Dpi:         203,
+		ZPL:         ZPLOptions{NativeCommands: true},
+		TextLines: []TextLine{
+			{Text: "Größe M", Position: TextPositionAbove, Size: TextSizeMedium},
+			{Text: "倉庫 A1", Position: TextPositionBelow, Size: TextSizeMedium},
+		},
+	}
+	output, err := GenerateBarcode(input)
+	require.NoError(t, err)
+
+	lines := strings.Split(output.ZPL, "\n")
+	require.Len(t, lines, 7)
+	assert.Equal(t, "^CI28", lines[2])
+	assert.Regexp(t, `^\^FO0,\d+\^FB399,1,0,C,0\^A0N,\d+,\d+\^FDGröße M\^FS$`, lines[3])
+	assert.Contains(t, lines[4], "^GFA,")
+
+	input.ZPL.Font = "E:NOTOSANS.TTF"
+	output, err = GenerateBarcode(input)
+	require.NoError(t, err)
+	assert.Contains(t, output.ZPL, ",E:NOTOSANS.TTF^FD倉庫 A1^FS\n")
+	assert.NotContains(t, output.ZPL, "^GF")
+
+	input.ZPL.Font = "NOTOSANS"
+	_, err = GenerateBarcode(input)
+	assert.ErrorContains(t, err, "invalid ZPL font")
+}
+
+// TestZPLFontDownload verifies fonts are stored under their path without the extension
+func TestZPLFontDownload(t *testing.T) {
+	download, err := ZPLFontDownload("E:GOREG.TTF", goregular.TTF)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(download, fmt.Sprintf("~DYE:GOREG,A,T,%d,,%X", len(goregular.TTF), goregular.TTF[:4])))
+
+	_, err = ZPLFontDownload("E:GOREG.TTF", []byte("not a font"))
+	assert.ErrorContains(t, err, "invalid TrueType font")
+	_, err = ZPLFontDownload("GOREG", goregular.TTF)
+	assert.ErrorContains(t, err, "invalid ZPL font")
+}
+
+// TestWriteZPLGraphic verifies dark pixels are set bits and rows are padded white
+func TestWriteZPLGraphic(t *testing.T) {
+	img := createBlankLabel(10, 2)
+	img.Set(0, 0, color.Black)
+	img.Set(9, 1, color.Black)
+
+	var zpl strings.Builder
+	writeZPLGraphic(&zpl, img, image.Rect(0, 0, 20, 2), false)
+	assert.Equal(t, "^FO0,0^GFA,4,4,2,80000040^FS\n", zpl.String())
+}
+
+// TestEncodeZ64 verifies compressed graphics round-trip and carry the CRC of the base64 text
+func TestEncodeZ64(t *testing.T) {
+	data := bytes.Repeat([]byte{0xFF, 0x00, 0x0F}, 200)
+	encoded := encodeZ64(data)
+
+	parts := strings.Split(encoded, ":")
+	require.Len(t, parts, 4)
+	assert.Equal(t, []string{"", "Z64"}, parts[:2])
+	assert.Equal(t, fmt.Sprintf("%04X", crc16CCITT([]byte(parts[2]))), parts[3])
+
+	compressed, err := base64.StdEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	reader, err := zlib.NewReader(bytes.NewReader(compressed))
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, data, decompressed)
+}
+
+// TestCRC16CCITT verifies the checksum against the CRC-16/XMODEM check value
+func TestCRC16CCITT(t *testing.T) {
+	assert.Equal(t, uint16(0x31C3), crc16CCITT([]byte("123456789")))
+}
+
+// TestGenerateBarcode_ZPLZ64 verifies rasterized and native labels send graphics as Z64
+func TestGenerateBarcode_ZPLZ64(t *testing.T) {
+	for _, options := range []ZPLOptions{{CompressZ64: true, Quantity: 2}, {CompressZ64: true, NativeCommands: true}} {
+		output, err := GenerateBarcode(BarcodeInput{
+			BarcodeData: "https://example.com",
+			BarcodeType: BarcodeTypeQR,
+			Width:       50,
+			Height:      30,
+			Dpi:         600,
+			QR:          QROptions{MinVersion: 10},
+			ZPL:         options,
+		})
+		require.NoError(t, err)
+		assert.Regexp(t, `\^GFA,\d+,\d+,\d+,:Z64:[A-Za-z0-9+/=]+:[0-9A-F]{4}\^FS`, output.ZPL)
+		assert.True(t, strings.HasPrefix(output.ZPL, "^XA\n"))
+		assert.True(t, strings.HasSuffix(output.ZPL, "^XZ\n"))
+	}
+}
+
+// TestZPLFieldData verifies command prefixes in field data are hex-escaped
+func TestZPLFieldData(t *testing.T) {
+	assert.Equal(t, "^FDLOC-1^FS", zplFieldData("LOC-1"))
+	assert.Equal(t, `^FH\^FDA\5EB\7EC\5CD^FS`, zplFieldData(`A^B~C\D`))
+}
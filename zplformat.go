@@ -0,0 +1,168 @@
+package barcode
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// zplFormatNamePattern matches stored format paths: a memory device, a name
+// of up to 16 characters and the .ZPL extension
+var zplFormatNamePattern = regexp.MustCompile(`^[RBEA]:[A-Z0-9_-]{1,16}\.ZPL$`)
+
+// ZPLStoredFormat is a label format stored in printer memory with ^DF. The
+// barcode and text lines are variable fields, so each label only sends a
+// short ^XF recall with its field values instead of the whole label.
+type ZPLStoredFormat struct {
+	Name   string // Printer path of the format, e.g. "E:LOCATION.ZPL"
+	Format string // ZPL that stores the format, sent once before recalling it
+
+	sample     BarcodeInput // Input the format was laid out from
+	textFields int          // Number of variable text fields after the barcode
+}
+
+// GenerateZPLStoredFormat lays out the label like GenerateBarcode with native
+// ZPL commands and returns it as a stored format, named e.g. "E:LOCATION.ZPL"
+// to keep it in flash memory across power cycles. The barcode is field ^FN1
+// and the text lines, followed by the text block lines, are ^FN2 onwards.
+//
+// Field positions are fixed by the sample input, so variable data should
+// have the same length as the sample, e.g. serial numbers with a fixed
+// number of digits. The barcode must be one ZPL prints natively (Code128 in
+// the automatic code set, ITF, ISBN/ISSN without add-on, or QR in the
+// automatic mode and version). Text beyond Latin-1 needs ZPL.Font.
+func GenerateZPLStoredFormat(input BarcodeInput, name string) (format *ZPLStoredFormat, err error) {
+	defer recoverToError(&err)
+
+	if !zplFormatNamePattern.MatchString(name) {
+		return nil, errorf(ErrInvalidInput, "invalid ZPL format name: %q. Expected a device, a name of up to 16 characters and .ZPL, e.g. E:LABEL.ZPL", name)
+	}
+	if input, err = applyUnits(input); err != nil {
+		return nil, err
+	}
+	if input, err = applyLabelPreset(input); err != nil {
+		return nil, err
+	}
+	if input, err = applyAutoHeight(input); err != nil {
+		return nil, err
+	}
+	if err := validateInput(input); err != nil {
+		return nil, err
+	}
+	if input.Mirror || input.Rotation != 0 {
+		return nil, errorf(ErrInvalidInput, "mirrored and rotated labels cannot be stored as ZPL formats")
+	}
+	if input.RFID.Data != "" {
+		return nil, errorf(ErrInvalidInput, "RFID data differs per tag and cannot be part of a stored ZPL format")
+	}
+	if input.HumanReadable {
+		return nil, errorf(ErrInvalidInput, "human-readable lines follow the barcode data and cannot be part of a stored ZPL format")
+	}
+	if hasQRLogo(input) {
+		return nil, errorf(ErrInvalidInput, "QR logos are drawn over the symbol and cannot be part of a stored ZPL format")
+	}
+
+	bc, err := encodeBarcode(input)
+	if err != nil {
+		return nil, err
+	}
+	printImg, err := renderLabelImage(input, bc, input.Dpi)
+	if err != nil {
+		return nil, err
+	}
+	printImg, err = zplRasterImage(zplReverseSource(input, printImg), input.ZPL)
+	if err != nil {
+		return nil, err
+	}
+	layout, err := layoutLabel(input, bc, input.Dpi)
+	if err != nil {
+		return nil, err
+	}
+
+	barcodeField, _, ok := zplBarcodeField(input, bc, layout)
+	if !ok {
+		return nil, errorf(ErrInvalidInput, "barcode type %s with these options cannot be a variable ZPL field", input.BarcodeType)
+	}
+
+	var zpl strings.Builder
+	fmt.Fprintf(&zpl, "^XA\n^DF%s^FS\n", name)
+	zpl.WriteString(zplSetupCommands(input, printImg.Bounds()))
+	fmt.Fprintf(&zpl, "%s^FN1^FS\n", barcodeField)
+
+	lines := layoutNativeTextLines(input, printImg, layout)
+	for i, line := range lines {
+		if !zplTextSupported(line.Text, input.ZPL) {
+			return nil, errorf(ErrInvalidInput, "invalid text line for a ZPL format: %q. Text beyond Latin-1 needs ZPL.Font", line.Text)
+		}
+		if line.FontName != "" {
+			return nil, errorf(ErrInvalidInput, "invalid text line for a ZPL format: %q. Registered fonts are not stored in the printer; download the font and use ZPL.Font", line.Text)
+		}
+		if hasRightToLeft(line.Text) {
+			return nil, errorf(ErrInvalidInput, "invalid text line for a ZPL format: %q. Right-to-left text is ordered and shaped during rendering", line.Text)
+		}
+		if isRotatedText(line.TextLine) {
+			return nil, errorf(ErrInvalidInput, "invalid text line for a ZPL format: %q. Rotated lines are printed as graphics", line.Text)
+		}
+		fmt.Fprintf(&zpl, "%s^FN%d^FS\n", zplTextField(line, input.Dpi, layout.width, input.ZPL), i+2)
+	}
+	writeZPLLabelBarcodes(&zpl, input, printImg)
+	writeZPLLabelShapes(&zpl, input, printImg)
+	writeZPLLabelGraphics(&zpl, input, printImg)
+	writeZPLReverseRegions(&zpl, input)
+	zpl.WriteString("^XZ\n")
+
+	return &ZPLStoredFormat{Name: name, Format: zpl.String(), sample: input, textFields: len(lines)}, nil
+}
+
+// Recall returns the ZPL that prints one label from the stored format with
+// the given barcode data and text, one value per text field in order. The
+// barcode data is validated and check digits are added as for
+// GenerateBarcode.
+func (f *ZPLStoredFormat) Recall(barcodeData string, text ...string) (zpl string, err error) {
+	defer recoverToError(&err)
+
+	if len(text) != f.textFields {
+		return "", errorf(ErrInvalidInput, "invalid number of text fields: %d. The format %s has %d", len(text), f.Name, f.textFields)
+	}
+	utf8 := false
+	for _, value := range text {
+		if !zplTextSupported(value, f.sample.ZPL) {
+			return "", errorf(ErrInvalidInput, "invalid text field: %q. Text must be printable, and beyond Latin-1 needs ZPL.Font", value)
+		}
+		if hasRightToLeft(value) {
+			return "", errorf(ErrInvalidInput, "invalid text field: %q. Right-to-left text is ordered and shaped during rendering and cannot be recalled", value)
+		}
+		utf8 = utf8 || !isPrintableASCII(value)
+	}
+
+	input := f.sample
+	input.BarcodeData, input.BarcodeDataBytes = barcodeData, nil
+	if err := validateBarcodeData(input); err != nil {
+		return "", err
+	}
+	bc, err := encodeBarcode(input)
+	if err != nil {
+		return "", err
+	}
+	layout, err := layoutLabel(input, bc, input.Dpi)
+	if err != nil {
+		return "", err
+	}
+	_, data, ok := zplBarcodeField(input, bc, layout)
+	if !ok {
+		return "", errorf(ErrInvalidData, "invalid barcode data for the format %s: %q", f.Name, barcodeData)
+	}
+
+	var recall strings.Builder
+	recall.WriteString("^XA\n")
+	if utf8 {
+		recall.WriteString("^CI28\n")
+	}
+	fmt.Fprintf(&recall, "^XF%s^FS\n^FN1%s\n", f.Name, zplFieldData(data))
+	for i, value := range text {
+		fmt.Fprintf(&recall, "^FN%d%s\n", i+2, zplFieldData(value))
+	}
+	recall.WriteString(zplQuantityCommand(input.ZPL))
+	recall.WriteString("^XZ\n")
+	return recall.String(), nil
+}
@@ -0,0 +1,119 @@
+package barcode
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateZPLStoredFormat verifies the barcode and text lines become numbered variable fields
+func TestGenerateZPLStoredFormat(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "SN-000001",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50,
+		Height:      25,
+		Dpi:         203,
+		ZPL:         ZPLOptions{Darkness: 5, Quantity: 2},
+		TextLines:   []TextLine{{Text: "SN-000001", Position: TextPositionBelow, Size: TextSizeMedium}},
+	}
+	format, err := GenerateZPLStoredFormat(input, "E:SERIAL.ZPL")
+	require.NoError(t, err)
+
+	bc, err := encodeBarcode(input)
+	require.NoError(t, err)
+	layout, err := layoutLabel(input, bc, input.Dpi)
+	require.NoError(t, err)
+	_, moduleWidth, ok := nativeModuleLayout(bc, layout)
+	require.True(t, ok)
+
+	lines := strings.Split(format.Format, "\n")
+	require.Len(t, lines, 7)
+	assert.Equal(t, []string{"^XA", "^DFE:SERIAL.ZPL^FS", "^MD5"}, lines[:3])
+	assert.Regexp(t, fmt.Sprintf(`^\^FO\d+,\d+\^BY%d,2,\d+\^BCN,\d+,N,N,N,A\^FN1\^FS$`, moduleWidth), lines[3])
+	assert.Regexp(t, `^\^FO0,\d+\^FB399,1,0,C,0\^A0N,\d+,\d+\^FN2\^FS$`, lines[4])
+	assert.Equal(t, []string{"^XZ", ""}, lines[5:])
+	assert.NotContains(t, format.Format, "SN-000001")
+
+	recall, err := format.Recall("SN-000002", "SN-000002")
+	require.NoError(t, err)
+	assert.Equal(t, "^XA\n^XFE:SERIAL.ZPL^FS\n^FN1^FDSN-000002^FS\n^FN2^FDSN-000002^FS\n^PQ2\n^XZ\n", recall)
+}
+
+// TestGenerateZPLStoredFormat_CheckDigits verifies recalled data is encoded like a generated label
+func TestGenerateZPLStoredFormat_CheckDigits(t *testing.T) {
+	format, err := GenerateZPLStoredFormat(BarcodeInput{
+		BarcodeData: "1234567",
+		BarcodeType: BarcodeTypeITF,
+		Width:       50,
+		Height:      25,
+		Dpi:         203,
+		ITF:         ITFOptions{CheckDigit: true},
+	}, "R:TOTE.ZPL")
+	require.NoError(t, err)
+
+	recall, err := format.Recall("7654321")
+	require.NoError(t, err)
+	assert.Contains(t, recall, "^FN1^FD76543210^FS")
+
+	_, err = format.Recall("ABC")
+	assert.Error(t, err)
+}
+
+// TestGenerateZPLStoredFormat_Errors verifies labels that cannot be stored formats are rejected
+func TestGenerateZPLStoredFormat_Errors(t *testing.T) {
+	valid := BarcodeInput{BarcodeData: "LOC-1", BarcodeType: BarcodeTypeCode128, Width: 50, Height: 25, Dpi: 203}
+
+	tests := []struct {
+		name    string
+		modify  func(input *BarcodeInput)
+		format  string
+		message string
+	}{
+		{"Name", func(input *BarcodeInput) {}, "LABEL", "invalid ZPL format name"},
+		{"Mirror", func(input *BarcodeInput) { input.Mirror = true }, "R:LABEL.ZPL", "mirrored"},
+		{"Barcode", func(input *BarcodeInput) { input.Code128.CodeSet = Code128CodeSetB }, "R:LABEL.ZPL", "cannot be a variable ZPL field"},
+		{"Text", func(input *BarcodeInput) {
+			input.TextLines = []TextLine{{Text: "倉庫 A1", Position: TextPositionBelow, Size: TextSizeMedium}}
+		}, "R:LABEL.ZPL", "needs ZPL.Font"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := valid
+			tt.modify(&input)
+			_, err := GenerateZPLStoredFormat(input, tt.format)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+
+	format, err := GenerateZPLStoredFormat(valid, "R:LABEL.ZPL")
+	require.NoError(t, err)
+	_, err = format.Recall("LOC-2", "unexpected")
+	assert.Error(t, err)
+}
+
+// TestZPLStoredFormat_RecallUTF8 verifies text beyond ASCII is recalled as UTF-8
+func TestZPLStoredFormat_RecallUTF8(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-1",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50,
+		Height:      25,
+		Dpi:         203,
+		TextLines:   []TextLine{{Text: "Lager", Position: TextPositionBelow, Size: TextSizeMedium}},
+	}
+	format, err := GenerateZPLStoredFormat(input, "R:LABEL.ZPL")
+	require.NoError(t, err)
+
+	recall, err := format.Recall("LOC-2", "Größe")
+	require.NoError(t, err)
+	assert.Equal(t, "^XA\n^CI28\n^XFR:LABEL.ZPL^FS\n^FN1^FDLOC-2^FS\n^FN2^FDGröße^FS\n^XZ\n", recall)
+
+	_, err = format.Recall("LOC-2", "倉庫")
+	assert.Error(t, err)
+}
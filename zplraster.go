@@ -0,0 +1,88 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// ZPLRasterMode selects how grey pixels, such as anti-aliased text edges,
+// become dots in ZPL graphics
+type ZPLRasterMode string
+
+const (
+	ZPLRasterThreshold      ZPLRasterMode = "THRESHOLD"       // Pixels darker than RasterThreshold print
+	ZPLRasterFloydSteinberg ZPLRasterMode = "FLOYD_STEINBERG" // Error diffusion, for shaded artwork and photos
+	ZPLRasterBilevel        ZPLRasterMode = "BILEVEL"         // Grey pixels are an error instead of being converted
+)
+
+// defaultZPLRasterThreshold is the grey level below which pixels print in
+// THRESHOLD mode
+const defaultZPLRasterThreshold = 128
+
+// zplRasterImage converts the print image to black and white for ZPL
+// graphics as the options ask, so every graphic converts the same way.
+// Transparent pixels are treated as white.
+func zplRasterImage(img *image.RGBA, options ZPLOptions) (*image.RGBA, error) {
+	bounds := img.Bounds()
+	gray := make([]float64, bounds.Dx()*bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			white := 255 - c.A // Premultiplied colors over a white background
+			level := color.GrayModel.Convert(color.RGBA{R: c.R + white, G: c.G + white, B: c.B + white, A: 255}).(color.Gray).Y
+			if options.RasterMode == ZPLRasterBilevel && level != 0 && level != 255 {
+				return nil, fmt.Errorf("invalid ZPL graphic: the pixel at %d,%d is grey (%d). %s graphics must be black and white; use %s or %s to convert grey pixels",
+					x, y, level, ZPLRasterBilevel, ZPLRasterThreshold, ZPLRasterFloydSteinberg)
+			}
+			gray[(y-bounds.Min.Y)*bounds.Dx()+x-bounds.Min.X] = float64(level)
+		}
+	}
+
+	threshold := float64(defaultZPLRasterThreshold)
+	if options.RasterThreshold != 0 {
+		threshold = float64(options.RasterThreshold)
+	}
+
+	bilevel := image.NewRGBA(bounds)
+	width, height := bounds.Dx(), bounds.Dy()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			level := gray[y*width+x]
+			dark := level < threshold
+			c := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			if dark {
+				c = color.RGBA{A: 255}
+			}
+			bilevel.SetRGBA(bounds.Min.X+x, bounds.Min.Y+y, c)
+
+			if options.RasterMode != ZPLRasterFloydSteinberg {
+				continue
+			}
+			// Spread the error to the unvisited neighbours in 7/16, 3/16,
+			// 5/16 and 1/16 shares
+			err := level - float64(c.R)
+			for _, share := range []struct{ dx, dy, weight int }{{1, 0, 7}, {-1, 1, 3}, {0, 1, 5}, {1, 1, 1}} {
+				nx, ny := x+share.dx, y+share.dy
+				if nx >= 0 && nx < width && ny < height {
+					gray[ny*width+nx] += err * float64(share.weight) / 16
+				}
+			}
+		}
+	}
+	return bilevel, nil
+}
+
+// validateZPLRasterOptions ensures the raster mode is known and the threshold
+// is a grey level
+func validateZPLRasterOptions(options ZPLOptions) error {
+	switch options.RasterMode {
+	case "", ZPLRasterThreshold, ZPLRasterFloydSteinberg, ZPLRasterBilevel:
+	default:
+		return fmt.Errorf("invalid ZPL raster mode: %q. Supported modes are %s, %s and %s", options.RasterMode, ZPLRasterThreshold, ZPLRasterFloydSteinberg, ZPLRasterBilevel)
+	}
+	if options.RasterThreshold < 0 || options.RasterThreshold > 255 {
+		return fmt.Errorf("invalid ZPL raster threshold: %d. Must be between 1 and 255", options.RasterThreshold)
+	}
+	return nil
+}
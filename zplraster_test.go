@@ -0,0 +1,93 @@
+package barcode
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestZPLRasterImage_Threshold verifies grey pixels print below the threshold
+// and transparent pixels are white
+func TestZPLRasterImage_Threshold(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 1))
+	img.SetRGBA(0, 0, color.RGBA{R: 100, G: 100, B: 100, A: 255})
+	img.SetRGBA(1, 0, color.RGBA{R: 200, G: 200, B: 200, A: 255})
+
+	bilevel, err := zplRasterImage(img, ZPLOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{A: 255}, bilevel.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, bilevel.RGBAAt(1, 0))
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, bilevel.RGBAAt(2, 0), "transparent")
+
+	bilevel, err = zplRasterImage(img, ZPLOptions{RasterMode: ZPLRasterThreshold, RasterThreshold: 90})
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, bilevel.RGBAAt(0, 0))
+
+	bilevel, err = zplRasterImage(img, ZPLOptions{RasterThreshold: 220})
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{A: 255}, bilevel.RGBAAt(1, 0))
+}
+
+// TestZPLRasterImage_FloydSteinberg verifies a mid grey area dithers to about
+// half its dots, where a threshold makes it all white
+func TestZPLRasterImage_FloydSteinberg(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	draw.Draw(img, img.Bounds(), &image.Uniform{color.RGBA{R: 140, G: 140, B: 140, A: 255}}, image.Point{}, draw.Src)
+
+	countDark := func(img *image.RGBA) int {
+		dark := 0
+		for i := 0; i < len(img.Pix); i += 4 {
+			if img.Pix[i] == 0 {
+				dark++
+			}
+		}
+		return dark
+	}
+
+	thresholded, err := zplRasterImage(img, ZPLOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, countDark(thresholded))
+
+	dithered, err := zplRasterImage(img, ZPLOptions{RasterMode: ZPLRasterFloydSteinberg})
+	require.NoError(t, err)
+	assert.InDelta(t, 32*32*(255-140)/255, countDark(dithered), 16)
+}
+
+// TestZPLRasterImage_Bilevel verifies black and white images pass unchanged
+// and anti-aliased text is rejected
+func TestZPLRasterImage_Bilevel(t *testing.T) {
+	img := createBlankLabel(4, 4)
+	img.SetRGBA(1, 1, color.RGBA{A: 255})
+	bilevel, err := zplRasterImage(img, ZPLOptions{RasterMode: ZPLRasterBilevel})
+	require.NoError(t, err)
+	assert.Equal(t, img.Pix, bilevel.Pix)
+
+	input := BarcodeInput{
+		BarcodeData:   "LOC-A1-B2-C3",
+		BarcodeType:   BarcodeTypeCode128,
+		Width:         50,
+		Height:        25,
+		Dpi:           203,
+		TextLines:     []TextLine{{Text: "LOC-A1-B2-C3", Position: TextPositionBelow, Size: TextSizeSmall}},
+		OutputFormats: []OutputFormat{OutputFormatZPL},
+		ZPL:           ZPLOptions{RasterMode: ZPLRasterBilevel},
+	}
+	_, err = GenerateBarcode(input)
+	assert.ErrorContains(t, err, "invalid ZPL graphic: the pixel at")
+
+	input.TextLines = nil
+	_, err = GenerateBarcode(input)
+	assert.NoError(t, err)
+}
+
+// TestValidateZPLRasterOptions verifies unknown modes and thresholds outside
+// the grey levels are rejected
+func TestValidateZPLRasterOptions(t *testing.T) {
+	assert.NoError(t, validateZPLRasterOptions(ZPLOptions{RasterMode: ZPLRasterFloydSteinberg, RasterThreshold: 100}))
+	assert.ErrorContains(t, validateZPLRasterOptions(ZPLOptions{RasterMode: "ATKINSON"}), "Supported modes are THRESHOLD, FLOYD_STEINBERG and BILEVEL")
+	assert.ErrorContains(t, validateZPLRasterOptions(ZPLOptions{RasterThreshold: 256}), "Must be between 1 and 255")
+}
@@ -0,0 +1,310 @@
+package barcode
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// zplCommand is one ZPL command with its arguments
+type zplCommand struct {
+	name   string // Prefix and name in capitals, e.g. "^FO" or "~DG"
+	args   string // Everything up to the next command
+	offset int    // Byte offset of the command in the data
+}
+
+// zplLabelState tracks the settings and open field of a label format
+// while it is checked
+type zplLabelState struct {
+	printWidth, labelLength int // Zero when not declared
+	homeX, homeY            int
+	origin                  *zplCommand // Field origin of the open field
+}
+
+// ValidateZPL checks ZPL before it is sent to a printer, which silently
+// truncates or skips what it cannot print. It reports label formats without
+// ^XZ, fields without ^FS, graphics whose data does not match their declared
+// byte count, and fields and graphics beyond the declared ^PW and ^LL. All
+// problems found are returned together.
+func ValidateZPL(zpl string) error {
+	commands, err := tokenizeZPL(zpl)
+	if err != nil {
+		return err
+	}
+
+	var problems []error
+	var label *zplLabelState
+	for i := range commands {
+		command := &commands[i]
+		if command.name == "~DG" {
+			problems = append(problems, validateZPLDownloadGraphic(command)...)
+			continue
+		}
+		if command.name == "^XA" {
+			if label != nil {
+				problems = append(problems, fmt.Errorf("invalid ZPL: ^XA at offset %d inside an open label format", command.offset))
+			}
+			label = &zplLabelState{}
+			continue
+		}
+		if label == nil {
+			if strings.HasPrefix(command.name, "^") {
+				problems = append(problems, fmt.Errorf("invalid ZPL: %s at offset %d outside a label format", command.name, command.offset))
+			}
+			continue
+		}
+		if command.name == "^XZ" {
+			if label.origin != nil {
+				problems = append(problems, fmt.Errorf("invalid ZPL: field at offset %d is not closed with ^FS", label.origin.offset))
+			}
+			label = nil
+			continue
+		}
+		problems = append(problems, label.check(command)...)
+	}
+	if label != nil {
+		problems = append(problems, errors.New("invalid ZPL: label format not terminated with ^XZ"))
+	}
+	return errors.Join(problems...)
+}
+
+// check applies one command within a label format to the state and returns
+// the problems it has
+func (label *zplLabelState) check(command *zplCommand) []error {
+	args := zplArgs(command.args)
+	switch command.name {
+	case "^PW":
+		label.printWidth = args.int(0)
+	case "^LL":
+		label.labelLength = args.int(0)
+	case "^LH":
+		label.homeX, label.homeY = args.int(0), args.int(1)
+	case "^FO", "^FT":
+		if label.origin != nil {
+			return []error{fmt.Errorf("invalid ZPL: field at offset %d is not closed with ^FS", label.origin.offset)}
+		}
+		label.origin = command
+		x, y := label.homeX+args.int(0), label.homeY+args.int(1)
+		if (label.printWidth > 0 && x >= label.printWidth) || (label.labelLength > 0 && y >= label.labelLength) {
+			return []error{fmt.Errorf("invalid ZPL: field at %d,%d (offset %d) is outside the %dx%d label", x, y, command.offset, label.printWidth, label.labelLength)}
+		}
+	case "^FS":
+		label.origin = nil
+	case "^GF":
+		problems := validateZPLGraphicData(command, args)
+		if label.origin != nil {
+			problems = append(problems, label.checkGraphicExtent(command, args)...)
+		}
+		return problems
+	}
+	return nil
+}
+
+// checkGraphicExtent reports graphics reaching beyond the print width or
+// label length. Rows are padded to whole bytes, so up to 7 dots of padding
+// may extend beyond the print width.
+func (label *zplLabelState) checkGraphicExtent(command *zplCommand, args zplArgs) []error {
+	total, rowBytes := args.int(2), args.int(3)
+	if rowBytes <= 0 {
+		return nil
+	}
+	origin := zplArgs(label.origin.args)
+	x, y := label.homeX+origin.int(0), label.homeY+origin.int(1)
+
+	var problems []error
+	if label.printWidth > 0 && x+rowBytes*8-7 > label.printWidth {
+		problems = append(problems, fmt.Errorf("invalid ZPL: graphic at offset %d is %d dots wide at x=%d, beyond the print width of %d", command.offset, rowBytes*8, x, label.printWidth))
+	}
+	if rows := total / rowBytes; label.labelLength > 0 && y+rows > label.labelLength {
+		problems = append(problems, fmt.Errorf("invalid ZPL: graphic at offset %d is %d rows high at y=%d, beyond the label length of %d", command.offset, rows, y, label.labelLength))
+	}
+	return problems
+}
+
+// validateZPLGraphicData checks that the data of a ^GF graphic field holds
+// the declared number of bytes, in whole rows
+func validateZPLGraphicData(command *zplCommand, args zplArgs) []error {
+	format, total, rowBytes := args.string(0), args.int(2), args.int(3)
+	if total <= 0 || rowBytes <= 0 || total%rowBytes != 0 {
+		return []error{fmt.Errorf("invalid ZPL: graphic at offset %d declares %d bytes in rows of %d", command.offset, total, rowBytes)}
+	}
+	if format != "A" {
+		return nil // Binary data was skipped by byte count while tokenizing
+	}
+	return checkZPLGraphicBytes(command, args.rest(4), total, rowBytes)
+}
+
+// validateZPLDownloadGraphic checks that a ~DG download holds the declared
+// number of bytes
+func validateZPLDownloadGraphic(command *zplCommand) []error {
+	args := zplArgs(command.args)
+	total, rowBytes := args.int(1), args.int(2)
+	if total <= 0 || rowBytes <= 0 || total%rowBytes != 0 {
+		return []error{fmt.Errorf("invalid ZPL: graphic download at offset %d declares %d bytes in rows of %d", command.offset, total, rowBytes)}
+	}
+	return checkZPLGraphicBytes(command, args.rest(3), total, rowBytes)
+}
+
+// checkZPLGraphicBytes decodes ASCII graphic data, plain or compressed hex
+// or Z64, and compares its length with the declared byte count
+func checkZPLGraphicBytes(command *zplCommand, data string, total, rowBytes int) []error {
+	var length int
+	var err error
+	if strings.HasPrefix(data, ":Z64:") || strings.HasPrefix(data, ":B64:") {
+		length, err = zplBase64GraphicLength(data)
+	} else {
+		length, err = zplCompressedASCIILength(data, rowBytes)
+	}
+	if err != nil {
+		return []error{fmt.Errorf("invalid ZPL: graphic at offset %d: %w", command.offset, err)}
+	}
+	if length != total {
+		return []error{fmt.Errorf("invalid ZPL: graphic at offset %d declares %d bytes but its data holds %d", command.offset, total, length)}
+	}
+	return nil
+}
+
+// zplBase64GraphicLength checks the CRC of :Z64: or :B64: data and returns
+// the number of bytes it decodes to
+func zplBase64GraphicLength(data string) (int, error) {
+	parts := strings.Split(strings.TrimSpace(data), ":")
+	if len(parts) != 4 {
+		return 0, errors.New("base64 data must be followed by a CRC")
+	}
+	encoded := parts[2]
+	if crc, err := strconv.ParseUint(parts[3], 16, 16); err != nil || uint16(crc) != crc16CCITT([]byte(encoded)) {
+		return 0, fmt.Errorf("CRC %q does not match the data", parts[3])
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, fmt.Errorf("malformed base64 data: %w", err)
+	}
+	if parts[1] == "B64" {
+		return len(decoded), nil
+	}
+
+	reader, err := zlib.NewReader(bytes.NewReader(decoded))
+	if err != nil {
+		return 0, fmt.Errorf("malformed Z64 data: %w", err)
+	}
+	length, err := io.Copy(io.Discard, reader)
+	if err != nil {
+		return 0, fmt.Errorf("malformed Z64 data: %w", err)
+	}
+	return int(length), nil
+}
+
+// zplCompressedASCIILength returns the number of bytes hex graphic data
+// holds, expanding ZPL's ASCII compression: G-Y repeat the next digit 1-19
+// times and g-z 20-400 times, a comma fills the rest of the row with zeros,
+// an exclamation mark with ones, and a colon repeats the previous row.
+func zplCompressedASCIILength(data string, rowBytes int) (int, error) {
+	rowDigits := rowBytes * 2
+	digits, count := 0, 0
+	for _, c := range data {
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			continue
+		case c >= 'G' && c <= 'Y':
+			count += int(c-'G') + 1
+			continue
+		case c >= 'g' && c <= 'z':
+			count += (int(c-'g') + 1) * 20
+			continue
+		case c >= '0' && c <= '9', c >= 'A' && c <= 'F', c >= 'a' && c <= 'f':
+			digits += max(count, 1)
+		case c == ',' || c == '!':
+			digits += rowDigits - digits%rowDigits
+		case c == ':':
+			if digits == 0 || digits%rowDigits != 0 {
+				return 0, errors.New("row repeat must follow a complete row")
+			}
+			digits += rowDigits
+		default:
+			return 0, fmt.Errorf("unexpected character %q in graphic data", c)
+		}
+		count = 0
+	}
+	return digits / 2, nil
+}
+
+// tokenizeZPL splits data into commands. Binary ^GF graphic data is skipped
+// by its declared byte count, since it may contain the command prefixes.
+func tokenizeZPL(data string) ([]zplCommand, error) {
+	var commands []zplCommand
+	start := strings.IndexAny(data, "^~")
+	for start >= 0 {
+		if start+2 >= len(data) {
+			return nil, fmt.Errorf("invalid ZPL: truncated command at offset %d", start)
+		}
+		command := zplCommand{name: strings.ToUpper(data[start : start+3]), offset: start}
+
+		end := start + 3
+		if command.name == "^GF" {
+			end = zplBinaryGraphicEnd(data, end)
+		}
+		next := strings.IndexAny(data[end:], "^~")
+		if next < 0 {
+			next = len(data) - end
+		}
+		command.args = data[start+3 : end+next]
+		commands = append(commands, command)
+
+		if end+next >= len(data) {
+			break
+		}
+		start = end + next
+	}
+	return commands, nil
+}
+
+// zplBinaryGraphicEnd returns the end of binary ^GF data starting after the
+// command name at start, or start for ASCII data
+func zplBinaryGraphicEnd(data string, start int) int {
+	header := data[start:]
+	if len(header) == 0 || (header[0] != 'B' && header[0] != 'C') {
+		return start
+	}
+	fields := strings.SplitN(header, ",", 5)
+	if len(fields) < 5 {
+		return start
+	}
+	size, err := strconv.Atoi(strings.TrimSpace(fields[1]))
+	if err != nil {
+		return start
+	}
+	dataStart := start + len(header) - len(fields[4])
+	return min(len(data), dataStart+size)
+}
+
+// zplArgs are the comma-separated arguments of a command
+type zplArgs string
+
+// string returns argument i, trimmed
+func (a zplArgs) string(i int) string {
+	fields := strings.Split(string(a), ",")
+	if i >= len(fields) {
+		return ""
+	}
+	return strings.TrimSpace(fields[i])
+}
+
+// int returns argument i as a number, or 0 when it is missing or invalid
+func (a zplArgs) int(i int) int {
+	n, _ := strconv.Atoi(a.string(i))
+	return n
+}
+
+// rest returns everything from argument i on, including later commas
+func (a zplArgs) rest(i int) string {
+	fields := strings.SplitN(string(a), ",", i+1)
+	if i >= len(fields) {
+		return ""
+	}
+	return strings.TrimSpace(fields[i])
+}
@@ -0,0 +1,109 @@
+package barcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidateZPL_Generated verifies generated labels, stored formats and downloads pass validation
+func TestValidateZPL_Generated(t *testing.T) {
+	input := BarcodeInput{
+		BarcodeData: "LOC-A1-B2-C3",
+		BarcodeType: BarcodeTypeCode128,
+		Width:       50,
+		Height:      25,
+		Dpi:         203,
+		TextLines:   []TextLine{{Text: "LOC-A1-B2-C3", Position: TextPositionBelow, Size: TextSizeMedium}},
+		Graphics:    []LabelGraphic{{Name: "LOGO", PNG: testLogoPNG(t), XMM: 1, YMM: 1, WidthMM: 4}},
+	}
+
+	tests := []struct {
+		name    string
+		options ZPLOptions
+	}{
+		{"Raster", ZPLOptions{}},
+		{"RasterSettings", ZPLOptions{Darkness: 5, SetPrintWidth: true, SetLabelLength: true, Quantity: 3}},
+		{"Native", ZPLOptions{NativeCommands: true, SetPrintWidth: true, SetLabelLength: true}},
+		{"Z64", ZPLOptions{CompressZ64: true, SetPrintWidth: true, SetLabelLength: true}},
+		{"StoredGraphics", ZPLOptions{NativeCommands: true, StoredGraphics: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input.ZPL = tt.options
+			output, err := GenerateBarcode(input)
+			require.NoError(t, err)
+			assert.NoError(t, ValidateZPL(output.ZPL))
+		})
+	}
+
+	input.ZPL = ZPLOptions{}
+	format, err := GenerateZPLStoredFormat(input, "E:LABEL.ZPL")
+	require.NoError(t, err)
+	assert.NoError(t, ValidateZPL(format.Format))
+	recall, err := format.Recall("LOC-A1-B2-C4", "LOC-A1-B2-C4")
+	require.NoError(t, err)
+	assert.NoError(t, ValidateZPL(recall))
+
+	download, err := ZPLGraphicDownload(input.Graphics[0], 203)
+	require.NoError(t, err)
+	assert.NoError(t, ValidateZPL(download))
+}
+
+// TestValidateZPL_Errors verifies malformed labels are reported
+func TestValidateZPL_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		zpl     string
+		message string
+	}{
+		{"MissingXZ", "^XA^FO10,10^FDONE^FS", "not terminated with ^XZ"},
+		{"NestedXA", "^XA^XA^XZ", "inside an open label format"},
+		{"OutsideLabel", "^FO10,10^FDONE^FS", "outside a label format"},
+		{"UnclosedField", "^XA^FO10,10^FDONE^FO20,20^FDTWO^FS^XZ", "not closed with ^FS"},
+		{"UnclosedFieldAtXZ", "^XA^FO10,10^FDONE^XZ", "not closed with ^FS"},
+		{"ByteCount", "^XA^FO0,0^GFA,4,4,2,FF00FF^FS^XZ", "declares 4 bytes but its data holds 3"},
+		{"PartialRows", "^XA^FO0,0^GFA,5,5,2,FF00FF00FF^FS^XZ", "in rows of 2"},
+		{"RowFill", "^XA^FO0,0^GFA,4,4,2,FF00FF,00^FS^XZ", "declares 4 bytes but its data holds 5"},
+		{"RowRepeat", "^XA^FO0,0^GFA,4,4,2,FF:^FS^XZ", "row repeat must follow a complete row"},
+		{"Character", "^XA^FO0,0^GFA,2,2,2,FF-0^FS^XZ", "unexpected character"},
+		{"Z64CRC", "^XA^FO0,0^GFA,2,2,2,:Z64:eJz7/x8AAv4B/g==:0000^FS^XZ", "CRC"},
+		{"Download", "~DGE:LOGO.GRF,4,2,FF00", "declares 4 bytes but its data holds 2"},
+		{"FieldBeyondWidth", "^XA^PW100^FO120,0^FDONE^FS^XZ", "outside the 100x0 label"},
+		{"FieldBeyondHome", "^XA^LH50,0^PW100^FO60,0^FDONE^FS^XZ", "is outside"},
+		{"GraphicBeyondWidth", "^XA^PW20^FO8,0^GFA,3,3,3,FFFFFF^FS^XZ", "beyond the print width of 20"},
+		{"GraphicBeyondLength", "^XA^LL2^FO0,0^GFA,3,3,1,FFFFFF^FS^XZ", "beyond the label length of 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateZPL(tt.zpl)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+// TestValidateZPL_GraphicData verifies compressed, padded and binary graphic data are measured correctly
+func TestValidateZPL_GraphicData(t *testing.T) {
+	tests := []struct {
+		name string
+		zpl  string
+	}{
+		{"CompressedRepeat", "^XA^FO0,0^GFA,24,24,8,NF\nN0\ngIF,^FS^XZ"},
+		{"RowFill", "^XA^FO0,0^GFA,8,8,4,FF,\n!\n^FS^XZ"},
+		{"RowRepeat", "^XA^FO0,0^GFA,12,12,4,FF00FF00\n:\n:\n^FS^XZ"},
+		{"Z64", "^XA^FO0,0^GFA,4,4,2," + encodeZ64([]byte{0xFF, 0, 0xFF, 0}) + "^FS^XZ"},
+		{"Binary", "^XA^FO0,0^GFB,4,4,2,^~^~^FS^XZ"},
+		{"PaddedRow", "^XA^PW20^FO0,0^GFA,3,3,3,FFFFF0^FS^XZ"},
+		{"StrayFS", "^XA,^FS\n^FO0,0\n^GFA,2,2,2,\nFFFF\n^FS\n^XZ\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, ValidateZPL(tt.zpl))
+		})
+	}
+}